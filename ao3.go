@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/cbrasser/cozy/ebook"
+)
+
+// runAO3 saves works from Archive of Our Own into the library, or checks
+// the saved works in progress for new chapters
+func runAO3(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("ao3", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: cozy ao3 work-url-or-id... | cozy ao3 check")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("no works given")
+	}
+	if flags.NArg() == 1 && flags.Arg(0) == "check" {
+		updated, err := ebook.CheckAO3Works(cfg)
+		for _, work := range updated {
+			fmt.Printf("new chapters in %s: %d posted -> %s\n", work.Title, work.Chapters, work.Path)
+		}
+		if err == nil && len(updated) == 0 {
+			fmt.Println("no new chapters")
+		}
+		return err
+	}
+
+	failed := 0
+	for _, work := range flags.Args() {
+		saved, err := ebook.SaveAO3Work(cfg, work)
+		switch {
+		case errors.Is(err, ebook.ErrDuplicate):
+			fmt.Printf("skipped %s: already in the library as %s\n", work, saved.Path)
+		case err != nil:
+			fmt.Printf("failed %s: %v\n", work, err)
+			failed++
+		default:
+			fmt.Printf("saved %s -> %s\n", saved.Title, saved.Path)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d works could not be saved", failed, flags.NArg())
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// AO3Work is a work saved from Archive of Our Own, kept to check it for
+// new chapters
+type AO3Work struct {
+	ID            string    `json:"id"`
+	Title         string    `json:"title"`
+	Path          string    `json:"path"`           // The saved EPUB
+	Chapters      int       `json:"chapters"`       // Chapters posted when it was saved
+	TotalChapters int       `json:"total_chapters"` // Planned chapters, 0 if the author hasn't said
+	Updated       string    `json:"updated"`        // Date of the last update, as AO3 shows it
+	Checked       time.Time `json:"checked"`
+}
+
+// Complete reports whether all the planned chapters of a work are posted
+func (w AO3Work) Complete() bool {
+	return w.TotalChapters > 0 && w.Chapters >= w.TotalChapters
+}
+
+// AO3Data stores the works saved from AO3
+type AO3Data struct {
+	Works     map[string]AO3Work `json:"works"` // Key is the work id
+	LastCheck time.Time          `json:"last_check"`
+}
+
+// AO3Path returns the folder works from AO3 go in, or "" if there is no
+// library to put them in
+func (c *Config) AO3Path() string {
+	return c.libraryFolder(c.AO3.Folder)
+}
+
+// AO3CheckDue reports whether it is time to check the works in progress
+// for new chapters
+func (c *Config) AO3CheckDue(works *AO3Data) bool {
+	return c.AO3.CheckHours > 0 && time.Since(works.LastCheck) >= time.Duration(c.AO3.CheckHours)*time.Hour
+}
+
+// InProgress returns the works that may still get new chapters
+func (d *AO3Data) InProgress() []AO3Work {
+	var works []AO3Work
+	for _, work := range d.Works {
+		if !work.Complete() {
+			works = append(works, work)
+		}
+	}
+	return works
+}
+
+// LoadAO3 loads the saved works from the data directory
+func LoadAO3(cfg *Config) (*AO3Data, error) {
+	ao3Path := filepath.Join(cfg.DataDirectory(), "ao3.json")
+
+	// If file doesn't exist, return no works
+	if _, err := os.Stat(ao3Path); os.IsNotExist(err) {
+		return &AO3Data{Works: make(map[string]AO3Work)}, nil
+	}
+
+	data, err := os.ReadFile(ao3Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read AO3 file: %w", err)
+	}
+
+	var works AO3Data
+	if err := json.Unmarshal(data, &works); err != nil {
+		return nil, fmt.Errorf("failed to parse AO3 file: %w", err)
+	}
+	if works.Works == nil {
+		works.Works = make(map[string]AO3Work)
+	}
+	return &works, nil
+}
+
+// SaveAO3 writes the saved works to the data directory
+func SaveAO3(cfg *Config, works *AO3Data) error {
+	if err := cfg.EnsureDataDir(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(works, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal AO3 works: %w", err)
+	}
+
+	if err := writeFileAtomic(filepath.Join(cfg.DataDirectory(), "ao3.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write AO3 file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// ArticlesPath returns the folder saved web articles go in, or "" if
+// there is no library to put them in
+func (c *Config) ArticlesPath() string {
+	return c.libraryFolder(c.Library.ArticlesFolder)
+}
+
+// libraryFolder returns a folder of the library given by a setting, which
+// may also be a path of its own, or "" if either is unset
+func (c *Config) libraryFolder(folder string) string {
+	if c.Library.Path == "" || folder == "" {
+		return ""
+	}
+	if filepath.IsAbs(folder) {
+		return folder
+	}
+	return filepath.Join(c.Library.Path, folder)
+}
+
+// IsArticle reports whether a book is a saved web article, kept in the
+// articles folder
+func (c *Config) IsArticle(bookPath string) bool {
+	dir := c.ArticlesPath()
+	if dir == "" {
+		return false
+	}
+	rel, err := filepath.Rel(dir, bookPath)
+	return err == nil && rel != "." && !strings.HasPrefix(rel, "..")
+}
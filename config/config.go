@@ -1,32 +1,110 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 
 	"github.com/BurntSushi/toml"
 )
 
 type Config struct {
-	Library          LibraryConfig `toml:"library"`
-	ThemeName        string        `toml:"theme_name"` // Name of theme to load
-	Reading          ReadingConfig `toml:"reading"`
-	Display          DisplayConfig `toml:"display"`
-	DataDir          string        `toml:"data_dir"`           // Directory for app data (bookmarks, progress, etc.)
-	UseLibraryForData bool          `toml:"use_library_for_data"` // If true, store data in library path
+	Library           LibraryConfig    `toml:"library"`
+	ThemeName         string           `toml:"theme_name"` // Name of theme to load
+	Language          string           `toml:"language"`   // Language of the interface: "en", "de", "es" or one in locales/
+	Reading           ReadingConfig    `toml:"reading"`
+	Display           DisplayConfig    `toml:"display"`
+	Device            DeviceConfig     `toml:"device"`
+	Dictionary        DictionaryConfig `toml:"dictionary"`
+	Notes             NotesConfig      `toml:"notes"`
+	Hooks             HooksConfig      `toml:"hooks"`
+	Remote            RemoteConfig     `toml:"remote"`
+	Sync              SyncConfig       `toml:"sync"`
+	AO3               AO3Config        `toml:"ao3"`
+	Digest            DigestConfig     `toml:"digest"`
+	Mail              MailConfig       `toml:"mail"`
+	Restricted        RestrictedConfig `toml:"restricted"`
+	DataDir           string           `toml:"data_dir"`             // Directory for app data (bookmarks, progress, etc.)
+	UseLibraryForData bool             `toml:"use_library_for_data"` // If true, store data in library path
 
 	// Active theme (loaded at runtime, not saved to file)
 	ActiveTheme *Theme `toml:"-"`
+
+	// config.toml didn't exist and was just written with the defaults, so
+	// the setup wizard runs
+	FirstRun bool `toml:"-"`
+
+	// Problems found in config.toml or the theme; see validate.go
+	Warnings []string `toml:"-"`
+	broken   bool     // config.toml could not be parsed, so Save leaves it alone
+
+	savedLibraryPath *string // Library path from the file while --library overrides it
+	savedRestricted  *bool   // Restricted mode from the file while --restricted turns it on
 }
 
 type LibraryConfig struct {
-	Path string `toml:"path"`
+	Path  string `toml:"path"`
+	View  string `toml:"view"`  // "list" or "gallery"
+	Watch bool   `toml:"watch"` // Refresh the library when files change on disk
+
+	// Formats to keep when removing duplicate books, most preferred first
+	PreferredFormats []string `toml:"preferred_formats"`
+
+	// Folder of the library that saved web articles go in, listed as their own section
+	ArticlesFolder string `toml:"articles_folder"`
 }
 
 type ReadingConfig struct {
-	CurrentBook string `toml:"current_book"`
-	Position    int    `toml:"position"`
+	CurrentBook    string `toml:"current_book"` // Book that was open last
+	Position       int    `toml:"position"`
+	NonLinear      string `toml:"non_linear"`       // Spine items marked linear="no": "include", "end" or "skip"
+	ResumeLastBook bool   `toml:"resume_last_book"` // Skip the library and reopen CurrentBook on startup
+
+	// Plain text chapter detection: "auto", "headings", "blank", "sections" or "none"
+	TextSplit       string `toml:"text_split"`
+	TextSectionSize int    `toml:"text_section_size"` // Characters per section for "sections"
+
+	// Strip Project Gutenberg boilerplate, unwrap paragraphs and style _emphasis_ in text files
+	GutenbergCleanup bool `toml:"gutenberg_cleanup"`
+
+	WordsPerMinute int  `toml:"words_per_minute"` // Reading speed for time estimates
+	FlowChapters   bool `toml:"flow_chapters"`    // Scrolling past the end of a chapter opens the next one
+	Continuous     bool `toml:"continuous"`       // Chapters run on into each other as one scrolling text
+
+	// Deepest heading level, 1 to 6, that the section keys stop at
+	SectionDepth int `toml:"section_depth"`
+
+	// Speed reading shows this many words at a time, at this speed
+	RSVPWordsPerMinute int `toml:"rsvp_words_per_minute"`
+	RSVPChunk          int `toml:"rsvp_chunk"`
+
+	// Command that plays the read-aloud audio of books with media overlays;
+	// {file} is the audio file and {start} the second to start at
+	AudioPlayer string `toml:"audio_player"`
+
+	// Words on a page. Page numbers count these rather than screens, so
+	// they are the same for every format and terminal size.
+	WordsPerPage int `toml:"words_per_page"`
+
+	// Show a break reminder after reading this long; 0 disables it
+	ReminderMinutes int `toml:"reminder_minutes"`
+	SnoozeMinutes   int `toml:"snooze_minutes"`
+
+	// Sum up time read and progress made when leaving a book
+	SessionSummary bool `toml:"session_summary"`
+
+	// Focus mode alternates reading and breaks, pomodoro style. Focus turns
+	// it on whenever a book is opened; :focus toggles it while reading.
+	Focus        bool `toml:"focus"`
+	FocusMinutes int  `toml:"focus_minutes"`
+	BreakMinutes int  `toml:"break_minutes"`
+
+	// Language learning: m in the word cursor marks unknown words, kept
+	// with their sentence in a vocabulary list per language, and marked
+	// words are underlined wherever they come up again
+	Vocabulary bool `toml:"vocabulary"`
 }
 
 type DisplayConfig struct {
@@ -34,6 +112,206 @@ type DisplayConfig struct {
 	LineSpacing int `toml:"line_spacing"`
 	MarginLeft  int `toml:"margin_left"`
 	MarginRight int `toml:"margin_right"`
+
+	// How margin_left and margin_right are read: "cells", or "percent" of
+	// the terminal width. "auto" ignores them and centers lines of
+	// line_length characters.
+	MarginMode string `toml:"margin_mode"`
+	LineLength int    `toml:"line_length"`
+
+	Justify bool `toml:"justify"` // Stretch lines to the full width
+	Zen     bool `toml:"zen"`     // Show only the text while reading
+
+	// Curly quotes, em dashes and ellipses in place of straight quotes,
+	// double hyphens and three dots. French books also get no-break spaces
+	// before ; : ! ? and inside guillemets.
+	SmartTypography bool `toml:"smart_typography"`
+
+	SmoothScroll bool `toml:"smooth_scroll"` // Animate page and half-page scrolling
+	Paged        bool `toml:"paged"`         // Scroll keys turn a whole page instead of a line
+
+	// Lines the scroll keys move, and lines of the last page still shown
+	// after turning a page
+	ScrollLines int `toml:"scroll_lines"`
+	PageOverlap int `toml:"page_overlap"`
+
+	// Dim all but the line being read: "off", "line", or "band" for three lines
+	Ruler string `toml:"ruler"`
+
+	// Room between letters and words for easier reading. Accessible turns
+	// on both, with shorter lines and no justification.
+	LetterSpacing bool `toml:"letter_spacing"`
+	WordSpacing   int  `toml:"word_spacing"` // Spaces added between words
+	Accessible    bool `toml:"accessible"`
+
+	// Plain text for terminal screen readers: no colors, borders, rules or
+	// bullets, and chapter changes announced on the status line
+	ScreenReader bool `toml:"screen_reader"`
+
+	// Only ASCII characters, for terminals and fonts without Unicode:
+	// accents are dropped and quotes, dashes and symbols spelled out
+	ASCII bool `toml:"ascii"`
+
+	// Large text for reading from a distance: "off", "headings" or "all".
+	// The "double" style uses the double width and height lines of xterm
+	// and other terminals that support them; "banner" draws headings in
+	// block letters anywhere.
+	LargeText      string `toml:"large_text"`
+	LargeTextStyle string `toml:"large_text_style"`
+
+	// Reader footer templates with placeholders such as {chapter}, {chapters},
+	// {chapter_title}, {scroll}, {percent}, {page}, {pages}, {time_left},
+	// {clock}, {book_title} and {author}. "t" switches between the verbose
+	// and minimal one.
+	StatusFormat        string `toml:"status_format"`
+	StatusFormatMinimal string `toml:"status_format_minimal"`
+
+	// Bars for the position in the chapter and the book in the footer
+	ProgressBar bool `toml:"progress_bar"`
+
+	// A scrollbar at the right edge marking headings, bookmarks,
+	// highlights and search matches in the chapter
+	Scrollbar bool `toml:"scrollbar"`
+
+	// How cover images are drawn: "auto", "kitty", "iterm", "blocks" or "none"
+	CoverProtocol string `toml:"cover_protocol"`
+
+	// Colors the terminal supports: "auto", "truecolor", "256", "16" or
+	// "none". Theme colors are mapped to the nearest available color.
+	ColorProfile string `toml:"color_profile"`
+}
+
+type DeviceConfig struct {
+	Path        string `toml:"path"`         // Mount point or folder on the e-reader, e.g. /media/kobo
+	ConvertText bool   `toml:"convert_text"` // Convert plain text books to EPUB when sending
+}
+
+type NotesConfig struct {
+	// Folder highlights are exported to as Markdown, one file per book,
+	// e.g. a folder in an Obsidian vault. Empty uses "highlights" in the
+	// data directory.
+	ExportDir string `toml:"export_dir"`
+}
+
+// HighlightsExportDir returns the folder highlight exports are written to
+func (c *Config) HighlightsExportDir() string {
+	if c.Notes.ExportDir != "" {
+		return c.Notes.ExportDir
+	}
+	return filepath.Join(c.DataDirectory(), "highlights")
+}
+
+// HooksConfig holds commands run when something happens while reading,
+// e.g. to log books to a task manager or send a notification. Each is run
+// by the shell with the event as JSON on standard input; empty runs
+// nothing.
+type HooksConfig struct {
+	BookOpened       string `toml:"book_opened"`
+	BookFinished     string `toml:"book_finished"`
+	HighlightCreated string `toml:"highlight_created"`
+	SessionEnded     string `toml:"session_ended"`
+}
+
+// RemoteConfig controls the socket other programs use to query and control
+// a running cozy, e.g. a status bar showing the book being read
+type RemoteConfig struct {
+	Enabled bool   `toml:"enabled"`
+	Socket  string `toml:"socket"` // Empty uses cozy.sock in $XDG_RUNTIME_DIR or the data directory
+}
+
+// RemoteSocket returns the path of the remote control socket
+func (c *Config) RemoteSocket() string {
+	if c.Remote.Socket != "" {
+		return c.Remote.Socket
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "cozy.sock")
+	}
+	return filepath.Join(c.DataDirectory(), "cozy.sock")
+}
+
+// AO3Config sets up saving works from Archive of Our Own, see ao3.go
+type AO3Config struct {
+	Folder     string `toml:"folder"`      // Folder of the library works go in, under a folder per fandom
+	CheckHours int    `toml:"check_hours"` // Hours between checks of works in progress for new chapters; 0 checks only with :ao3 check
+}
+
+// DigestConfig sets up the daily edition built from news feeds, see
+// digest.go
+type DigestConfig struct {
+	Feeds      []string `toml:"feeds"`        // RSS or Atom feed addresses
+	Folder     string   `toml:"folder"`       // Folder of the library editions go in
+	MaxPerFeed int      `toml:"max_per_feed"` // Most articles taken from each feed
+	FullText   bool     `toml:"full_text"`    // Download the page of articles the feed only has a summary of
+}
+
+// DigestPath returns the folder daily editions go in, or "" if there is
+// no library to put them in
+func (c *Config) DigestPath() string {
+	return c.libraryFolder(c.Digest.Folder)
+}
+
+// MailConfig sets up importing books sent by email, see mail.go. Books are
+// taken from the attachments of mail in a maildir, an IMAP mailbox, or both.
+type MailConfig struct {
+	Maildir string `toml:"maildir"` // Maildir folder the mail is delivered to, e.g. ~/Mail/cozy
+
+	// IMAP server as host or host:port, reached over TLS on port 993 by
+	// default. Use an app password rather than the account's.
+	IMAPServer   string `toml:"imap_server"`
+	IMAPUser     string `toml:"imap_user"`
+	IMAPPassword string `toml:"imap_password"`
+	IMAPMailbox  string `toml:"imap_mailbox"`
+
+	AllowedSenders []string `toml:"allowed_senders"` // Addresses or @domains books are taken from; empty takes them from anyone
+	Folder         string   `toml:"folder"`          // Folder of the library books go in, under a folder per sender
+	CheckMinutes   int      `toml:"check_minutes"`   // Minutes between checks while cozy runs; 0 checks only with :mail
+}
+
+// MailPath returns the folder books sent by email go in, or "" if there
+// is no library to put them in
+func (c *Config) MailPath() string {
+	return c.libraryFolder(c.Mail.Folder)
+}
+
+// RestrictedConfig limits the library to some books and hides the settings,
+// for a machine children or guests share. Books are allowed when they are
+// in one of the folders, or in a folder named like one of the tags.
+type RestrictedConfig struct {
+	Enabled bool     `toml:"enabled"`
+	Folders []string `toml:"folders"` // Folders in the library, e.g. "Kids/Picture books"
+	Tags    []string `toml:"tags"`    // Folder names anywhere in the library, e.g. "kids"
+}
+
+// SyncConfig keeps a copy of the progress, bookmarks, highlights and notes
+// of each book in a folder that Syncthing, Dropbox or Git syncs between
+// machines, see sync.go, or that :sync copies to a WebDAV server
+type SyncConfig struct {
+	Directory string `toml:"directory"` // Empty turns syncing off, unless webdav_url is set
+
+	// WebDAV folder, e.g. https://cloud.example.com/remote.php/dav/files/me/cozy
+	// on Nextcloud. Use an app password rather than the account's.
+	WebDAVURL      string `toml:"webdav_url"`
+	WebDAVUser     string `toml:"webdav_user"`
+	WebDAVPassword string `toml:"webdav_password"`
+}
+
+// SyncDirectory returns the sync directory: sync.directory, or "sync" in
+// the data directory when only WebDAV is set up. Empty means syncing is
+// off.
+func (c *Config) SyncDirectory() string {
+	if c.Sync.Directory == "" && c.Sync.WebDAVURL != "" {
+		return filepath.Join(c.DataDirectory(), "sync")
+	}
+	return c.Sync.Directory
+}
+
+type DictionaryConfig struct {
+	// StarDict .ifo or dictd .index files, or directories containing them
+	Paths []string `toml:"paths"`
+
+	OnlineLookups  bool   `toml:"online_lookups"`  // Allow fetching extracts from Wiktionary and Wikipedia
+	OnlineLanguage string `toml:"online_language"` // Wikimedia language edition, e.g. "en" or "de"
 }
 
 // DefaultConfig returns a config with sensible defaults
@@ -44,42 +322,170 @@ func DefaultConfig() Config {
 	}
 
 	defaultTheme := CozyDark
-	configDir := filepath.Join(homeDir, ".config", "cozy")
 
 	return Config{
 		Library: LibraryConfig{
-			Path: filepath.Join(homeDir, "Documents", "Books"),
+			Path:  filepath.Join(homeDir, "Documents", "Books"),
+			View:  "list",
+			Watch: true,
+
+			PreferredFormats: []string{"epub", "txt"},
+			ArticlesFolder:   "Articles",
 		},
-		ThemeName:        "cozy-dark",
-		DataDir:          filepath.Join(configDir, "data"),
+		ThemeName:         "cozy-dark",
+		Language:          "en",
+		DataDir:           DefaultDataDir(),
 		UseLibraryForData: false,
 		Reading: ReadingConfig{
-			CurrentBook: "",
-			Position:    0,
+			CurrentBook:     "",
+			Position:        0,
+			NonLinear:       "include",
+			TextSplit:       "auto",
+			TextSectionSize: 20000,
+			WordsPerMinute:  250,
+			WordsPerPage:    250,
+			SnoozeMinutes:   10,
+			SessionSummary:  true,
+			FocusMinutes:    25,
+			BreakMinutes:    5,
+
+			RSVPWordsPerMinute: 350,
+			RSVPChunk:          1,
+
+			AudioPlayer: "mpv --no-video --really-quiet --start={start} {file}",
+
+			SectionDepth: 3,
 		},
 		Display: DisplayConfig{
-			FontSize:    14,
-			LineSpacing: 2,
-			MarginLeft:  4,
-			MarginRight: 4,
+			FontSize:      14,
+			LineSpacing:   2,
+			MarginLeft:    4,
+			MarginRight:   4,
+			MarginMode:    "cells",
+			LineLength:    66,
+			Justify:       true,
+			CoverProtocol: "auto",
+			ColorProfile:  "auto",
+			Ruler:         "off",
+
+			ScrollLines: 1,
+
+			LargeText:      "off",
+			LargeTextStyle: "double",
+
+			StatusFormat:        "Chapter {chapter}/{chapters} • Scroll: {scroll}%",
+			StatusFormatMinimal: "{percent}%",
+			ProgressBar:         true,
+		},
+		AO3: AO3Config{
+			Folder:     "AO3",
+			CheckHours: 24,
+		},
+		Digest: DigestConfig{
+			Folder:     "Digest",
+			MaxPerFeed: 10,
+			FullText:   true,
+		},
+		Mail: MailConfig{
+			IMAPMailbox:  "INBOX",
+			Folder:       "Email",
+			CheckMinutes: 5,
 		},
 		ActiveTheme: &defaultTheme,
 	}
 }
 
-// ConfigDir returns the path to the config directory
+// Set from the command line to use another config file or library
+var (
+	configPathOverride  string
+	libraryPathOverride string
+	restrictedOverride  bool
+)
+
+// SetConfigPath makes Load and Save use another config file. Themes and
+// dictionaries are then looked up next to it.
+func SetConfigPath(path string) {
+	configPathOverride = absPath(path)
+}
+
+// SetLibraryPath makes Load use another library folder without changing
+// the one saved in the config file
+func SetLibraryPath(path string) {
+	libraryPathOverride = absPath(path)
+}
+
+// SetRestricted makes Load turn on restricted mode without saving it to
+// the config file
+func SetRestricted() {
+	restrictedOverride = true
+}
+
+// absPath makes a path from the command line absolute, since book paths
+// are used as keys in the progress file
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// ConfigDir returns the path to the config directory: $XDG_CONFIG_HOME/cozy
+// or ~/.config/cozy on Linux, ~/Library/Application Support/cozy on macOS
+// and %AppData%\cozy on Windows
 func ConfigDir() (string, error) {
+	if configPathOverride != "" {
+		return filepath.Dir(configPathOverride), nil
+	}
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the config directory: %w", err)
+	}
+	return filepath.Join(base, "cozy"), nil
+}
+
+// DefaultDataDir returns where progress and highlights are kept unless
+// data_dir says otherwise: $XDG_DATA_HOME/cozy or ~/.local/share/cozy on
+// Linux, a data folder in the config directory on macOS and
+// %LocalAppData%\cozy on Windows
+func DefaultDataDir() string {
+	var dir string
+	var err error
+	switch runtime.GOOS {
+	case "darwin":
+		dir, err = ConfigDir()
+		dir = filepath.Join(dir, "data")
+	case "windows":
+		// Go's cache directory on Windows is %LocalAppData%
+		dir, err = os.UserCacheDir()
+		dir = filepath.Join(dir, "cozy")
+	default:
+		dir, err = xdgDir("XDG_DATA_HOME", filepath.Join(".local", "share", "cozy"))
+	}
+	if err != nil {
+		return filepath.Join("~", ".local", "share", "cozy")
+	}
+	return dir
+}
+
+// xdgDir returns the cozy folder inside an XDG base directory, or the
+// given folder in the home directory when the variable is unset. Relative
+// paths are invalid per the spec and ignored.
+func xdgDir(variable, fallback string) (string, error) {
+	if base := os.Getenv(variable); filepath.IsAbs(base) {
+		return filepath.Join(base, "cozy"), nil
+	}
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get user home directory: %w", err)
 	}
-
-	configDir := filepath.Join(homeDir, ".config", "cozy")
-	return configDir, nil
+	return filepath.Join(homeDir, fallback), nil
 }
 
 // ConfigPath returns the full path to the config file
 func ConfigPath() (string, error) {
+	if configPathOverride != "" {
+		return configPathOverride, nil
+	}
 	configDir, err := ConfigDir()
 	if err != nil {
 		return "", err
@@ -98,6 +504,48 @@ func (c *Config) DataDirectory() string {
 	return c.DataDir
 }
 
+// CacheDirectory returns the directory for files that can be recreated:
+// the platform's cache directory, or the data directory when data is kept
+// in the library so the library stays self-contained
+func (c *Config) CacheDirectory() string {
+	if !c.UseLibraryForData {
+		if base, err := os.UserCacheDir(); err == nil {
+			return filepath.Join(base, "cozy")
+		}
+	}
+	return c.DataDirectory()
+}
+
+// CoverCacheDir returns the directory where cover thumbnails are cached
+func (c *Config) CoverCacheDir() string {
+	return filepath.Join(c.CacheDirectory(), "covers")
+}
+
+// SearchIndexDir returns the directory where the search indexes of books
+// are cached
+func (c *Config) SearchIndexDir() string {
+	return filepath.Join(c.CacheDirectory(), "search")
+}
+
+// LookupCacheDir returns the directory where online lookups are cached
+func (c *Config) LookupCacheDir() string {
+	return filepath.Join(c.CacheDirectory(), "lookups")
+}
+
+// DictionaryPaths returns the configured dictionary locations, or the
+// usual system and user locations when none are set
+func (c *Config) DictionaryPaths() []string {
+	if len(c.Dictionary.Paths) > 0 {
+		return c.Dictionary.Paths
+	}
+
+	paths := []string{"/usr/share/dictd", "/usr/share/stardict/dic"}
+	if configDir, err := ConfigDir(); err == nil {
+		paths = append([]string{filepath.Join(configDir, "dictionaries")}, paths...)
+	}
+	return paths
+}
+
 // EnsureDataDir creates the data directory if it doesn't exist
 func (c *Config) EnsureDataDir() error {
 	dataDir := c.DataDirectory()
@@ -114,6 +562,9 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
+	// Older versions kept the config in ~/.config/cozy on every platform
+	legacyDir, migrateErr := migrateLegacyConfig()
+
 	// Check if config exists
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		// Create config directory
@@ -132,14 +583,30 @@ func Load() (*Config, error) {
 			return nil, fmt.Errorf("failed to create default config: %w", err)
 		}
 
+		config.overrideLibraryPath()
+		config.overrideRestricted()
+		config.FirstRun = true
+		if migrateErr != nil {
+			config.Warnings = append(config.Warnings, migrateErr.Error())
+		}
 		return &config, nil
 	}
 
-	// Load existing config
-	var config Config
-	if _, err := toml.DecodeFile(configPath, &config); err != nil {
-		return nil, fmt.Errorf("failed to decode config file: %w", err)
+	// Load existing config; settings missing from the file or unusable
+	// keep their defaults
+	config := DefaultConfig()
+	warnings, err := decodeConfig(configPath, &config)
+	if err != nil {
+		config = DefaultConfig()
+		config.broken = true
+		warnings = []string{err.Error() + "; using the default settings"}
+	}
+	if legacyDir != "" && config.relocateDataDir(legacyDir) {
+		Save(&config)
 	}
+	config.overrideLibraryPath()
+	config.overrideRestricted()
+	config.Warnings = append(warnings, validateConfig(&config)...)
 
 	// Load the theme
 	if config.ThemeName == "" {
@@ -149,31 +616,79 @@ func Load() (*Config, error) {
 	theme, err := LoadTheme(config.ThemeName)
 	if err != nil {
 		// Fall back to default theme if loading fails
+		config.Warnings = append(config.Warnings, fmt.Sprintf("theme_name: %v; using cozy-dark", err))
 		defaultTheme := CozyDark
 		theme = &defaultTheme
 	}
+	config.Warnings = append(config.Warnings, validateTheme(theme)...)
 	config.ActiveTheme = theme
 
 	return &config, nil
 }
 
-// Save saves the config to the config file
+// overrideLibraryPath applies the library path given on the command line
+func (c *Config) overrideLibraryPath() {
+	if libraryPathOverride == "" {
+		return
+	}
+	saved := c.Library.Path
+	c.savedLibraryPath = &saved
+	c.Library.Path = libraryPathOverride
+}
+
+// overrideRestricted turns on restricted mode when asked on the command
+// line
+func (c *Config) overrideRestricted() {
+	if !restrictedOverride {
+		return
+	}
+	saved := c.Restricted.Enabled
+	c.savedRestricted = &saved
+	c.Restricted.Enabled = true
+}
+
+// SetLibrary changes the library folder saved to config.toml. A folder
+// given with --library stays in use for this run.
+func (c *Config) SetLibrary(path string) {
+	if c.savedLibraryPath != nil {
+		*c.savedLibraryPath = path
+		return
+	}
+	c.Library.Path = path
+}
+
+// Save saves the config to the config file. The write is locked and
+// atomic, so instances saving at the same time can't interleave.
 func Save(config *Config) error {
+	if config.broken {
+		return errConfigBroken
+	}
 	configPath, err := ConfigPath()
 	if err != nil {
 		return err
 	}
 
-	file, err := os.Create(configPath)
-	if err != nil {
-		return fmt.Errorf("failed to create config file: %w", err)
+	// A library or restricted mode given on the command line is only used
+	// for this run
+	file := *config
+	if config.savedLibraryPath != nil {
+		file.Library.Path = *config.savedLibraryPath
+	}
+	if config.savedRestricted != nil {
+		file.Restricted.Enabled = *config.savedRestricted
 	}
-	defer file.Close()
 
-	encoder := toml.NewEncoder(file)
-	if err := encoder.Encode(config); err != nil {
+	var buf bytes.Buffer
+	encoder := toml.NewEncoder(&buf)
+	if err := encoder.Encode(file); err != nil {
 		return fmt.Errorf("failed to encode config: %w", err)
 	}
 
-	return nil
+	return withFileLock(configPath, func() error {
+		// Only readable by the user, as it holds passwords
+		if err := writeFileAtomic(configPath, buf.Bytes(), 0600); err != nil {
+			return fmt.Errorf("failed to write config file: %w", err)
+		}
+		return nil
+	})
 }
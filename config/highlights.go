@@ -0,0 +1,146 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Highlight is a passage marked while reading
+type Highlight struct {
+	Chapter int       `json:"chapter"`
+	Text    string    `json:"text"`
+	Note    string    `json:"note,omitempty"`
+	Created time.Time `json:"created"`
+}
+
+// HighlightData stores the highlights of all books
+type HighlightData struct {
+	Books map[string][]Highlight `json:"books"` // Key is book path
+
+	changed map[string]bool // Books highlighted since the last save
+}
+
+// LoadHighlights loads highlights from the data directory
+func LoadHighlights(cfg *Config) (*HighlightData, error) {
+	highlights, err := readHighlightsFile(filepath.Join(cfg.DataDirectory(), "highlights.json"))
+	if err != nil {
+		return nil, err
+	}
+	highlights.mergeSynced(cfg)
+	return highlights, nil
+}
+
+// readHighlightsFile reads a highlights file, which may not exist yet
+func readHighlightsFile(highlightsPath string) (*HighlightData, error) {
+	// If file doesn't exist, return empty highlights
+	if _, err := os.Stat(highlightsPath); os.IsNotExist(err) {
+		return &HighlightData{
+			Books: make(map[string][]Highlight),
+		}, nil
+	}
+
+	data, err := os.ReadFile(highlightsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read highlights file: %w", err)
+	}
+
+	var highlights HighlightData
+	if err := json.Unmarshal(data, &highlights); err != nil {
+		return nil, fmt.Errorf("failed to parse highlights file: %w", err)
+	}
+
+	if highlights.Books == nil {
+		highlights.Books = make(map[string][]Highlight)
+	}
+
+	return &highlights, nil
+}
+
+// SaveHighlights saves highlights to the data directory. Highlights other
+// cozy instances saved since this one loaded the file are merged in, like
+// SaveProgress does.
+func SaveHighlights(cfg *Config, highlights *HighlightData) error {
+	if err := cfg.EnsureDataDir(); err != nil {
+		return err
+	}
+
+	highlightsPath := filepath.Join(cfg.DataDirectory(), "highlights.json")
+	err := saveMerged(highlightsPath, "highlights", highlights, readHighlightsFile, func(onDisk *HighlightData) {
+		// Highlights are only ever added, so both sides of a book
+		// highlighted here are kept
+		for path := range highlights.changed {
+			if list, ok := highlights.Books[path]; ok && len(onDisk.Books[path]) > 0 {
+				highlights.Books[path] = mergeHighlights(onDisk.Books[path], list)
+			}
+		}
+		mergeUnchanged(highlights.Books, onDisk.Books, highlights.changed)
+	})
+	if err != nil {
+		return err
+	}
+
+	for path := range highlights.changed {
+		if err := updateSyncedBook(cfg, path, SyncedBook{Highlights: highlights.Books[path]}); err != nil {
+			return fmt.Errorf("failed to write sync file: %w", err)
+		}
+	}
+	highlights.changed = nil
+	return nil
+}
+
+// markChanged records that a book's highlights need saving
+func (h *HighlightData) markChanged(bookPath string) {
+	if h.changed == nil {
+		h.changed = make(map[string]bool)
+	}
+	h.changed[bookPath] = true
+}
+
+// mergeSynced merges in the highlights of books in the sync directory,
+// see sync.go
+func (h *HighlightData) mergeSynced(cfg *Config) {
+	if cfg.SyncDirectory() == "" {
+		return
+	}
+	for bookPath, synced := range readSyncDirectory(cfg) {
+		if len(synced.Highlights) == 0 {
+			continue
+		}
+		merged := mergeHighlights(h.Books[bookPath], synced.Highlights)
+		if !sameJSON(merged, h.Books[bookPath]) {
+			h.markChanged(bookPath)
+		}
+		h.Books[bookPath] = merged
+	}
+}
+
+// AddHighlight stores a new highlight for a book
+func (h *HighlightData) AddHighlight(bookPath string, highlight Highlight) {
+	h.Books[bookPath] = append(h.Books[bookPath], highlight)
+	h.markChanged(bookPath)
+}
+
+// MoveBook moves the highlights of a book to another path, after those
+// already there
+func (h *HighlightData) MoveBook(from, to string) {
+	if highlights, ok := h.Books[from]; ok {
+		h.Books[to] = append(h.Books[to], highlights...)
+		delete(h.Books, from)
+		h.markChanged(from)
+		h.markChanged(to)
+	}
+}
+
+// ChapterHighlights returns the highlights in one chapter of a book
+func (h *HighlightData) ChapterHighlights(bookPath string, chapter int) []Highlight {
+	var result []Highlight
+	for _, highlight := range h.Books[bookPath] {
+		if highlight.Chapter == chapter {
+			result = append(result, highlight)
+		}
+	}
+	return result
+}
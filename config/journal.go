@@ -0,0 +1,149 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// JournalEntry is the free-form notes kept on a book, such as chapter
+// summaries and thoughts, in Markdown
+type JournalEntry struct {
+	Text    string    `json:"text"`
+	Updated time.Time `json:"updated"`
+}
+
+// JournalData stores the notes on all books
+type JournalData struct {
+	Books map[string]JournalEntry `json:"books"` // Key is book path
+
+	changed map[string]bool // Books whose notes changed since the last save
+}
+
+// LoadJournal loads the notes on books from the data directory
+func LoadJournal(cfg *Config) (*JournalData, error) {
+	journal, err := readJournalFile(filepath.Join(cfg.DataDirectory(), "journal.json"))
+	if err != nil {
+		return nil, err
+	}
+	journal.mergeSynced(cfg)
+	return journal, nil
+}
+
+// readJournalFile reads a journal file, which may not exist yet
+func readJournalFile(journalPath string) (*JournalData, error) {
+	// If file doesn't exist, return an empty journal
+	if _, err := os.Stat(journalPath); os.IsNotExist(err) {
+		return &JournalData{
+			Books: make(map[string]JournalEntry),
+		}, nil
+	}
+
+	data, err := os.ReadFile(journalPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal file: %w", err)
+	}
+
+	var journal JournalData
+	if err := json.Unmarshal(data, &journal); err != nil {
+		return nil, fmt.Errorf("failed to parse journal file: %w", err)
+	}
+
+	if journal.Books == nil {
+		journal.Books = make(map[string]JournalEntry)
+	}
+
+	return &journal, nil
+}
+
+// SaveJournal saves the notes on books to the data directory. Notes other
+// cozy instances saved since this one loaded the file are merged in, like
+// SaveProgress does.
+func SaveJournal(cfg *Config, journal *JournalData) error {
+	if err := cfg.EnsureDataDir(); err != nil {
+		return err
+	}
+
+	journalPath := filepath.Join(cfg.DataDirectory(), "journal.json")
+	err := saveMerged(journalPath, "journal", journal, readJournalFile, func(onDisk *JournalData) {
+		mergeUnchanged(journal.Books, onDisk.Books, journal.changed)
+	})
+	if err != nil {
+		return err
+	}
+
+	for path := range journal.changed {
+		entry, ok := journal.Books[path]
+		if !ok {
+			continue
+		}
+		if err := updateSyncedBook(cfg, path, SyncedBook{Journal: &entry}); err != nil {
+			return fmt.Errorf("failed to write sync file: %w", err)
+		}
+	}
+	journal.changed = nil
+	return nil
+}
+
+// markChanged records that the notes on a book need saving
+func (j *JournalData) markChanged(bookPath string) {
+	if j.changed == nil {
+		j.changed = make(map[string]bool)
+	}
+	j.changed[bookPath] = true
+}
+
+// mergeSynced merges in the notes on books in the sync directory, the
+// newer where both have notes, see sync.go
+func (j *JournalData) mergeSynced(cfg *Config) {
+	if cfg.SyncDirectory() == "" {
+		return
+	}
+	for bookPath, synced := range readSyncDirectory(cfg) {
+		if synced.Journal != nil && synced.Journal.Updated.After(j.Books[bookPath].Updated) {
+			j.Books[bookPath] = *synced.Journal
+			j.markChanged(bookPath)
+		}
+	}
+}
+
+// Text returns the notes on a book
+func (j *JournalData) Text(path string) string {
+	return j.Books[path].Text
+}
+
+// SetText replaces the notes on a book, dropping them when only
+// whitespace is left. It reports whether they changed.
+func (j *JournalData) SetText(path, text string) bool {
+	if strings.TrimSpace(text) == "" {
+		text = ""
+	}
+	if text == j.Books[path].Text {
+		return false
+	}
+	if text == "" {
+		delete(j.Books, path)
+	} else {
+		j.Books[path] = JournalEntry{Text: text, Updated: time.Now()}
+	}
+	j.markChanged(path)
+	return true
+}
+
+// MoveBook gives the notes on a book to another path, unless that one has
+// notes already
+func (j *JournalData) MoveBook(from, to string) {
+	entry, ok := j.Books[from]
+	if !ok {
+		return
+	}
+	delete(j.Books, from)
+	j.markChanged(from)
+	if _, exists := j.Books[to]; !exists {
+		j.Books[to] = entry
+		j.markChanged(to)
+	}
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+)
+
+// The interface is written in English, and a catalog translates its texts
+// into another language. Catalogs are keyed by the English text, and texts
+// with values in them by their format, as in "Chapter %d of %d", so a
+// translation keeps the verbs and can reorder them with %[2]d. Texts
+// missing from a catalog stay in English.
+//
+// A file locales/<language>.toml in the config dir adds to the built-in
+// catalog of that language, or adds a language:
+//
+//	"Your Library" = "Meine Bücher"
+//	"Chapter %d of %d" = "Kapitel %d von %d"
+
+// Catalog translates the texts of the interface
+type Catalog map[string]string
+
+// BuiltInLocales returns the built-in catalogs by language code
+func BuiltInLocales() map[string]Catalog {
+	return map[string]Catalog{
+		"de": German,
+		"es": Spanish,
+	}
+}
+
+// LoadLocale loads the catalog of a language, built-in or from file. English
+// needs none and returns nil.
+func LoadLocale(language string) (Catalog, error) {
+	if language == "" || language == "en" {
+		return nil, nil
+	}
+
+	builtIn, ok := BuiltInLocales()[language]
+	catalog := maps.Clone(builtIn)
+	if catalog == nil {
+		catalog = make(Catalog)
+	}
+
+	localePath, err := LocalePath(language)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(localePath); os.IsNotExist(err) {
+		if !ok {
+			return nil, fmt.Errorf("language not found: %s", language)
+		}
+		return catalog, nil
+	}
+
+	var custom Catalog
+	if _, err := toml.DecodeFile(localePath, &custom); err != nil {
+		return nil, fmt.Errorf("failed to load language file: %w", err)
+	}
+	maps.Copy(catalog, custom)
+	return catalog, nil
+}
+
+// LocalePath returns the path to the catalog file of a language
+func LocalePath(language string) (string, error) {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "locales", language+".toml"), nil
+}
+
+// ListLocales returns the languages the interface can be shown in
+func ListLocales() []string {
+	languages := []string{"en"}
+	for language := range BuiltInLocales() {
+		languages = append(languages, language)
+	}
+
+	if configDir, err := ConfigDir(); err == nil {
+		entries, _ := os.ReadDir(filepath.Join(configDir, "locales"))
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || filepath.Ext(name) != ".toml" {
+				continue
+			}
+			if language := name[:len(name)-5]; BuiltInLocales()[language] == nil && language != "en" {
+				languages = append(languages, language)
+			}
+		}
+	}
+	sort.Strings(languages[1:])
+	return languages
+}
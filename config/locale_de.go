@@ -0,0 +1,438 @@
+package config
+
+// German is the built-in German catalog
+var German = Catalog{
+	"Accessible layout on":     "Barrierearmes Layout an",
+	"Accessible layout off":    "Barrierearmes Layout aus",
+	" (1 book)":                " (1 Buch)",
+	" (%d books)":              " (%d Bücher)",
+	"No folders here":          "Keine Ordner hier",
+	"No folders or books here": "Keine Ordner oder Bücher hier",
+	"up":                       "hoch",
+	"down":                     "runter",
+	"open":                     "öffnen",
+	"parent":                   "übergeordneter Ordner",
+	"home":                     "Benutzerordner",
+	"show hidden":              "versteckte zeigen",
+	"Heading %d":               "Überschrift %d",
+	"%d words":                 "%d Wörter",
+	"Reading":                  "Wird gelesen",
+	"Read":                     "Gelesen",
+	"Chapters":                 "Kapitel",
+	"type to filter • ↑/↓ move • enter jump • esc close": "tippen zum Filtern • ↑/↓ bewegen • enter springen • esc schließen",
+	"usage: %s":                                    "Aufruf: %s",
+	"not a chapter number: %s":                     "keine Kapitelnummer: %s",
+	"not a percentage: %s":                         "keine Prozentangabe: %s",
+	"Focus mode off":                               "Fokusmodus aus",
+	"Focus mode is already on":                     "Fokusmodus ist schon an",
+	"Focus mode on: %d min reading, %d min breaks": "Fokusmodus an: %d Min. lesen, %d Min. Pause",
+	"Theme: %s":                                    "Farbschema: %s",
+	"no book selected":                             "kein Buch ausgewählt",
+	"bookmark %d":                                  "Lesezeichen %d",
+	"Bookmark %q added":                            "Lesezeichen %q hinzugefügt",
+	"No bookmarks":                                 "Keine Lesezeichen",
+	"Bookmarks: %s":                                "Lesezeichen: %s",
+	"scroll lines must be a number greater than 0":      "scroll_lines muss eine Zahl größer als 0 sein",
+	"page overlap must be 0 or more":                    "page_overlap muss 0 oder mehr sein",
+	"section depth must be a heading level from 1 to 6": "section_depth muss eine Überschriftenebene von 1 bis 6 sein",
+	"word spacing must be a number from 0 to 4":         "word_spacing muss eine Zahl von 0 bis 4 sein",
+	"line length must be a number of at least 20":       "line_length muss eine Zahl von mindestens 20 sein",
+	"%s must be one of: %s":                             "%s muss eines davon sein: %s",
+	"unknown option: %s":                                "unbekannte Option: %s",
+	"expected on or off, got %q":                        "on oder off erwartet, nicht %q",
+	"margin must be a number from 0 to 40":              "Der Rand muss eine Zahl von 0 bis 40 sein",
+	"(y/n)":                                             "(y/n)",
+	"Cancelled":                                         "Abgebrochen",
+	"unknown command: %s":                               "unbekannter Befehl: %s",
+	"ambiguous command: %s":                             "mehrdeutiger Befehl: %s",
+	"no cover":                                          "kein Cover",
+	"Description":                                       "Beschreibung",
+	"Not started":                                       "Nicht begonnen",
+	"Finished %s":                                       "Ausgelesen am %s",
+	"January 2, 2006":                                   "2.1.2006",
+	"Finished":                                          "Ausgelesen",
+	"%.0f%% (chapter %d/%d)":                            "%.0f%% (Kapitel %d/%d)",
+	"No book selected":                                  "Kein Buch ausgewählt",
+	"Book Details":                                      "Buchdetails",
+	"read":                                              "lesen",
+	"scroll up":                                         "nach oben",
+	"scroll down":                                       "nach unten",
+	"back to library":                                   "zurück zur Bibliothek",
+	"quit":                                              "beenden",
+	"No duplicate books":                                "Keine doppelten Bücher",
+	"%d duplicated: %s":                                 "%d doppelt: %s",
+	"Moved %d duplicate(s) to %s":                       "%d Duplikat(e) nach %s verschoben",
+	"%s; could not move %s":                             "%s; konnte %s nicht verschieben",
+	"Recent":                                            "Zuletzt",
+	"Browse":                                            "Durchsuchen",
+	"Open a book":                                       "Buch öffnen",
+	"No recent files; books opened from outside the library are listed here": "Keine zuletzt geöffneten Dateien; Bücher von außerhalb der Bibliothek erscheinen hier",
+	"(missing)":                       "(fehlt)",
+	"Could not save recent files: %v": "Zuletzt geöffnete Dateien konnten nicht gespeichert werden: %v",
+	"recent/browse":                   "zuletzt/durchsuchen",
+	"forget":                          "vergessen",
+	"back":                            "zurück",
+	"%s is managed by Calibre; change it there": "%s wird von Calibre verwaltet; bitte dort ändern",
+	"Rename %s to %s?":                          "%s in %s umbenennen?",
+	"%s is outside the library":                 "%s liegt außerhalb der Bibliothek",
+	"Move %s to %s?":                            "%s nach %s verschieben?",
+	"Move %s to the trash?":                     "%s in den Papierkorb verschieben?",
+	"Moved to %s":                               "Nach %s verschoben",
+	"Import failed: %v":                         "Import fehlgeschlagen: %v",
+	"Imported %d book(s)":                       "%d Buch/Bücher importiert",
+	", %d already in the library":               ", %d schon in der Bibliothek",
+	", %d failed":                               ", %d fehlgeschlagen",
+	"Importing %s...":                           "Importiere %s...",
+	"not allowed in restricted mode":            "im eingeschränkten Modus nicht erlaubt",
+	"Opening %s":                                "Öffne %s",
+	"case":                                      "Groß/klein",
+	"words":                                     "Wörter",
+	"regex":                                     "Regex",
+	"no matches":                                "keine Treffer",
+	"none in this chapter":                      "keine in diesem Kapitel",
+	"in %d of %d chapters":                      "in %d von %d Kapiteln",
+	"invalid pattern: %s":                       "ungültiges Muster: %s",
+	"next match":                                "nächster Treffer",
+	"previous match":                            "vorheriger Treffer",
+	"match case":                                "Groß-/Kleinschreibung",
+	"whole words":                               "ganze Wörter",
+	"regular expression":                        "regulärer Ausdruck",
+	"keep highlights":                           "Markierungen behalten",
+	"cancel":                                    "abbrechen",
+	"break %dm":                                 "Pause %d Min.",
+	"focus %dm":                                 "Fokus %d Min.",
+	"1 focus round":                             "1 Fokusrunde",
+	"%d focus rounds":                           "%d Fokusrunden",
+	"Break":                                     "Pause",
+	"%s done. Rest your eyes for %d min;\nreading resumes when the break is over.": "%s geschafft. Gönn deinen Augen %d Min. Ruhe;\ndanach geht es mit dem Lesen weiter.",
+	"enter skip break • q save and quit":                                           "enter Pause überspringen • q speichern und beenden",
+	"Break over, back to reading":                                                  "Pause vorbei, weiter geht's",
+	"Gallery • v list view • i details • / filter":                                 "Galerie • v Listenansicht • i Details • / filtern",
+	"No books found.":                  "Keine Bücher gefunden.",
+	"previous book":                    "vorheriges Buch",
+	"next book":                        "nächstes Buch",
+	"row up":                           "Zeile hoch",
+	"row down":                         "Zeile runter",
+	"first book":                       "erstes Buch",
+	"last book":                        "letztes Buch",
+	"Everywhere":                       "Überall",
+	"Library":                          "Bibliothek",
+	"Library list":                     "Bücherliste",
+	"Cover gallery":                    "Cover-Galerie",
+	"Book details":                     "Buchdetails",
+	"File browser":                     "Dateiauswahl",
+	"Reader":                           "Lesen",
+	"Search":                           "Suche",
+	"Word lookup":                      "Wörter nachschlagen",
+	"Journal":                          "Notizen",
+	"Key reference":                    "Tastenübersicht",
+	"Commands":                         "Befehle",
+	"anywhere":                         "überall",
+	"search keys and commands":         "Tasten und Befehle durchsuchen",
+	"Nothing matches %q":               "Nichts passt zu %q",
+	"Keys":                             "Tasten",
+	"search":                           "suchen",
+	"top":                              "Anfang",
+	"bottom":                           "Ende",
+	"Could not save highlight: %v":     "Markierung konnte nicht gespeichert werden: %v",
+	"Highlight added":                  "Markierung hinzugefügt",
+	"Could not read highlights: %v":    "Markierungen konnten nicht gelesen werden: %v",
+	"Could not read notes: %v":         "Notizen konnten nicht gelesen werden: %v",
+	"Could not export %s: %v":          "%s konnte nicht exportiert werden: %v",
+	"No highlights or notes to export": "Keine Markierungen oder Notizen zum Exportieren",
+	"Exported highlights of %d book(s) to %s":              "Markierungen von %d Buch/Büchern nach %s exportiert",
+	"stopped after %s":                                     "nach %s abgebrochen",
+	"Hook %s failed: %v":                                   "Hook %s fehlgeschlagen: %v",
+	"%s is not a Calibre library":                          "%s ist keine Calibre-Bibliothek",
+	"Importing %s progress from %s...":                     "Importiere Fortschritt aus %s von %s...",
+	"Could not import progress: %v":                        "Fortschritt konnte nicht importiert werden: %v",
+	"Could not save progress: %v":                          "Fortschritt konnte nicht gespeichert werden: %v",
+	"Imported the %s progress of %d book(s)":               "Fortschritt aus %s für %d Buch/Bücher importiert",
+	", %d already further in cozy":                         ", %d in cozy schon weiter",
+	", %d not in the library":                              ", %d nicht in der Bibliothek",
+	", %d could not be opened":                             ", %d konnten nicht geöffnet werden",
+	"Chapter summaries, thoughts, questions... (Markdown)": "Kapitelzusammenfassungen, Gedanken, Fragen... (Markdown)",
+	"Notes on %s":                                          "Notizen zu %s",
+	"Could not save notes: %v":                             "Notizen konnten nicht gespeichert werden: %v",
+	"Could not open the editor: %v":                        "Der Editor konnte nicht geöffnet werden: %v",
+	"Editor failed: %v":                                    "Editor fehlgeschlagen: %v",
+	"Could not read notes back: %v":                        "Notizen konnten nicht zurückgelesen werden: %v",
+	"Notes saved":                                          "Notizen gespeichert",
+	"save":                                                 "speichern",
+	"open in $EDITOR":                                      "in $EDITOR öffnen",
+	"save and close":                                       "speichern und schließen",
+	"Up next #%d":                                          "Als Nächstes #%d",
+	"Duplicate":                                            "Doppelt",
+	"finished books":                                       "ausgelesene Bücher",
+	"unreadable books":                                     "unlesbare Bücher",
+	"enter to show %s":                                     "enter zeigt %s",
+	"enter to hide %s":                                     "enter verbirgt %s",
+	"Your Library":                                         "Deine Bibliothek",
+	"Filter: ":                                             "Filter: ",
+	"book":                                                 "Buch",
+	"books":                                                "Bücher",
+	"no device path set in config.toml":                    "kein Gerätepfad in config.toml gesetzt",
+	"Loading...":                                           "Lade...",
+	"Cozy - E-Book Reader":                                 "Cozy - E-Book-Reader",
+	"Already on device: %s":                                "Schon auf dem Gerät: %s",
+	"Send failed: %v":                                      "Senden fehlgeschlagen: %v",
+	"Sent %s to device":                                    "%s an das Gerät gesendet",
+	"Can't open %s: %v":                                    "%s kann nicht geöffnet werden: %v",
+	"Can't open %s in restricted mode":                     "%s kann im eingeschränkten Modus nicht geöffnet werden",
+	"read, or fold a section":                              "lesen oder Abschnitt auf-/zuklappen",
+	"toggle finished":                                      "Ausgelesene ein/aus",
+	"details":                                              "Details",
+	"gallery":                                              "Galerie",
+	"send to device":                                       "an Gerät senden",
+	"refresh":                                              "aktualisieren",
+	"queue":                                                "vormerken",
+	"move up the queue":                                    "in der Warteschlange nach oben",
+	"move down the queue":                                  "in der Warteschlange nach unten",
+	"open a file":                                          "Datei öffnen",
+	"notes on the book":                                    "Notizen zum Buch",
+	"Look up: ":                                            "Nachschlagen: ",
+	"online lookups are off; set dictionary.online_lookups in config.toml": "Online-Nachschlagen ist aus; setze dictionary.online_lookups in config.toml",
+	"Lookup failed: %v":          "Nachschlagen fehlgeschlagen: %v",
+	"No definition found for %q": "Keine Definition für %q gefunden",
+	"esc close":                  "esc schließen",
+	"↓/j more":                   "↓/j mehr",
+	"next word":                  "nächstes Wort",
+	"previous word":              "vorheriges Wort",
+	"line down":                  "Zeile runter",
+	"line up":                    "Zeile hoch",
+	"select phrase":              "Wortgruppe markieren",
+	"define":                     "nachschlagen",
+	"look up online":             "online nachschlagen",
+	"highlight":                  "markieren",
+	"stop lookup":                "Nachschlagen beenden",
+	"Mark %s set":                "Marke %s gesetzt",
+	"No jump to go back from":    "Kein Sprung, von dem es zurückgeht",
+	"No mark %s":                 "Keine Marke %s",
+	"%s: chapter %d":             "%s: Kapitel %d",
+	"No marks":                   "Keine Marken",
+	"Marks: %s":                  "Marken: %s",
+	"Could not save mark: %v":    "Marke konnte nicht gespeichert werden: %v",
+	"Could not load library: %v": "Bibliothek konnte nicht geladen werden: %v",
+	"Could not open book: %v":    "Buch konnte nicht geöffnet werden: %v",
+	"Error: %v":                  "Fehler: %v",
+	"Press q to quit.":           "q drücken zum Beenden.",
+	"Remote control: %v":         "Fernsteuerung: %v",
+	"The file browser is off in restricted mode":      "Die Dateiauswahl ist im eingeschränkten Modus aus",
+	"Could not open %s: %v":                           "%s konnte nicht geöffnet werden: %v",
+	"command line":                                    "Befehlszeile",
+	"all keys and commands":                           "alle Tasten und Befehle",
+	"undo the last deletion":                          "letztes Löschen rückgängig",
+	"sync with WebDAV":                                "mit WebDAV synchronisieren",
+	"External link: %s":                               "Externer Link: %s",
+	"Open the next book in your queue, %s?":           "Das nächste vorgemerkte Buch öffnen, %s?",
+	"The queue is empty; press a on a book to add it": "Nichts vorgemerkt; a auf einem Buch merkt es vor",
+	"Up next: %s":                                     "Als Nächstes: %s",
+	"Can't queue %s: %v":                              "%s kann nicht vorgemerkt werden: %v",
+	"Could not save the queue: %v":                    "Die Warteschlange konnte nicht gespeichert werden: %v",
+	"Queued %s (#%d)":                                 "%s vorgemerkt (#%d)",
+	"Removed %s from the queue":                       "%s aus der Warteschlange entfernt",
+	"no book open":                                    "kein Buch geöffnet",
+	"chapter must be between 1 and %d":                "Das Kapitel muss zwischen 1 und %d liegen",
+	"percentage must be between 0 and 100":            "Die Prozentangabe muss zwischen 0 und 100 liegen",
+	"no bookmark named %q":                            "kein Lesezeichen namens %q",
+	"No book loaded":                                  "Kein Buch geladen",
+	"Chapter %d/%d: ":                                 "Kapitel %d/%d: ",
+	"Progress saved":                                  "Fortschritt gespeichert",
+	"next chapter":                                    "nächstes Kapitel",
+	"previous chapter":                                "vorheriges Kapitel",
+	"next section":                                    "nächster Abschnitt",
+	"previous section":                                "vorheriger Abschnitt",
+	"first chapter":                                   "erstes Kapitel",
+	"last chapter":                                    "letztes Kapitel",
+	"go to chapter":                                   "zu Kapitel springen",
+	"half page up":                                    "halbe Seite hoch",
+	"half page down":                                  "halbe Seite runter",
+	"look up a word":                                  "Wort nachschlagen",
+	"type a word to look up":                          "Wort zum Nachschlagen eingeben",
+	"find":                                            "suchen",
+	"zen mode":                                        "Zen-Modus",
+	"reading ruler":                                   "Leselineal",
+	"accessible layout":                               "barrierearmes Layout",
+	"speed reading":                                   "Schnelllesen",
+	"set a mark":                                      "Marke setzen",
+	"jump to a mark ('' jumps back)":                  "zu Marke springen ('' springt zurück)",
+	"minimal/verbose status":                          "knappe/ausführliche Statuszeile",
+	"Could not reload config: %v":                     "Einstellungen konnten nicht neu geladen werden: %v",
+	"Settings reloaded":                               "Einstellungen neu geladen",
+	"%d min":                                          "%d Min.",
+	"%dh %02d min":                                    "%d Std. %02d Min.",
+	"Time for a break?":                               "Zeit für eine Pause?",
+	"You've been reading for %s.":                     "Du liest seit %s.",
+	"s snooze %d min • q save and quit • esc keep reading": "s %d Min. später • q speichern und beenden • esc weiterlesen",
+	"remote control socket %s is in use by another cozy":   "Fernsteuerungs-Socket %s wird von einem anderen cozy benutzt",
+	"no reading position saved for this book":              "für dieses Buch ist keine Leseposition gespeichert",
+	"chapter %d":     "Kapitel %d",
+	"chapter %d, %s": "Kapitel %d, %s",
+	"Welcome back":   "Willkommen zurück",
+	"You left off in chapter %d, but you have read up to\n%s.":                                   "Du hast in Kapitel %d aufgehört, aber schon bis\n%s gelesen.",
+	"enter resume here • f jump to furthest":                                                     "enter hier weiterlesen • f zur weitesten Stelle",
+	"enter keep position • s start over":                                                         "enter Position behalten • s von vorn beginnen",
+	"enter keep position • f find my place • s start over":                                       "enter Position behalten • f Stelle suchen • s von vorn beginnen",
+	"This book has changed":                                                                      "Dieses Buch hat sich geändert",
+	"The file was replaced since you last read it, so your\nplace in chapter %d may have moved.": "Die Datei wurde seit dem letzten Lesen ersetzt, deine\nStelle in Kapitel %d hat sich vielleicht verschoben.",
+	"Could not find your place in the new file":                                                  "Deine Stelle wurde in der neuen Datei nicht gefunden",
+	"Found your place in chapter %d":                                                             "Deine Stelle ist in Kapitel %d",
+	"A few words (optional)":                                                                     "Ein paar Worte (optional)",
+	"You finished %s":                                                                            "Du hast %s ausgelesen",
+	"How many stars?":                                                                            "Wie viele Sterne?",
+	"1-5 to rate • esc to skip":                                                                  "1-5 bewerten • esc überspringen",
+	"Rated":                                                                                      "Bewertet",
+	"enter to save • esc to save without a review":                                               "enter speichern • esc ohne Rezension speichern",
+	"Could not save the review: %v":                                                              "Die Bewertung konnte nicht gespeichert werden: %v",
+	"Rated %s %s":                                                                                "%s mit %s bewertet",
+	"%d wpm":                                                                                     "%d WpM",
+	"paused":                                                                                     "angehalten",
+	"pause":                                                                                      "anhalten",
+	"rewind":                                                                                     "zurückspulen",
+	"skip ahead":                                                                                 "vorspulen",
+	"faster":                                                                                     "schneller",
+	"slower":                                                                                     "langsamer",
+	"back to the page":                                                                           "zurück zur Seite",
+	"Reading ruler: %s":                                                                          "Leselineal: %s",
+	"Chapter %d of %d":                                                                           "Kapitel %d von %d",
+	"Book %s of %d in %s":                                                                        "Band %s von %d in %s",
+	"Open the next in the series, %s?":                                                           "Den nächsten Band der Reihe öffnen, %s?",
+	"Open the next in the series, %s (%s)?":                                                      "Den nächsten Band der Reihe öffnen, %s (%s)?",
+	"under a minute":                                                                             "unter einer Minute",
+	"%d words, about %d pages":                                                                   "%d Wörter, etwa %d Seiten",
+	"%s to go":                                                                                   "noch %s",
+	"%s to go at %d wpm":                                                                         "noch %s bei %d WpM",
+	"Finished!":                                                                                  "Ausgelesen!",
+	"any key to continue":                                                                        "beliebige Taste zum Weitermachen",
+	"any key to quit":                                                                            "beliebige Taste zum Beenden",
+	"Reading session":                                                                            "Lesesitzung",
+	"Time":                                                                                       "Zeit",
+	"Progress":                                                                                   "Stand",
+	"Left":                                                                                       "Rest",
+	"Where are your books?":                                                                      "Wo sind deine Bücher?",
+	"Browse to the folder holding your e-books and press enter to use it.": "Wechsle in den Ordner mit deinen E-Books und drücke enter, um ihn zu nutzen.",
+	"Pick a theme": "Wähle ein Farbschema",
+	"Move through the themes to try them on.":           "Geh durch die Farbschemata, um sie auszuprobieren.",
+	"How should the reader move?":                       "Wie soll sich der Text bewegen?",
+	"You can change this later with :set paged on|off.": "Das lässt sich später mit :set paged on|off ändern.",
+	"Welcome to cozy":                                   "Willkommen bei cozy",
+	"Step %d of 3":                                      "Schritt %d von 3",
+	"choose":                                            "auswählen",
+	"skip setup":                                        "Einrichtung überspringen",
+	"%dh %02dm":                                         "%d:%02d h",
+	"Config: %s":                                        "Einstellungen: %s",
+	"%d more problems in config.toml; run cozy -check-config to list them": "%d weitere Probleme in config.toml; cozy -check-config listet sie auf",
+	"%s undoes":                      "%s macht es rückgängig",
+	"Moved %s to the trash; %s":      "%s in den Papierkorb verschoben; %s",
+	"%s has no reading progress":     "%s hat keinen Lesefortschritt",
+	"Cleared the progress of %s; %s": "Fortschritt von %s gelöscht; %s",
+	"Bookmark %q deleted; %s":        "Lesezeichen %q gelöscht; %s",
+	"nothing to undo":                "nichts rückgängig zu machen",
+	"Restored %s":                    "%s wiederhergestellt",
+	"Restored the progress of %s":    "Fortschritt von %s wiederhergestellt",
+	"Restored bookmark %q":           "Lesezeichen %q wiederhergestellt",
+	"can't restore a %s":             "%s kann nicht wiederhergestellt werden",
+	"The trash is empty":             "Der Papierkorb ist leer",
+	"%d deleted item(s) in the trash; %s restores the last": "%d gelöschte(s) Element(e) im Papierkorb; %s stellt das letzte wieder her",
+	"Delete the %d item(s) in the trash for good?":          "Die %d Element(e) im Papierkorb endgültig löschen?",
+	"Emptied the trash": "Papierkorb geleert",
+	"Undo: %v":          "Rückgängig: %v",
+	"Set sync.webdav_url in config.toml to sync": "Zum Synchronisieren sync.webdav_url in config.toml setzen",
+	"Syncing…":                                   "Synchronisiere…",
+	"Could not sync: %v":                         "Synchronisieren fehlgeschlagen: %v",
+	"Could not read synced progress: %v":         "Synchronisierter Fortschritt konnte nicht gelesen werden: %v",
+	"Synced: %d file(s) downloaded, %d uploaded": "Synchronisiert: %d Datei(en) heruntergeladen, %d hochgeladen",
+	"Series":       "Reihe",
+	"Rating":       "Bewertung",
+	"Contributors": "Mitwirkende",
+	"Language":     "Sprache",
+	"Publisher":    "Verlag",
+	"Published":    "Erschienen",
+	"Identifiers":  "Kennungen",
+	"Subjects":     "Themen",
+	"Tags":         "Tags",
+	"Words":        "Wörter",
+	"Pages":        "Seiten",
+	"File size":    "Dateigröße",
+	"Path":         "Pfad",
+	"Unreadable":   "Unlesbar",
+	"Scroll":       "Scrollen",
+	"j and k move a line at a time, like a web page":     "j und k bewegen um eine Zeile, wie auf einer Webseite",
+	"j and k turn a whole screen at a time, like a book": "j und k blättern einen ganzen Bildschirm um, wie ein Buch",
+	"reader":       "Lesen",
+	"help":         "Hilfe",
+	"files":        "Dateien",
+	"journal":      "Notizen",
+	"library":      "Bibliothek",
+	"off":          "aus",
+	"line":         "Zeile",
+	"band":         "Streifen",
+	"prev page":    "vorige Seite",
+	"next page":    "nächste Seite",
+	"go to start":  "zum Anfang",
+	"go to end":    "zum Ende",
+	"filter":       "filtern",
+	"clear filter": "Filter löschen",
+	"apply filter": "Filter anwenden",
+	"more":         "mehr",
+	"close help":   "Hilfe schließen",
+	"force quit":   "sofort beenden",
+	"Chapter {chapter}/{chapters} • Scroll: {scroll}%": "Kapitel {chapter}/{chapters} • Position: {scroll}%",
+	"read aloud":                           "vorlesen",
+	"previous passage":                     "vorherige Stelle",
+	"next passage":                         "nächste Stelle",
+	"This chapter has no read-aloud audio": "Dieses Kapitel hat keine Vorlese-Aufnahme",
+	"Could not play the audio: %v":         "Die Aufnahme konnte nicht abgespielt werden: %v",
+	"Audio player failed: %v":              "Audio-Player fehlgeschlagen: %v",
+	"End of the read-aloud audio":          "Ende der Vorlese-Aufnahme",
+	"♪ paused":                             "♪ angehalten",
+	"♪ reading aloud":                      "♪ wird vorgelesen",
+	"failed to open the audio: %w":         "Aufnahme konnte nicht geöffnet werden: %w",
+	"failed to copy the audio: %w":         "Aufnahme konnte nicht kopiert werden: %w",
+	"no audio player set in config.toml":   "kein Audio-Player in config.toml gesetzt",
+	"Read-aloud":                           "Vorlesen",
+	"Included":                             "Enthalten",
+	"%s, read by %s":                       "%s, gelesen von %s",
+	"Downloading %s...":                    "Lade %s herunter...",
+	"Download failed: %v":                  "Download fehlgeschlagen: %v",
+	"Downloaded %s":                        "%s heruntergeladen",
+	"Already in the library as %s":         "Bereits in der Bibliothek als %s",
+	"Articles":                             "Artikel",
+	"saved articles":                       "gespeicherte Artikel",
+	"Source":                               "Quelle",
+	"Already saved as %s":                  "Bereits gespeichert als %s",
+	"Could not save article: %v":           "Artikel konnte nicht gespeichert werden: %v",
+	"Saved %s":                             "%s gespeichert",
+	"Saving %s...":                         "Speichere %s...",
+	"no articles folder: set library.articles_folder": "kein Artikelordner: library.articles_folder setzen",
+	"Checking AO3 for new chapters...":                "Suche auf AO3 nach neuen Kapiteln...",
+	"Already in the library: %s":                      "Bereits in der Bibliothek: %s",
+	"Could not save AO3 work: %v":                     "AO3-Werk konnte nicht gespeichert werden: %v",
+	"Downloading from AO3...":                         "Lade von AO3 herunter...",
+	"AO3 check: %v":                                   "AO3-Prüfung: %v",
+	"New chapters: %s":                                "Neue Kapitel: %s",
+	"No new chapters":                                 "Keine neuen Kapitel",
+	"Building today's edition...":                     "Erstelle die heutige Ausgabe...",
+	"Today's edition is already in the library; use :digest -replace to build it again": "Die heutige Ausgabe ist schon in der Bibliothek; mit :digest -replace neu erstellen",
+	"Could not build today's edition: %v":                                               "Die heutige Ausgabe konnte nicht erstellt werden: %v",
+	"Today's edition: %d article(s)":                                                    "Heutige Ausgabe: %d Artikel",
+	"Today's edition: %d article(s), %d feed(s) could not be read":                      "Heutige Ausgabe: %d Artikel, %d Feed(s) konnten nicht gelesen werden",
+	"no digest folder: set digest.folder":                                               "kein Ordner für Ausgaben: digest.folder setzen",
+	"no feeds: add some to digest.feeds":                                                "keine Feeds: in digest.feeds eintragen",
+	"Checking mail for books...":                                                        "Suche in der Post nach Büchern...",
+	"no mailbox: set mail.maildir or mail.imap_server":                                  "kein Postfach: mail.maildir oder mail.imap_server setzen",
+	"Mail check: %v": "Postprüfung: %v",
+	"Mail check: %d email(s) could not be read: %v": "Postprüfung: %d E-Mail(s) konnten nicht gelesen werden: %v",
+	"Imported %d book(s) from email":                "%d Buch/Bücher aus E-Mails importiert",
+	"No new books by email":                         "Keine neuen Bücher per E-Mail",
+	"mark unknown word":                             "unbekanntes Wort merken",
+	"Removed %q from the vocabulary":                "%q aus dem Wortschatz entfernt",
+	"Added %q to the vocabulary (%s)":               "%q zum Wortschatz hinzugefügt (%s)",
+	"Could not save vocabulary: %v":                 "Wortschatz konnte nicht gespeichert werden: %v",
+	"Could not read vocabulary: %v":                 "Wortschatz konnte nicht gelesen werden: %v",
+	"Could not export vocabulary: %v":               "Wortschatz konnte nicht exportiert werden: %v",
+	"No vocabulary to export":                       "Kein Wortschatz zum Exportieren",
+	"Exported %d word(s) to %s":                     "%d Wort/Wörter nach %s exportiert",
+	"Could not save settings: %v":                   "Einstellungen konnten nicht gespeichert werden: %v",
+	"Could not save config: %v":                     "Konfiguration konnte nicht gespeichert werden: %v",
+}
@@ -0,0 +1,438 @@
+package config
+
+// Spanish is the built-in Spanish catalog
+var Spanish = Catalog{
+	"Accessible layout on":     "Diseño accesible activado",
+	"Accessible layout off":    "Diseño accesible desactivado",
+	" (1 book)":                " (1 libro)",
+	" (%d books)":              " (%d libros)",
+	"No folders here":          "No hay carpetas aquí",
+	"No folders or books here": "No hay carpetas ni libros aquí",
+	"up":                       "arriba",
+	"down":                     "abajo",
+	"open":                     "abrir",
+	"parent":                   "carpeta superior",
+	"home":                     "carpeta personal",
+	"show hidden":              "mostrar ocultos",
+	"Heading %d":               "Encabezado %d",
+	"%d words":                 "%d palabras",
+	"Reading":                  "Leyendo",
+	"Read":                     "Leído",
+	"Chapters":                 "Capítulos",
+	"type to filter • ↑/↓ move • enter jump • esc close": "escribe para filtrar • ↑/↓ mover • enter saltar • esc cerrar",
+	"usage: %s":                                    "uso: %s",
+	"not a chapter number: %s":                     "no es un número de capítulo: %s",
+	"not a percentage: %s":                         "no es un porcentaje: %s",
+	"Focus mode off":                               "Modo concentración desactivado",
+	"Focus mode is already on":                     "El modo concentración ya está activado",
+	"Focus mode on: %d min reading, %d min breaks": "Modo concentración activado: %d min de lectura, %d min de descanso",
+	"Theme: %s":                                    "Tema: %s",
+	"no book selected":                             "ningún libro seleccionado",
+	"bookmark %d":                                  "marcador %d",
+	"Bookmark %q added":                            "Marcador %q añadido",
+	"No bookmarks":                                 "No hay marcadores",
+	"Bookmarks: %s":                                "Marcadores: %s",
+	"scroll lines must be a number greater than 0":      "scroll_lines debe ser un número mayor que 0",
+	"page overlap must be 0 or more":                    "page_overlap debe ser 0 o más",
+	"section depth must be a heading level from 1 to 6": "section_depth debe ser un nivel de encabezado de 1 a 6",
+	"word spacing must be a number from 0 to 4":         "word_spacing debe ser un número de 0 a 4",
+	"line length must be a number of at least 20":       "line_length debe ser un número de al menos 20",
+	"%s must be one of: %s":                             "%s debe ser uno de: %s",
+	"unknown option: %s":                                "opción desconocida: %s",
+	"expected on or off, got %q":                        "se esperaba on u off, no %q",
+	"margin must be a number from 0 to 40":              "el margen debe ser un número de 0 a 40",
+	"(y/n)":                                             "(y/n)",
+	"Cancelled":                                         "Cancelado",
+	"unknown command: %s":                               "orden desconocida: %s",
+	"ambiguous command: %s":                             "orden ambigua: %s",
+	"no cover":                                          "sin portada",
+	"Description":                                       "Descripción",
+	"Not started":                                       "Sin empezar",
+	"Finished %s":                                       "Terminado el %s",
+	"January 2, 2006":                                   "2/1/2006",
+	"Finished":                                          "Terminado",
+	"%.0f%% (chapter %d/%d)":                            "%.0f%% (capítulo %d/%d)",
+	"No book selected":                                  "Ningún libro seleccionado",
+	"Book Details":                                      "Detalles del libro",
+	"read":                                              "leer",
+	"scroll up":                                         "subir",
+	"scroll down":                                       "bajar",
+	"back to library":                                   "volver a la biblioteca",
+	"quit":                                              "salir",
+	"No duplicate books":                                "No hay libros duplicados",
+	"%d duplicated: %s":                                 "%d duplicados: %s",
+	"Moved %d duplicate(s) to %s":                       "%d duplicado(s) movido(s) a %s",
+	"%s; could not move %s":                             "%s; no se pudo mover %s",
+	"Recent":                                            "Recientes",
+	"Browse":                                            "Explorar",
+	"Open a book":                                       "Abrir un libro",
+	"No recent files; books opened from outside the library are listed here": "No hay archivos recientes; aquí aparecen los libros abiertos desde fuera de la biblioteca",
+	"(missing)":                       "(no encontrado)",
+	"Could not save recent files: %v": "No se pudieron guardar los archivos recientes: %v",
+	"recent/browse":                   "recientes/explorar",
+	"forget":                          "olvidar",
+	"back":                            "volver",
+	"%s is managed by Calibre; change it there": "%s lo gestiona Calibre; cámbialo allí",
+	"Rename %s to %s?":                          "¿Renombrar %s como %s?",
+	"%s is outside the library":                 "%s está fuera de la biblioteca",
+	"Move %s to %s?":                            "¿Mover %s a %s?",
+	"Move %s to the trash?":                     "¿Mover %s a la papelera?",
+	"Moved to %s":                               "Movido a %s",
+	"Import failed: %v":                         "Falló la importación: %v",
+	"Imported %d book(s)":                       "%d libro(s) importado(s)",
+	", %d already in the library":               ", %d ya en la biblioteca",
+	", %d failed":                               ", %d fallaron",
+	"Importing %s...":                           "Importando %s...",
+	"not allowed in restricted mode":            "no permitido en el modo restringido",
+	"Opening %s":                                "Abriendo %s",
+	"case":                                      "mayúsculas",
+	"words":                                     "palabras",
+	"regex":                                     "regex",
+	"no matches":                                "sin resultados",
+	"none in this chapter":                      "ninguno en este capítulo",
+	"in %d of %d chapters":                      "en %d de %d capítulos",
+	"invalid pattern: %s":                       "patrón no válido: %s",
+	"next match":                                "resultado siguiente",
+	"previous match":                            "resultado anterior",
+	"match case":                                "distinguir mayúsculas",
+	"whole words":                               "palabras completas",
+	"regular expression":                        "expresión regular",
+	"keep highlights":                           "mantener resaltado",
+	"cancel":                                    "cancelar",
+	"break %dm":                                 "descanso %d min",
+	"focus %dm":                                 "concentración %d min",
+	"1 focus round":                             "1 ronda de concentración",
+	"%d focus rounds":                           "%d rondas de concentración",
+	"Break":                                     "Descanso",
+	"%s done. Rest your eyes for %d min;\nreading resumes when the break is over.": "%s hecho. Descansa la vista %d min;\nla lectura sigue cuando acabe el descanso.",
+	"enter skip break • q save and quit":                                           "enter saltar descanso • q guardar y salir",
+	"Break over, back to reading":                                                  "Fin del descanso, a seguir leyendo",
+	"Gallery • v list view • i details • / filter":                                 "Galería • v vista de lista • i detalles • / filtrar",
+	"No books found.":                  "No se encontraron libros.",
+	"previous book":                    "libro anterior",
+	"next book":                        "libro siguiente",
+	"row up":                           "fila arriba",
+	"row down":                         "fila abajo",
+	"first book":                       "primer libro",
+	"last book":                        "último libro",
+	"Everywhere":                       "En todas partes",
+	"Library":                          "Biblioteca",
+	"Library list":                     "Lista de libros",
+	"Cover gallery":                    "Galería de portadas",
+	"Book details":                     "Detalles del libro",
+	"File browser":                     "Explorador de archivos",
+	"Reader":                           "Lector",
+	"Search":                           "Búsqueda",
+	"Word lookup":                      "Consulta de palabras",
+	"Journal":                          "Notas",
+	"Key reference":                    "Referencia de teclas",
+	"Commands":                         "Órdenes",
+	"anywhere":                         "en todas partes",
+	"search keys and commands":         "buscar teclas y órdenes",
+	"Nothing matches %q":               "Nada coincide con %q",
+	"Keys":                             "Teclas",
+	"search":                           "buscar",
+	"top":                              "inicio",
+	"bottom":                           "final",
+	"Could not save highlight: %v":     "No se pudo guardar el resaltado: %v",
+	"Highlight added":                  "Resaltado añadido",
+	"Could not read highlights: %v":    "No se pudieron leer los resaltados: %v",
+	"Could not read notes: %v":         "No se pudieron leer las notas: %v",
+	"Could not export %s: %v":          "No se pudo exportar %s: %v",
+	"No highlights or notes to export": "No hay resaltados ni notas que exportar",
+	"Exported highlights of %d book(s) to %s":              "Resaltados de %d libro(s) exportados a %s",
+	"stopped after %s":                                     "detenido tras %s",
+	"Hook %s failed: %v":                                   "Falló el hook %s: %v",
+	"%s is not a Calibre library":                          "%s no es una biblioteca de Calibre",
+	"Importing %s progress from %s...":                     "Importando el progreso de %s desde %s...",
+	"Could not import progress: %v":                        "No se pudo importar el progreso: %v",
+	"Could not save progress: %v":                          "No se pudo guardar el progreso: %v",
+	"Imported the %s progress of %d book(s)":               "Progreso de %s importado para %d libro(s)",
+	", %d already further in cozy":                         ", %d ya más avanzados en cozy",
+	", %d not in the library":                              ", %d no están en la biblioteca",
+	", %d could not be opened":                             ", %d no se pudieron abrir",
+	"Chapter summaries, thoughts, questions... (Markdown)": "Resúmenes de capítulos, ideas, preguntas... (Markdown)",
+	"Notes on %s":                                          "Notas sobre %s",
+	"Could not save notes: %v":                             "No se pudieron guardar las notas: %v",
+	"Could not open the editor: %v":                        "No se pudo abrir el editor: %v",
+	"Editor failed: %v":                                    "Falló el editor: %v",
+	"Could not read notes back: %v":                        "No se pudieron volver a leer las notas: %v",
+	"Notes saved":                                          "Notas guardadas",
+	"save":                                                 "guardar",
+	"open in $EDITOR":                                      "abrir en $EDITOR",
+	"save and close":                                       "guardar y cerrar",
+	"Up next #%d":                                          "A continuación #%d",
+	"Duplicate":                                            "Duplicado",
+	"finished books":                                       "libros terminados",
+	"unreadable books":                                     "libros ilegibles",
+	"enter to show %s":                                     "enter muestra %s",
+	"enter to hide %s":                                     "enter oculta %s",
+	"Your Library":                                         "Tu biblioteca",
+	"Filter: ":                                             "Filtro: ",
+	"book":                                                 "libro",
+	"books":                                                "libros",
+	"no device path set in config.toml":                    "no hay ruta de dispositivo en config.toml",
+	"Loading...":                                           "Cargando...",
+	"Cozy - E-Book Reader":                                 "Cozy - Lector de libros electrónicos",
+	"Already on device: %s":                                "Ya está en el dispositivo: %s",
+	"Send failed: %v":                                      "Falló el envío: %v",
+	"Sent %s to device":                                    "%s enviado al dispositivo",
+	"Can't open %s: %v":                                    "No se puede abrir %s: %v",
+	"Can't open %s in restricted mode":                     "No se puede abrir %s en el modo restringido",
+	"read, or fold a section":                              "leer, o plegar una sección",
+	"toggle finished":                                      "mostrar/ocultar terminados",
+	"details":                                              "detalles",
+	"gallery":                                              "galería",
+	"send to device":                                       "enviar al dispositivo",
+	"refresh":                                              "actualizar",
+	"queue":                                                "poner en cola",
+	"move up the queue":                                    "subir en la cola",
+	"move down the queue":                                  "bajar en la cola",
+	"open a file":                                          "abrir un archivo",
+	"notes on the book":                                    "notas del libro",
+	"Look up: ":                                            "Consultar: ",
+	"online lookups are off; set dictionary.online_lookups in config.toml": "las consultas en línea están desactivadas; activa dictionary.online_lookups en config.toml",
+	"Lookup failed: %v":          "Falló la consulta: %v",
+	"No definition found for %q": "No se encontró ninguna definición de %q",
+	"esc close":                  "esc cerrar",
+	"↓/j more":                   "↓/j más",
+	"next word":                  "palabra siguiente",
+	"previous word":              "palabra anterior",
+	"line down":                  "línea abajo",
+	"line up":                    "línea arriba",
+	"select phrase":              "seleccionar frase",
+	"define":                     "definir",
+	"look up online":             "consultar en línea",
+	"highlight":                  "resaltar",
+	"stop lookup":                "dejar de consultar",
+	"Mark %s set":                "Marca %s puesta",
+	"No jump to go back from":    "No hay ningún salto del que volver",
+	"No mark %s":                 "No hay marca %s",
+	"%s: chapter %d":             "%s: capítulo %d",
+	"No marks":                   "No hay marcas",
+	"Marks: %s":                  "Marcas: %s",
+	"Could not save mark: %v":    "No se pudo guardar la marca: %v",
+	"Could not load library: %v": "No se pudo cargar la biblioteca: %v",
+	"Could not open book: %v":    "No se pudo abrir el libro: %v",
+	"Error: %v":                  "Error: %v",
+	"Press q to quit.":           "Pulsa q para salir.",
+	"Remote control: %v":         "Control remoto: %v",
+	"The file browser is off in restricted mode":      "El explorador de archivos está desactivado en el modo restringido",
+	"Could not open %s: %v":                           "No se pudo abrir %s: %v",
+	"command line":                                    "línea de órdenes",
+	"all keys and commands":                           "todas las teclas y órdenes",
+	"undo the last deletion":                          "deshacer el último borrado",
+	"sync with WebDAV":                                "sincronizar con WebDAV",
+	"External link: %s":                               "Enlace externo: %s",
+	"Open the next book in your queue, %s?":           "¿Abrir el siguiente libro de la cola, %s?",
+	"The queue is empty; press a on a book to add it": "La cola está vacía; pulsa a sobre un libro para añadirlo",
+	"Up next: %s":                                     "A continuación: %s",
+	"Can't queue %s: %v":                              "No se puede poner %s en la cola: %v",
+	"Could not save the queue: %v":                    "No se pudo guardar la cola: %v",
+	"Queued %s (#%d)":                                 "%s en la cola (#%d)",
+	"Removed %s from the queue":                       "%s quitado de la cola",
+	"no book open":                                    "ningún libro abierto",
+	"chapter must be between 1 and %d":                "el capítulo debe estar entre 1 y %d",
+	"percentage must be between 0 and 100":            "el porcentaje debe estar entre 0 y 100",
+	"no bookmark named %q":                            "no hay ningún marcador llamado %q",
+	"No book loaded":                                  "Ningún libro cargado",
+	"Chapter %d/%d: ":                                 "Capítulo %d/%d: ",
+	"Progress saved":                                  "Progreso guardado",
+	"next chapter":                                    "capítulo siguiente",
+	"previous chapter":                                "capítulo anterior",
+	"next section":                                    "sección siguiente",
+	"previous section":                                "sección anterior",
+	"first chapter":                                   "primer capítulo",
+	"last chapter":                                    "último capítulo",
+	"go to chapter":                                   "ir al capítulo",
+	"half page up":                                    "media página arriba",
+	"half page down":                                  "media página abajo",
+	"look up a word":                                  "consultar una palabra",
+	"type a word to look up":                          "escribir una palabra para consultar",
+	"find":                                            "buscar",
+	"zen mode":                                        "modo zen",
+	"reading ruler":                                   "regla de lectura",
+	"accessible layout":                               "diseño accesible",
+	"speed reading":                                   "lectura rápida",
+	"set a mark":                                      "poner una marca",
+	"jump to a mark ('' jumps back)":                  "saltar a una marca ('' vuelve)",
+	"minimal/verbose status":                          "estado breve/detallado",
+	"Could not reload config: %v":                     "No se pudo recargar la configuración: %v",
+	"Settings reloaded":                               "Configuración recargada",
+	"%d min":                                          "%d min",
+	"%dh %02d min":                                    "%d h %02d min",
+	"Time for a break?":                               "¿Hora de un descanso?",
+	"You've been reading for %s.":                     "Llevas leyendo %s.",
+	"s snooze %d min • q save and quit • esc keep reading": "s posponer %d min • q guardar y salir • esc seguir leyendo",
+	"remote control socket %s is in use by another cozy":   "otro cozy está usando el socket de control remoto %s",
+	"no reading position saved for this book":              "no hay posición de lectura guardada para este libro",
+	"chapter %d":     "capítulo %d",
+	"chapter %d, %s": "capítulo %d, %s",
+	"Welcome back":   "Bienvenido de nuevo",
+	"You left off in chapter %d, but you have read up to\n%s.":                                   "Lo dejaste en el capítulo %d, pero has leído hasta\n%s.",
+	"enter resume here • f jump to furthest":                                                     "enter seguir aquí • f saltar a lo más avanzado",
+	"enter keep position • s start over":                                                         "enter mantener posición • s empezar de nuevo",
+	"enter keep position • f find my place • s start over":                                       "enter mantener posición • f buscar mi sitio • s empezar de nuevo",
+	"This book has changed":                                                                      "Este libro ha cambiado",
+	"The file was replaced since you last read it, so your\nplace in chapter %d may have moved.": "El archivo se reemplazó desde tu última lectura, así que tu\nsitio en el capítulo %d puede haberse movido.",
+	"Could not find your place in the new file":                                                  "No se encontró tu sitio en el archivo nuevo",
+	"Found your place in chapter %d":                                                             "Tu sitio está en el capítulo %d",
+	"A few words (optional)":                                                                     "Unas palabras (opcional)",
+	"You finished %s":                                                                            "Has terminado %s",
+	"How many stars?":                                                                            "¿Cuántas estrellas?",
+	"1-5 to rate • esc to skip":                                                                  "1-5 para valorar • esc para saltar",
+	"Rated":                                                                                      "Valorado",
+	"enter to save • esc to save without a review":                                               "enter guardar • esc guardar sin reseña",
+	"Could not save the review: %v":                                                              "No se pudo guardar la reseña: %v",
+	"Rated %s %s":                                                                                "%s valorado con %s",
+	"%d wpm":                                                                                     "%d ppm",
+	"paused":                                                                                     "en pausa",
+	"pause":                                                                                      "pausar",
+	"rewind":                                                                                     "retroceder",
+	"skip ahead":                                                                                 "avanzar",
+	"faster":                                                                                     "más rápido",
+	"slower":                                                                                     "más lento",
+	"back to the page":                                                                           "volver a la página",
+	"Reading ruler: %s":                                                                          "Regla de lectura: %s",
+	"Chapter %d of %d":                                                                           "Capítulo %d de %d",
+	"Book %s of %d in %s":                                                                        "Libro %s de %d de %s",
+	"Open the next in the series, %s?":                                                           "¿Abrir el siguiente de la serie, %s?",
+	"Open the next in the series, %s (%s)?":                                                      "¿Abrir el siguiente de la serie, %s (%s)?",
+	"under a minute":                                                                             "menos de un minuto",
+	"%d words, about %d pages":                                                                   "%d palabras, unas %d páginas",
+	"%s to go":                                                                                   "faltan %s",
+	"%s to go at %d wpm":                                                                         "faltan %s a %d ppm",
+	"Finished!":                                                                                  "¡Terminado!",
+	"any key to continue":                                                                        "cualquier tecla para continuar",
+	"any key to quit":                                                                            "cualquier tecla para salir",
+	"Reading session":                                                                            "Sesión de lectura",
+	"Time":                                                                                       "Tiempo",
+	"Progress":                                                                                   "Progreso",
+	"Left":                                                                                       "Falta",
+	"Where are your books?":                                                                      "¿Dónde están tus libros?",
+	"Browse to the folder holding your e-books and press enter to use it.": "Ve a la carpeta con tus libros electrónicos y pulsa enter para usarla.",
+	"Pick a theme": "Elige un tema",
+	"Move through the themes to try them on.":           "Recorre los temas para probarlos.",
+	"How should the reader move?":                       "¿Cómo debe avanzar el texto?",
+	"You can change this later with :set paged on|off.": "Puedes cambiarlo luego con :set paged on|off.",
+	"Welcome to cozy":                                   "Bienvenido a cozy",
+	"Step %d of 3":                                      "Paso %d de 3",
+	"choose":                                            "elegir",
+	"skip setup":                                        "saltar la configuración",
+	"%dh %02dm":                                         "%d h %02d min",
+	"Config: %s":                                        "Configuración: %s",
+	"%d more problems in config.toml; run cozy -check-config to list them": "%d problemas más en config.toml; cozy -check-config los muestra",
+	"%s undoes":                      "%s deshace",
+	"Moved %s to the trash; %s":      "%s movido a la papelera; %s",
+	"%s has no reading progress":     "%s no tiene progreso de lectura",
+	"Cleared the progress of %s; %s": "Progreso de %s borrado; %s",
+	"Bookmark %q deleted; %s":        "Marcador %q borrado; %s",
+	"nothing to undo":                "nada que deshacer",
+	"Restored %s":                    "%s restaurado",
+	"Restored the progress of %s":    "Progreso de %s restaurado",
+	"Restored bookmark %q":           "Marcador %q restaurado",
+	"can't restore a %s":             "no se puede restaurar un %s",
+	"The trash is empty":             "La papelera está vacía",
+	"%d deleted item(s) in the trash; %s restores the last": "%d elemento(s) borrado(s) en la papelera; %s restaura el último",
+	"Delete the %d item(s) in the trash for good?":          "¿Borrar definitivamente los %d elemento(s) de la papelera?",
+	"Emptied the trash": "Papelera vaciada",
+	"Undo: %v":          "Deshacer: %v",
+	"Set sync.webdav_url in config.toml to sync": "Define sync.webdav_url en config.toml para sincronizar",
+	"Syncing…":                                   "Sincronizando…",
+	"Could not sync: %v":                         "No se pudo sincronizar: %v",
+	"Could not read synced progress: %v":         "No se pudo leer el progreso sincronizado: %v",
+	"Synced: %d file(s) downloaded, %d uploaded": "Sincronizado: %d archivo(s) descargado(s), %d subido(s)",
+	"Series":       "Serie",
+	"Rating":       "Valoración",
+	"Contributors": "Colaboradores",
+	"Language":     "Idioma",
+	"Publisher":    "Editorial",
+	"Published":    "Publicado",
+	"Identifiers":  "Identificadores",
+	"Subjects":     "Temas",
+	"Tags":         "Etiquetas",
+	"Words":        "Palabras",
+	"Pages":        "Páginas",
+	"File size":    "Tamaño",
+	"Path":         "Ruta",
+	"Unreadable":   "Ilegibles",
+	"Scroll":       "Desplazar",
+	"j and k move a line at a time, like a web page":     "j y k mueven una línea cada vez, como una página web",
+	"j and k turn a whole screen at a time, like a book": "j y k pasan una pantalla entera cada vez, como un libro",
+	"reader":       "lector",
+	"help":         "ayuda",
+	"files":        "archivos",
+	"journal":      "notas",
+	"library":      "biblioteca",
+	"off":          "no",
+	"line":         "línea",
+	"band":         "franja",
+	"prev page":    "página anterior",
+	"next page":    "página siguiente",
+	"go to start":  "ir al inicio",
+	"go to end":    "ir al final",
+	"filter":       "filtrar",
+	"clear filter": "quitar filtro",
+	"apply filter": "aplicar filtro",
+	"more":         "más",
+	"close help":   "cerrar ayuda",
+	"force quit":   "forzar salida",
+	"Chapter {chapter}/{chapters} • Scroll: {scroll}%": "Capítulo {chapter}/{chapters} • Posición: {scroll}%",
+	"read aloud":                           "leer en voz alta",
+	"previous passage":                     "pasaje anterior",
+	"next passage":                         "pasaje siguiente",
+	"This chapter has no read-aloud audio": "Este capítulo no tiene audio para leer en voz alta",
+	"Could not play the audio: %v":         "No se pudo reproducir el audio: %v",
+	"Audio player failed: %v":              "Falló el reproductor de audio: %v",
+	"End of the read-aloud audio":          "Fin del audio",
+	"♪ paused":                             "♪ en pausa",
+	"♪ reading aloud":                      "♪ leyendo en voz alta",
+	"failed to open the audio: %w":         "no se pudo abrir el audio: %w",
+	"failed to copy the audio: %w":         "no se pudo copiar el audio: %w",
+	"no audio player set in config.toml":   "no hay reproductor de audio en config.toml",
+	"Read-aloud":                           "Audio",
+	"Included":                             "Incluido",
+	"%s, read by %s":                       "%s, narrado por %s",
+	"Downloading %s...":                    "Descargando %s...",
+	"Download failed: %v":                  "Error en la descarga: %v",
+	"Downloaded %s":                        "%s descargado",
+	"Already in the library as %s":         "Ya está en la biblioteca como %s",
+	"Articles":                             "Artículos",
+	"saved articles":                       "artículos guardados",
+	"Source":                               "Fuente",
+	"Already saved as %s":                  "Ya guardado como %s",
+	"Could not save article: %v":           "No se pudo guardar el artículo: %v",
+	"Saved %s":                             "%s guardado",
+	"Saving %s...":                         "Guardando %s...",
+	"no articles folder: set library.articles_folder": "no hay carpeta de artículos: define library.articles_folder",
+	"Checking AO3 for new chapters...":                "Buscando capítulos nuevos en AO3...",
+	"Already in the library: %s":                      "Ya está en la biblioteca: %s",
+	"Could not save AO3 work: %v":                     "No se pudo guardar la obra de AO3: %v",
+	"Downloading from AO3...":                         "Descargando de AO3...",
+	"AO3 check: %v":                                   "Comprobación de AO3: %v",
+	"New chapters: %s":                                "Capítulos nuevos: %s",
+	"No new chapters":                                 "No hay capítulos nuevos",
+	"Building today's edition...":                     "Preparando la edición de hoy...",
+	"Today's edition is already in the library; use :digest -replace to build it again": "La edición de hoy ya está en la biblioteca; usa :digest -replace para volver a prepararla",
+	"Could not build today's edition: %v":                                               "No se pudo preparar la edición de hoy: %v",
+	"Today's edition: %d article(s)":                                                    "Edición de hoy: %d artículo(s)",
+	"Today's edition: %d article(s), %d feed(s) could not be read":                      "Edición de hoy: %d artículo(s), no se pudieron leer %d fuente(s)",
+	"no digest folder: set digest.folder":                                               "no hay carpeta para las ediciones: define digest.folder",
+	"no feeds: add some to digest.feeds":                                                "no hay fuentes: añade algunas en digest.feeds",
+	"Checking mail for books...":                                                        "Buscando libros en el correo...",
+	"no mailbox: set mail.maildir or mail.imap_server":                                  "sin buzón: configura mail.maildir o mail.imap_server",
+	"Mail check: %v": "Revisión del correo: %v",
+	"Mail check: %d email(s) could not be read: %v": "Revisión del correo: no se pudieron leer %d correo(s): %v",
+	"Imported %d book(s) from email":                "%d libro(s) importado(s) del correo",
+	"No new books by email":                         "No hay libros nuevos por correo",
+	"mark unknown word":                             "marcar palabra desconocida",
+	"Removed %q from the vocabulary":                "%q quitada del vocabulario",
+	"Added %q to the vocabulary (%s)":               "%q añadida al vocabulario (%s)",
+	"Could not save vocabulary: %v":                 "No se pudo guardar el vocabulario: %v",
+	"Could not read vocabulary: %v":                 "No se pudo leer el vocabulario: %v",
+	"Could not export vocabulary: %v":               "No se pudo exportar el vocabulario: %v",
+	"No vocabulary to export":                       "No hay vocabulario que exportar",
+	"Exported %d word(s) to %s":                     "%d palabra(s) exportada(s) a %s",
+	"Could not save settings: %v":                   "No se pudieron guardar los ajustes: %v",
+	"Could not save config: %v":                     "No se pudo guardar la configuración: %v",
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// withFileLock runs fn while holding an exclusive lock on path+".lock", so
+// several cozy instances don't write the same file at once
+func withFileLock(path string, fn func() error) error {
+	file, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open lock file: %w", err)
+	}
+	defer file.Close()
+
+	if err := lockFile(file); err != nil {
+		return fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	defer unlockFile(file)
+
+	return fn()
+}
+
+// mergeUnchanged takes the entries another cozy instance saved to a file
+// since it was loaded here, for the keys not changed here: they replace
+// the local ones, and keys the file no longer has are dropped
+func mergeUnchanged[V any](local, onDisk map[string]V, changed map[string]bool) {
+	for key := range local {
+		if _, ok := onDisk[key]; !ok && !changed[key] {
+			delete(local, key)
+		}
+	}
+	for key, value := range onDisk {
+		if !changed[key] {
+			local[key] = value
+		}
+	}
+}
+
+// saveMerged writes data as JSON to a file of the data directory while
+// holding its lock. What other cozy instances saved to the file since it
+// was loaded here is handed to merge first; a file that can't be read is
+// overwritten rather than blocking saves forever. name says what the file
+// holds, for errors.
+func saveMerged[T any](path, name string, data *T, read func(string) (*T, error), merge func(onDisk *T)) error {
+	return withFileLock(path, func() error {
+		if onDisk, err := read(path); err == nil {
+			merge(onDisk)
+		}
+
+		encoded, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", name, err)
+		}
+
+		if err := writeFileAtomic(path, encoded, 0644); err != nil {
+			return fmt.Errorf("failed to write %s file: %w", name, err)
+		}
+		return nil
+	})
+}
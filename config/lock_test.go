@@ -0,0 +1,64 @@
+package config
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestMergeUnchanged(t *testing.T) {
+	tests := []struct {
+		name    string
+		local   map[string]int
+		onDisk  map[string]int
+		changed map[string]bool
+		want    map[string]int
+	}{
+		{
+			name:    "changed here",
+			local:   map[string]int{"a": 2},
+			onDisk:  map[string]int{"a": 1},
+			changed: map[string]bool{"a": true},
+			want:    map[string]int{"a": 2},
+		},
+		{
+			name:   "changed elsewhere",
+			local:  map[string]int{"a": 1},
+			onDisk: map[string]int{"a": 3},
+			want:   map[string]int{"a": 3},
+		},
+		{
+			name:   "added elsewhere",
+			local:  map[string]int{"a": 1},
+			onDisk: map[string]int{"a": 1, "b": 1},
+			want:   map[string]int{"a": 1, "b": 1},
+		},
+		{
+			name:   "deleted elsewhere",
+			local:  map[string]int{"a": 1, "b": 1},
+			onDisk: map[string]int{"a": 1},
+			want:   map[string]int{"a": 1},
+		},
+		{
+			name:    "added here",
+			local:   map[string]int{"a": 1, "b": 1},
+			onDisk:  map[string]int{"a": 1},
+			changed: map[string]bool{"b": true},
+			want:    map[string]int{"a": 1, "b": 1},
+		},
+		{
+			name:    "deleted here",
+			local:   map[string]int{"a": 1},
+			onDisk:  map[string]int{"a": 1, "b": 1},
+			changed: map[string]bool{"b": true},
+			want:    map[string]int{"a": 1},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mergeUnchanged(test.local, test.onDisk, test.changed)
+			if !maps.Equal(test.local, test.want) {
+				t.Errorf("got %v, want %v", test.local, test.want)
+			}
+		})
+	}
+}
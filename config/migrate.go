@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// legacyConfigDir is where cozy kept its config on every platform before
+// it used the platform's config directory
+func legacyConfigDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".config", "cozy"), nil
+}
+
+// migrateLegacyConfig moves the files of the legacy config folder into
+// ConfigDir when that has no config yet. It returns the folder the files
+// came from, or "" if there was nothing to move.
+func migrateLegacyConfig() (string, error) {
+	if configPathOverride != "" {
+		return "", nil
+	}
+	legacyDir, err := legacyConfigDir()
+	if err != nil {
+		return "", nil
+	}
+	configDir, err := ConfigDir()
+	if err != nil || filepath.Clean(legacyDir) == filepath.Clean(configDir) {
+		return "", nil
+	}
+	if _, err := os.Stat(filepath.Join(legacyDir, "config.toml")); err != nil {
+		return "", nil
+	}
+	if _, err := os.Stat(filepath.Join(configDir, "config.toml")); err == nil {
+		return "", nil
+	}
+
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+	entries, err := os.ReadDir(legacyDir)
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		target := filepath.Join(configDir, entry.Name())
+		if _, err := os.Stat(target); err == nil {
+			continue
+		}
+		if err := os.Rename(filepath.Join(legacyDir, entry.Name()), target); err != nil {
+			return "", fmt.Errorf("failed to move %s to %s: %w", legacyDir, configDir, err)
+		}
+	}
+	os.Remove(legacyDir) // Only succeeds once everything has moved
+	return legacyDir, nil
+}
+
+// relocateDataDir points data_dir at the new config folder when it was
+// inside the legacy one, reporting whether it changed
+func (c *Config) relocateDataDir(legacyDir string) bool {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(legacyDir, c.DataDir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	c.DataDir = filepath.Join(configDir, rel)
+	return true
+}
@@ -3,22 +3,63 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"time"
 )
 
 // BookProgress tracks reading progress for a book
 type BookProgress struct {
-	BookPath       string `json:"book_path"`
-	CurrentChapter int    `json:"current_chapter"`
-	ScrollOffset   int    `json:"scroll_offset"` // Viewport Y offset within chapter
-	TotalChapters  int    `json:"total_chapters"`
-	Finished       bool   `json:"finished"`
+	BookPath       string          `json:"book_path"`
+	CurrentChapter int             `json:"current_chapter"`
+	ScrollOffset   int             `json:"scroll_offset"`         // Viewport Y offset within chapter
+	TextOffset     int             `json:"text_offset,omitempty"` // Letters before the top line, survives re-wrapping
+	TotalChapters  int             `json:"total_chapters"`
+	Finished       bool            `json:"finished"`
+	FinishedAt     time.Time       `json:"finished_at,omitzero"`
+	Bookmarks      []Bookmark      `json:"bookmarks,omitempty"`
+	Marks          map[string]Mark `json:"marks,omitempty"` // Key is the letter
+
+	ReadChapters    []int `json:"read_chapters,omitempty"`    // Chapters scrolled to the end
+	FurthestChapter int   `json:"furthest_chapter,omitempty"` // Furthest position reached, which
+	FurthestOffset  int   `json:"furthest_offset,omitempty"`  // stays when flipping back to reread
+
+	FileHash string `json:"file_hash,omitempty"` // SHA-256 of the book file the position is in
+	Context  string `json:"context,omitempty"`   // Letters at the position, to find it in another edition
+
+	UpdatedAt time.Time `json:"updated_at,omitzero"` // Last save, for merging synced copies
+
+	// Bookmarks deleted, by name, and when, so merging with a synced copy
+	// that still has them doesn't bring them back. Kept for removalAge.
+	RemovedBookmarks map[string]time.Time `json:"removed_bookmarks,omitempty"`
+}
+
+// Bookmark is a named reading position
+type Bookmark struct {
+	Name         string `json:"name"`
+	Chapter      int    `json:"chapter"`
+	ScrollOffset int    `json:"scroll_offset"`
+	TextOffset   int    `json:"text_offset,omitempty"`
+
+	Created time.Time `json:"created,omitzero"` // Tells it from a bookmark of the same name deleted before
+}
+
+// Mark is a position set with m and a letter, as in vim. The mark "'" is
+// where the last jump to a mark started.
+type Mark struct {
+	Chapter      int `json:"chapter"`
+	ScrollOffset int `json:"scroll_offset"`
+	TextOffset   int `json:"text_offset,omitempty"`
 }
 
 // ProgressData stores all reading progress
 type ProgressData struct {
 	Books map[string]BookProgress `json:"books"` // Key is book path
+
+	changed map[string]bool      // Books updated since the last save
+	cleared map[string]time.Time // Books whose progress was removed, and when
 }
 
 // LoadProgress loads reading progress from the data directory
@@ -27,8 +68,43 @@ func LoadProgress(cfg *Config) (*ProgressData, error) {
 		return nil, err
 	}
 
-	progressPath := filepath.Join(cfg.DataDirectory(), "progress.json")
+	progress, err := readProgressFile(filepath.Join(cfg.DataDirectory(), "progress.json"))
+	if err != nil {
+		return nil, err
+	}
+	progress.mergeSynced(cfg)
+	return progress, nil
+}
 
+// mergeSynced merges in the progress of books in the sync directory, see
+// sync.go. Books it changes are saved with the next SaveProgress.
+func (p *ProgressData) mergeSynced(cfg *Config) {
+	if cfg.SyncDirectory() == "" {
+		return
+	}
+	for bookPath, synced := range readSyncDirectory(cfg) {
+		if synced.Progress == nil {
+			// Progress cleared on another machine since it was saved here
+			if local, ok := p.Books[bookPath]; ok && !synced.ProgressCleared.IsZero() && !local.UpdatedAt.After(synced.ProgressCleared) {
+				delete(p.Books, bookPath)
+				p.markCleared(bookPath, synced.ProgressCleared)
+			}
+			continue
+		}
+		merged := *synced.Progress
+		if local, ok := p.Books[bookPath]; ok {
+			merged = mergeProgress(local, merged)
+		}
+		merged.BookPath = bookPath
+		if !sameJSON(merged, p.Books[bookPath]) {
+			p.Books[bookPath] = merged
+			p.markChanged(bookPath)
+		}
+	}
+}
+
+// readProgressFile reads a progress file, which may not exist yet
+func readProgressFile(progressPath string) (*ProgressData, error) {
 	// If file doesn't exist, return empty progress
 	if _, err := os.Stat(progressPath); os.IsNotExist(err) {
 		return &ProgressData{
@@ -53,26 +129,64 @@ func LoadProgress(cfg *Config) (*ProgressData, error) {
 	return &progress, nil
 }
 
-// SaveProgress saves reading progress to the data directory
+// SaveProgress saves reading progress to the data directory. Other cozy
+// instances may have saved since this one loaded the file, so their
+// progress is merged in: books changed here are written, the rest are
+// taken from the file, and books it no longer has are dropped.
 func SaveProgress(cfg *Config, progress *ProgressData) error {
 	if err := cfg.EnsureDataDir(); err != nil {
 		return err
 	}
 
-	progressPath := filepath.Join(cfg.DataDirectory(), "progress.json")
+	now := time.Now()
+	for path := range progress.changed {
+		if book, ok := progress.Books[path]; ok {
+			book.UpdatedAt = now
+			progress.Books[path] = book
+		}
+	}
 
-	data, err := json.MarshalIndent(progress, "", "  ")
+	progressPath := filepath.Join(cfg.DataDirectory(), "progress.json")
+	err := saveMerged(progressPath, "progress", progress, readProgressFile, func(onDisk *ProgressData) {
+		mergeUnchanged(progress.Books, onDisk.Books, progress.changed)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to marshal progress: %w", err)
+		return err
 	}
 
-	if err := os.WriteFile(progressPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write progress file: %w", err)
+	for path := range progress.changed {
+		synced := SyncedBook{ProgressCleared: progress.cleared[path]}
+		if book, ok := progress.Books[path]; ok {
+			synced = SyncedBook{Progress: &book}
+		}
+		if err := updateSyncedBook(cfg, path, synced); err != nil {
+			return fmt.Errorf("failed to write sync file: %w", err)
+		}
 	}
 
+	progress.changed = nil
+	progress.cleared = nil
 	return nil
 }
 
+// markChanged records that a book's progress needs saving
+func (p *ProgressData) markChanged(bookPath string) {
+	if p.changed == nil {
+		p.changed = make(map[string]bool)
+	}
+	p.changed[bookPath] = true
+}
+
+// markCleared records that a book's progress was removed, so the removal
+// is synced rather than undone by the synced copy
+func (p *ProgressData) markCleared(bookPath string, cleared time.Time) {
+	if p.cleared == nil {
+		p.cleared = make(map[string]time.Time)
+	}
+	p.cleared[bookPath] = cleared
+	p.markChanged(bookPath)
+}
+
 // GetBookProgress retrieves progress for a specific book
 func (p *ProgressData) GetBookProgress(bookPath string) (BookProgress, bool) {
 	progress, exists := p.Books[bookPath]
@@ -80,22 +194,170 @@ func (p *ProgressData) GetBookProgress(bookPath string) (BookProgress, bool) {
 }
 
 // SetBookProgress updates progress for a specific book
-func (p *ProgressData) SetBookProgress(bookPath string, chapter, offset, totalChapters int) {
+func (p *ProgressData) SetBookProgress(bookPath string, chapter, offset, textOffset, totalChapters int) {
+	// Finished status, bookmarks and read chapters are kept
+	existing := p.Books[bookPath]
+	existing.BookPath = bookPath
+	existing.CurrentChapter = chapter
+	existing.ScrollOffset = offset
+	existing.TextOffset = textOffset
+	existing.TotalChapters = totalChapters
+	existing.Context = "" // Set again by SetPositionContext
+	if chapter > existing.FurthestChapter || (chapter == existing.FurthestChapter && textOffset > existing.FurthestOffset) {
+		existing.FurthestChapter = chapter
+		existing.FurthestOffset = textOffset
+	}
+	p.Books[bookPath] = existing
+	p.markChanged(bookPath)
+}
+
+// SetPositionContext records which file a book's position is in and the
+// text at it, see ebook.RelocatePosition
+func (p *ProgressData) SetPositionContext(bookPath, fileHash, context string) {
+	existing := p.Books[bookPath]
+	existing.BookPath = bookPath
+	existing.FileHash = fileHash
+	existing.Context = context
+	p.Books[bookPath] = existing
+	p.markChanged(bookPath)
+}
+
+// RestartBook forgets how far a book was read, for reading it from the
+// start. Bookmarks, marks and the finished status are kept.
+func (p *ProgressData) RestartBook(bookPath string) {
 	existing := p.Books[bookPath]
-	p.Books[bookPath] = BookProgress{
-		BookPath:       bookPath,
-		CurrentChapter: chapter,
-		ScrollOffset:   offset,
-		TotalChapters:  totalChapters,
-		Finished:       existing.Finished, // Preserve finished status
+	existing.BookPath = bookPath
+	existing.CurrentChapter = 0
+	existing.ScrollOffset = 0
+	existing.TextOffset = 0
+	existing.Context = ""
+	existing.ReadChapters = nil
+	existing.FurthestChapter = 0
+	existing.FurthestOffset = 0
+	p.Books[bookPath] = existing
+	p.markChanged(bookPath)
+}
+
+// MarkChapterRead records that a chapter was scrolled to the end
+func (p *ProgressData) MarkChapterRead(bookPath string, chapter int) {
+	existing := p.Books[bookPath]
+	if existing.ChapterRead(chapter) {
+		return
 	}
+	existing.BookPath = bookPath
+	existing.ReadChapters = append(slices.Clone(existing.ReadChapters), chapter)
+	slices.Sort(existing.ReadChapters)
+	p.Books[bookPath] = existing
+	p.markChanged(bookPath)
 }
 
-// SetBookFinished marks a book as finished or unfinished
+// SetBookFinished marks a book as finished or unfinished, recording when
+// it was finished
 func (p *ProgressData) SetBookFinished(bookPath string, finished bool) {
 	existing := p.Books[bookPath]
+	existing.BookPath = bookPath
+	if finished && !existing.Finished {
+		existing.FinishedAt = time.Now()
+	} else if !finished {
+		existing.FinishedAt = time.Time{}
+	}
 	existing.Finished = finished
 	p.Books[bookPath] = existing
+	p.markChanged(bookPath)
+}
+
+// MoveBook gives the progress of a book to another path, for a book that
+// was moved or a duplicate that was removed. Progress already saved for the
+// new path is kept.
+func (p *ProgressData) MoveBook(from, to string) {
+	progress, ok := p.Books[from]
+	if !ok {
+		return
+	}
+	delete(p.Books, from)
+	p.markCleared(from, time.Now())
+	if _, exists := p.Books[to]; exists {
+		return
+	}
+	progress.BookPath = to
+	p.Books[to] = progress
+	p.markChanged(to)
+}
+
+// RemoveBook forgets a book's progress, returning what was removed
+func (p *ProgressData) RemoveBook(bookPath string) (BookProgress, bool) {
+	progress, ok := p.Books[bookPath]
+	if !ok {
+		return BookProgress{}, false
+	}
+	delete(p.Books, bookPath)
+	p.markCleared(bookPath, time.Now())
+	return progress, true
+}
+
+// RestoreBook puts back progress removed with RemoveBook
+func (p *ProgressData) RestoreBook(progress BookProgress) {
+	p.Books[progress.BookPath] = progress
+	delete(p.cleared, progress.BookPath)
+	p.markChanged(progress.BookPath)
+}
+
+// AddBookmark stores a bookmark for a book, replacing one with the same name
+func (p *ProgressData) AddBookmark(bookPath string, bookmark Bookmark) {
+	p.markChanged(bookPath)
+	existing := p.Books[bookPath]
+	existing.BookPath = bookPath
+	bookmark.Created = time.Now()
+	if _, ok := existing.RemovedBookmarks[bookmark.Name]; ok {
+		existing.RemovedBookmarks = maps.Clone(existing.RemovedBookmarks)
+		delete(existing.RemovedBookmarks, bookmark.Name)
+	}
+	for i, b := range existing.Bookmarks {
+		if b.Name == bookmark.Name {
+			existing.Bookmarks[i] = bookmark
+			p.Books[bookPath] = existing
+			return
+		}
+	}
+	existing.Bookmarks = append(existing.Bookmarks, bookmark)
+	p.Books[bookPath] = existing
+}
+
+// RemoveBookmark deletes a named bookmark, reporting whether it existed
+func (p *ProgressData) RemoveBookmark(bookPath, name string) bool {
+	existing := p.Books[bookPath]
+	for i, b := range existing.Bookmarks {
+		if b.Name == name {
+			existing.Bookmarks = append(existing.Bookmarks[:i:i], existing.Bookmarks[i+1:]...)
+			existing.RemovedBookmarks = pruneRemovals(maps.Clone(existing.RemovedBookmarks))
+			if existing.RemovedBookmarks == nil {
+				existing.RemovedBookmarks = make(map[string]time.Time)
+			}
+			existing.RemovedBookmarks[name] = time.Now()
+			p.Books[bookPath] = existing
+			p.markChanged(bookPath)
+			return true
+		}
+	}
+	return false
+}
+
+// SetMark stores a mark for a book, replacing one with the same letter
+func (p *ProgressData) SetMark(bookPath, name string, mark Mark) {
+	existing := p.Books[bookPath]
+	existing.BookPath = bookPath
+	existing.Marks = maps.Clone(existing.Marks)
+	if existing.Marks == nil {
+		existing.Marks = make(map[string]Mark)
+	}
+	existing.Marks[name] = mark
+	p.Books[bookPath] = existing
+	p.markChanged(bookPath)
+}
+
+// ChapterRead reports whether a chapter was scrolled to the end
+func (bp BookProgress) ChapterRead(chapter int) bool {
+	return slices.Contains(bp.ReadChapters, chapter)
 }
 
 // GetCompletionPercentage calculates completion percentage for a book
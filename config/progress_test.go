@@ -0,0 +1,116 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testConfig returns a config whose data directory is a temporary folder
+func testConfig(t *testing.T) *Config {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.DataDir = t.TempDir()
+	cfg.Library.Path = t.TempDir()
+	return &cfg
+}
+
+func TestSaveProgress(t *testing.T) {
+	tests := []struct {
+		name      string
+		elsewhere func(p *ProgressData) // Saved by another instance first
+		here      func(p *ProgressData)
+		corrupt   bool           // The file is unreadable before this instance saves
+		want      map[string]int // Chapter of each book, saved
+	}{
+		{
+			name: "changed here",
+			here: func(p *ProgressData) { p.SetBookProgress("a.epub", 5, 0, 0, 10) },
+			want: map[string]int{"a.epub": 5, "b.epub": 2},
+		},
+		{
+			name:      "changed elsewhere",
+			elsewhere: func(p *ProgressData) { p.SetBookProgress("b.epub", 7, 0, 0, 10) },
+			here:      func(p *ProgressData) { p.SetBookProgress("a.epub", 5, 0, 0, 10) },
+			want:      map[string]int{"a.epub": 5, "b.epub": 7},
+		},
+		{
+			name:      "changed on both sides",
+			elsewhere: func(p *ProgressData) { p.SetBookProgress("a.epub", 7, 0, 0, 10) },
+			here:      func(p *ProgressData) { p.SetBookProgress("a.epub", 5, 0, 0, 10) },
+			want:      map[string]int{"a.epub": 5, "b.epub": 2},
+		},
+		{
+			name:      "deleted elsewhere",
+			elsewhere: func(p *ProgressData) { p.RemoveBook("b.epub") },
+			here:      func(p *ProgressData) { p.SetBookProgress("a.epub", 5, 0, 0, 10) },
+			want:      map[string]int{"a.epub": 5},
+		},
+		{
+			name: "deleted here",
+			here: func(p *ProgressData) { p.RemoveBook("a.epub") },
+			want: map[string]int{"b.epub": 2},
+		},
+		{
+			name:    "unreadable file",
+			here:    func(p *ProgressData) { p.SetBookProgress("a.epub", 5, 0, 0, 10) },
+			corrupt: true,
+			want:    map[string]int{"a.epub": 5, "b.epub": 2},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := testConfig(t)
+			seed, err := LoadProgress(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			seed.SetBookProgress("a.epub", 1, 0, 0, 10)
+			seed.SetBookProgress("b.epub", 2, 0, 0, 10)
+			if err := SaveProgress(cfg, seed); err != nil {
+				t.Fatal(err)
+			}
+
+			here, err := LoadProgress(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.elsewhere != nil {
+				elsewhere, err := LoadProgress(cfg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				test.elsewhere(elsewhere)
+				if err := SaveProgress(cfg, elsewhere); err != nil {
+					t.Fatal(err)
+				}
+			}
+			progressPath := filepath.Join(cfg.DataDirectory(), "progress.json")
+			if test.corrupt {
+				if err := os.WriteFile(progressPath, []byte("<<<<<<< HEAD"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			test.here(here)
+			if err := SaveProgress(cfg, here); err != nil {
+				t.Fatal(err)
+			}
+
+			saved, err := readProgressFile(progressPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(saved.Books) != len(test.want) {
+				t.Errorf("saved %d books, want %d", len(saved.Books), len(test.want))
+			}
+			for path, chapter := range test.want {
+				book, ok := saved.Books[path]
+				if !ok {
+					t.Errorf("%s missing", path)
+				} else if book.CurrentChapter != chapter {
+					t.Errorf("chapter of %s = %d, want %d", path, book.CurrentChapter, chapter)
+				}
+			}
+		})
+	}
+}
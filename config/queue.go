@@ -0,0 +1,138 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// QueueData is the ordered list of books to read next
+type QueueData struct {
+	Books []string `json:"books"` // Book paths, first is read next
+
+	loaded  []string // The queue as last loaded or saved
+	changed bool     // Changed since then
+}
+
+// LoadQueue loads the reading queue from the data directory
+func LoadQueue(cfg *Config) (*QueueData, error) {
+	queue, err := readQueueFile(filepath.Join(cfg.DataDirectory(), "queue.json"))
+	if err != nil {
+		return nil, err
+	}
+	queue.loaded = slices.Clone(queue.Books)
+	return queue, nil
+}
+
+// readQueueFile reads a queue file, which may not exist yet
+func readQueueFile(queuePath string) (*QueueData, error) {
+	// If file doesn't exist, return an empty queue
+	if _, err := os.Stat(queuePath); os.IsNotExist(err) {
+		return &QueueData{}, nil
+	}
+
+	data, err := os.ReadFile(queuePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue file: %w", err)
+	}
+
+	var queue QueueData
+	if err := json.Unmarshal(data, &queue); err != nil {
+		return nil, fmt.Errorf("failed to parse queue file: %w", err)
+	}
+	return &queue, nil
+}
+
+// SaveQueue saves the reading queue to the data directory. Changes other
+// cozy instances saved since this one loaded the file are merged in: the
+// books they queued go at the end and the books they took out are left
+// out, while the order here is kept.
+func SaveQueue(cfg *Config, queue *QueueData) error {
+	if err := cfg.EnsureDataDir(); err != nil {
+		return err
+	}
+
+	queuePath := filepath.Join(cfg.DataDirectory(), "queue.json")
+	err := saveMerged(queuePath, "queue", queue, readQueueFile, func(onDisk *QueueData) {
+		queue.merge(onDisk.Books)
+	})
+	if err != nil {
+		return err
+	}
+	queue.loaded = slices.Clone(queue.Books)
+	queue.changed = false
+	return nil
+}
+
+// merge combines the queue with the one saved by another instance
+func (q *QueueData) merge(saved []string) {
+	if !q.changed {
+		q.Books = slices.Clone(saved)
+		return
+	}
+	q.Books = slices.DeleteFunc(q.Books, func(path string) bool {
+		return slices.Contains(q.loaded, path) && !slices.Contains(saved, path)
+	})
+	for _, path := range saved {
+		if !slices.Contains(q.loaded, path) && !slices.Contains(q.Books, path) {
+			q.Books = append(q.Books, path)
+		}
+	}
+}
+
+// Position returns where a book is in the queue, counting from 1, or 0 if
+// it isn't queued
+func (q *QueueData) Position(bookPath string) int {
+	return slices.Index(q.Books, bookPath) + 1
+}
+
+// Toggle adds a book to the end of the queue, or removes it if it is
+// queued already. It reports whether the book was added.
+func (q *QueueData) Toggle(bookPath string) bool {
+	if q.Remove(bookPath) {
+		return false
+	}
+	q.Books = append(q.Books, bookPath)
+	q.changed = true
+	return true
+}
+
+// Remove takes a book out of the queue, reporting whether it was queued
+func (q *QueueData) Remove(bookPath string) bool {
+	i := slices.Index(q.Books, bookPath)
+	if i < 0 {
+		return false
+	}
+	q.Books = slices.Delete(q.Books, i, i+1)
+	q.changed = true
+	return true
+}
+
+// Move moves a queued book up (negative delta) or down the queue,
+// reporting whether it moved
+func (q *QueueData) Move(bookPath string, delta int) bool {
+	i := slices.Index(q.Books, bookPath)
+	j := i + delta
+	if i < 0 || j < 0 || j >= len(q.Books) {
+		return false
+	}
+	q.Books[i], q.Books[j] = q.Books[j], q.Books[i]
+	q.changed = true
+	return true
+}
+
+// MoveBook updates the queue for a book whose file was moved. If the new
+// path is queued already, the old one is dropped.
+func (q *QueueData) MoveBook(from, to string) {
+	i := slices.Index(q.Books, from)
+	switch {
+	case i < 0:
+	case slices.Contains(q.Books, to):
+		q.Remove(from)
+	default:
+		q.Books[i] = to
+		q.changed = true
+	}
+}
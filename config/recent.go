@@ -0,0 +1,74 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Number of recent files remembered
+const maxRecentFiles = 20
+
+// RecentData lists the books opened from outside the library
+type RecentData struct {
+	Files []string `json:"files"` // Book paths, most recent first
+}
+
+// LoadRecent loads the recent files from the data directory
+func LoadRecent(cfg *Config) (*RecentData, error) {
+	recentPath := filepath.Join(cfg.DataDirectory(), "recent.json")
+
+	// If file doesn't exist, return an empty list
+	if _, err := os.Stat(recentPath); os.IsNotExist(err) {
+		return &RecentData{}, nil
+	}
+
+	data, err := os.ReadFile(recentPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recent files: %w", err)
+	}
+
+	var recent RecentData
+	if err := json.Unmarshal(data, &recent); err != nil {
+		return nil, fmt.Errorf("failed to parse recent files: %w", err)
+	}
+	return &recent, nil
+}
+
+// SaveRecent saves the recent files to the data directory
+func SaveRecent(cfg *Config, recent *RecentData) error {
+	if err := cfg.EnsureDataDir(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(recent, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal recent files: %w", err)
+	}
+
+	if err := writeFileAtomic(filepath.Join(cfg.DataDirectory(), "recent.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write recent files: %w", err)
+	}
+	return nil
+}
+
+// Add puts a book first in the list, dropping the oldest beyond the limit
+func (r *RecentData) Add(bookPath string) {
+	r.Remove(bookPath)
+	r.Files = slices.Insert(r.Files, 0, bookPath)
+	if len(r.Files) > maxRecentFiles {
+		r.Files = r.Files[:maxRecentFiles]
+	}
+}
+
+// Remove takes a book off the list, reporting whether it was on it
+func (r *RecentData) Remove(bookPath string) bool {
+	i := slices.Index(r.Files, bookPath)
+	if i < 0 {
+		return false
+	}
+	r.Files = slices.Delete(r.Files, i, i+1)
+	return true
+}
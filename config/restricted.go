@@ -0,0 +1,39 @@
+package config
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// BookAllowed reports whether a book may be shown. Outside restricted mode
+// every book is; in it, only books in the library under one of the
+// allowed folders, or under a folder named like one of the tags.
+func (c *Config) BookAllowed(bookPath string) bool {
+	if !c.Restricted.Enabled {
+		return true
+	}
+	if c.Library.Path == "" {
+		return false
+	}
+	rel, err := filepath.Rel(c.Library.Path, bookPath)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+
+	for _, folder := range c.Restricted.Folders {
+		folder = strings.Trim(filepath.ToSlash(folder), "/")
+		if folder != "" && strings.HasPrefix(strings.ToLower(rel), strings.ToLower(folder)+"/") {
+			return true
+		}
+	}
+	folders := strings.Split(rel, "/")
+	folders = folders[:len(folders)-1]
+	for _, tag := range c.Restricted.Tags {
+		if slices.ContainsFunc(folders, func(folder string) bool { return strings.EqualFold(folder, tag) }) {
+			return true
+		}
+	}
+	return false
+}
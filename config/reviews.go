@@ -0,0 +1,103 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Review is the rating, and optionally a few words, given to a finished
+// book
+type Review struct {
+	Rating  int       `json:"rating"` // 1 to 5 stars
+	Text    string    `json:"text,omitempty"`
+	Created time.Time `json:"created"`
+}
+
+// ReviewData stores the reviews of all books
+type ReviewData struct {
+	Books map[string]Review `json:"books"` // Key is book path
+
+	changed map[string]bool // Books reviewed since the last save
+}
+
+// LoadReviews loads reviews from the data directory
+func LoadReviews(cfg *Config) (*ReviewData, error) {
+	return readReviewsFile(filepath.Join(cfg.DataDirectory(), "reviews.json"))
+}
+
+// readReviewsFile reads a reviews file, which may not exist yet
+func readReviewsFile(reviewsPath string) (*ReviewData, error) {
+	// If file doesn't exist, return empty reviews
+	if _, err := os.Stat(reviewsPath); os.IsNotExist(err) {
+		return &ReviewData{
+			Books: make(map[string]Review),
+		}, nil
+	}
+
+	data, err := os.ReadFile(reviewsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reviews file: %w", err)
+	}
+
+	var reviews ReviewData
+	if err := json.Unmarshal(data, &reviews); err != nil {
+		return nil, fmt.Errorf("failed to parse reviews file: %w", err)
+	}
+
+	if reviews.Books == nil {
+		reviews.Books = make(map[string]Review)
+	}
+
+	return &reviews, nil
+}
+
+// SaveReviews saves reviews to the data directory. Reviews other cozy
+// instances saved since this one loaded the file are merged in, like
+// SaveProgress does.
+func SaveReviews(cfg *Config, reviews *ReviewData) error {
+	if err := cfg.EnsureDataDir(); err != nil {
+		return err
+	}
+
+	reviewsPath := filepath.Join(cfg.DataDirectory(), "reviews.json")
+	err := saveMerged(reviewsPath, "reviews", reviews, readReviewsFile, func(onDisk *ReviewData) {
+		mergeUnchanged(reviews.Books, onDisk.Books, reviews.changed)
+	})
+	if err != nil {
+		return err
+	}
+	reviews.changed = nil
+	return nil
+}
+
+// markChanged records that a book's review needs saving
+func (r *ReviewData) markChanged(bookPath string) {
+	if r.changed == nil {
+		r.changed = make(map[string]bool)
+	}
+	r.changed[bookPath] = true
+}
+
+// SetReview stores the review of a book, replacing an earlier one
+func (r *ReviewData) SetReview(bookPath string, review Review) {
+	r.Books[bookPath] = review
+	r.markChanged(bookPath)
+}
+
+// MoveBook gives the review of a book to another path, unless that one
+// has been reviewed already
+func (r *ReviewData) MoveBook(from, to string) {
+	review, ok := r.Books[from]
+	if !ok {
+		return
+	}
+	delete(r.Books, from)
+	r.markChanged(from)
+	if _, exists := r.Books[to]; !exists {
+		r.Books[to] = review
+		r.markChanged(to)
+	}
+}
@@ -0,0 +1,310 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Progress, bookmarks, highlights and notes can be kept in a sync
+// directory as well, one file per book, for Syncthing, Dropbox or Git to
+// carry between machines. A file is named after the book's path in the
+// library, so the same book has the same file everywhere; books outside
+// the library are not synced.
+//
+// Loading merges the files of books in the library into the local data:
+// the newer reading position and notes win, while bookmarks, marks, read
+// chapters and highlights of both sides are kept and the furthest
+// position is the furthest of the two. Saving merges the other way, so a
+// machine never overwrites what another one wrote. Conflict copies left
+// by a sync tool name the same book inside and are merged in, then
+// removed. Deleting a bookmark or clearing a book's progress is recorded
+// with the time, so merging drops copies older than that instead of
+// bringing them back. A book that is moved or deleted from the trash
+// takes its file along.
+
+// SyncedBook is the file of a book in the sync directory
+type SyncedBook struct {
+	Book       string        `json:"book"` // Path in the library, with forward slashes
+	Progress   *BookProgress `json:"progress,omitempty"`
+	Highlights []Highlight   `json:"highlights,omitempty"`
+	Journal    *JournalEntry `json:"journal,omitempty"`
+
+	ProgressCleared time.Time `json:"progress_cleared,omitzero"` // Progress saved before was cleared
+}
+
+// Deletions are remembered this long, after which every machine is
+// expected to have synced them
+const removalAge = 90 * 24 * time.Hour
+
+// syncedBookName returns the name a book is synced under, its path in the
+// library, or false if it isn't synced
+func (c *Config) syncedBookName(bookPath string) (string, bool) {
+	if c.SyncDirectory() == "" || c.Library.Path == "" {
+		return "", false
+	}
+	rel, err := filepath.Rel(c.Library.Path, bookPath)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// syncFileName returns the file a book is synced in. Folders become part
+// of the name, and characters some file systems reject are replaced.
+func syncFileName(book string) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < ' ' {
+			return '_'
+		}
+		return r
+	}, book)
+	return strings.TrimLeft(name, ".") + ".json"
+}
+
+// readSyncedBook reads a file of the sync directory
+func readSyncedBook(path string) (SyncedBook, error) {
+	var book SyncedBook
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return book, err
+	}
+	err = json.Unmarshal(data, &book)
+	return book, err
+}
+
+// readSyncDirectory reads the synced books whose files are in this
+// library, keyed by their local path. Conflict copies are merged into
+// the book's file and removed. Unreadable files, such as a file Git left
+// conflict markers in, are skipped.
+func readSyncDirectory(cfg *Config) map[string]SyncedBook {
+	entries, err := os.ReadDir(cfg.SyncDirectory())
+	if err != nil {
+		return nil
+	}
+
+	books := make(map[string]SyncedBook)
+	files := make(map[string][]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !syncableName(name) {
+			continue
+		}
+		path := filepath.Join(cfg.SyncDirectory(), name)
+		book, err := readSyncedBook(path)
+		if err != nil || book.Book == "" {
+			continue
+		}
+		bookPath := filepath.Join(cfg.Library.Path, filepath.FromSlash(book.Book))
+		if _, err := os.Stat(bookPath); err != nil {
+			continue
+		}
+		if merged, ok := books[bookPath]; ok {
+			book = mergeSyncedBooks(merged, book)
+		}
+		books[bookPath] = book
+		files[bookPath] = append(files[bookPath], path)
+	}
+
+	for bookPath, paths := range files {
+		main := filepath.Join(cfg.SyncDirectory(), syncFileName(books[bookPath].Book))
+		if len(paths) == 1 && paths[0] == main {
+			continue
+		}
+		if writeSyncedBook(main, books[bookPath]) != nil {
+			continue
+		}
+		for _, path := range paths {
+			if path != main {
+				os.Remove(path)
+			}
+		}
+	}
+	return books
+}
+
+// writeSyncedBook writes a file of the sync directory
+func writeSyncedBook(path string, book SyncedBook) error {
+	data, err := json.MarshalIndent(book, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+// updateSyncedBook merges local data of a book into its file in the sync
+// directory. Books that aren't synced are left alone.
+func updateSyncedBook(cfg *Config, bookPath string, local SyncedBook) error {
+	name, ok := cfg.syncedBookName(bookPath)
+	if !ok {
+		return nil
+	}
+	path := filepath.Join(cfg.SyncDirectory(), syncFileName(name))
+	local.Book = name
+	if local.Progress != nil {
+		progress := *local.Progress
+		progress.BookPath = ""
+		local.Progress = &progress
+	}
+
+	synced, err := readSyncedBook(path)
+	if err != nil && local.Progress == nil && local.Highlights == nil && local.Journal == nil {
+		return nil // Nothing synced to clear
+	}
+	if err == nil {
+		merged := mergeSyncedBooks(synced, local)
+		if sameJSON(merged, synced) {
+			return nil
+		}
+		local = merged
+	}
+	return writeSyncedBook(path, local)
+}
+
+// RemoveSyncedBook deletes the file of a book from the sync directory, for
+// a book that was moved or deleted. Its data goes with the book's new
+// path, if any, when that is saved.
+func RemoveSyncedBook(cfg *Config, bookPath string) error {
+	name, ok := cfg.syncedBookName(bookPath)
+	if !ok {
+		return nil
+	}
+	err := os.Remove(filepath.Join(cfg.SyncDirectory(), syncFileName(name)))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// mergeSyncedBooks combines two copies of a book's data. Progress older
+// than the latest clearing of it is dropped.
+func mergeSyncedBooks(a, b SyncedBook) SyncedBook {
+	merged := SyncedBook{Book: a.Book, ProgressCleared: a.ProgressCleared}
+	if b.ProgressCleared.After(merged.ProgressCleared) {
+		merged.ProgressCleared = b.ProgressCleared
+	}
+	if !merged.ProgressCleared.IsZero() {
+		if a.Progress != nil && !a.Progress.UpdatedAt.After(merged.ProgressCleared) {
+			a.Progress = nil
+		}
+		if b.Progress != nil && !b.Progress.UpdatedAt.After(merged.ProgressCleared) {
+			b.Progress = nil
+		}
+	}
+	switch {
+	case a.Progress == nil:
+		merged.Progress = b.Progress
+	case b.Progress == nil:
+		merged.Progress = a.Progress
+	default:
+		progress := mergeProgress(*a.Progress, *b.Progress)
+		merged.Progress = &progress
+	}
+	merged.Highlights = mergeHighlights(a.Highlights, b.Highlights)
+	merged.Journal = a.Journal
+	if b.Journal != nil && (a.Journal == nil || b.Journal.Updated.After(a.Journal.Updated)) {
+		merged.Journal = b.Journal
+	}
+	return merged
+}
+
+// mergeProgress combines two copies of a book's progress. The position,
+// finished status and file of the newer copy win; bookmarks and marks of
+// both are kept, the newer copy's where they have the same name, except
+// bookmarks deleted on either side after they were made.
+func mergeProgress(a, b BookProgress) BookProgress {
+	older, newer := a, b
+	if a.UpdatedAt.After(b.UpdatedAt) {
+		older, newer = b, a
+	}
+	merged := newer
+
+	merged.RemovedBookmarks = maps.Clone(older.RemovedBookmarks)
+	for name, removed := range newer.RemovedBookmarks {
+		if merged.RemovedBookmarks == nil {
+			merged.RemovedBookmarks = make(map[string]time.Time)
+		}
+		if removed.After(merged.RemovedBookmarks[name]) {
+			merged.RemovedBookmarks[name] = removed
+		}
+	}
+	merged.RemovedBookmarks = pruneRemovals(merged.RemovedBookmarks)
+
+	merged.Bookmarks = nil
+	for _, bookmark := range slices.Concat(older.Bookmarks, newer.Bookmarks) {
+		if removed, ok := merged.RemovedBookmarks[bookmark.Name]; ok && !bookmark.Created.After(removed) {
+			continue
+		}
+		i := slices.IndexFunc(merged.Bookmarks, func(b Bookmark) bool { return b.Name == bookmark.Name })
+		if i >= 0 {
+			merged.Bookmarks[i] = bookmark
+		} else {
+			merged.Bookmarks = append(merged.Bookmarks, bookmark)
+		}
+	}
+	if len(older.Marks) > 0 {
+		merged.Marks = maps.Clone(older.Marks)
+		maps.Copy(merged.Marks, newer.Marks)
+	}
+
+	merged.ReadChapters = slices.Clone(newer.ReadChapters)
+	for _, chapter := range older.ReadChapters {
+		if !slices.Contains(merged.ReadChapters, chapter) {
+			merged.ReadChapters = append(merged.ReadChapters, chapter)
+		}
+	}
+	slices.Sort(merged.ReadChapters)
+
+	if older.FurthestChapter > newer.FurthestChapter ||
+		(older.FurthestChapter == newer.FurthestChapter && older.FurthestOffset > newer.FurthestOffset) {
+		merged.FurthestChapter = older.FurthestChapter
+		merged.FurthestOffset = older.FurthestOffset
+	}
+	return merged
+}
+
+// pruneRemovals forgets deletions older than removalAge
+func pruneRemovals(removals map[string]time.Time) map[string]time.Time {
+	for name, removed := range removals {
+		if time.Since(removed) > removalAge {
+			delete(removals, name)
+		}
+	}
+	if len(removals) == 0 {
+		return nil
+	}
+	return removals
+}
+
+// mergeHighlights returns the highlights of both lists, once each
+func mergeHighlights(a, b []Highlight) []Highlight {
+	merged := slices.Clone(a)
+	for _, highlight := range b {
+		i := slices.IndexFunc(merged, func(h Highlight) bool {
+			return h.Chapter == highlight.Chapter && h.Text == highlight.Text && h.Created.Equal(highlight.Created)
+		})
+		switch {
+		case i < 0:
+			merged = append(merged, highlight)
+		case merged[i].Note == "":
+			merged[i].Note = highlight.Note
+		}
+	}
+	return merged
+}
+
+// sameJSON reports whether two values are written the same, which unlike
+// reflect.DeepEqual ignores how times were made
+func sameJSON(a, b any) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(encodedA, encodedB)
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Kinds of things that can be in the trash
+const (
+	TrashBook     = "book"     // A book file, moved to the trash folder
+	TrashProgress = "progress" // A book's reading progress
+	TrashBookmark = "bookmark" // One bookmark of a book
+)
+
+// TrashItem is something deleted, kept so it can be restored
+type TrashItem struct {
+	Kind    string          `json:"kind"`
+	Book    string          `json:"book"`           // Path of the book it belongs to
+	File    string          `json:"file,omitempty"` // The book file in the trash folder
+	Data    json.RawMessage `json:"data,omitempty"` // The progress or bookmark deleted
+	Deleted time.Time       `json:"deleted"`
+}
+
+// TrashData lists what was deleted, oldest first
+type TrashData struct {
+	Items []TrashItem `json:"items"`
+}
+
+// TrashDir returns the folder deleted book files are moved to
+func (c *Config) TrashDir() string {
+	return filepath.Join(c.DataDirectory(), "trash")
+}
+
+// LoadTrash loads the list of deleted things from the data directory
+func LoadTrash(cfg *Config) (*TrashData, error) {
+	trashPath := filepath.Join(cfg.DataDirectory(), "trash.json")
+
+	// If file doesn't exist, the trash is empty
+	if _, err := os.Stat(trashPath); os.IsNotExist(err) {
+		return &TrashData{}, nil
+	}
+
+	data, err := os.ReadFile(trashPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash file: %w", err)
+	}
+
+	var trash TrashData
+	if err := json.Unmarshal(data, &trash); err != nil {
+		return nil, fmt.Errorf("failed to parse trash file: %w", err)
+	}
+	return &trash, nil
+}
+
+// SaveTrash saves the list of deleted things to the data directory
+func SaveTrash(cfg *Config, trash *TrashData) error {
+	if err := cfg.EnsureDataDir(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(trash, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal trash: %w", err)
+	}
+
+	if err := writeFileAtomic(filepath.Join(cfg.DataDirectory(), "trash.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write trash file: %w", err)
+	}
+	return nil
+}
+
+// Add puts something deleted in the trash. Data is stored as JSON.
+func (t *TrashData) Add(kind, book, file string, data any) error {
+	item := TrashItem{Kind: kind, Book: book, File: file, Deleted: time.Now()}
+	if data != nil {
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		item.Data = encoded
+	}
+	t.Items = append(t.Items, item)
+	return nil
+}
+
+// Last returns the most recently deleted item
+func (t *TrashData) Last() (TrashItem, bool) {
+	if len(t.Items) == 0 {
+		return TrashItem{}, false
+	}
+	return t.Items[len(t.Items)-1], true
+}
+
+// DropLast takes the most recently deleted item out of the trash, once it
+// has been restored
+func (t *TrashData) DropLast() {
+	if len(t.Items) > 0 {
+		t.Items = t.Items[:len(t.Items)-1]
+	}
+}
+
+// Empty deletes the book files in the trash for good and forgets
+// everything in it. Files that can't be removed stay listed.
+func (t *TrashData) Empty() error {
+	var kept []TrashItem
+	var firstErr error
+	for _, item := range t.Items {
+		if item.File == "" {
+			continue
+		}
+		if err := os.Remove(item.File); err != nil && !os.IsNotExist(err) {
+			kept = append(kept, item)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	t.Items = kept
+	return firstErr
+}
@@ -0,0 +1,305 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// A broken config file doesn't stop cozy from starting. Settings that
+// can't be used keep their defaults, and each problem is kept in
+// Config.Warnings with a message saying what to fix.
+
+// errConfigBroken stops Save from replacing a config file that could not
+// be parsed, so a typo doesn't cost the user their settings
+var errConfigBroken = errors.New("config.toml has syntax errors; fix them before changing settings")
+
+// Allowed values of the settings that take one of a few words
+var configChoices = map[string][]string{
+	"library.view":           {"list", "gallery"},
+	"reading.non_linear":     {"include", "end", "skip"},
+	"reading.text_split":     {"auto", "headings", "blank", "sections", "none"},
+	"display.cover_protocol": {"auto", "kitty", "iterm", "blocks", "none"},
+	"display.color_profile":  {"auto", "truecolor", "256", "16", "none"},
+	"display.margin_mode":    {"cells", "percent", "auto"},
+	"display.ruler":          {"off", "line", "band"},
+
+	"display.large_text":       {"off", "headings", "all"},
+	"display.large_text_style": {"double", "banner"},
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// decodeConfig decodes a config file over the defaults in config one
+// setting at a time, so a value of the wrong type only loses that setting.
+// It returns the problems found; a syntax error is returned as an error.
+func decodeConfig(path string, config *Config) ([]string, error) {
+	var file map[string]toml.Primitive
+	md, err := toml.DecodeFile(path, &file)
+	if err != nil {
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, fmt.Errorf("%s line %d: %s", path, parseErr.Position.Line, parseErr.Message)
+		}
+		return nil, err
+	}
+
+	var warnings []string
+	root := reflect.ValueOf(config).Elem()
+	for _, key := range sortedKeys(file) {
+		field, ok := fieldByTag(root, key)
+		if !ok {
+			warnings = append(warnings, unknownKey(key, root))
+			continue
+		}
+		if field.Kind() != reflect.Struct {
+			warnings = append(warnings, decodeSetting(md, file[key], key, field)...)
+			continue
+		}
+
+		// Tables are decoded setting by setting
+		var section map[string]toml.Primitive
+		if err := md.PrimitiveDecode(file[key], &section); err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s should be a [%s] table; using the defaults", key, key))
+			continue
+		}
+		for _, name := range sortedKeys(section) {
+			setting := key + "." + name
+			if sub, ok := fieldByTag(field, name); ok {
+				warnings = append(warnings, decodeSetting(md, section[name], setting, sub)...)
+			} else {
+				warnings = append(warnings, unknownKey(setting, root))
+			}
+		}
+	}
+	return warnings, nil
+}
+
+// decodeSetting decodes one value, leaving the default in place if the
+// value has the wrong type
+func decodeSetting(md toml.MetaData, value toml.Primitive, key string, field reflect.Value) []string {
+	decoded := reflect.New(field.Type())
+	if err := md.PrimitiveDecode(value, decoded.Interface()); err != nil {
+		return []string{fmt.Sprintf("%s should be %s, got %s; using the default %s",
+			key, describeKind(field), strings.ToLower(md.Type(strings.Split(key, ".")...)), formatDefault(field))}
+	}
+	field.Set(decoded.Elem())
+	return nil
+}
+
+// validateConfig checks the decoded values, resetting invalid ones to
+// their defaults
+func validateConfig(config *Config) []string {
+	var warnings []string
+	defaults := DefaultConfig()
+	root := reflect.ValueOf(config).Elem()
+	defaultRoot := reflect.ValueOf(&defaults).Elem()
+
+	for _, key := range sortedKeys(configChoices) {
+		field, _ := fieldPath(root, key)
+		if value := field.String(); !slices.Contains(configChoices[key], value) {
+			fallback, _ := fieldPath(defaultRoot, key)
+			field.Set(fallback)
+			warnings = append(warnings, fmt.Sprintf("%s = %q is not one of %s; using %q",
+				key, value, strings.Join(configChoices[key], ", "), fallback.String()))
+		}
+	}
+
+	// Counts and sizes that have to be positive to make sense
+	for _, key := range []string{"reading.words_per_minute", "reading.words_per_page", "reading.rsvp_words_per_minute", "reading.rsvp_chunk", "reading.text_section_size", "reading.snooze_minutes",
+		"reading.focus_minutes", "reading.break_minutes", "display.line_length", "display.scroll_lines", "reading.section_depth", "digest.max_per_feed"} {
+		field, _ := fieldPath(root, key)
+		if field.Int() <= 0 {
+			fallback, _ := fieldPath(defaultRoot, key)
+			warnings = append(warnings, fmt.Sprintf("%s must be greater than 0; using %d", key, fallback.Int()))
+			field.Set(fallback)
+		}
+	}
+	for _, key := range []string{"display.margin_left", "display.margin_right", "display.word_spacing", "display.page_overlap", "reading.reminder_minutes", "ao3.check_hours", "mail.check_minutes"} {
+		field, _ := fieldPath(root, key)
+		if field.Int() < 0 {
+			warnings = append(warnings, fmt.Sprintf("%s can't be negative; using 0", key))
+			field.SetInt(0)
+		}
+	}
+
+	if path := config.Library.Path; path != "" {
+		if info, err := os.Stat(path); err != nil {
+			warnings = append(warnings, fmt.Sprintf("library.path %s does not exist; create it or set library.path to your books folder", path))
+		} else if !info.IsDir() {
+			warnings = append(warnings, fmt.Sprintf("library.path %s is not a folder", path))
+		}
+	}
+	if _, err := LoadLocale(config.Language); err != nil {
+		warnings = append(warnings, fmt.Sprintf("language: %v; using en", err))
+		config.Language = "en"
+	}
+	if path := config.Sync.Directory; path != "" {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			warnings = append(warnings, fmt.Sprintf("sync.directory %s is not a folder; syncing is off", path))
+			config.Sync.Directory = ""
+		}
+	}
+	if webdav := config.Sync.WebDAVURL; webdav != "" {
+		if u, err := url.Parse(webdav); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			warnings = append(warnings, fmt.Sprintf("sync.webdav_url %s is not an http or https URL; WebDAV sync is off", webdav))
+			config.Sync.WebDAVURL = ""
+		}
+	}
+	feeds := config.Digest.Feeds[:0]
+	for _, feed := range config.Digest.Feeds {
+		if u, err := url.Parse(feed); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			warnings = append(warnings, fmt.Sprintf("digest.feeds: %s is not an http or https URL; leaving it out", feed))
+			continue
+		}
+		feeds = append(feeds, feed)
+	}
+	config.Digest.Feeds = feeds
+	return warnings
+}
+
+// validateTheme replaces colors lipgloss can't use with the ones from the
+// default theme
+func validateTheme(theme *Theme) []string {
+	var warnings []string
+	value := reflect.ValueOf(theme).Elem()
+	fallback := reflect.ValueOf(CozyDark)
+	for i := 0; i < value.NumField(); i++ {
+		tag := value.Type().Field(i).Tag.Get("toml")
+		if !strings.HasSuffix(tag, "_color") {
+			continue
+		}
+		if color := value.Field(i).String(); !validColor(color) {
+			value.Field(i).Set(fallback.Field(i))
+			warnings = append(warnings, fmt.Sprintf("theme %s: %s = %q is not a color (use #rrggbb or an ANSI number 0-255); using %s",
+				theme.Name, tag, color, fallback.Field(i).String()))
+		}
+	}
+	return warnings
+}
+
+// validColor reports whether a color is a hex color or an ANSI color
+// number. Colors left out of a theme use the terminal's own.
+func validColor(color string) bool {
+	if color == "" || hexColor.MatchString(color) {
+		return true
+	}
+	n, err := strconv.Atoi(color)
+	return err == nil && n >= 0 && n <= 255
+}
+
+// fieldByTag returns the struct field with the given toml tag
+func fieldByTag(v reflect.Value, tag string) (reflect.Value, bool) {
+	for i := 0; i < v.NumField(); i++ {
+		if name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("toml"), ","); name == tag && name != "-" {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// fieldPath returns the field for a dotted key such as "display.justify"
+func fieldPath(v reflect.Value, key string) (reflect.Value, bool) {
+	for _, part := range strings.Split(key, ".") {
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+		var ok bool
+		if v, ok = fieldByTag(v, part); !ok {
+			return reflect.Value{}, false
+		}
+	}
+	return v, true
+}
+
+// settingKeys lists every dotted key the config understands
+func settingKeys(v reflect.Value, prefix string) []string {
+	var keys []string
+	for i := 0; i < v.NumField(); i++ {
+		name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("toml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		if v.Field(i).Kind() == reflect.Struct {
+			keys = append(keys, settingKeys(v.Field(i), prefix+name+".")...)
+		} else {
+			keys = append(keys, prefix+name)
+		}
+	}
+	return keys
+}
+
+// unknownKey describes a setting cozy doesn't know, suggesting the
+// closest one when it looks like a typo
+func unknownKey(key string, root reflect.Value) string {
+	best, bestDistance := "", len(key)/3+1
+	for _, known := range settingKeys(root, "") {
+		if d := editDistance(key, known); d < bestDistance {
+			best, bestDistance = known, d
+		}
+	}
+	if best != "" {
+		return fmt.Sprintf("unknown setting %s (did you mean %s?); it is ignored", key, best)
+	}
+	return fmt.Sprintf("unknown setting %s; it is ignored", key)
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+// describeKind names the TOML type a setting expects
+func describeKind(field reflect.Value) string {
+	switch field.Kind() {
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int, reflect.Int64:
+		return "a whole number"
+	case reflect.Slice:
+		return "a list"
+	}
+	return "a string"
+}
+
+// formatDefault shows a default value the way it is written in TOML
+func formatDefault(field reflect.Value) string {
+	if field.Kind() == reflect.String {
+		return strconv.Quote(field.String())
+	}
+	return fmt.Sprint(field.Interface())
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
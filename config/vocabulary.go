@@ -0,0 +1,186 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// UnknownLanguage is the vocabulary list of books that don't say what
+// language they are in
+const UnknownLanguage = "unknown"
+
+// VocabularyWord is a word marked as unknown while reading, with the
+// sentence it was found in
+type VocabularyWord struct {
+	Word    string    `json:"word"`
+	Context string    `json:"context,omitempty"`
+	Book    string    `json:"book,omitempty"` // Title of the book it was found in
+	Added   time.Time `json:"added"`
+}
+
+// VocabularyData stores the vocabulary lists of all languages
+type VocabularyData struct {
+	Languages map[string][]VocabularyWord `json:"languages"` // Key is a language code, e.g. "de"
+
+	changed map[string]bool // Words added or removed since the last save, see vocabularyKey
+}
+
+// LoadVocabulary loads the vocabulary lists from the data directory
+func LoadVocabulary(cfg *Config) (*VocabularyData, error) {
+	return readVocabularyFile(filepath.Join(cfg.DataDirectory(), "vocabulary.json"))
+}
+
+// readVocabularyFile reads a vocabulary file, which may not exist yet
+func readVocabularyFile(vocabularyPath string) (*VocabularyData, error) {
+	// If file doesn't exist, return empty lists
+	if _, err := os.Stat(vocabularyPath); os.IsNotExist(err) {
+		return &VocabularyData{
+			Languages: make(map[string][]VocabularyWord),
+		}, nil
+	}
+
+	data, err := os.ReadFile(vocabularyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vocabulary file: %w", err)
+	}
+
+	var vocabulary VocabularyData
+	if err := json.Unmarshal(data, &vocabulary); err != nil {
+		return nil, fmt.Errorf("failed to parse vocabulary file: %w", err)
+	}
+
+	if vocabulary.Languages == nil {
+		vocabulary.Languages = make(map[string][]VocabularyWord)
+	}
+
+	return &vocabulary, nil
+}
+
+// SaveVocabulary saves the vocabulary lists to the data directory. Words
+// other cozy instances added or removed since this one loaded the file are
+// merged in, like SaveProgress does for books.
+func SaveVocabulary(cfg *Config, vocabulary *VocabularyData) error {
+	if err := cfg.EnsureDataDir(); err != nil {
+		return err
+	}
+
+	vocabularyPath := filepath.Join(cfg.DataDirectory(), "vocabulary.json")
+	err := saveMerged(vocabularyPath, "vocabulary", vocabulary, readVocabularyFile, func(onDisk *VocabularyData) {
+		vocabulary.merge(onDisk.Languages)
+	})
+	if err != nil {
+		return err
+	}
+	vocabulary.changed = nil
+	return nil
+}
+
+// merge combines the lists with those saved by another instance: the saved
+// words are kept, except those added or removed here, which are taken from
+// here
+func (v *VocabularyData) merge(saved map[string][]VocabularyWord) {
+	merged := make(map[string][]VocabularyWord)
+	for language, words := range saved {
+		for _, word := range words {
+			if !v.changed[vocabularyKey(language, word.Word)] {
+				merged[language] = append(merged[language], word)
+			}
+		}
+	}
+	for language, words := range v.Languages {
+		for _, word := range words {
+			if v.changed[vocabularyKey(language, word.Word)] {
+				merged[language] = append(merged[language], word)
+			}
+		}
+	}
+	v.Languages = merged
+}
+
+// vocabularyKey identifies a word of a language's list
+func vocabularyKey(language, word string) string {
+	return language + "/" + strings.ToLower(word)
+}
+
+// markChanged records that a word was added or removed
+func (v *VocabularyData) markChanged(language, word string) {
+	if v.changed == nil {
+		v.changed = make(map[string]bool)
+	}
+	v.changed[vocabularyKey(language, word)] = true
+}
+
+// VocabularyLanguage returns the list a book's words go in: its primary
+// language subtag, so "de-CH" and "de" share a list
+func VocabularyLanguage(language string) string {
+	language, _, _ = strings.Cut(strings.ToLower(strings.TrimSpace(language)), "-")
+	language, _, _ = strings.Cut(language, "_")
+	if language == "" || language == "und" {
+		return UnknownLanguage
+	}
+	return language
+}
+
+// Has reports whether a word is in the list of a language. Case is
+// ignored, so a word marked at the start of a sentence is found anywhere.
+func (v *VocabularyData) Has(language, word string) bool {
+	for _, known := range v.Languages[language] {
+		if strings.EqualFold(known.Word, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// Add puts a word in the list of a language, unless it is there already
+func (v *VocabularyData) Add(language string, word VocabularyWord) bool {
+	if v.Has(language, word.Word) {
+		return false
+	}
+	v.Languages[language] = append(v.Languages[language], word)
+	v.markChanged(language, word.Word)
+	return true
+}
+
+// Remove takes a word out of the list of a language
+func (v *VocabularyData) Remove(language, word string) bool {
+	words := v.Languages[language]
+	for i, known := range words {
+		if strings.EqualFold(known.Word, word) {
+			v.Languages[language] = append(words[:i:i], words[i+1:]...)
+			if len(v.Languages[language]) == 0 {
+				delete(v.Languages, language)
+			}
+			v.markChanged(language, word)
+			return true
+		}
+	}
+	return false
+}
+
+// Words returns the words of a language's list in lowercase, for matching
+// against text
+func (v *VocabularyData) Words(language string) map[string]bool {
+	words := make(map[string]bool, len(v.Languages[language]))
+	for _, known := range v.Languages[language] {
+		words[strings.ToLower(known.Word)] = true
+	}
+	return words
+}
+
+// LanguageList returns the languages that have words, sorted
+func (v *VocabularyData) LanguageList() []string {
+	var languages []string
+	for language, words := range v.Languages {
+		if len(words) > 0 {
+			languages = append(languages, language)
+		}
+	}
+	sort.Strings(languages)
+	return languages
+}
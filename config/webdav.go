@@ -0,0 +1,228 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// A WebDAV server such as Nextcloud can stand in for a sync tool: syncing
+// copies the files of the sync directory to a WebDAV folder and back.
+// Where both sides have a book's file they are merged as in sync.go, so
+// the newer position wins whichever machine it was saved on, and the
+// merged file is written to both.
+
+const webdavTimeout = 30 * time.Second
+
+// WebDAVSyncResult counts the files a WebDAV sync changed
+type WebDAVSyncResult struct {
+	Downloaded int // Files of the sync directory changed from the server
+	Uploaded   int // Files changed on the server
+}
+
+// webdavClient talks to the WebDAV folder of a config
+type webdavClient struct {
+	base     string // Folder URL, ending in a slash
+	user     string
+	password string
+	http     *http.Client
+}
+
+// SyncWebDAV copies the sync directory to the WebDAV folder of a config
+// and back. Progress, highlights and notes saved since are merged with the
+// downloaded files by LoadProgress, LoadHighlights and LoadJournal.
+func SyncWebDAV(cfg *Config) (WebDAVSyncResult, error) {
+	var result WebDAVSyncResult
+	if cfg.Sync.WebDAVURL == "" {
+		return result, fmt.Errorf("sync.webdav_url is not set")
+	}
+	client := webdavClient{
+		base:     strings.TrimSuffix(cfg.Sync.WebDAVURL, "/") + "/",
+		user:     cfg.Sync.WebDAVUser,
+		password: cfg.Sync.WebDAVPassword,
+		http:     &http.Client{Timeout: webdavTimeout},
+	}
+
+	dir := cfg.SyncDirectory()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return result, fmt.Errorf("failed to create sync directory: %w", err)
+	}
+	remote, err := client.list()
+	if err != nil {
+		return result, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return result, fmt.Errorf("failed to read sync directory: %w", err)
+	}
+
+	names := slices.Clone(remote)
+	for _, entry := range entries {
+		if name := entry.Name(); !entry.IsDir() && syncableName(name) && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	for _, name := range names {
+		localPath := filepath.Join(dir, name)
+		local, localErr := readSyncedBook(localPath)
+
+		if !slices.Contains(remote, name) {
+			if localErr != nil {
+				continue
+			}
+			if err := client.put(name, local); err != nil {
+				return result, err
+			}
+			result.Uploaded++
+			continue
+		}
+
+		data, err := client.get(name)
+		if err != nil {
+			return result, err
+		}
+		var downloaded SyncedBook
+		if json.Unmarshal(data, &downloaded) != nil || downloaded.Book == "" {
+			continue // Not a file cozy wrote
+		}
+
+		merged := downloaded
+		if localErr == nil {
+			merged = mergeSyncedBooks(local, downloaded)
+		}
+		if localErr != nil || !sameJSON(merged, local) {
+			if err := writeSyncedBook(localPath, merged); err != nil {
+				return result, fmt.Errorf("failed to write sync file: %w", err)
+			}
+			result.Downloaded++
+		}
+		if !sameJSON(merged, downloaded) {
+			if err := client.put(name, merged); err != nil {
+				return result, err
+			}
+			result.Uploaded++
+		}
+	}
+	return result, nil
+}
+
+// syncableName reports whether a file name is one of a synced book
+func syncableName(name string) bool {
+	return !strings.HasPrefix(name, ".") && path.Ext(name) == ".json"
+}
+
+// request sends a request to a file of the folder, or the folder itself
+// for an empty name
+func (c webdavClient) request(method, name string, body []byte, header map[string]string) (*http.Response, error) {
+	request, err := http.NewRequest(method, c.base+url.PathEscape(name), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if c.user != "" {
+		request.SetBasicAuth(c.user, c.password)
+	}
+	for key, value := range header {
+		request.Header.Set(key, value)
+	}
+	return c.http.Do(request)
+}
+
+// list returns the names of the synced books' files in the folder,
+// creating the folder if it doesn't exist yet
+func (c webdavClient) list() ([]string, error) {
+	body := []byte(`<?xml version="1.0" encoding="utf-8"?><d:propfind xmlns:d="DAV:"><d:prop><d:resourcetype/></d:prop></d:propfind>`)
+	response, err := c.request("PROPFIND", "", body, map[string]string{"Depth": "1", "Content-Type": "application/xml"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list WebDAV folder: %w", err)
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusMultiStatus:
+	case http.StatusNotFound:
+		return nil, c.createFolder()
+	default:
+		return nil, fmt.Errorf("failed to list WebDAV folder: %s", response.Status)
+	}
+
+	var multistatus struct {
+		Responses []struct {
+			Href       string    `xml:"href"`
+			Collection *struct{} `xml:"propstat>prop>resourcetype>collection"`
+		} `xml:"response"`
+	}
+	if err := xml.NewDecoder(response.Body).Decode(&multistatus); err != nil {
+		return nil, fmt.Errorf("failed to list WebDAV folder: %w", err)
+	}
+
+	var names []string
+	for _, entry := range multistatus.Responses {
+		href, err := url.PathUnescape(entry.Href)
+		if err != nil || entry.Collection != nil {
+			continue
+		}
+		if name := path.Base(href); syncableName(name) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// createFolder creates the WebDAV folder
+func (c webdavClient) createFolder() error {
+	response, err := c.request("MKCOL", "", nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create WebDAV folder: %w", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusCreated {
+		return fmt.Errorf("failed to create WebDAV folder: %s", response.Status)
+	}
+	return nil
+}
+
+// get downloads a file of the folder
+func (c webdavClient) get(name string) ([]byte, error) {
+	response, err := c.request(http.MethodGet, name, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", name, response.Status)
+	}
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// put uploads a synced book to a file of the folder
+func (c webdavClient) put(name string, book SyncedBook) error {
+	data, err := json.MarshalIndent(book, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", name, err)
+	}
+	response, err := c.request(http.MethodPut, name, data, map[string]string{"Content-Type": "application/json"})
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", name, err)
+	}
+	response.Body.Close()
+	switch response.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	}
+	return fmt.Errorf("failed to upload %s: %s", name, response.Status)
+}
@@ -0,0 +1,127 @@
+package dictionary
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// dictd reads dictionaries in the dictd format (.index, .dict[.dz]), which
+// is also how WordNet and the FreeDict collection are commonly packaged
+type dictd struct {
+	name     string
+	dataPath string
+
+	index map[string][]dictdEntry // Keyed by lowercased headword
+
+	once sync.Once
+	data []byte
+	err  error
+}
+
+// dictdEntry locates one article in the .dict file
+type dictdEntry struct {
+	word   string
+	offset int
+	size   int
+}
+
+// dictd encodes offsets in base 64 with this alphabet, most significant digit first
+const dictdDigits = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+func openDictd(indexPath string) (*dictd, error) {
+	base := strings.TrimSuffix(indexPath, ".index")
+	dataPath, err := findFile(base, ".dict", ".dict.dz")
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(indexPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dictd index: %w", err)
+	}
+	defer file.Close()
+
+	d := &dictd{
+		name:     filepath.Base(base),
+		dataPath: dataPath,
+		index:    make(map[string][]dictdEntry),
+	}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < 3 {
+			continue
+		}
+		entry := dictdEntry{
+			word:   fields[0],
+			offset: decodeDictdNumber(fields[1]),
+			size:   decodeDictdNumber(fields[2]),
+		}
+		if entry.offset < 0 || entry.size < 0 {
+			continue
+		}
+		key := strings.ToLower(entry.word)
+		d.index[key] = append(d.index[key], entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read dictd index: %w", err)
+	}
+
+	// The short name is stored as a special entry near the start of the data
+	if entries := d.index["00-database-short"]; len(entries) > 0 {
+		if article, err := readRange(dataPath, entries[0].offset, entries[0].size); err == nil {
+			lines := strings.Split(strings.TrimSpace(string(article)), "\n")
+			if name := strings.TrimSpace(lines[len(lines)-1]); name != "" {
+				d.name = name
+			}
+		}
+	}
+
+	return d, nil
+}
+
+// decodeDictdNumber decodes a dictd base 64 number, or returns -1
+func decodeDictdNumber(s string) int {
+	n := 0
+	for _, c := range s {
+		digit := strings.IndexRune(dictdDigits, c)
+		if digit < 0 {
+			return -1
+		}
+		n = n*64 + digit
+	}
+	return n
+}
+
+func (d *dictd) Name() string { return d.name }
+
+// Lookup returns the articles for a word, ignoring case
+func (d *dictd) Lookup(word string) ([]Entry, error) {
+	found := d.index[strings.ToLower(word)]
+	if len(found) == 0 {
+		return nil, nil
+	}
+
+	// Article data is only read once it is needed
+	d.once.Do(func() {
+		d.data, d.err = readFile(d.dataPath)
+	})
+	if d.err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", d.name, d.err)
+	}
+
+	var entries []Entry
+	for _, e := range found {
+		if e.offset+e.size > len(d.data) {
+			continue
+		}
+		text := strings.TrimSpace(string(d.data[e.offset : e.offset+e.size]))
+		entries = append(entries, Entry{Word: e.word, Dictionary: d.name, Definition: text})
+	}
+	return entries, nil
+}
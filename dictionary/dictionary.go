@@ -0,0 +1,262 @@
+// Package dictionary looks up word definitions in local dictionary files.
+package dictionary
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Entry is one definition of a word from a single dictionary
+type Entry struct {
+	Word       string
+	Dictionary string
+	Definition string
+}
+
+// Dictionary is a source of definitions
+type Dictionary interface {
+	Name() string
+	Lookup(word string) ([]Entry, error)
+}
+
+// Open opens a dictionary file. StarDict dictionaries are opened by their
+// .ifo file and dictd dictionaries by their .index file.
+func Open(path string) (Dictionary, error) {
+	switch {
+	case strings.HasSuffix(path, ".ifo"):
+		return openStarDict(path)
+	case strings.HasSuffix(path, ".index"):
+		return openDictd(path)
+	}
+	return nil, fmt.Errorf("unsupported dictionary format: %s", filepath.Base(path))
+}
+
+// Set is a group of dictionaries that are searched together. Dictionaries
+// are loaded on the first lookup; a Set is safe for concurrent use.
+type Set struct {
+	paths []string
+
+	mu           sync.Mutex
+	loaded       bool
+	dictionaries []Dictionary
+}
+
+// NewSet creates a set from dictionary files and directories containing them
+func NewSet(paths []string) *Set {
+	return &Set{paths: paths}
+}
+
+// Add appends a dictionary to the set, e.g. an online backend
+func (s *Set) Add(d Dictionary) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.load()
+	s.dictionaries = append(s.dictionaries, d)
+}
+
+// load opens every dictionary found under the configured paths
+func (s *Set) load() {
+	if s.loaded {
+		return
+	}
+	s.loaded = true
+
+	for _, path := range s.paths {
+		for _, file := range findDictionaries(path) {
+			if d, err := Open(file); err == nil {
+				s.dictionaries = append(s.dictionaries, d)
+			}
+		}
+	}
+}
+
+// findDictionaries returns the dictionary files at or directly below path
+func findDictionaries(path string) []string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	if !info.IsDir() {
+		return []string{path}
+	}
+
+	var files []string
+	filepath.WalkDir(path, func(file string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		// StarDict bundles usually live one directory down; go no deeper
+		rel, _ := filepath.Rel(path, file)
+		if d.IsDir() && strings.Contains(rel, string(filepath.Separator)) {
+			return filepath.SkipDir
+		}
+		if strings.HasSuffix(file, ".ifo") || strings.HasSuffix(file, ".index") {
+			files = append(files, file)
+		}
+		return nil
+	})
+	return files
+}
+
+// Names returns the names of the dictionaries in the set
+func (s *Set) Names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.load()
+
+	names := make([]string, len(s.dictionaries))
+	for i, d := range s.dictionaries {
+		names[i] = d.Name()
+	}
+	return names
+}
+
+// Lookup searches every dictionary for the word, falling back to simple
+// base forms ("running" -> "run") when the word itself is not found
+func (s *Set) Lookup(word string) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.load()
+
+	if len(s.dictionaries) == 0 {
+		return nil, fmt.Errorf("no dictionaries found")
+	}
+
+	var lastErr error
+	for _, candidate := range Candidates(word) {
+		var entries []Entry
+		for _, d := range s.dictionaries {
+			found, err := d.Lookup(candidate)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			entries = append(entries, found...)
+		}
+		if len(entries) > 0 {
+			return entries, nil
+		}
+	}
+	return nil, lastErr
+}
+
+// CleanWord trims surrounding punctuation from a word as it appears in text
+func CleanWord(word string) string {
+	return strings.TrimFunc(word, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Candidates returns the forms of a word to try, most specific first
+func Candidates(word string) []string {
+	word = CleanWord(word)
+	if word == "" {
+		return nil
+	}
+
+	lower := strings.ToLower(word)
+	candidates := []string{word}
+	add := func(s string) {
+		if len([]rune(s)) < 2 {
+			return
+		}
+		for _, existing := range candidates {
+			if existing == s {
+				return
+			}
+		}
+		candidates = append(candidates, s)
+	}
+	add(lower)
+
+	for _, suffix := range []string{"'s", "’s"} {
+		if strings.HasSuffix(lower, suffix) {
+			add(strings.TrimSuffix(lower, suffix))
+		}
+	}
+
+	// Common English inflections
+	rules := []struct{ suffix, replacement string }{
+		{"ies", "y"}, {"es", ""}, {"s", ""},
+		{"ied", "y"}, {"ed", "e"}, {"ed", ""},
+		{"ing", "e"}, {"ing", ""},
+		{"ily", "y"}, {"ly", ""},
+		{"er", ""}, {"est", ""},
+	}
+	for _, rule := range rules {
+		if stem, ok := strings.CutSuffix(lower, rule.suffix); ok {
+			add(stem + rule.replacement)
+			// Doubled consonants: "stopped" -> "stop"
+			if n := len(stem); rule.replacement == "" && n > 2 && stem[n-1] == stem[n-2] {
+				add(stem[:n-1])
+			}
+		}
+	}
+
+	return candidates
+}
+
+// readFile reads a possibly gzip- or dictzip-compressed file
+func readFile(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if !strings.HasSuffix(path, ".gz") && !strings.HasSuffix(path, ".dz") {
+		return io.ReadAll(file)
+	}
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s: %w", filepath.Base(path), err)
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// readRange reads size bytes at offset from a possibly compressed file
+// without loading the rest of it
+func readRange(path string, offset, size int) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(path, ".gz") || strings.HasSuffix(path, ".dz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	if _, err := io.CopyN(io.Discard, reader, int64(offset)); err != nil {
+		return nil, err
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// findFile returns the first of base+suffix that exists
+func findFile(base string, suffixes ...string) (string, error) {
+	for _, suffix := range suffixes {
+		if _, err := os.Stat(base + suffix); err == nil {
+			return base + suffix, nil
+		}
+	}
+	return "", fmt.Errorf("missing %s%s", filepath.Base(base), suffixes[0])
+}
@@ -0,0 +1,201 @@
+package dictionary
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Wikimedia asks API clients to identify themselves
+const userAgent = "cozy-ebook-reader (https://github.com/cbrasser/cozy)"
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// Wiktionary looks up definitions on Wiktionary
+type Wiktionary struct {
+	language string
+	cache    cache
+}
+
+// NewWiktionary creates a Wiktionary backend for the given language edition
+// ("en", "de", ...), caching responses in cacheDir
+func NewWiktionary(language, cacheDir string) *Wiktionary {
+	return &Wiktionary{language: defaultLanguage(language), cache: cache{dir: cacheDir}}
+}
+
+func (w *Wiktionary) Name() string { return "Wiktionary" }
+
+// wiktionaryUsage is one part of speech in the definition API response
+type wiktionaryUsage struct {
+	PartOfSpeech string `json:"partOfSpeech"`
+	Language     string `json:"language"`
+	Definitions  []struct {
+		Definition string `json:"definition"`
+	} `json:"definitions"`
+}
+
+// Lookup fetches the definitions of a word or phrase
+func (w *Wiktionary) Lookup(word string) ([]Entry, error) {
+	return w.cache.fetch("wiktionary-"+w.language, word, func() ([]Entry, error) {
+		endpoint := fmt.Sprintf("https://%s.wiktionary.org/api/rest_v1/page/definition/%s",
+			w.language, url.PathEscape(strings.ReplaceAll(word, " ", "_")))
+
+		var response map[string][]wiktionaryUsage
+		if found, err := getJSON(endpoint, &response); err != nil || !found {
+			return nil, err
+		}
+
+		// Usages in the edition's own language come first
+		var others []string
+		for language := range response {
+			if language != w.language {
+				others = append(others, language)
+			}
+		}
+		sort.Strings(others)
+		languages := append([]string{w.language}, others...)
+
+		var text strings.Builder
+		for _, language := range languages {
+			for _, usage := range response[language] {
+				if text.Len() > 0 {
+					text.WriteString("\n")
+				}
+				fmt.Fprintf(&text, "%s (%s)\n", usage.PartOfSpeech, usage.Language)
+				for i, definition := range usage.Definitions {
+					// Keep the extract short
+					if i == 3 {
+						break
+					}
+					if plain := stripMarkup(definition.Definition); plain != "" {
+						fmt.Fprintf(&text, "  %d. %s\n", i+1, plain)
+					}
+				}
+			}
+		}
+		if text.Len() == 0 {
+			return nil, nil
+		}
+		return []Entry{{Word: word, Dictionary: w.Name(), Definition: strings.TrimSpace(text.String())}}, nil
+	})
+}
+
+// Wikipedia looks up the summary of a Wikipedia article
+type Wikipedia struct {
+	language string
+	cache    cache
+}
+
+// NewWikipedia creates a Wikipedia backend for the given language edition,
+// caching responses in cacheDir
+func NewWikipedia(language, cacheDir string) *Wikipedia {
+	return &Wikipedia{language: defaultLanguage(language), cache: cache{dir: cacheDir}}
+}
+
+func (w *Wikipedia) Name() string { return "Wikipedia" }
+
+// Lookup fetches the introduction of the article with the given title
+func (w *Wikipedia) Lookup(title string) ([]Entry, error) {
+	return w.cache.fetch("wikipedia-"+w.language, title, func() ([]Entry, error) {
+		endpoint := fmt.Sprintf("https://%s.wikipedia.org/api/rest_v1/page/summary/%s",
+			w.language, url.PathEscape(strings.ReplaceAll(title, " ", "_")))
+
+		var response struct {
+			Type    string `json:"type"`
+			Title   string `json:"title"`
+			Extract string `json:"extract"`
+		}
+		if found, err := getJSON(endpoint, &response); err != nil || !found {
+			return nil, err
+		}
+
+		// Disambiguation pages have no useful extract
+		if response.Extract == "" || response.Type == "disambiguation" {
+			return nil, nil
+		}
+		return []Entry{{Word: response.Title, Dictionary: w.Name(), Definition: response.Extract}}, nil
+	})
+}
+
+// getJSON fetches and decodes a JSON document. It reports false for pages
+// that do not exist.
+func getJSON(endpoint string, v any) (bool, error) {
+	request, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return false, err
+	}
+	request.Header.Set("User-Agent", userAgent)
+	request.Header.Set("Accept", "application/json")
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return false, fmt.Errorf("online lookup failed: %w", err)
+	}
+	defer response.Body.Close()
+
+	switch {
+	case response.StatusCode == http.StatusNotFound:
+		return false, nil
+	case response.StatusCode != http.StatusOK:
+		return false, fmt.Errorf("online lookup failed: %s", response.Status)
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(v); err != nil {
+		return false, fmt.Errorf("online lookup failed: %w", err)
+	}
+	return true, nil
+}
+
+// cache stores online lookup results on disk so re-reads work offline.
+// Misses are cached too, as an empty list.
+type cache struct {
+	dir string
+}
+
+// fetch returns the cached result for a query or calls lookup and stores it
+func (c cache) fetch(source, query string, lookup func() ([]Entry, error)) ([]Entry, error) {
+	hash := sha1.Sum([]byte(source + "|" + strings.ToLower(query)))
+	path := filepath.Join(c.dir, source, hex.EncodeToString(hash[:])+".json")
+
+	if data, err := os.ReadFile(path); err == nil {
+		var entries []Entry
+		if json.Unmarshal(data, &entries) == nil {
+			return entries, nil
+		}
+	}
+
+	entries, err := lookup()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.dir != "" {
+		if data, err := json.Marshal(entries); err == nil {
+			if os.MkdirAll(filepath.Dir(path), 0755) == nil {
+				os.WriteFile(path, data, 0644)
+			}
+		}
+	}
+	return entries, nil
+}
+
+// stripMarkup converts an HTML snippet to plain text
+func stripMarkup(s string) string {
+	return strings.TrimSpace(html.UnescapeString(markupTagPattern.ReplaceAllString(s, "")))
+}
+
+func defaultLanguage(language string) string {
+	if language == "" {
+		return "en"
+	}
+	return language
+}
@@ -0,0 +1,209 @@
+package dictionary
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// starDict reads StarDict dictionaries (.ifo, .idx[.gz], .dict[.dz])
+type starDict struct {
+	name         string
+	typeSequence string // sametypesequence from the .ifo, if any
+	dataPath     string
+
+	index map[string][]starDictEntry // Keyed by lowercased headword
+
+	once sync.Once
+	data []byte
+	err  error
+}
+
+// starDictEntry locates one article in the .dict file
+type starDictEntry struct {
+	word   string
+	offset uint64
+	size   uint32
+}
+
+var markupTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+func openStarDict(ifoPath string) (*starDict, error) {
+	info, err := readIfo(ifoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	base := strings.TrimSuffix(ifoPath, ".ifo")
+	idxPath, err := findFile(base, ".idx", ".idx.gz")
+	if err != nil {
+		return nil, err
+	}
+	dataPath, err := findFile(base, ".dict", ".dict.dz")
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := readFile(idxPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read StarDict index: %w", err)
+	}
+
+	d := &starDict{
+		name:         info["bookname"],
+		typeSequence: info["sametypesequence"],
+		dataPath:     dataPath,
+		index:        make(map[string][]starDictEntry),
+	}
+	if d.name == "" {
+		d.name = base
+	}
+
+	offsetSize := 4
+	if info["idxoffsetbits"] == "64" {
+		offsetSize = 8
+	}
+
+	// Each index record is: word, NUL, offset, size (big-endian)
+	for len(idx) > 0 {
+		end := bytes.IndexByte(idx, 0)
+		if end < 0 || len(idx) < end+1+offsetSize+4 {
+			break
+		}
+		entry := starDictEntry{word: string(idx[:end])}
+		idx = idx[end+1:]
+		if offsetSize == 8 {
+			entry.offset = binary.BigEndian.Uint64(idx)
+		} else {
+			entry.offset = uint64(binary.BigEndian.Uint32(idx))
+		}
+		entry.size = binary.BigEndian.Uint32(idx[offsetSize:])
+		idx = idx[offsetSize+4:]
+
+		key := strings.ToLower(entry.word)
+		d.index[key] = append(d.index[key], entry)
+	}
+
+	return d, nil
+}
+
+// readIfo parses the key=value lines of a StarDict .ifo file
+func readIfo(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open StarDict info: %w", err)
+	}
+	defer file.Close()
+
+	info := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if key, value, ok := strings.Cut(scanner.Text(), "="); ok {
+			info[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return info, scanner.Err()
+}
+
+func (d *starDict) Name() string { return d.name }
+
+// Lookup returns the articles for a word, ignoring case
+func (d *starDict) Lookup(word string) ([]Entry, error) {
+	found := d.index[strings.ToLower(word)]
+	if len(found) == 0 {
+		return nil, nil
+	}
+
+	// Article data is only read once it is needed
+	d.once.Do(func() {
+		d.data, d.err = readFile(d.dataPath)
+	})
+	if d.err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", d.name, d.err)
+	}
+
+	var entries []Entry
+	for _, e := range found {
+		end := e.offset + uint64(e.size)
+		if end > uint64(len(d.data)) {
+			continue
+		}
+		text := d.article(d.data[e.offset:end])
+		entries = append(entries, Entry{Word: e.word, Dictionary: d.name, Definition: text})
+	}
+	return entries, nil
+}
+
+// article extracts readable text from an article's typed fields
+func (d *starDict) article(data []byte) string {
+	var parts []string
+	add := func(fieldType byte, field []byte) {
+		switch fieldType {
+		case 'm', 'l', 'y', 'k', 't':
+			parts = append(parts, string(field))
+		case 'g', 'h', 'x':
+			// Pango, HTML and XDXF markup
+			text := strings.ReplaceAll(string(field), "<br>", "\n")
+			parts = append(parts, unescapeMarkup(markupTagPattern.ReplaceAllString(text, "")))
+		}
+	}
+
+	// Lowercase types are NUL-terminated text, uppercase types are
+	// length-prefixed binary data
+	readField := func(fieldType byte, last bool) []byte {
+		if fieldType >= 'A' && fieldType <= 'Z' {
+			if last && d.typeSequence != "" {
+				field := data
+				data = nil
+				return field
+			}
+			if len(data) < 4 {
+				data = nil
+				return nil
+			}
+			size := binary.BigEndian.Uint32(data)
+			data = data[4:]
+			size = min(size, uint32(len(data)))
+			field := data[:size]
+			data = data[size:]
+			return field
+		}
+		if last && d.typeSequence != "" {
+			field := data
+			data = nil
+			return field
+		}
+		end := bytes.IndexByte(data, 0)
+		if end < 0 {
+			end = len(data)
+		}
+		field := data[:end]
+		data = data[min(end+1, len(data)):]
+		return field
+	}
+
+	if d.typeSequence != "" {
+		for i := 0; i < len(d.typeSequence); i++ {
+			fieldType := d.typeSequence[i]
+			add(fieldType, readField(fieldType, i == len(d.typeSequence)-1))
+		}
+	} else {
+		for len(data) > 0 {
+			fieldType := data[0]
+			data = data[1:]
+			add(fieldType, readField(fieldType, false))
+		}
+	}
+
+	return strings.TrimSpace(strings.Join(parts, "\n"))
+}
+
+// unescapeMarkup replaces the common XML entities
+func unescapeMarkup(s string) string {
+	return strings.NewReplacer("&lt;", "<", "&gt;", ">", "&quot;", "\"", "&apos;", "'", "&amp;", "&").Replace(s)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/cbrasser/cozy/ebook"
+)
+
+// runDigest builds today's edition from the feeds in [digest] and puts it
+// in the library
+func runDigest(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("digest", flag.ContinueOnError)
+	dest := flags.String("to", cfg.DigestPath(), "folder to save to (defaults to digest.folder)")
+	since := flags.Duration("since", 24*time.Hour, "take articles published this long ago or later")
+	replace := flags.Bool("replace", false, "build today's edition again if there is one")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: cozy digest [-to dir] [-since 24h] [-replace]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *dest == "" {
+		return errors.New("no digest folder: set library.path and digest.folder or use -to")
+	}
+
+	now := time.Now()
+	options := ebook.DigestOptions{
+		Since:      now.Add(-*since),
+		MaxPerFeed: cfg.Digest.MaxPerFeed,
+		FullText:   cfg.Digest.FullText,
+		Replace:    *replace,
+	}
+	digest, err := ebook.MakeDigest(cfg.Digest.Feeds, *dest, now, options)
+	for _, failed := range digest.Failed {
+		fmt.Printf("skipped %v\n", failed)
+	}
+	if errors.Is(err, ebook.ErrDuplicate) {
+		return fmt.Errorf("today's edition is already in the library as %s; use -replace to build it again", digest.Path)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d articles -> %s\n", digest.Articles, digest.Path)
+	return nil
+}
@@ -0,0 +1,73 @@
+package ebook
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Books link to places inside chapters by the id of an element, as in
+// "notes.xhtml#note12". The anchor map lists the ids of the whole book
+// with the chapter each is in, read from the HTML on first use, so a link
+// leads to its chapter even when its path doesn't name one. The line an
+// id is on depends on the layout and is in the Anchors of the chapter's
+// RenderResult.
+
+// Anchors returns the chapter of every element id in the book, keyed by
+// the path of the chapter file and the id, as in "text/ch1.xhtml#note1".
+// Chapters are loaded to build it. The map is shared and must not be
+// modified.
+func (b *Book) Anchors() map[string]int {
+	b.anchorsOnce.Do(func() {
+		b.anchors = make(map[string]int)
+		for i := range b.Chapters {
+			chapter := &b.Chapters[i]
+			if !chapter.HTML {
+				continue
+			}
+			for _, id := range elementIDs(chapter.Text()) {
+				key := chapter.Href + "#" + id
+				if _, ok := b.anchors[key]; !ok {
+					b.anchors[key] = i
+				}
+			}
+		}
+	})
+	return b.anchors
+}
+
+// AnchorChapter returns the first chapter with an element id
+func (b *Book) AnchorChapter(id string) (int, bool) {
+	if id == "" {
+		return 0, false
+	}
+	anchors := b.Anchors()
+	for _, chapter := range b.Chapters {
+		if index, ok := anchors[chapter.Href+"#"+id]; ok {
+			return index, true
+		}
+	}
+	return 0, false
+}
+
+// elementIDs returns the ids of the elements in HTML, and the names of
+// <a name> anchors
+func elementIDs(htmlContent string) []string {
+	var ids []string
+	tokenizer := html.NewTokenizer(strings.NewReader(htmlContent))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return ids
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokenizer.TagName()
+			for hasAttr {
+				var key, val []byte
+				key, val, hasAttr = tokenizer.TagAttr()
+				if len(val) > 0 && (string(key) == "id" || (string(key) == "name" && string(name) == "a")) {
+					ids = append(ids, string(val))
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package ebook
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cbrasser/cozy/config"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Works on Archive of Our Own are saved from the EPUB download AO3 offers
+// on each work page. The page also says which fandoms a work belongs to,
+// which become its folder and so its tag in the library, and how many of
+// its chapters are posted, so works in progress can be downloaded again
+// when new chapters come out.
+
+// AO3Site is where works are downloaded from
+const AO3Site = "https://archiveofourown.org"
+
+// Work addresses, also in collections and on the ao3.org short domain
+var (
+	ao3WorkURL = regexp.MustCompile(`^https?://(?:www\.)?(?:archiveofourown\.org|ao3\.org)/(?:collections/[^/]+/)?works/(\d+)`)
+	ao3WorkID  = regexp.MustCompile(`^\d+$`)
+)
+
+// Time between requests when checking several works, as AO3 turns away
+// clients that ask too often
+const ao3Pause = 2 * time.Second
+
+// AO3Work is what a work page says about a work
+type AO3Work struct {
+	ID            string
+	Title         string
+	Author        string
+	Fandoms       []string
+	Chapters      int    // Chapters posted
+	TotalChapters int    // Planned chapters, 0 if the author hasn't said
+	Updated       string // Date of the last update, or of publication
+	Download      string // Address of the EPUB download
+}
+
+// ParseAO3Work returns the id of a work from its address or its id
+func ParseAO3Work(work string) (string, error) {
+	work = strings.TrimSpace(work)
+	if ao3WorkID.MatchString(work) {
+		return work, nil
+	}
+	if match := ao3WorkURL.FindStringSubmatch(work); match != nil {
+		return match[1], nil
+	}
+	return "", fmt.Errorf("not an AO3 work: %s", work)
+}
+
+// SaveAO3Work downloads a work, given by its address or id, into the AO3
+// folder of the library and keeps track of it to check for new chapters.
+// A work saved before is left as it is, returning ErrDuplicate.
+func SaveAO3Work(cfg *config.Config, work string) (config.AO3Work, error) {
+	id, err := ParseAO3Work(work)
+	if err != nil {
+		return config.AO3Work{}, err
+	}
+	dir := cfg.AO3Path()
+	if dir == "" {
+		return config.AO3Work{}, fmt.Errorf("no AO3 folder: set library.path and ao3.folder")
+	}
+	works, err := config.LoadAO3(cfg)
+	if err != nil {
+		return config.AO3Work{}, err
+	}
+	if saved, ok := works.Works[id]; ok {
+		if _, err := os.Stat(saved.Path); err == nil {
+			return saved, ErrDuplicate
+		}
+	}
+
+	page, err := FetchAO3Work(id)
+	if err != nil {
+		return config.AO3Work{}, err
+	}
+	path, err := DownloadAO3Work(page, dir, "")
+	if err != nil {
+		return config.AO3Work{}, err
+	}
+	saved := config.AO3Work{
+		ID:            id,
+		Title:         page.Title,
+		Path:          path,
+		Chapters:      page.Chapters,
+		TotalChapters: page.TotalChapters,
+		Updated:       page.Updated,
+		Checked:       time.Now(),
+	}
+	works.Works[id] = saved
+	return saved, config.SaveAO3(cfg, works)
+}
+
+// CheckAO3Works looks for new chapters of the saved works in progress and
+// downloads the ones that have some again, returning them. Works no longer
+// in the library are forgotten. Works that could not be checked are
+// reported in the error, after the others are done.
+func CheckAO3Works(cfg *config.Config) ([]config.AO3Work, error) {
+	works, err := config.LoadAO3(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated []config.AO3Work
+	var failed []error
+	for i, saved := range works.InProgress() {
+		if _, err := os.Stat(saved.Path); errors.Is(err, fs.ErrNotExist) {
+			delete(works.Works, saved.ID)
+			continue
+		}
+		if i > 0 {
+			time.Sleep(ao3Pause)
+		}
+
+		page, err := FetchAO3Work(saved.ID)
+		if err != nil {
+			failed = append(failed, fmt.Errorf("%s: %w", saved.Title, err))
+			continue
+		}
+		if page.Chapters > saved.Chapters || page.Updated != saved.Updated {
+			if _, err := DownloadAO3Work(page, "", saved.Path); err != nil {
+				failed = append(failed, fmt.Errorf("%s: %w", saved.Title, err))
+				continue
+			}
+			newChapters := page.Chapters > saved.Chapters
+			saved.Title, saved.Chapters, saved.TotalChapters, saved.Updated = page.Title, page.Chapters, page.TotalChapters, page.Updated
+			if newChapters {
+				updated = append(updated, saved)
+			}
+		}
+		saved.Checked = time.Now()
+		works.Works[saved.ID] = saved
+	}
+
+	works.LastCheck = time.Now()
+	if err := config.SaveAO3(cfg, works); err != nil {
+		return updated, err
+	}
+	return updated, errors.Join(failed...)
+}
+
+// FetchAO3Work reads the page of a work
+func FetchAO3Work(id string) (*AO3Work, error) {
+	// Works rated for adults show a warning instead unless asked not to
+	pageURL := AO3Site + "/works/" + id + "?view_adult=true"
+	response, err := ao3Get(pageURL)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	// Works for registered users only send everyone else to the login page
+	if strings.HasPrefix(response.Request.URL.Path, "/users/login") {
+		return nil, fmt.Errorf("work %s is only shown to logged-in AO3 users", id)
+	}
+	doc, err := html.Parse(io.LimitReader(response.Body, maxArticleSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse AO3 work: %w", err)
+	}
+
+	work := parseAO3Work(doc)
+	work.ID = id
+	if work.Title == "" {
+		return nil, fmt.Errorf("no AO3 work found at %s", pageURL)
+	}
+	if work.Download == "" {
+		work.Download = AO3Site + "/downloads/" + id + "/work.epub"
+	} else if link, err := response.Request.URL.Parse(work.Download); err == nil {
+		work.Download = link.String()
+	}
+	return work, nil
+}
+
+// DownloadAO3Work saves the EPUB of a work as Author - Title.epub in a
+// folder named after its first fandom, and returns its path. An earlier
+// download at path is replaced, so a work keeps its place in the library
+// and its reading progress when downloaded again; with no path, a new file
+// is made under dir.
+func DownloadAO3Work(work *AO3Work, dir, path string) (string, error) {
+	if path == "" {
+		fandom := "Unknown Fandom"
+		if len(work.Fandoms) > 0 {
+			fandom = work.Fandoms[0]
+		}
+		folder := filepath.Join(dir, BookFileName(fandom, ""))
+		if err := os.MkdirAll(folder, 0755); err != nil {
+			return "", fmt.Errorf("failed to create %s: %w", folder, err)
+		}
+		path = uniquePath(filepath.Join(folder, BookFileName(work.Title, work.Author)+".epub"))
+	}
+
+	response, err := ao3Get(work.Download)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	// Download next to the book and move it over, so a failed download
+	// leaves the old copy
+	temp, err := os.CreateTemp(filepath.Dir(path), ".cozy-ao3-*.epub")
+	if err != nil {
+		return "", fmt.Errorf("failed to download AO3 work: %w", err)
+	}
+	defer os.Remove(temp.Name())
+	_, err = io.Copy(temp, response.Body)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to download AO3 work: %w", err)
+	}
+	if !isZipFile(temp.Name()) {
+		return "", fmt.Errorf("AO3 did not send an EPUB for work %s", work.ID)
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to save AO3 work: %w", err)
+	}
+	return path, nil
+}
+
+// ao3Get requests a page or download from AO3
+func ao3Get(address string) (*http.Response, error) {
+	request, err := http.NewRequest(http.MethodGet, address, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach AO3: %w", err)
+	}
+	request.Header.Set("User-Agent", fetchUserAgent)
+	response, err := fetchClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach AO3: %w", err)
+	}
+	switch response.StatusCode {
+	case http.StatusOK:
+		return response, nil
+	case http.StatusNotFound:
+		response.Body.Close()
+		return nil, fmt.Errorf("AO3 has no such work: %s", address)
+	case http.StatusTooManyRequests:
+		response.Body.Close()
+		return nil, fmt.Errorf("AO3 asks to slow down; try again later")
+	}
+	response.Body.Close()
+	return nil, fmt.Errorf("failed to reach AO3: %s", response.Status)
+}
+
+// parseAO3Work reads the title, author, fandoms, chapters and download
+// link of a work page
+func parseAO3Work(doc *html.Node) *AO3Work {
+	work := &AO3Work{}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type != html.ElementNode:
+		case n.DataAtom == atom.H2 && hasClass(n, "title") && work.Title == "":
+			work.Title = articleText(n)
+		case n.DataAtom == atom.A && attribute(n, "rel") == "author" && work.Author == "":
+			work.Author = articleText(n)
+		case n.DataAtom == atom.Dd && hasClass(n, "fandom"):
+			work.Fandoms = append(work.Fandoms, tagTexts(n)...)
+		case n.DataAtom == atom.Dd && hasClass(n, "chapters"):
+			work.Chapters, work.TotalChapters = parseChapterCount(articleText(n))
+		case n.DataAtom == atom.Dd && hasClass(n, "published") && work.Updated == "":
+			work.Updated = articleText(n)
+		case n.DataAtom == atom.Dd && hasClass(n, "status"):
+			// Updated or completed, which comes after published
+			work.Updated = articleText(n)
+		case n.DataAtom == atom.A && work.Download == "":
+			if link, err := url.Parse(attribute(n, "href")); err == nil && strings.HasPrefix(link.Path, "/downloads/") && strings.HasSuffix(link.Path, ".epub") {
+				work.Download = link.String()
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+	return work
+}
+
+// parseChapterCount reads AO3's "3/10" chapter count, where "?" means the
+// author hasn't said how many chapters there will be
+func parseChapterCount(text string) (posted, total int) {
+	before, after, _ := strings.Cut(strings.ReplaceAll(text, ",", ""), "/")
+	posted, _ = strconv.Atoi(strings.TrimSpace(before))
+	total, _ = strconv.Atoi(strings.TrimSpace(after))
+	return posted, total
+}
+
+// tagTexts returns the text of the tag links inside an element
+func tagTexts(n *html.Node) []string {
+	var tags []string
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.DataAtom == atom.A && hasClass(child, "tag") {
+			tags = append(tags, articleText(child))
+			continue
+		}
+		tags = append(tags, tagTexts(child)...)
+	}
+	return tags
+}
+
+// isZipFile reports whether a file is a zip archive, as EPUBs are. AO3
+// sends a web page instead when a download goes wrong.
+func isZipFile(path string) bool {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return false
+	}
+	archive.Close()
+	return true
+}
+
+// hasClass reports whether an element has a class
+func hasClass(n *html.Node, class string) bool {
+	for _, name := range strings.Fields(attribute(n, "class")) {
+		if name == class {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,552 @@
+package ebook
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+// Web articles can be saved to read later. The page is cut down to its
+// text the way Readability and browsers' reader views do it: paragraphs
+// are scored by their length and commas, the scores go to the elements
+// holding them, and the best scored element, less the share of its text
+// that is links, is taken to be the article, along with siblings that
+// score well too. The result is written as a one-chapter EPUB keeping the
+// page's address as its dc:source.
+
+// Largest page read, to keep a runaway download from filling memory
+const maxArticleSize = 10 << 20
+
+// Article is the readable part of a web page
+type Article struct {
+	Title     string
+	Byline    string // Author, as the page names them
+	Site      string // Name of the site, or its host
+	Published string // Publication date, as YYYY-MM-DD when the page gives a timestamp
+	Excerpt   string
+	Language  string
+	URL       string
+	Content   string // HTML of the article text
+}
+
+var (
+	// Class names and ids of page furniture, unless they also look like content
+	unlikelyClass = regexp.MustCompile(`(?i)-ad-|ai2html|banner|breadcrumbs|combx|comment|community|cover-wrap|disqus|extra|footer|gdpr|header|legends|menu|related|remark|replies|rss|shoutbox|sidebar|skyscraper|social|sponsor|supplemental|ad-break|agegate|pagination|pager|popup|yom-remote`)
+	maybeClass    = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+
+	// Class names and ids that make an element more or less likely to be the article
+	positiveClass = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|post|text|blog|story`)
+	negativeClass = regexp.MustCompile(`(?i)-ad-|hidden|^hid$|banner|combx|comment|com-|contact|foot|footer|footnote|gdpr|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+)
+
+// Elements dropped from a page with their content before scoring
+var articleDropped = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Nav:      true,
+	atom.Aside:    true,
+	atom.Footer:   true,
+	atom.Form:     true,
+	atom.Button:   true,
+	atom.Input:    true,
+	atom.Select:   true,
+	atom.Textarea: true,
+	atom.Iframe:   true,
+	atom.Object:   true,
+	atom.Embed:    true,
+	atom.Svg:      true,
+	atom.Canvas:   true,
+	atom.Video:    true,
+	atom.Audio:    true,
+	atom.Img:      true,
+	atom.Picture:  true,
+	atom.Link:     true,
+	atom.Meta:     true,
+}
+
+// Elements that break a <div> into blocks, so it isn't scored as a paragraph
+var articleBlocks = map[atom.Atom]bool{
+	atom.Address:    true,
+	atom.Article:    true,
+	atom.Blockquote: true,
+	atom.Div:        true,
+	atom.Dl:         true,
+	atom.Figure:     true,
+	atom.H1:         true,
+	atom.H2:         true,
+	atom.H3:         true,
+	atom.H4:         true,
+	atom.H5:         true,
+	atom.H6:         true,
+	atom.Ol:         true,
+	atom.P:          true,
+	atom.Pre:        true,
+	atom.Section:    true,
+	atom.Table:      true,
+	atom.Ul:         true,
+}
+
+// FetchArticle downloads a web page and extracts its article
+func FetchArticle(rawURL string) (*Article, error) {
+	link, err := url.Parse(rawURL)
+	if err != nil || (link.Scheme != "http" && link.Scheme != "https") || link.Host == "" {
+		return nil, fmt.Errorf("not a web address: %s", rawURL)
+	}
+
+	request, err := http.NewRequest(http.MethodGet, link.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download article: %w", err)
+	}
+	request.Header.Set("User-Agent", fetchUserAgent)
+	request.Header.Set("Accept", "text/html,application/xhtml+xml")
+	response, err := fetchClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download article: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download article: %s", response.Status)
+	}
+
+	contentType := response.Header.Get("Content-Type")
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "" && mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("%s is not a web page (%s)", rawURL, mediaType)
+	}
+	body, err := charset.NewReader(io.LimitReader(response.Body, maxArticleSize), contentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read article: %w", err)
+	}
+	doc, err := html.Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse article: %w", err)
+	}
+
+	// Links are relative to where redirects ended up
+	article := ExtractArticle(doc, response.Request.URL)
+	if strings.TrimSpace(htmlToText(article.Content)) == "" {
+		return nil, fmt.Errorf("no article text found at %s", rawURL)
+	}
+	return article, nil
+}
+
+// ExtractArticle finds the article of a parsed web page and its metadata.
+// The page is changed in the process.
+func ExtractArticle(doc *html.Node, pageURL *url.URL) *Article {
+	article := articleMetadata(doc)
+	article.URL = pageURL.String()
+	if article.Site == "" {
+		article.Site = strings.TrimPrefix(pageURL.Hostname(), "www.")
+	}
+
+	body := findElement(doc, "body")
+	if body == nil {
+		body = doc
+	}
+	pruneArticle(body)
+
+	nodes := articleNodes(body)
+	for _, node := range nodes {
+		cleanArticle(node, pageURL)
+	}
+	// A title repeated in the text would show twice under the chapter heading
+	for _, node := range nodes {
+		if heading := findHeading(node); heading != nil {
+			if heading != node && articleText(heading) == article.Title {
+				heading.Parent.RemoveChild(heading)
+			}
+			break
+		}
+	}
+
+	var content strings.Builder
+	for _, node := range nodes {
+		if node == body {
+			for child := body.FirstChild; child != nil; child = child.NextSibling {
+				html.Render(&content, child)
+			}
+			continue
+		}
+		html.Render(&content, node)
+	}
+	article.Content = content.String()
+	return article
+}
+
+// Book returns the article as a book of one chapter, headed by its title
+// and byline
+func (a *Article) Book() *Book {
+	var details []string
+	for _, detail := range []string{a.Byline, a.Site, a.Published} {
+		if detail != "" {
+			details = append(details, html.EscapeString(detail))
+		}
+	}
+	content := "<h1>" + html.EscapeString(a.Title) + "</h1>\n"
+	if len(details) > 0 {
+		content += "<p><em>" + strings.Join(details, " · ") + "</em></p>\n"
+	}
+	content += a.Content
+
+	book := &Book{
+		Title:    a.Title,
+		Author:   a.Byline,
+		Format:   FormatEPUB,
+		Chapters: []Chapter{{Title: a.Title, Content: content, HTML: true}},
+		Metadata: map[string]string{
+			"publisher":   a.Site,
+			"date":        a.Published,
+			"description": a.Excerpt,
+			"language":    a.Language,
+			"source":      a.URL,
+		},
+	}
+	if a.Byline != "" {
+		book.Contributors = []Contributor{{Name: a.Byline, Role: RoleAuthor}}
+	}
+	return book
+}
+
+// ImportArticle downloads a web article into a folder as an EPUB named
+// "Site - Title" and returns the path of the new file. If the folder
+// already has an article of that name, ErrDuplicate is returned along with
+// its path.
+func ImportArticle(rawURL, dir string) (string, error) {
+	article, err := FetchArticle(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	target := filepath.Join(dir, BookFileName(article.Title, article.Site)+".epub")
+	if _, err := os.Stat(target); err == nil {
+		return target, ErrDuplicate
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	return target, WriteEPUB(article.Book(), target)
+}
+
+// articleMetadata reads the title, author, site and date of a page from
+// its <head>, preferring the Open Graph and article properties that sites
+// fill in for link previews
+func articleMetadata(doc *html.Node) *Article {
+	meta := make(map[string]string)
+	var title string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Html:
+				meta["lang"] = attribute(n, "lang")
+			case atom.Title:
+				if title == "" {
+					title = articleText(n)
+				}
+			case atom.Meta:
+				name := strings.ToLower(attribute(n, "property"))
+				if name == "" {
+					name = strings.ToLower(attribute(n, "name"))
+				}
+				if value := strings.Join(strings.Fields(attribute(n, "content")), " "); name != "" && value != "" && meta[name] == "" {
+					meta[name] = value
+				}
+			case atom.Body:
+				return
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+
+	first := func(names ...string) string {
+		for _, name := range names {
+			if value := meta[name]; value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+	article := &Article{
+		Title:     first("og:title", "twitter:title", "dc.title"),
+		Byline:    first("author", "article:author", "dc.creator", "parsely-author"),
+		Site:      first("og:site_name", "application-name"),
+		Published: first("article:published_time", "dc.date", "date", "parsely-pub-date"),
+		Excerpt:   first("og:description", "description", "twitter:description"),
+		Language:  meta["lang"],
+	}
+	if article.Title == "" {
+		article.Title = title
+	}
+	if article.Title == "" {
+		article.Title = "Untitled"
+	}
+	// Profile links are no use as a byline
+	if strings.HasPrefix(article.Byline, "http://") || strings.HasPrefix(article.Byline, "https://") {
+		article.Byline = ""
+	}
+	if published, err := time.Parse(time.RFC3339, article.Published); err == nil {
+		article.Published = published.Format("2006-01-02")
+	} else if i := strings.Index(article.Published, "T"); i > 0 {
+		article.Published = article.Published[:i]
+	}
+	return article
+}
+
+// pruneArticle drops what can't be part of the article: scripts, forms,
+// navigation, media, hidden elements and page furniture
+func pruneArticle(n *html.Node) {
+	for child := n.FirstChild; child != nil; {
+		next := child.NextSibling
+		switch {
+		case child.Type == html.CommentNode:
+			n.RemoveChild(child)
+		case child.Type != html.ElementNode:
+		case articleDropped[child.DataAtom] || isHidden(child) || isUnlikely(child):
+			n.RemoveChild(child)
+		default:
+			pruneArticle(child)
+		}
+		child = next
+	}
+}
+
+// isHidden reports whether an element is hidden from readers of the page
+func isHidden(n *html.Node) bool {
+	style := strings.ReplaceAll(strings.ToLower(attribute(n, "style")), " ", "")
+	_, hidden := attributeValue(n, "hidden")
+	return hidden || attribute(n, "aria-hidden") == "true" ||
+		strings.Contains(style, "display:none") || strings.Contains(style, "visibility:hidden")
+}
+
+// isUnlikely reports whether the class or id of an element marks it as
+// page furniture rather than content
+func isUnlikely(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.Body, atom.Article, atom.Main, atom.A, atom.Table, atom.Tbody, atom.Tr, atom.Td, atom.Th:
+		return false
+	}
+	names := attribute(n, "class") + " " + attribute(n, "id")
+	return unlikelyClass.MatchString(names) && !maybeClass.MatchString(names) || attribute(n, "role") == "complementary"
+}
+
+// articleNodes scores the elements of a pruned page and returns the ones
+// that make up its article, in page order
+func articleNodes(body *html.Node) []*html.Node {
+	scores := make(map[*html.Node]float64)
+	addScore := func(n *html.Node, score float64) {
+		if n == nil || n.Type != html.ElementNode {
+			return
+		}
+		if _, ok := scores[n]; !ok {
+			scores[n] = initialScore(n)
+		}
+		scores[n] += score
+	}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			if !isParagraph(child) {
+				walk(child)
+				continue
+			}
+			text := articleText(child)
+			length := utf8.RuneCountInString(text)
+			if length < 25 {
+				continue
+			}
+			score := 1 + float64(strings.Count(text, ",")) + float64(min(length/100, 3))
+			addScore(child.Parent, score)
+			if child.Parent != nil {
+				addScore(child.Parent.Parent, score/2)
+			}
+		}
+	}
+	walk(body)
+
+	// The best candidate, less the share of its text that is links
+	var top *html.Node
+	var topScore float64
+	for n, score := range scores {
+		scores[n] = score * (1 - linkDensity(n))
+		if top == nil || scores[n] > topScore {
+			top, topScore = n, scores[n]
+		}
+	}
+	if top == nil || top == body || top.Parent == nil {
+		return []*html.Node{body}
+	}
+
+	// Siblings that score well, or read like paragraphs, belong to it too
+	threshold := math.Max(10, topScore*0.2)
+	var nodes []*html.Node
+	for sibling := top.Parent.FirstChild; sibling != nil; sibling = sibling.NextSibling {
+		if sibling == top || (sibling.Type == html.ElementNode && (scores[sibling] >= threshold || readsLikeParagraph(sibling))) {
+			nodes = append(nodes, sibling)
+		}
+	}
+	return nodes
+}
+
+// readsLikeParagraph reports whether a <p> next to the article is part of
+// it: long with few links, or a sentence without any
+func readsLikeParagraph(n *html.Node) bool {
+	if n.DataAtom != atom.P {
+		return false
+	}
+	text := articleText(n)
+	length := utf8.RuneCountInString(text)
+	density := linkDensity(n)
+	return (length > 80 && density < 0.25) || (length > 0 && density == 0 && strings.Contains(text, ". "))
+}
+
+// isParagraph reports whether an element holds a paragraph of text: a <p>,
+// <pre> or <td>, or a <div> with nothing but text and inline elements
+func isParagraph(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.P, atom.Pre, atom.Td, atom.Blockquote:
+		return true
+	case atom.Div:
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type == html.ElementNode && articleBlocks[child.DataAtom] {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// initialScore starts the score of an element from its tag and its class
+// names and id
+func initialScore(n *html.Node) float64 {
+	var score float64
+	switch n.DataAtom {
+	case atom.Article:
+		score = 10
+	case atom.Div, atom.Main:
+		score = 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		score = 3
+	case atom.Address, atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li:
+		score = -3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score = -5
+	}
+	for _, name := range []string{attribute(n, "class"), attribute(n, "id")} {
+		if name == "" {
+			continue
+		}
+		if negativeClass.MatchString(name) {
+			score -= 25
+		}
+		if positiveClass.MatchString(name) {
+			score += 25
+		}
+	}
+	return score
+}
+
+// linkDensity returns the share of an element's text that is link text
+func linkDensity(n *html.Node) float64 {
+	length := utf8.RuneCountInString(articleText(n))
+	if length == 0 {
+		return 0
+	}
+	var links int
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.A {
+			links += utf8.RuneCountInString(articleText(n))
+			return
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return float64(links) / float64(length)
+}
+
+// cleanArticle strips the article down to its markup: lists and tables
+// that are mostly links go, links become absolute, and other attributes
+// are dropped
+func cleanArticle(n *html.Node, pageURL *url.URL) {
+	if n.Type != html.ElementNode {
+		return
+	}
+	var kept []html.Attribute
+	for _, attr := range n.Attr {
+		if attr.Key != "href" || n.DataAtom != atom.A {
+			continue
+		}
+		target, err := pageURL.Parse(attr.Val)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+			continue
+		}
+		attr.Val = target.String()
+		kept = append(kept, attr)
+	}
+	n.Attr = kept
+
+	for child := n.FirstChild; child != nil; {
+		next := child.NextSibling
+		if child.Type == html.ElementNode && isLinkList(child) {
+			n.RemoveChild(child)
+		} else {
+			cleanArticle(child, pageURL)
+		}
+		child = next
+	}
+}
+
+// isLinkList reports whether an element is a short block of mostly links,
+// like "related stories" or share buttons
+func isLinkList(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.Ul, atom.Ol, atom.Div, atom.Section, atom.Table, atom.Header:
+	default:
+		return false
+	}
+	length := utf8.RuneCountInString(articleText(n))
+	return length == 0 || (length < 500 && linkDensity(n) > 0.5)
+}
+
+// findHeading returns the first heading in an element, or the element
+// itself if it is one
+func findHeading(n *html.Node) *html.Node {
+	if n.Type == html.ElementNode && (n.DataAtom == atom.H1 || n.DataAtom == atom.H2) {
+		return n
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if heading := findHeading(child); heading != nil {
+			return heading
+		}
+	}
+	return nil
+}
+
+// articleText returns the text inside a node with its white space collapsed
+func articleText(n *html.Node) string {
+	return strings.Join(strings.Fields(textContent(n)), " ")
+}
@@ -0,0 +1,92 @@
+package ebook
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Terminals and fonts without Unicode get text in plain ASCII: letters lose
+// their accents, and quotes, dashes and other symbols are spelled out with
+// the characters at hand. Anything else becomes a question mark.
+// No-break spaces keep words together until the text is wrapped, and are
+// written as spaces after that.
+
+var asciiReplacer = strings.NewReplacer(
+	// Quotes
+	"‘", "'", "’", "'", "‚", "'", "‛", "'", "′", "'",
+	"“", "\"", "”", "\"", "„", "\"", "‟", "\"", "″", "\"",
+	"«", "<<", "»", ">>", "‹", "<", "›", ">",
+
+	// Dashes, spaces and invisible characters
+	"‐", "-", "‑", "-", "‒", "-", "–", "-", "—", "--", "―", "--", "−", "-",
+	"\u2002", " ", "\u2003", " ", "\u2009", " ", "\u202f", " ",
+	"\u00ad", "", "\u200b", "", "\u200c", "", "\u200d", "", "\ufeff", "",
+
+	// Punctuation and symbols
+	"…", "...", "•", "*", "·", ".", "†", "+", "‡", "++", "§", "S", "¶", "P",
+	"¡", "!", "¿", "?", "×", "x", "÷", "/", "±", "+/-", "≠", "!=", "≤", "<=", "≥", ">=",
+	"→", "->", "←", "<-", "⇒", "=>", "°", " deg", "©", "(c)", "®", "(R)", "™", "(TM)",
+	"€", "EUR", "£", "GBP", "¥", "JPY", "¢", "c",
+	"½", "1/2", "¼", "1/4", "¾", "3/4",
+
+	// Letters that don't decompose into a letter and accents
+	"ß", "ss", "æ", "ae", "Æ", "AE", "œ", "oe", "Œ", "OE", "ø", "o", "Ø", "O",
+	"ł", "l", "Ł", "L", "đ", "d", "Đ", "D", "ð", "d", "Ð", "D", "þ", "th", "Þ", "Th",
+	"ı", "i", "ĸ", "k", "ŋ", "ng", "Ŋ", "NG",
+)
+
+// Transliterate replaces the characters of text that are not ASCII with
+// ASCII look-alikes, except for no-break spaces
+func Transliterate(text string) string {
+	if isASCII(text) {
+		return text
+	}
+
+	// Decomposing splits accents off letters and ligatures such as ﬁ into
+	// their letters. It would turn no-break spaces into spaces as well.
+	text = strings.ReplaceAll(asciiReplacer.Replace(text), string(nbsp), string(nbspPlaceholder))
+	var b strings.Builder
+	for _, c := range norm.NFKD.String(text) {
+		switch {
+		case c < utf8.RuneSelf:
+			b.WriteRune(c)
+		case c == nbspPlaceholder:
+			b.WriteRune(nbsp)
+		case unicode.Is(unicode.Mn, c):
+			// Accents are dropped
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// isASCII reports whether text has only ASCII characters
+func isASCII(text string) bool {
+	for i := 0; i < len(text); i++ {
+		if text[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// glyph returns a character the renderer draws, or its stand-in in ASCII
+func (r *Renderer) glyph(fancy, plain string) string {
+	if r.options.ASCII {
+		return plain
+	}
+	return fancy
+}
+
+// quoteBorder returns the border drawn left of block quotes
+func (r *Renderer) quoteBorder() lipgloss.Border {
+	if r.options.ASCII {
+		return lipgloss.Border{Left: "|"}
+	}
+	return lipgloss.ThickBorder()
+}
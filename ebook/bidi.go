@@ -0,0 +1,222 @@
+package ebook
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"golang.org/x/text/unicode/bidi"
+)
+
+// Terminals draw characters left to right in the order they are written,
+// so Arabic and Hebrew text is put into display order by the renderer. This
+// is a simplified version of the Unicode bidirectional algorithm: no
+// explicit embeddings, and numbers are always read left to right.
+
+// Languages written right to left
+var rtlLanguages = []string{"ar", "he", "iw", "fa", "ur", "yi", "ps", "sd", "ug", "dv", "ckb"}
+
+// Brackets are mirrored in right-to-left text
+var mirroredRunes = map[rune]rune{
+	'(': ')', ')': '(', '[': ']', ']': '[', '{': '}', '}': '{',
+	'<': '>', '>': '<', '«': '»', '»': '«', '‹': '›', '›': '‹',
+}
+
+// isRTLLanguage reports whether a language code such as "he" or "ar-EG" is
+// written right to left
+func isRTLLanguage(language string) bool {
+	primary, _, _ := strings.Cut(strings.ToLower(language), "-")
+	return slices.Contains(rtlLanguages, primary)
+}
+
+// direction is the resolved direction of a character
+type direction int
+
+const (
+	dirNeutral direction = iota
+	dirLTR
+	dirRTL
+	dirNumber
+)
+
+func runeDirection(r rune) direction {
+	if r == nbspPlaceholder {
+		return dirNeutral
+	}
+	properties, _ := bidi.LookupRune(r)
+	switch properties.Class() {
+	case bidi.L:
+		return dirLTR
+	case bidi.R, bidi.AL:
+		return dirRTL
+	case bidi.EN, bidi.AN:
+		return dirNumber
+	}
+	return dirNeutral
+}
+
+// hasRTL reports whether text contains right-to-left characters
+func hasRTL(text string) bool {
+	return strings.ContainsFunc(text, func(r rune) bool { return runeDirection(r) == dirRTL })
+}
+
+// startsRTL reports whether the first strong character of text is right to
+// left, which decides the direction of dir="auto" elements
+func startsRTL(text string) bool {
+	for _, r := range text {
+		switch runeDirection(r) {
+		case dirLTR:
+			return false
+		case dirRTL:
+			return true
+		}
+	}
+	return false
+}
+
+// visualOrder reorders one line from reading order to display order
+func visualOrder(line string, rtl bool) string {
+	runes := []rune(line)
+	order, levels := displayOrder(runes, rtl)
+	visual := make([]rune, len(runes))
+	for i, j := range order {
+		visual[i] = mirror(runes[j], levels[j])
+	}
+	return string(visual)
+}
+
+// displayOrder returns the position in reading order of each character of
+// a line in display order, and the level of each character
+func displayOrder(runes []rune, rtl bool) ([]int, []int) {
+	levels := bidiLevels(runes, rtl)
+	order := make([]int, len(runes))
+	for i := range order {
+		order[i] = i
+	}
+
+	// Reverse runs from the highest level down to the lowest odd level
+	highest, lowestOdd := 0, 2
+	for _, level := range levels {
+		highest = max(highest, level)
+		if level%2 == 1 {
+			lowestOdd = min(lowestOdd, level)
+		}
+	}
+	for level := highest; level >= lowestOdd && level > 0; level-- {
+		for i := 0; i < len(order); {
+			if levels[order[i]] < level {
+				i++
+				continue
+			}
+			j := i
+			for j < len(order) && levels[order[j]] >= level {
+				j++
+			}
+			slices.Reverse(order[i:j])
+			i = j
+		}
+	}
+	return order, levels
+}
+
+// mirror returns the mirror image of a bracket shown right to left
+func mirror(r rune, level int) rune {
+	if mirrored, ok := mirroredRunes[r]; ok && level%2 == 1 {
+		return mirrored
+	}
+	return r
+}
+
+// bidiLevels resolves the embedding level of each character: even levels
+// are shown left to right, odd levels right to left
+func bidiLevels(runes []rune, rtl bool) []int {
+	base := dirLTR
+	if rtl {
+		base = dirRTL
+	}
+
+	directions := make([]direction, len(runes))
+	last := base
+	for i, r := range runes {
+		directions[i] = runeDirection(r)
+		switch directions[i] {
+		case dirLTR, dirRTL:
+			last = directions[i]
+		case dirNumber:
+			// Numbers after left-to-right text are part of it
+			if last == dirLTR {
+				directions[i] = dirLTR
+			}
+		}
+	}
+
+	// Neutrals between two runs of the same direction take that direction,
+	// others the direction of the paragraph. Numbers count as right to left.
+	strong := func(d direction) direction {
+		if d == dirNumber {
+			return dirRTL
+		}
+		return d
+	}
+	for i := 0; i < len(runes); {
+		if directions[i] != dirNeutral {
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && directions[j] == dirNeutral {
+			j++
+		}
+		before, after := base, base
+		if i > 0 {
+			before = strong(directions[i-1])
+		}
+		if j < len(runes) {
+			after = strong(directions[j])
+		}
+		resolved := base
+		if before == after {
+			resolved = before
+		}
+		for k := i; k < j; k++ {
+			directions[k] = resolved
+		}
+		i = j
+	}
+
+	levels := make([]int, len(runes))
+	for i, d := range directions {
+		switch {
+		case !rtl && d == dirRTL:
+			levels[i] = 1
+		case !rtl && d == dirNumber:
+			levels[i] = 2
+		case rtl && d == dirRTL:
+			levels[i] = 1
+		case rtl:
+			levels[i] = 2
+		}
+	}
+	return levels
+}
+
+// orderLines puts wrapped lines into display order, aligning them to the
+// right edge in right-to-left paragraphs
+func orderLines(text string, rtl bool, width int) string {
+	if !rtl && !hasRTL(text) {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if !rtl {
+			lines[i] = visualOrder(line, false)
+			continue
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			line = visualOrder(line, true)
+			line = strings.Repeat(" ", max(0, width-ansi.StringWidth(line))) + line
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
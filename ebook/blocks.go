@@ -0,0 +1,317 @@
+package ebook
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// HTML is rendered in two steps. Parsing walks the HTML and collects a
+// list of blocks: paragraphs of inline runs, and the line breaks, bullets,
+// rules and placeholders between them. Layout then wraps each block at the
+// width and styles it with the theme. Only layout depends on the width and
+// the theme, so a parsed Document is laid out again when the terminal is
+// resized or the theme changes, without going back to the HTML.
+// Headings, anchors and links are kept as places in the blocks until
+// layout turns them into byte offsets of the text.
+
+// blockKind is what a block holds and how it is laid out
+type blockKind int
+
+const (
+	textBlock         blockKind = iota // Line breaks, bullets and indentation, written as they are
+	paragraphBlock                     // Inline runs, wrapped at the width
+	ruleBlock                          // A horizontal rule as wide as the text
+	placeholderBlock                   // An image that can't be shown
+	largeHeadingBlock                  // A heading drawn large, see largetext.go
+)
+
+// block is a part of a document that is laid out on its own
+type block struct {
+	kind     blockKind
+	text     string      // Text of a text block, label of a placeholder or title of a large heading
+	runs     []inlineRun // Runs of a paragraph
+	children *blockList  // Content of a large heading
+}
+
+// position is a place in a block list: where a run of a paragraph starts,
+// or where the run before it ends. For other blocks it is where the block
+// starts, and one past the last block is the end of the list.
+type position struct {
+	block int
+	run   int
+	after bool // The end of the run before, rather than the start of this one
+}
+
+// linkPosition is where a hyperlink starts and ends
+type linkPosition struct {
+	href       string
+	start, end position
+}
+
+// blockList is parsed HTML, and the paragraph being collected while it is
+// parsed
+type blockList struct {
+	blocks   []block
+	headings []Heading           // Lines are filled in by layout
+	starts   []position          // Where the headings start
+	anchors  map[string]position // Where element ids start
+	links    []linkPosition
+
+	runs []inlineRun // The paragraph being collected, see inline.go
+	tail string      // The last characters written, to tell where lines start
+}
+
+// newBlockList creates an empty block list
+func newBlockList() *blockList {
+	return &blockList{anchors: make(map[string]position)}
+}
+
+// add appends a block to the list
+func (l *blockList) add(b block) {
+	l.blocks = append(l.blocks, b)
+	switch b.kind {
+	case textBlock:
+		l.tail += b.text
+		if len(l.tail) > 2 {
+			l.tail = l.tail[len(l.tail)-2:]
+		}
+	case paragraphBlock:
+		// Empty paragraphs write nothing
+		ctx := b.runs[0].ctx
+		if ctx.inPre || ctx.inHeading > 0 || slices.ContainsFunc(b.runs, hasText) {
+			l.tail = "."
+		}
+	default:
+		l.tail = "."
+	}
+}
+
+// write adds text that is written as it is
+func (l *blockList) write(text string) {
+	l.add(block{kind: textBlock, text: text})
+}
+
+// atLineStart reports whether the next text starts a new line
+func (l *blockList) atLineStart() bool {
+	return l.tail == "" || strings.HasSuffix(l.tail, "\n")
+}
+
+// blankLine ends the text with an empty line, unless it already does
+func (l *blockList) blankLine() {
+	if l.tail == "" {
+		return
+	}
+	for !strings.HasSuffix(l.tail, "\n\n") {
+		l.write("\n")
+	}
+}
+
+// position returns where the next text starts
+func (l *blockList) position() position {
+	return position{block: len(l.blocks), run: len(l.runs)}
+}
+
+// positionAfter returns where the text so far ends
+func (l *blockList) positionAfter() position {
+	return position{block: len(l.blocks), run: len(l.runs), after: true}
+}
+
+// hasText reports whether a run has more than spaces
+func hasText(run inlineRun) bool {
+	return strings.TrimSpace(run.text) != ""
+}
+
+// laidOut is where the headings, anchors and links of a block list ended
+// up in the text
+type laidOut struct {
+	starts  []int          // Byte offsets of the headings
+	anchors map[string]int // Byte offsets of element ids
+	links   []textSpan
+}
+
+// layout writes a block list at the renderer's width and returns the byte
+// offsets of its positions
+func (r *Renderer) layout(l *blockList, out *strings.Builder) laidOut {
+	result := laidOut{anchors: make(map[string]int)}
+	blockStarts := make([]int, len(l.blocks)+1)
+	runStarts, runEnds := make([][]int, len(l.blocks)), make([][]int, len(l.blocks))
+
+	for i, b := range l.blocks {
+		blockStarts[i] = out.Len()
+		switch b.kind {
+		case textBlock:
+			out.WriteString(b.text)
+		case paragraphBlock:
+			runStarts[i], runEnds[i] = r.writeBlock(out, b.runs)
+		case ruleBlock:
+			style := lipgloss.NewStyle().Foreground(lipgloss.Color(r.theme.MutedTextColor))
+			out.WriteString(style.Render(strings.Repeat(r.glyph("─", "-"), min(r.width, 80))))
+		case placeholderBlock:
+			r.writePlaceholder(out, b.text)
+		case largeHeadingBlock:
+			heading := r.writeLargeHeading(out, b)
+			maps.Copy(result.anchors, heading.anchors)
+			result.links = append(result.links, heading.links...)
+		}
+	}
+	blockStarts[len(l.blocks)] = out.Len()
+
+	offset := func(p position) int {
+		if p.block >= len(l.blocks) || l.blocks[p.block].kind != paragraphBlock {
+			return blockStarts[p.block]
+		}
+		starts, ends := runStarts[p.block], runEnds[p.block]
+		if p.after {
+			for i := min(p.run, len(ends)) - 1; i >= 0; i-- {
+				if ends[i] >= 0 {
+					return ends[i]
+				}
+			}
+			return blockStarts[p.block]
+		}
+		for i := p.run; i < len(starts); i++ {
+			if starts[i] >= 0 {
+				return starts[i]
+			}
+		}
+		return blockStarts[p.block+1]
+	}
+
+	for _, start := range l.starts {
+		result.starts = append(result.starts, offset(start))
+	}
+	for id, p := range l.anchors {
+		result.anchors[id] = offset(p)
+	}
+	for _, link := range l.links {
+		result.links = append(result.links, textSpan{href: link.href, start: offset(link.start), end: offset(link.end)})
+	}
+	slices.SortStableFunc(result.links, func(a, b textSpan) int { return a.start - b.start })
+	return result
+}
+
+// layoutText lays out a parsed chapter and finds the lines of its headings
+// and anchors, and the columns of its links
+func (r *Renderer) layoutText(l *blockList) RenderResult {
+	var result strings.Builder
+	placed := r.layout(l, &result)
+
+	text, leading := trimOutput(result.String())
+
+	// Convert byte offsets in the raw output to positions in the trimmed text
+	position := func(offset int) int {
+		return min(max(offset-leading, 0), len(text))
+	}
+
+	// Anchors and headings are recorded before the block's leading
+	// newlines, so they are on the first line after them
+	lineAt := func(offset int) int {
+		start := position(offset)
+		for start < len(text) && text[start] == '\n' {
+			start++
+		}
+		return strings.Count(text[:start], "\n")
+	}
+
+	anchors := make(map[string]int, len(placed.anchors))
+	for id, offset := range placed.anchors {
+		anchors[id] = lineAt(offset)
+	}
+
+	// The parsed headings are shared by every layout of the document
+	headings := slices.Clone(l.headings)
+	for i, offset := range placed.starts {
+		headings[i].Line = lineAt(offset)
+	}
+
+	var links []Link
+	for _, span := range placed.links {
+		links = append(links, linkLines(text, span.href, position(span.start), position(span.end))...)
+	}
+
+	return RenderResult{
+		Text:     text,
+		Headings: headings,
+		Links:    links,
+		Anchors:  anchors,
+	}
+}
+
+// Document is a chapter parsed for layout. It can be laid out any number
+// of times, from several goroutines, at any width and with any theme.
+type Document struct {
+	options RenderOptions
+	source  string     // The HTML or plain text of the chapter
+	html    bool       // The source is HTML
+	body    *blockList // The parsed HTML, or nil if it could not be parsed
+}
+
+// ParseDocument parses HTML for layout
+func ParseDocument(htmlContent string, options RenderOptions) *Document {
+	renderer := NewRendererWithOptions(nil, 0, options)
+	return &Document{
+		options: options,
+		source:  htmlContent,
+		html:    true,
+		body:    renderer.parse(htmlContent),
+	}
+}
+
+// Layout lays out the document at a width with a theme, as the reader
+// shows it: plain text is wrapped, and lines are drawn at double width
+// with large text everywhere. A width of 0 uses 80 columns and a nil theme
+// the cozy-dark theme.
+func (d *Document) Layout(theme *config.Theme, width int) RenderResult {
+	if width <= 0 {
+		width = 80
+	}
+	// Lines drawn at double width hold half as much
+	if d.options.DoubleWidth() {
+		width = max(width/2, 10)
+	}
+
+	var result RenderResult
+	switch {
+	case !d.html && d.options.spaced():
+		result = RenderResult{Text: wrapSpaced(spaceText(d.source, d.options), width)}
+	case !d.html:
+		result = RenderResult{Text: WrapText(d.source, width)}
+	default:
+		if theme == nil {
+			defaultTheme := config.CozyDark
+			theme = &defaultTheme
+		}
+		result = d.render(theme, width)
+	}
+
+	// No-break spaces have done their job once lines are wrapped
+	if !d.html && d.options.ASCII {
+		result.Text = strings.ReplaceAll(result.Text, string(nbsp), " ")
+	}
+
+	if d.options.DoubleWidth() {
+		result.Text = widenLines(result.Text)
+	}
+	return result
+}
+
+// render lays out parsed HTML, falling back to its plain text when there
+// is nothing to show
+func (d *Document) render(theme *config.Theme, width int) RenderResult {
+	if d.body != nil {
+		renderer := NewRendererWithOptions(theme, width, d.options)
+		if result := renderer.layoutText(d.body); strings.TrimSpace(result.Text) != "" {
+			return result
+		}
+	}
+
+	text := htmlToText(d.source)
+	if d.options.ASCII {
+		text = Transliterate(text)
+	}
+	return RenderResult{Text: text}
+}
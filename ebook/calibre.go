@@ -0,0 +1,268 @@
+package ebook
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+// CalibreDatabase is the file name of a Calibre library's metadata database
+const CalibreDatabase = "metadata.db"
+
+// calibreBook is the metadata Calibre stores for one book
+type calibreBook struct {
+	title       string
+	authors     []string
+	series      string
+	seriesIndex float64
+	tags        []string
+	rating      int // 0-10, two points per star
+	formats     []string
+}
+
+// HasCalibreLibrary reports whether dir is the root of a Calibre library
+func HasCalibreLibrary(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, CalibreDatabase))
+	return err == nil && !info.IsDir()
+}
+
+// loadCalibreLibrary reads book metadata from a Calibre metadata.db,
+// keyed by the absolute path of each book file
+func loadCalibreLibrary(dir string) (map[string]calibreBook, error) {
+	dbPath := filepath.Join(dir, CalibreDatabase)
+
+	// Open read-only so a running Calibre instance is never disturbed
+	db, err := sql.Open("sqlite", "file:"+dbPath+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Calibre database: %w", err)
+	}
+	defer db.Close()
+
+	books := make(map[int64]*calibreBook)
+	bookPaths := make(map[int64]string)
+
+	rows, err := db.Query(`SELECT id, title, path, series_index FROM books`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Calibre books: %w", err)
+	}
+	for rows.Next() {
+		var id int64
+		var title, path string
+		var seriesIndex sql.NullFloat64
+		if err := rows.Scan(&id, &title, &path, &seriesIndex); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to read Calibre books: %w", err)
+		}
+		books[id] = &calibreBook{title: title, seriesIndex: seriesIndex.Float64}
+		bookPaths[id] = path
+	}
+	rows.Close()
+
+	// Each link query returns (book id, value) pairs
+	links := []struct {
+		query string
+		apply func(book *calibreBook, value string)
+	}{
+		{
+			`SELECT l.book, a.name FROM books_authors_link l JOIN authors a ON a.id = l.author ORDER BY l.id`,
+			func(book *calibreBook, value string) { book.authors = append(book.authors, value) },
+		},
+		{
+			`SELECT l.book, s.name FROM books_series_link l JOIN series s ON s.id = l.series`,
+			func(book *calibreBook, value string) { book.series = value },
+		},
+		{
+			`SELECT l.book, t.name FROM books_tags_link l JOIN tags t ON t.id = l.tag ORDER BY t.name`,
+			func(book *calibreBook, value string) { book.tags = append(book.tags, value) },
+		},
+		{
+			`SELECT l.book, r.rating FROM books_ratings_link l JOIN ratings r ON r.id = l.rating`,
+			func(book *calibreBook, value string) { book.rating, _ = strconv.Atoi(value) },
+		},
+		{
+			`SELECT book, name || '.' || lower(format) FROM data`,
+			func(book *calibreBook, value string) { book.formats = append(book.formats, value) },
+		},
+	}
+
+	for _, link := range links {
+		rows, err := db.Query(link.query)
+		if err != nil {
+			// Older databases may lack some tables; use what is there
+			continue
+		}
+		for rows.Next() {
+			var id int64
+			var value sql.NullString
+			if err := rows.Scan(&id, &value); err != nil || !value.Valid {
+				continue
+			}
+			if book, ok := books[id]; ok {
+				link.apply(book, value.String)
+			}
+		}
+		rows.Close()
+	}
+
+	result := make(map[string]calibreBook)
+	for id, book := range books {
+		for _, format := range book.formats {
+			path := filepath.Join(dir, filepath.FromSlash(bookPaths[id]), format)
+			result[path] = *book
+		}
+	}
+
+	return result, nil
+}
+
+// bookInfo converts Calibre metadata into library display information
+func (c calibreBook) bookInfo(path string) BookInfo {
+	info := BookInfo{
+		Path:     path,
+		Title:    c.title,
+		Tags:     c.tags,
+		Metadata: make(map[string]string),
+		Calibre:  true,
+	}
+	if info.Tags == nil {
+		info.Tags = []string{}
+	}
+
+	for _, author := range c.authors {
+		info.Contributors = append(info.Contributors, Contributor{Name: author, Role: RoleAuthor})
+	}
+	if len(c.authors) > 0 {
+		info.Author = c.authors[0]
+	}
+
+	if c.series != "" {
+		info.Metadata["series"] = c.series
+		info.Metadata["series_index"] = strings.TrimSuffix(strconv.FormatFloat(c.seriesIndex, 'f', -1, 64), ".0")
+	}
+	if c.rating > 0 {
+		info.Metadata["rating"] = strconv.Itoa(c.rating / 2)
+	}
+
+	return info
+}
+
+// Calibre has no reading position of its own; plugins such as KOReader
+// sync or Kobo Utilities keep it in custom columns. A number column whose
+// label mentions progress or percent is taken as the percentage read, and a
+// yes/no column labeled read or finished as the finished status.
+
+// LoadCalibreStates reads the reading positions kept in the custom columns
+// of a Calibre library
+func LoadCalibreStates(dir string) ([]ReadingState, error) {
+	dbPath := filepath.Join(dir, CalibreDatabase)
+	db, err := sql.Open("sqlite", "file:"+dbPath+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Calibre database: %w", err)
+	}
+	defer db.Close()
+
+	percentColumn, finishedColumn, err := calibreProgressColumns(db)
+	if err != nil {
+		return nil, err
+	}
+	if percentColumn == 0 && finishedColumn == 0 {
+		return nil, fmt.Errorf("no reading progress columns in the Calibre library")
+	}
+
+	states := make(map[int64]*ReadingState)
+	state := func(id int64) *ReadingState {
+		if states[id] == nil {
+			states[id] = &ReadingState{}
+		}
+		return states[id]
+	}
+	if percentColumn != 0 {
+		rows, err := db.Query(fmt.Sprintf(`SELECT book, value FROM custom_column_%d`, percentColumn))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Calibre progress: %w", err)
+		}
+		for rows.Next() {
+			var id int64
+			var value sql.NullFloat64
+			if err := rows.Scan(&id, &value); err != nil || !value.Valid {
+				continue
+			}
+			// Some plugins store a fraction rather than a percentage
+			percent := value.Float64
+			if percent > 0 && percent <= 1 && percent != float64(int(percent)) {
+				percent *= 100
+			}
+			state(id).Percent = clampPercent(percent)
+		}
+		rows.Close()
+	}
+	if finishedColumn != 0 {
+		rows, err := db.Query(fmt.Sprintf(`SELECT book, value FROM custom_column_%d`, finishedColumn))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Calibre progress: %w", err)
+		}
+		for rows.Next() {
+			var id int64
+			var value sql.NullBool
+			if err := rows.Scan(&id, &value); err != nil || !value.Valid {
+				continue
+			}
+			state(id).Finished = value.Bool
+		}
+		rows.Close()
+	}
+
+	rows, err := db.Query(`SELECT b.id, b.path, d.name || '.' || lower(d.format) FROM books b JOIN data d ON d.book = b.id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Calibre books: %w", err)
+	}
+	defer rows.Close()
+	var result []ReadingState
+	for rows.Next() {
+		var id int64
+		var path, file string
+		if err := rows.Scan(&id, &path, &file); err != nil {
+			return nil, fmt.Errorf("failed to read Calibre books: %w", err)
+		}
+		if s, ok := states[id]; ok && (s.Percent > 0 || s.Finished) {
+			result = append(result, ReadingState{
+				Path:     filepath.Join(dir, filepath.FromSlash(path), file),
+				Percent:  s.Percent,
+				Finished: s.Finished,
+			})
+		}
+	}
+	return result, nil
+}
+
+// calibreProgressColumns finds the custom columns holding the percentage
+// read and the finished status, 0 for those that are missing
+func calibreProgressColumns(db *sql.DB) (percent, finished int, err error) {
+	rows, err := db.Query(`SELECT id, label, datatype FROM custom_columns`)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read Calibre columns: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		var label, datatype string
+		if err := rows.Scan(&id, &label, &datatype); err != nil {
+			return 0, 0, fmt.Errorf("failed to read Calibre columns: %w", err)
+		}
+		label = strings.ToLower(label)
+		switch {
+		case (datatype == "int" || datatype == "float") && percent == 0 &&
+			(strings.Contains(label, "progress") || strings.Contains(label, "percent")):
+			percent = id
+		case datatype == "bool" && finished == 0 &&
+			(label == "read" || strings.Contains(label, "finished")):
+			finished = id
+		}
+	}
+	return percent, finished, nil
+}
@@ -0,0 +1,89 @@
+package ebook
+
+import (
+	"fmt"
+	"unicode"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// ChapterOptions controls how a chapter is rendered. Colors follow the
+// terminal lipgloss writes to, so output to a pipe or file is plain text.
+type ChapterOptions struct {
+	Width int           // Columns to wrap at; 0 uses 80
+	Theme *config.Theme // nil uses the cozy-dark theme
+	RenderOptions
+}
+
+// RenderChapter renders a chapter of the book (zero-based) as styled,
+// wrapped text. The book's language and stylesheet are used unless the
+// options set others.
+func (b *Book) RenderChapter(index int, options ChapterOptions) (RenderResult, error) {
+	chapter := b.GetChapter(index)
+	if chapter == nil {
+		return RenderResult{}, fmt.Errorf("chapter must be between 0 and %d", b.ChapterCount()-1)
+	}
+	if options.Language == "" {
+		options.Language = b.Metadata["language"]
+	}
+	if options.Stylesheet == nil {
+		options.Stylesheet = b.Styles
+	}
+	return chapter.Render(options), nil
+}
+
+// Render renders the chapter: HTML with the theme, plain text wrapped
+func (c *Chapter) Render(options ChapterOptions) RenderResult {
+	return c.Parse(options.RenderOptions).Layout(options.Theme, options.Width)
+}
+
+// Parse parses the chapter for layout. A chapter parsed once is laid out
+// again at another width or with another theme without parsing it again.
+func (c *Chapter) Parse(options RenderOptions) *Document {
+	if c.HTML {
+		return ParseDocument(c.Text(), options)
+	}
+
+	text := c.Text()
+	if options.ASCII {
+		text = Transliterate(text)
+	}
+	return &Document{options: options, source: text}
+}
+
+// Reading positions are kept as the number of letters and digits before a
+// line. Unlike a line number, this stays the same when the chapter is
+// re-wrapped for another width, margin or justification.
+
+// TextOffsets returns the number of letters and digits before each
+// rendered line, and the total after the last one
+func TextOffsets(lines []string) []int {
+	offsets := make([]int, len(lines)+1)
+	for i, line := range lines {
+		offsets[i+1] = offsets[i] + countLetters(line)
+	}
+	return offsets
+}
+
+// LineAtTextOffset returns the line containing a text position, given the
+// offsets from TextOffsets, or the last line if it is past the end
+func LineAtTextOffset(offsets []int, offset int) int {
+	for line := 0; line+1 < len(offsets); line++ {
+		if offsets[line+1] > offset {
+			return line
+		}
+	}
+	return max(len(offsets)-2, 0)
+}
+
+// countLetters returns the number of letters and digits in a rendered line
+func countLetters(line string) int {
+	count := 0
+	for _, r := range ansi.Strip(line) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,135 @@
+package ebook
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/reflow/wordwrap"
+	"golang.org/x/net/html"
+)
+
+// Chinese and Japanese are written without spaces, so lines can break
+// between any two characters, except before closing punctuation and small
+// kana or after opening punctuation (kinsoku shori).
+const (
+	cjkNoBreakBefore = "、。，．：；？！）」』】〕〉》｝〙〗〟’”ー…‥・ゝゞヽヾぁぃぅぇぉっゃゅょゎァィゥェォッャュョヮヵヶ,.:;?!)]}"
+	cjkNoBreakAfter  = "（「『【〔〈《｛〘〖〝‘“([{"
+)
+
+// WrapText wraps text to a width like wordwrap, also breaking lines
+// between Chinese and Japanese characters
+func WrapText(text string, width int) string {
+	if !strings.ContainsFunc(text, isCJK) {
+		return wordwrap.String(text, width)
+	}
+
+	paragraphs := strings.Split(text, "\n")
+	for i, paragraph := range paragraphs {
+		paragraphs[i] = wrapCJK(paragraph, width)
+	}
+	return strings.Join(paragraphs, "\n")
+}
+
+// isCJK reports whether a character is Chinese or Japanese, including
+// full-width punctuation
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) ||
+		(r >= 0x3000 && r <= 0x303f) || (r >= 0xff00 && r <= 0xffef)
+}
+
+// isBreakSpace reports whether a line may break at a space character. The
+// ideographic space indents paragraphs and is kept like a character.
+func isBreakSpace(r rune) bool {
+	return unicode.IsSpace(r) && r != '　' && r != nbsp
+}
+
+// wrapCJK wraps one line of text, breaking at spaces and between CJK
+// characters where the punctuation rules allow it
+func wrapCJK(text string, width int) string {
+	var lines []string
+	var line strings.Builder
+	lineWidth, pendingSpace := 0, ""
+
+	for _, unit := range cjkUnits(text) {
+		if isBreakSpace([]rune(unit)[0]) {
+			if lineWidth > 0 {
+				pendingSpace = unit
+			}
+			continue
+		}
+
+		unitWidth := ansi.StringWidth(unit)
+		if lineWidth > 0 && lineWidth+len(pendingSpace)+unitWidth > width {
+			lines = append(lines, line.String())
+			line.Reset()
+			lineWidth, pendingSpace = 0, ""
+		}
+		line.WriteString(pendingSpace + unit)
+		lineWidth += len(pendingSpace) + unitWidth
+		pendingSpace = ""
+	}
+	return strings.Join(append(lines, line.String()), "\n")
+}
+
+// cjkUnits splits text into pieces that can't be broken: words, runs of
+// spaces and single CJK characters with the punctuation attached to them
+func cjkUnits(text string) []string {
+	var units []string
+	var unit []rune
+	for _, c := range text {
+		if len(unit) > 0 && canBreakBetween(unit[len(unit)-1], c) {
+			units = append(units, string(unit))
+			unit = unit[:0]
+		}
+		unit = append(unit, c)
+	}
+	if len(unit) > 0 {
+		units = append(units, string(unit))
+	}
+	return units
+}
+
+// canBreakBetween reports whether a line may break between two characters
+func canBreakBetween(previous, next rune) bool {
+	if isBreakSpace(previous) != isBreakSpace(next) {
+		return true
+	}
+	if isBreakSpace(previous) || (!isCJK(previous) && !isCJK(next)) {
+		return false
+	}
+	return !strings.ContainsRune(cjkNoBreakBefore, next) && !strings.ContainsRune(cjkNoBreakAfter, previous)
+}
+
+// rubyText writes ruby annotations, such as furigana, in parentheses after
+// the text they annotate: 漢字(かんじ). <rp> holds the parentheses shown by
+// readers without ruby support and is skipped.
+func rubyText(n *html.Node) string {
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case c.Type == html.ElementNode && (c.Data == "rt" || c.Data == "rtc"):
+			if annotation := strings.TrimSpace(textContent(c)); annotation != "" {
+				b.WriteString("(" + annotation + ")")
+			}
+		case c.Type == html.ElementNode && c.Data == "rp":
+		case c.Type == html.ElementNode && c.Data == "ruby":
+			b.WriteString(rubyText(c))
+		default:
+			b.WriteString(strings.TrimSpace(textContent(c)))
+		}
+	}
+	return b.String()
+}
+
+// textContent returns all text inside a node
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(textContent(c))
+	}
+	return b.String()
+}
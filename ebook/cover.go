@@ -0,0 +1,188 @@
+package ebook
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Thumbnail size in pixels used for the cover cache
+const (
+	thumbnailWidth  = 200
+	thumbnailHeight = 300
+)
+
+// ExtractCover returns the raw cover image of an EPUB file
+func ExtractCover(path string) ([]byte, error) {
+	if strings.ToLower(filepath.Ext(path)) != ".epub" {
+		return nil, fmt.Errorf("covers are only supported for EPUB files")
+	}
+
+	zipReader, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open EPUB file: %w", err)
+	}
+	defer zipReader.Close()
+
+	opfPath, err := findOPFPath(zipReader)
+	if err != nil {
+		return nil, err
+	}
+
+	opf, err := parseOPF(zipReader, opfPath)
+	if err != nil {
+		return nil, err
+	}
+
+	item, ok := findCoverItem(opf)
+	if !ok {
+		return nil, fmt.Errorf("no cover image found")
+	}
+
+	return readFileFromZip(zipReader, resolveHref(opfPath, item.Href))
+}
+
+// findCoverItem locates the cover image in the manifest
+func findCoverItem(opf *opfPackage) (opfItem, bool) {
+	// EPUB 3: manifest item with the cover-image property
+	for _, item := range opf.Manifest.Items {
+		for _, property := range strings.Fields(item.Properties) {
+			if property == "cover-image" {
+				return item, true
+			}
+		}
+	}
+
+	// EPUB 2: <meta name="cover" content="item-id"/>
+	for _, meta := range opf.Metadata.Meta {
+		if meta.Name != "cover" {
+			continue
+		}
+		for _, item := range opf.Manifest.Items {
+			if item.ID == meta.Content && strings.HasPrefix(item.MediaType, "image/") {
+				return item, true
+			}
+		}
+	}
+
+	// Last resort: an image whose id or file name mentions "cover"
+	for _, item := range opf.Manifest.Items {
+		if !strings.HasPrefix(item.MediaType, "image/") {
+			continue
+		}
+		if strings.Contains(strings.ToLower(item.ID), "cover") ||
+			strings.Contains(strings.ToLower(filepath.Base(item.Href)), "cover") {
+			return item, true
+		}
+	}
+
+	return opfItem{}, false
+}
+
+// LoadCoverThumbnail returns a downscaled cover for the book, using
+// cacheDir to avoid re-extracting the image on every call
+func LoadCoverThumbnail(path, cacheDir string) (image.Image, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Key the cache on path and modification time so replaced files are picked up
+	hash := sha1.Sum([]byte(fmt.Sprintf("%s|%d|%d", path, info.Size(), info.ModTime().UnixNano())))
+	cachePath := filepath.Join(cacheDir, hex.EncodeToString(hash[:])+".png")
+
+	if file, err := os.Open(cachePath); err == nil {
+		defer file.Close()
+		if img, err := png.Decode(file); err == nil {
+			return img, nil
+		}
+	}
+
+	data, err := ExtractCover(path)
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode cover image: %w", err)
+	}
+
+	thumbnail := ScaleImage(img, thumbnailWidth, thumbnailHeight)
+
+	// Caching is best effort; a failed write just means re-extracting next time
+	if err := os.MkdirAll(cacheDir, 0755); err == nil {
+		if file, err := os.Create(cachePath); err == nil {
+			png.Encode(file, thumbnail)
+			file.Close()
+		}
+	}
+
+	return thumbnail, nil
+}
+
+// ScaleImage downscales an image to fit within maxWidth x maxHeight,
+// preserving the aspect ratio and averaging the source pixels
+func ScaleImage(img image.Image, maxWidth, maxHeight int) *image.RGBA {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if srcW == 0 || srcH == 0 {
+		return image.NewRGBA(image.Rect(0, 0, 1, 1))
+	}
+
+	scale := math.Min(float64(maxWidth)/float64(srcW), float64(maxHeight)/float64(srcH))
+	if scale > 1 {
+		scale = 1
+	}
+	dstW := max(int(float64(srcW)*scale), 1)
+	dstH := max(int(float64(srcH)*scale), 1)
+
+	return ResizeImage(img, dstW, dstH)
+}
+
+// ResizeImage resizes an image to exactly width x height using a box filter
+func ResizeImage(img image.Image, width, height int) *image.RGBA {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*srcH/height
+		y1 := max(bounds.Min.Y+(y+1)*srcH/height, y0+1)
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*srcW/width
+			x1 := max(bounds.Min.X+(x+1)*srcW/width, x0+1)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					b += uint64(pb)
+					a += uint64(pa)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(b / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,321 @@
+package ebook
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"golang.org/x/net/html"
+)
+
+// Books style their text with CSS. Only the properties that have a
+// terminal equivalent are read: text-align, font-style, font-weight,
+// white-space, display: none and page breaks. Selectors are matched by their last part,
+// so "div.poem p" is treated as "p".
+
+var cssComment = regexp.MustCompile(`(?s)/\*.*?\*/`)
+
+// Stylesheet is the parsed CSS of a book
+type Stylesheet struct {
+	rules []cssRule
+}
+
+// cssRule is one selector of a rule with the hints its declarations give
+type cssRule struct {
+	selector cssSelector
+	hints    styleHints
+}
+
+// cssSelector is a simple selector such as p, .center, p.poem or #title
+type cssSelector struct {
+	tag     string
+	id      string
+	classes []string
+}
+
+// styleHints are the CSS properties the renderer honors. Unset values
+// leave the element as it is.
+type styleHints struct {
+	align       string // "center", "right", "left" (the default layout) or ""
+	italic      *bool
+	bold        *bool
+	hidden      *bool
+	whiteSpace  string
+	breakBefore bool
+	breakAfter  bool
+}
+
+// add appends the rules of a CSS file, which take precedence over earlier
+// rules of the same specificity. Rules it can't read are skipped.
+func (s *Stylesheet) add(css string) {
+	css = cssComment.ReplaceAllString(css, "")
+	for css != "" {
+		open := strings.IndexByte(css, '{')
+		if open < 0 {
+			return
+		}
+		prelude := strings.TrimSpace(css[:open])
+		end := matchingBrace(css, open)
+		body := css[open+1 : end]
+		css = css[min(end+1, len(css)):]
+
+		if strings.HasPrefix(prelude, "@") {
+			// Rules in @media and @supports apply; @font-face and @page
+			// have nothing for the terminal
+			if strings.HasPrefix(prelude, "@media") || strings.HasPrefix(prelude, "@supports") {
+				s.add(body)
+			}
+			continue
+		}
+
+		hints := parseDeclarations(body)
+		if hints == (styleHints{}) {
+			continue
+		}
+		for _, selector := range strings.Split(prelude, ",") {
+			if parsed, ok := parseSelector(selector); ok {
+				s.rules = append(s.rules, cssRule{selector: parsed, hints: hints})
+			}
+		}
+	}
+}
+
+// matchingBrace returns the index of the brace closing the one at open, or
+// the end of css if it isn't closed
+func matchingBrace(css string, open int) int {
+	depth := 0
+	for i := open; i < len(css); i++ {
+		switch css[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(css)
+}
+
+// parseSelector reads the last compound part of a selector
+func parseSelector(selector string) (cssSelector, bool) {
+	fields := strings.FieldsFunc(selector, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '\n' || r == '>' || r == '+' || r == '~'
+	})
+	if len(fields) == 0 {
+		return cssSelector{}, false
+	}
+	last := fields[len(fields)-1]
+	if strings.ContainsAny(last, "[:*") && last != "*" {
+		return cssSelector{}, false // Attribute selectors and pseudo-classes
+	}
+
+	var parsed cssSelector
+	for _, part := range splitSelector(last) {
+		switch part[0] {
+		case '.':
+			parsed.classes = append(parsed.classes, part[1:])
+		case '#':
+			parsed.id = part[1:]
+		default:
+			if part != "*" {
+				parsed.tag = strings.ToLower(part)
+			}
+		}
+	}
+	return parsed, true
+}
+
+// splitSelector splits p.poem#first into p, .poem and #first
+func splitSelector(compound string) []string {
+	var parts []string
+	start := 0
+	for i := 1; i < len(compound); i++ {
+		if compound[i] == '.' || compound[i] == '#' {
+			parts = append(parts, compound[start:i])
+			start = i
+		}
+	}
+	return append(parts, compound[start:])
+}
+
+// parseDeclarations reads the hints in the declarations of a rule or a
+// style attribute
+func parseDeclarations(body string) styleHints {
+	var hints styleHints
+	for _, declaration := range strings.Split(body, ";") {
+		name, value, ok := strings.Cut(declaration, ":")
+		if !ok {
+			continue
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.ToLower(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "!important")))
+
+		switch name {
+		case "text-align":
+			switch value {
+			case "center", "right", "left":
+				hints.align = value
+			case "end":
+				hints.align = "right"
+			case "start", "justify":
+				hints.align = "left"
+			}
+		case "font-style":
+			italic := value == "italic" || value == "oblique"
+			hints.italic = &italic
+		case "font-weight":
+			bold := value == "bold" || value == "bolder"
+			if weight, err := strconv.Atoi(value); err == nil {
+				bold = weight >= 600
+			}
+			hints.bold = &bold
+		case "white-space":
+			hints.whiteSpace = value
+		case "display":
+			hidden := value == "none"
+			hints.hidden = &hidden
+		case "page-break-before", "break-before":
+			hints.breakBefore = isPageBreak(value)
+		case "page-break-after", "break-after":
+			hints.breakAfter = isPageBreak(value)
+		}
+	}
+	return hints
+}
+
+func isPageBreak(value string) bool {
+	switch value {
+	case "always", "page", "left", "right", "recto", "verso":
+		return true
+	}
+	return false
+}
+
+// matches reports whether an element matches a selector
+func (s cssSelector) matches(n *html.Node) bool {
+	if s.tag != "" && s.tag != n.Data {
+		return false
+	}
+	if s.id != "" && s.id != attribute(n, "id") {
+		return false
+	}
+	classes := strings.Fields(attribute(n, "class"))
+	for _, class := range s.classes {
+		found := false
+		for _, c := range classes {
+			if c == class {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// specificity orders selectors as CSS does: ids, then classes, then tags
+func (s cssSelector) specificity() int {
+	specificity := len(s.classes) * 10
+	if s.id != "" {
+		specificity += 100
+	}
+	if s.tag != "" {
+		specificity++
+	}
+	return specificity
+}
+
+// styleOf returns the hints for an element from the matching rules and
+// its style attribute
+func (s *Stylesheet) styleOf(n *html.Node) styleHints {
+	var matched []cssRule
+	if s != nil {
+		for _, rule := range s.rules {
+			if rule.selector.matches(n) {
+				matched = append(matched, rule)
+			}
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].selector.specificity() < matched[j].selector.specificity()
+	})
+
+	var hints styleHints
+	for _, rule := range matched {
+		hints.merge(rule.hints)
+	}
+	if style := attribute(n, "style"); style != "" {
+		hints.merge(parseDeclarations(style))
+	}
+	return hints
+}
+
+// merge applies the hints set in other over these
+func (h *styleHints) merge(other styleHints) {
+	if other.align != "" {
+		h.align = other.align
+	}
+	if other.italic != nil {
+		h.italic = other.italic
+	}
+	if other.bold != nil {
+		h.bold = other.bold
+	}
+	if other.hidden != nil {
+		h.hidden = other.hidden
+	}
+	if other.whiteSpace != "" {
+		h.whiteSpace = other.whiteSpace
+	}
+	h.breakBefore = h.breakBefore || other.breakBefore
+	h.breakAfter = h.breakAfter || other.breakAfter
+}
+
+// documentStyles combines the book's stylesheet with the <style> elements
+// of a chapter
+func (r *Renderer) documentStyles(doc *html.Node) *Stylesheet {
+	sheet := &Stylesheet{}
+	if r.options.Stylesheet != nil {
+		sheet.rules = slices.Clone(r.options.Stylesheet.rules)
+	}
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "style" {
+			sheet.add(textContent(n))
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return sheet
+}
+
+// alignLines centers or right-aligns wrapped lines
+func alignLines(text, align string, width int) string {
+	if align != "center" && align != "right" {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			lines[i] = line
+			continue
+		}
+		padding := max(0, width-ansi.StringWidth(line))
+		if align == "center" {
+			padding /= 2
+		}
+		lines[i] = strings.Repeat(" ", padding) + line
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,173 @@
+package ebook
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	nethtml "golang.org/x/net/html"
+)
+
+// A daily edition gathers the articles news feeds published since the day
+// before into one book, a chapter per feed, like a morning paper. Feeds
+// that only carry a summary have the article downloaded from its page, see
+// article.go.
+
+// Feed items with less text than this are taken to be summaries
+const summaryLength = 1000
+
+// DigestOptions controls what goes into a daily edition
+type DigestOptions struct {
+	Since      time.Time // Leave out articles published before
+	MaxPerFeed int       // Most articles taken from each feed
+	FullText   bool      // Download the page of articles the feed only has a summary of
+	Replace    bool      // Build the edition again if there already is one for the day
+}
+
+// Digest is a daily edition that was written
+type Digest struct {
+	Path     string
+	Articles int
+	Failed   []error // Feeds that could not be read
+}
+
+// MakeDigest builds the daily edition of a day from news feeds and writes
+// it into a folder as "Daily Edition YYYY-MM-DD.epub". If there is one for
+// the day already, ErrDuplicate is returned with its path unless the
+// options ask to replace it. Feeds that can't be read are left out and
+// listed in the result.
+func MakeDigest(feeds []string, dir string, day time.Time, options DigestOptions) (Digest, error) {
+	title := "Daily Edition " + day.Format("2006-01-02")
+	digest := Digest{Path: filepath.Join(dir, BookFileName(title, "")+".epub")}
+	if len(feeds) == 0 {
+		return digest, fmt.Errorf("no feeds: add some to digest.feeds")
+	}
+	if _, err := os.Stat(digest.Path); err == nil && !options.Replace {
+		return digest, ErrDuplicate
+	}
+
+	book := &Book{
+		Title:  title,
+		Format: FormatEPUB,
+		Metadata: map[string]string{
+			"publisher": "cozy",
+			"date":      day.Format("2006-01-02"),
+		},
+	}
+	var sources []string
+	for _, feedURL := range feeds {
+		feed, err := FetchFeed(feedURL)
+		if err != nil {
+			digest.Failed = append(digest.Failed, fmt.Errorf("%s: %w", feedURL, err))
+			continue
+		}
+		chapter, count := digestChapter(feed, options)
+		if count == 0 {
+			continue
+		}
+		chapter.Order = len(book.Chapters)
+		book.Chapters = append(book.Chapters, chapter)
+		sources = append(sources, chapter.Title)
+		digest.Articles += count
+	}
+
+	if digest.Articles == 0 {
+		if len(digest.Failed) == len(feeds) {
+			return digest, fmt.Errorf("no feed could be read: %w", digest.Failed[0])
+		}
+		return digest, fmt.Errorf("no new articles since %s", options.Since.Format("2006-01-02 15:04"))
+	}
+	book.Metadata["description"] = fmt.Sprintf("%d articles from %s", digest.Articles, strings.Join(sources, ", "))
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return digest, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	return digest, WriteEPUB(book, digest.Path)
+}
+
+// digestChapter makes the chapter of a feed from its new articles, and
+// returns how many it has
+func digestChapter(feed *Feed, options DigestOptions) (Chapter, int) {
+	title := feed.Title
+	if title == "" {
+		if link, err := url.Parse(feed.URL); err == nil {
+			title = strings.TrimPrefix(link.Hostname(), "www.")
+		}
+	}
+
+	var content strings.Builder
+	content.WriteString("<h1>" + html.EscapeString(title) + "</h1>\n")
+	count := 0
+	for _, item := range feed.Items {
+		if count == options.MaxPerFeed {
+			break
+		}
+		if !item.Published.IsZero() && item.Published.Before(options.Since) {
+			continue
+		}
+		count++
+
+		text := item.Content
+		if options.FullText && item.Link != "" && utf8.RuneCountInString(htmlToText(text)) < summaryLength {
+			if article, err := FetchArticle(item.Link); err == nil {
+				text = article.Content
+			}
+		}
+
+		var details []string
+		for _, detail := range []string{item.Author, publishedTime(item.Published)} {
+			if detail != "" {
+				details = append(details, html.EscapeString(detail))
+			}
+		}
+		if link, err := url.Parse(item.Link); err == nil && (link.Scheme == "http" || link.Scheme == "https") {
+			details = append(details, `<a href="`+html.EscapeString(link.String())+`">`+html.EscapeString(link.Hostname())+"</a>")
+		}
+
+		fmt.Fprintf(&content, "<h2>%s</h2>\n", html.EscapeString(item.Title))
+		if len(details) > 0 {
+			content.WriteString("<p><em>" + strings.Join(details, " · ") + "</em></p>\n")
+		}
+		content.WriteString(cleanFeedHTML(text, item.Link) + "\n")
+	}
+	return Chapter{Title: title, Content: content.String(), HTML: true}, count
+}
+
+// publishedTime formats the date of an article, or returns "" if unknown
+func publishedTime(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+	return date.Local().Format("2006-01-02 15:04")
+}
+
+// cleanFeedHTML drops what a book can't show from the HTML of a feed item,
+// the way articles are cleaned, with links made absolute against the
+// item's page
+func cleanFeedHTML(content, link string) string {
+	doc, err := nethtml.Parse(strings.NewReader(content))
+	if err != nil {
+		return "<p>" + html.EscapeString(htmlToText(content)) + "</p>"
+	}
+	body := findElement(doc, "body")
+	if body == nil {
+		return ""
+	}
+	base, err := url.Parse(link)
+	if err != nil {
+		base = &url.URL{}
+	}
+	pruneArticle(body)
+	cleanArticle(body, base)
+
+	var out strings.Builder
+	for child := body.FirstChild; child != nil; child = child.NextSibling {
+		nethtml.Render(&out, child)
+	}
+	return out.String()
+}
@@ -0,0 +1,154 @@
+package ebook
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// DRMError is returned for books protected by DRM, which can't be read
+// without the key of the store they were bought from
+type DRMError struct {
+	Scheme string // e.g. "Adobe ADEPT" or "Kindle (Mobipocket)"
+}
+
+func (e *DRMError) Error() string {
+	return fmt.Sprintf("this book is DRM-protected (%s)", e.Scheme)
+}
+
+// Font obfuscation algorithms. Fonts mangled with these are not DRM; the
+// text of the book is readable.
+var fontObfuscation = map[string]bool{
+	"http://www.idpf.org/2008/embedding": true,
+	"http://ns.adobe.com/pdf/enc#RC":     true,
+}
+
+// Largest part of META-INF/encryption.xml and rights.xml read
+const maxDRMFileSize = 1 << 20
+
+type encryptionXML struct {
+	Data []struct {
+		Method struct {
+			Algorithm string `xml:"Algorithm,attr"`
+		} `xml:"EncryptionMethod"`
+		Cipher struct {
+			URI string `xml:"URI,attr"`
+		} `xml:"CipherData>CipherReference"`
+	} `xml:"EncryptedData"`
+}
+
+// epubDRM returns the DRM scheme an EPUB is protected with, or "" if its
+// content isn't encrypted
+func epubDRM(archive *zip.Reader) string {
+	files := make(map[string]*zip.File)
+	for _, file := range archive.File {
+		files[strings.ToLower(file.Name)] = file
+	}
+
+	if _, ok := files["meta-inf/license.lcpl"]; ok {
+		return "Readium LCP"
+	}
+	if _, ok := files["meta-inf/sinf.xml"]; ok {
+		return "Apple FairPlay"
+	}
+
+	file, ok := files["meta-inf/encryption.xml"]
+	if !ok {
+		return ""
+	}
+	content, err := readZipFile(file, maxDRMFileSize)
+	if err != nil {
+		return ""
+	}
+	var encryption encryptionXML
+	if xml.Unmarshal(content, &encryption) != nil {
+		return ""
+	}
+
+	// Encrypted text, rather than only obfuscated fonts, is DRM
+	algorithm := ""
+	for _, data := range encryption.Data {
+		if !fontObfuscation[data.Method.Algorithm] && !isFont(data.Cipher.URI) {
+			algorithm = data.Method.Algorithm
+			break
+		}
+	}
+	if algorithm == "" {
+		return ""
+	}
+
+	if rights, ok := files["meta-inf/rights.xml"]; ok {
+		if content, err := readZipFile(rights, maxDRMFileSize); err == nil && strings.Contains(string(content), "ns.adobe.com/adept") {
+			return "Adobe ADEPT"
+		}
+	}
+	if strings.Contains(string(content), "ns.adobe.com/adept") {
+		return "Adobe ADEPT"
+	}
+	if _, name, ok := strings.Cut(algorithm, "#"); ok {
+		return "unknown scheme, " + name + " encryption"
+	}
+	return "unknown scheme"
+}
+
+func isFont(uri string) bool {
+	switch strings.ToLower(path.Ext(uri)) {
+	case ".otf", ".ttf", ".woff", ".woff2":
+		return true
+	}
+	return false
+}
+
+// Kindle formats cozy can't read, but recognizes to say why
+var kindleExtensions = map[string]bool{
+	".mobi": true,
+	".azw":  true,
+	".azw3": true,
+	".prc":  true,
+}
+
+// kindleError explains why a Kindle book can't be opened: it is protected
+// by DRM, or it has to be converted to EPUB first
+func kindleError(filePath string) error {
+	if scheme := mobiDRM(filePath); scheme != "" {
+		return &DRMError{Scheme: scheme}
+	}
+	return fmt.Errorf("unsupported file format: %s; convert it to EPUB, for example with Calibre", path.Ext(filePath))
+}
+
+// mobiDRM returns the DRM scheme of a MOBI file from the encryption type
+// in its PalmDOC header, or "" if it isn't encrypted
+func mobiDRM(filePath string) string {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	// The Palm database header is 78 bytes, followed by the record list
+	// whose first entry points at the PalmDOC header
+	header := make([]byte, 86)
+	if _, err := io.ReadFull(file, header); err != nil {
+		return ""
+	}
+	if kind := string(header[60:68]); kind != "BOOKMOBI" && kind != "TEXtREAd" {
+		return ""
+	}
+	record := make([]byte, 14)
+	if _, err := file.ReadAt(record, int64(binary.BigEndian.Uint32(header[78:82]))); err != nil {
+		return ""
+	}
+
+	switch binary.BigEndian.Uint16(record[12:14]) {
+	case 1:
+		return "Mobipocket, old scheme"
+	case 2:
+		return "Kindle, Mobipocket"
+	}
+	return ""
+}
@@ -0,0 +1,147 @@
+package ebook
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DuplicateGroup is a set of files in the library holding the same book:
+// identical files, or books with the same title and author, for example an
+// EPUB and a text file of one novel
+type DuplicateGroup struct {
+	Books []BookInfo
+}
+
+// FindDuplicates groups the books that are copies of each other. Books
+// without a duplicate are left out.
+func FindDuplicates(books []BookInfo) []DuplicateGroup {
+	// Union-find over the books, joined by either kind of match
+	parent := make([]int, len(books))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	join := func(indices []int) {
+		for _, i := range indices[1:] {
+			parent[find(i)] = find(indices[0])
+		}
+	}
+
+	for _, indices := range groupBy(books, identityKey) {
+		join(indices)
+	}
+	for _, indices := range groupBy(books, sizeKey) {
+		// Files of the same size are compared by content
+		byHash := make(map[string][]int)
+		for _, i := range indices {
+			if hash, err := hashFile(books[i].Path); err == nil {
+				byHash[string(hash)] = append(byHash[string(hash)], i)
+			}
+		}
+		for _, same := range byHash {
+			if len(same) > 1 {
+				join(same)
+			}
+		}
+	}
+
+	members := make(map[int][]BookInfo)
+	var roots []int
+	for i, book := range books {
+		root := find(i)
+		if _, ok := members[root]; !ok {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], book)
+	}
+	var groups []DuplicateGroup
+	for _, root := range roots {
+		if len(members[root]) > 1 {
+			groups = append(groups, DuplicateGroup{Books: members[root]})
+		}
+	}
+	return groups
+}
+
+// Preferred returns the copy to keep: the one in the format listed first
+// in formats (such as "epub"), then the one with the shortest path
+func (g DuplicateGroup) Preferred(formats []string) BookInfo {
+	rank := func(book BookInfo) int {
+		format := strings.TrimPrefix(strings.ToLower(filepath.Ext(book.Path)), ".")
+		if i := slices.Index(formats, format); i >= 0 {
+			return i
+		}
+		return len(formats)
+	}
+	best := g.Books[0]
+	for _, book := range g.Books[1:] {
+		if r, b := rank(book), rank(best); r < b || (r == b && len(book.Path) < len(best.Path)) {
+			best = book
+		}
+	}
+	return best
+}
+
+// groupBy returns the indices of books sharing a key, skipping empty keys
+func groupBy(books []BookInfo, key func(BookInfo) string) [][]int {
+	indices := make(map[string][]int)
+	var keys []string
+	for i, book := range books {
+		k := key(book)
+		if k == "" {
+			continue
+		}
+		if _, ok := indices[k]; !ok {
+			keys = append(keys, k)
+		}
+		indices[k] = append(indices[k], i)
+	}
+	var groups [][]int
+	for _, k := range keys {
+		if len(indices[k]) > 1 {
+			groups = append(groups, indices[k])
+		}
+	}
+	return groups
+}
+
+// identityKey is the normalized title and author of a book, so "The Hobbit"
+// by "J.R.R. Tolkien" matches "the hobbit" by "J. R. R. Tolkien"
+func identityKey(book BookInfo) string {
+	title := normalizeForMatch(book.Title)
+	if title == "" {
+		return ""
+	}
+	return title + "\x00" + normalizeForMatch(book.Author)
+}
+
+// sizeKey is the size of a book's file, as only files of the same size
+// need hashing
+func sizeKey(book BookInfo) string {
+	info, err := os.Stat(book.Path)
+	if err != nil || info.Size() == 0 {
+		return ""
+	}
+	return strconv.FormatInt(info.Size(), 10)
+}
+
+// normalizeForMatch lowercases text and keeps only its letters and digits
+func normalizeForMatch(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
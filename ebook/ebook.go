@@ -2,27 +2,154 @@ package ebook
 
 import (
 	"fmt"
+	"io"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 )
 
 // Chapter represents a book chapter
 type Chapter struct {
 	Title   string
-	Content string // Full chapter content
+	Content string // Full chapter content, empty until loaded for lazy chapters; see Text
 	Order   int    // Position in book
+	HTML    bool   // Content is HTML rather than plain text
+	Href    string // Path of the chapter file inside the EPUB, for resolving links
+	Overlay string // Path of the chapter's media overlay inside the EPUB, see mediaoverlay.go
+
+	lazy *lazyContent // Set when the content is read from the book file on first use
+}
+
+// lazyContent loads the content of a chapter once, on first access
+type lazyContent struct {
+	once    sync.Once
+	size    int // Uncompressed size in bytes, known without loading
+	load    func() (string, error)
+	content string
+}
+
+// Text returns the chapter content, loading it from the book file first if
+// the chapter was read lazily. It is safe to call from several goroutines.
+func (c *Chapter) Text() string {
+	if c.lazy == nil {
+		return c.Content
+	}
+	c.lazy.once.Do(func() {
+		// A chapter that cannot be read shows up empty, as it would have
+		// been left out when reading the whole book up front
+		c.lazy.content, _ = c.lazy.load()
+	})
+	return c.lazy.content
+}
+
+// Length returns the size of the chapter content in bytes without loading it
+func (c *Chapter) Length() int {
+	if c.lazy == nil {
+		return len(c.Content)
+	}
+	return c.lazy.size
+}
+
+// MARC relator codes for the contributor roles we display
+const (
+	RoleAuthor      = "aut"
+	RoleTranslator  = "trl"
+	RoleEditor      = "edt"
+	RoleIllustrator = "ill"
+	RoleNarrator    = "nrt"
+)
+
+// Contributor is a person credited in the book metadata
+type Contributor struct {
+	Name string
+	Role string // MARC relator code, e.g. "aut" or "trl"
+}
+
+// RoleName returns a readable name for the contributor's role, or an
+// empty string for roles that are not displayed
+func (c Contributor) RoleName() string {
+	switch c.Role {
+	case RoleAuthor:
+		return "author"
+	case RoleTranslator:
+		return "translator"
+	case RoleEditor:
+		return "editor"
+	case RoleIllustrator:
+		return "illustrator"
+	case RoleNarrator:
+		return "narrator"
+	default:
+		return ""
+	}
+}
+
+// roleAbbreviation returns the short form used in bylines
+func (c Contributor) roleAbbreviation() string {
+	switch c.Role {
+	case RoleTranslator:
+		return "trans."
+	case RoleEditor:
+		return "ed."
+	case RoleIllustrator:
+		return "illus."
+	case RoleNarrator:
+		return "narr."
+	default:
+		return ""
+	}
+}
+
+// FormatByline formats contributors as "Author1, Author2 (trans. X)", or
+// "ed. X" for a book without authors, falling back to author when there
+// are no structured contributors
+func FormatByline(contributors []Contributor, author string) string {
+	var authors, others []string
+	for _, contributor := range contributors {
+		if contributor.Role == RoleAuthor {
+			authors = append(authors, contributor.Name)
+		} else if abbreviation := contributor.roleAbbreviation(); abbreviation != "" {
+			others = append(others, abbreviation+" "+contributor.Name)
+		}
+	}
+
+	byline := strings.Join(authors, ", ")
+	if byline == "" && len(others) > 0 {
+		// No author, the editor or translator goes first on their own
+		return strings.Join(others, "; ")
+	}
+	if byline == "" {
+		byline = author
+	}
+	if len(others) > 0 {
+		if byline != "" {
+			byline += " "
+		}
+		byline += "(" + strings.Join(others, "; ") + ")"
+	}
+	return byline
 }
 
 // Book represents an e-book
 type Book struct {
-	Path     string
-	Title    string
-	Author   string
-	Format   Format
-	Chapters []Chapter          // Book chapters
-	Metadata map[string]string
-	Tags     []string           // Folder names as tags (relative to library root)
+	Path         string
+	Title        string
+	Author       string        // First author, kept for sorting and simple display
+	Contributors []Contributor // All creators and contributors with roles
+	Format       Format
+	Chapters     []Chapter // Book chapters
+	Metadata     map[string]string
+	Tags         []string    // Folder names as tags (relative to library root)
+	Styles       *Stylesheet // CSS of the book, nil for plain text
+
+	archive io.Closer // Open book file that lazy chapters are read from
+
+	// Element ids and their chapters, see anchors.go
+	anchorsOnce sync.Once
+	anchors     map[string]int
 }
 
 // Format represents the e-book format
@@ -38,16 +165,58 @@ type Reader interface {
 	Read(path string) (*Book, error)
 }
 
+// NonLinearMode controls how spine items marked linear="no" are placed
+type NonLinearMode string
+
+const (
+	NonLinearInclude NonLinearMode = "include" // Keep them in spine order
+	NonLinearEnd     NonLinearMode = "end"     // Move them after the main text
+	NonLinearSkip    NonLinearMode = "skip"    // Leave them out entirely
+)
+
+// Options control how books are read
+type Options struct {
+	NonLinear        NonLinearMode
+	TextSplit        TextSplitMode
+	TextSectionSize  int  // Characters per section when splitting text into fixed sections
+	GutenbergCleanup bool // Strip Project Gutenberg boilerplate and unwrap plain text
+}
+
+// DefaultOptions returns the options used by Open
+func DefaultOptions() Options {
+	return Options{
+		NonLinear:       NonLinearInclude,
+		TextSplit:       TextSplitAuto,
+		TextSectionSize: 10 * charsPerPage,
+	}
+}
+
 // BookInfo holds basic information about a book for library display
 type BookInfo struct {
-	Path   string
-	Title  string
-	Author string
-	Tags   []string
+	Path         string
+	Title        string
+	Author       string
+	Contributors []Contributor
+	Tags         []string
+	Metadata     map[string]string
+	Calibre      bool  // Metadata came from a Calibre database rather than the file
+	Error        error // Why the book can't be opened, nil if it can
 }
 
-// Open opens an e-book file and returns a Book
+// Byline returns the formatted list of authors and contributors
+func (b BookInfo) Byline() string {
+	return FormatByline(b.Contributors, b.Author)
+}
+
+// Open opens an e-book file with the default options and returns a Book
 func Open(path string) (*Book, error) {
+	return OpenBook(path, DefaultOptions())
+}
+
+// OpenBook opens an e-book file with the given options and returns a
+// Book. Together with Book.RenderChapter and a ProgressStore, it lets other
+// programs read books without the TUI.
+func OpenBook(path string, options Options) (*Book, error) {
 	ext := strings.ToLower(filepath.Ext(path))
 
 	var reader Reader
@@ -55,12 +224,15 @@ func Open(path string) (*Book, error) {
 
 	switch ext {
 	case ".epub":
-		reader = &EPUBReader{}
+		reader = &EPUBReader{Options: options}
 		format = FormatEPUB
 	case ".txt":
-		reader = &TextReader{}
+		reader = &TextReader{Options: options}
 		format = FormatText
 	default:
+		if kindleExtensions[ext] {
+			return nil, kindleError(path)
+		}
 		return nil, fmt.Errorf("unsupported file format: %s", ext)
 	}
 
@@ -75,21 +247,39 @@ func Open(path string) (*Book, error) {
 	return book, nil
 }
 
-// ListBooks lists all supported e-books in a directory
+// ListBooks lists all supported e-books in a directory, outside hidden
+// folders
 func ListBooks(dir string) ([]BookInfo, error) {
 	var books []BookInfo
 
+	// Prefer Calibre's metadata when the library is managed by Calibre
+	var calibre map[string]calibreBook
+	if HasCalibreLibrary(dir) {
+		if library, err := loadCalibreLibrary(dir); err == nil {
+			calibre = library
+		}
+	}
+
 	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
 		if info.IsDir() {
+			// Hidden folders hold cozy's data, trash and duplicates
+			// when it is kept in the library
+			if path != dir && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
-		ext := strings.ToLower(filepath.Ext(path))
-		if ext == ".epub" || ext == ".txt" {
+		if IsBookFile(path) {
+			if entry, ok := calibre[path]; ok {
+				books = append(books, entry.bookInfo(path))
+				return nil
+			}
+
 			// Extract tags from folder path relative to library root
 			tags := extractTags(path, dir)
 
@@ -99,10 +289,16 @@ func ListBooks(dir string) ([]BookInfo, error) {
 				Tags: tags,
 			}
 
-			// Attempt to load title and author
+			// Load title and author, which also finds books that are
+			// corrupt or can't be read
 			if book, err := Open(path); err == nil {
 				bookInfo.Title = book.Title
 				bookInfo.Author = book.Author
+				bookInfo.Contributors = book.Contributors
+				bookInfo.Metadata = book.Metadata
+				book.Close()
+			} else {
+				bookInfo.Error = err
 			}
 
 			books = append(books, bookInfo)
@@ -118,6 +314,13 @@ func ListBooks(dir string) ([]BookInfo, error) {
 	return books, nil
 }
 
+// IsBookFile reports whether a file is listed in the library: a book cozy
+// can read, or a Kindle book it can say it can't read
+func IsBookFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".epub" || ext == ".txt" || kindleExtensions[ext]
+}
+
 // extractTags extracts folder names as tags from the book path
 func extractTags(bookPath, libraryRoot string) []string {
 	// Get relative path from library root
@@ -148,6 +351,33 @@ func extractTags(bookPath, libraryRoot string) []string {
 	return tags
 }
 
+// Authors returns the names of all contributors with the author role
+func (b *Book) Authors() []string {
+	var authors []string
+	for _, contributor := range b.Contributors {
+		if contributor.Role == RoleAuthor {
+			authors = append(authors, contributor.Name)
+		}
+	}
+	return authors
+}
+
+// Byline returns the formatted list of authors and contributors
+func (b *Book) Byline() string {
+	return FormatByline(b.Contributors, b.Author)
+}
+
+// Close releases the book file. Chapters that were not loaded yet read as
+// empty afterwards.
+func (b *Book) Close() error {
+	if b.archive == nil {
+		return nil
+	}
+	err := b.archive.Close()
+	b.archive = nil
+	return err
+}
+
 // GetChapter returns a specific chapter
 func (b *Book) GetChapter(index int) *Chapter {
 	if index < 0 || index >= len(b.Chapters) {
@@ -160,3 +390,76 @@ func (b *Book) GetChapter(index int) *Chapter {
 func (b *Book) ChapterCount() int {
 	return len(b.Chapters)
 }
+
+// WordCount returns the approximate number of words in the book
+func (b *Book) WordCount() int {
+	count := 0
+	for i := range b.Chapters {
+		count += b.Chapters[i].WordCount()
+	}
+	return count
+}
+
+// Words on a printed page, the default unit of page numbers
+const DefaultWordsPerPage = 250
+
+// Pages returns the number of pages of wordsPerPage words it takes to
+// hold a number of words, counting the last partly filled one
+func Pages(words, wordsPerPage int) int {
+	if wordsPerPage <= 0 {
+		wordsPerPage = DefaultWordsPerPage
+	}
+	return (words + wordsPerPage - 1) / wordsPerPage
+}
+
+// PageAt returns the page a percentage into a text of some number of
+// pages falls on, counting from 1
+func PageAt(percent float64, pages int) int {
+	return max(1, min(int(clampPercent(percent)/100*float64(pages))+1, pages))
+}
+
+// WordCount returns the approximate number of words in the chapter
+func (c *Chapter) WordCount() int {
+	if c.HTML {
+		return len(strings.Fields(ExtractPlainText(c.Text())))
+	}
+	return len(strings.Fields(c.Text()))
+}
+
+// ResolveLink finds the chapter an internal link points to. It returns the
+// chapter index and the fragment (anchor id), or false for external links
+// and links to files that are not part of the book.
+func (b *Book) ResolveLink(fromChapter int, href string) (int, string, bool) {
+	target, err := url.Parse(href)
+	if err != nil || target.Scheme != "" || target.Host != "" {
+		return 0, "", false
+	}
+
+	from := b.GetChapter(fromChapter)
+	if from == nil {
+		return 0, "", false
+	}
+
+	// Same-document link, unless the element is in another chapter, as in
+	// books split from one long file without fixing their links
+	if target.Path == "" {
+		if _, ok := b.Anchors()[from.Href+"#"+target.Fragment]; !ok {
+			if chapter, ok := b.AnchorChapter(target.Fragment); ok {
+				return chapter, target.Fragment, true
+			}
+		}
+		return fromChapter, target.Fragment, true
+	}
+	resolved := path.Join(path.Dir(from.Href), target.Path)
+	for i, chapter := range b.Chapters {
+		if chapter.Href != "" && strings.EqualFold(chapter.Href, resolved) {
+			return i, target.Fragment, true
+		}
+	}
+
+	// Files that aren't chapters may still name an element of one
+	if chapter, ok := b.AnchorChapter(target.Fragment); ok {
+		return chapter, target.Fragment, true
+	}
+	return 0, "", false
+}
@@ -0,0 +1,119 @@
+package ebook
+
+import (
+	"bytes"
+	"encoding/xml"
+	"regexp"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// How far into a file we look for an encoding declaration
+const encodingSniffLength = 1024
+
+var (
+	xmlEncodingPattern = regexp.MustCompile(`^\s*<\?xml[^>]*encoding\s*=\s*["']([A-Za-z0-9._:-]+)["']`)
+	metaCharsetPattern = regexp.MustCompile(`(?i)<meta[^>]+charset\s*=\s*["']?([A-Za-z0-9._:-]+)`)
+)
+
+// decodeText converts file content to UTF-8. The encoding is taken from a
+// byte order mark, then (for markup) the XML or HTML charset declaration,
+// and otherwise guessed from the bytes themselves.
+func decodeText(data []byte, markup bool) string {
+	enc := detectEncoding(data, markup)
+	if enc == nil {
+		return string(data)
+	}
+
+	decoded, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return string(data)
+	}
+	return string(decoded)
+}
+
+// detectEncoding returns the encoding of data, or nil for UTF-8
+func detectEncoding(data []byte, markup bool) encoding.Encoding {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return unicode.UTF8BOM
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM)
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM)
+	}
+
+	if enc := detectUTF16(data); enc != nil {
+		return enc
+	}
+
+	if markup {
+		head := data[:min(len(data), encodingSniffLength)]
+		// Covers <?xml encoding>, <meta charset> and http-equiv declarations
+		for _, pattern := range []*regexp.Regexp{xmlEncodingPattern, metaCharsetPattern} {
+			if match := pattern.FindSubmatch(head); match != nil {
+				if enc, err := htmlindex.Get(string(match[1])); err == nil {
+					return nilIfUTF8(enc)
+				}
+			}
+		}
+	}
+
+	if utf8.Valid(data) {
+		return nil
+	}
+
+	// Invalid UTF-8 without a declaration is most likely Latin-1 or its
+	// Windows superset
+	return charmap.Windows1252
+}
+
+// detectUTF16 recognises BOM-less UTF-16 by the NUL bytes of ASCII text
+func detectUTF16(data []byte) encoding.Encoding {
+	sample := data[:min(len(data), encodingSniffLength)]
+	if len(sample) < 4 {
+		return nil
+	}
+
+	evenNULs, oddNULs := 0, 0
+	for i, b := range sample {
+		if b != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			evenNULs++
+		} else {
+			oddNULs++
+		}
+	}
+
+	half := len(sample) / 2
+	switch {
+	case oddNULs > half*3/4 && evenNULs == 0:
+		return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	case evenNULs > half*3/4 && oddNULs == 0:
+		return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)
+	}
+	return nil
+}
+
+// nilIfUTF8 maps UTF-8 to nil so callers can skip decoding
+func nilIfUTF8(enc encoding.Encoding) encoding.Encoding {
+	if enc == unicode.UTF8 {
+		return nil
+	}
+	return enc
+}
+
+// unmarshalXML decodes an XML document, converting declared non-UTF-8
+// encodings on the way
+func unmarshalXML(data []byte, v any) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+	return decoder.Decode(v)
+}
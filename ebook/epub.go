@@ -4,7 +4,10 @@ import (
 	"archive/zip"
 	"encoding/xml"
 	"fmt"
+	htmlpkg "html"
 	"io"
+	"net/url"
+	"path"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -22,16 +25,46 @@ type rootfile struct {
 }
 
 type opfPackage struct {
-	XMLName  xml.Name     `xml:"package"`
-	Metadata opfMetadata  `xml:"metadata"`
-	Manifest opfManifest  `xml:"manifest"`
-	Spine    opfSpine     `xml:"spine"`
+	XMLName  xml.Name    `xml:"package"`
+	Metadata opfMetadata `xml:"metadata"`
+	Manifest opfManifest `xml:"manifest"`
+	Spine    opfSpine    `xml:"spine"`
 }
 
 type opfMetadata struct {
-	Title   []string `xml:"title"`
-	Creator []string `xml:"creator"`
-	Lang    string   `xml:"language"`
+	Title       []string        `xml:"title"`
+	Creator     []opfCreator    `xml:"creator"`
+	Contributor []opfCreator    `xml:"contributor"`
+	Lang        string          `xml:"language"`
+	Publisher   []string        `xml:"publisher"`
+	Date        []string        `xml:"date"`
+	Source      []string        `xml:"source"`
+	Description []string        `xml:"description"`
+	Subject     []string        `xml:"subject"`
+	Identifier  []opfIdentifier `xml:"identifier"`
+	Meta        []opfMeta       `xml:"meta"`
+}
+
+type opfCreator struct {
+	ID    string `xml:"id,attr"`
+	Role  string `xml:"role,attr"`
+	Value string `xml:",chardata"`
+}
+
+type opfIdentifier struct {
+	ID     string `xml:"id,attr"`
+	Scheme string `xml:"scheme,attr"`
+	Value  string `xml:",chardata"`
+}
+
+// opfMeta covers both EPUB 2 (name/content) and EPUB 3 (property/refines) meta elements
+type opfMeta struct {
+	Name     string `xml:"name,attr"`
+	Content  string `xml:"content,attr"`
+	Property string `xml:"property,attr"`
+	Refines  string `xml:"refines,attr"`
+	ID       string `xml:"id,attr"`
+	Value    string `xml:",chardata"`
 }
 
 type opfManifest struct {
@@ -39,9 +72,11 @@ type opfManifest struct {
 }
 
 type opfItem struct {
-	ID        string `xml:"id,attr"`
-	Href      string `xml:"href,attr"`
-	MediaType string `xml:"media-type,attr"`
+	ID           string `xml:"id,attr"`
+	Href         string `xml:"href,attr"`
+	MediaType    string `xml:"media-type,attr"`
+	Properties   string `xml:"properties,attr"`
+	MediaOverlay string `xml:"media-overlay,attr"` // Id of the item's SMIL read-aloud audio
 }
 
 type opfSpine struct {
@@ -49,11 +84,18 @@ type opfSpine struct {
 }
 
 type opfItemref struct {
-	IDref string `xml:"idref,attr"`
+	IDref  string `xml:"idref,attr"`
+	Linear string `xml:"linear,attr"` // "no" marks auxiliary content such as notes
 }
 
-// EPUBReader reads EPUB files
-type EPUBReader struct{}
+// Bytes read from the start of each chapter file to find its title
+const chapterPrefixSize = 16 * 1024
+
+// EPUBReader reads EPUB files. Chapter content is loaded lazily from the
+// open archive, so the book must be closed with Book.Close.
+type EPUBReader struct {
+	Options Options
+}
 
 // Read reads an EPUB file
 func (r *EPUBReader) Read(path string) (*Book, error) {
@@ -61,8 +103,20 @@ func (r *EPUBReader) Read(path string) (*Book, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to open EPUB file: %w", err)
 	}
-	defer zipReader.Close()
+	if scheme := epubDRM(&zipReader.Reader); scheme != "" {
+		zipReader.Close()
+		return nil, &DRMError{Scheme: scheme}
+	}
 
+	book, err := r.read(zipReader, path)
+	if err != nil || book.archive == nil {
+		zipReader.Close()
+	}
+	return book, err
+}
+
+// read reads the package and chapter list from an open EPUB archive
+func (r *EPUBReader) read(zipReader *zip.ReadCloser, path string) (*Book, error) {
 	book := &Book{
 		Metadata: make(map[string]string),
 		Title:    filepath.Base(path),
@@ -85,59 +139,115 @@ func (r *EPUBReader) Read(path string) (*Book, error) {
 	if len(opf.Metadata.Title) > 0 {
 		book.Title = opf.Metadata.Title[0]
 	}
-	if len(opf.Metadata.Creator) > 0 {
-		book.Author = opf.Metadata.Creator[0]
+	book.Contributors = parseContributors(&opf.Metadata)
+	if authors := book.Authors(); len(authors) > 0 {
+		book.Author = authors[0]
+	} else {
+		// Anthologies and translations may only name an editor or a
+		// translator, who is better to sort by than no one
+		book.Author = firstCreator(&opf.Metadata)
 	}
 	if opf.Metadata.Lang != "" {
 		book.Metadata["language"] = opf.Metadata.Lang
 	}
+	extractMetadata(book.Metadata, &opf.Metadata)
 
 	// Step 4: Build manifest map
 	manifestMap := make(map[string]opfItem)
 	for _, item := range opf.Manifest.Items {
 		manifestMap[item.ID] = item
 	}
+	book.Styles = readStylesheets(zipReader, opfPath, opf.Manifest.Items)
 
 	// Step 5: Read chapters in spine order
-	opfDir := filepath.Dir(opfPath)
+	var auxiliary []Chapter
 	for i, itemref := range opf.Spine.Itemrefs {
+		nonLinear := strings.EqualFold(itemref.Linear, "no")
+		if nonLinear && r.Options.NonLinear == NonLinearSkip {
+			continue
+		}
+
 		if item, ok := manifestMap[itemref.IDref]; ok {
 			// Construct the full path relative to OPF
-			contentPath := filepath.Join(opfDir, item.Href)
-			contentPath = filepath.Clean(contentPath)
+			contentPath := resolveHref(opfPath, item.Href)
 
-			// Read the chapter content
-			content, err := readFileFromZip(zipReader, contentPath)
-			if err != nil {
+			file := findFileInZip(zipReader, contentPath)
+			if file == nil {
 				continue
 			}
 
-			htmlContent := string(content)
+			// Only the start of the chapter is read now, for its title
+			prefix, err := readZipFile(file, chapterPrefixSize)
+			if err != nil {
+				continue
+			}
+			htmlPrefix := decodeText(prefix, true)
+			if int(file.UncompressedSize64) <= chapterPrefixSize && strings.TrimSpace(htmlPrefix) == "" {
+				continue
+			}
 
 			// Extract chapter title from the HTML or use a default
-			chapterTitle := extractTitle(htmlContent)
+			chapterTitle := extractTitle(htmlPrefix)
 			if chapterTitle == "" {
 				chapterTitle = fmt.Sprintf("Chapter %d", i+1)
 			}
 
-			// Store the raw HTML - we'll render it with theme later
-			if strings.TrimSpace(htmlContent) != "" {
-				book.Chapters = append(book.Chapters, Chapter{
-					Title:   chapterTitle,
-					Content: htmlContent, // Store raw HTML
-					Order:   i,
-				})
+			// The raw HTML is loaded on first use and rendered with the theme
+			// later
+			chapter := Chapter{
+				Title:   chapterTitle,
+				Order:   i,
+				HTML:    true,
+				Href:    contentPath,
+				Overlay: overlayPath(opfPath, item, manifestMap),
+				lazy: &lazyContent{
+					size: int(file.UncompressedSize64),
+					load: func() (string, error) {
+						content, err := readZipFile(file, -1)
+						return decodeText(content, true), err
+					},
+				},
+			}
+
+			// Demoted non-linear items are appended after the main text
+			if nonLinear && r.Options.NonLinear == NonLinearEnd {
+				auxiliary = append(auxiliary, chapter)
+			} else {
+				book.Chapters = append(book.Chapters, chapter)
 			}
 		}
 	}
+	book.Chapters = append(book.Chapters, auxiliary...)
 
 	if len(book.Chapters) == 0 {
 		return nil, fmt.Errorf("no chapters found in EPUB")
 	}
 
+	book.archive = zipReader
 	return book, nil
 }
 
+// Stylesheets larger than this are not read
+const maxStylesheetSize = 512 * 1024
+
+// readStylesheets parses the CSS files in the manifest
+func readStylesheets(zipReader *zip.ReadCloser, opfPath string, items []opfItem) *Stylesheet {
+	sheet := &Stylesheet{}
+	for _, item := range items {
+		if item.MediaType != "text/css" {
+			continue
+		}
+		file := findFileInZip(zipReader, resolveHref(opfPath, item.Href))
+		if file == nil || file.UncompressedSize64 > maxStylesheetSize {
+			continue
+		}
+		if content, err := readZipFile(file, -1); err == nil {
+			sheet.add(decodeText(content, false))
+		}
+	}
+	return sheet
+}
+
 // readFallback reads all HTML files when OPF parsing fails
 func (r *EPUBReader) readFallback(zipReader *zip.ReadCloser, book *Book) (*Book, error) {
 	type fileWithContent struct {
@@ -150,8 +260,8 @@ func (r *EPUBReader) readFallback(zipReader *zip.ReadCloser, book *Book) (*Book,
 	for _, f := range zipReader.File {
 		name := strings.ToLower(f.Name)
 		if strings.HasSuffix(name, ".html") ||
-		   strings.HasSuffix(name, ".xhtml") ||
-		   strings.HasSuffix(name, ".htm") {
+			strings.HasSuffix(name, ".xhtml") ||
+			strings.HasSuffix(name, ".htm") {
 			fileRC, err := f.Open()
 			if err != nil {
 				continue
@@ -163,7 +273,7 @@ func (r *EPUBReader) readFallback(zipReader *zip.ReadCloser, book *Book) (*Book,
 				continue
 			}
 
-			htmlContent := string(data)
+			htmlContent := decodeText(data, true)
 			if strings.TrimSpace(htmlContent) != "" && len(htmlContent) > 100 {
 				files = append(files, fileWithContent{
 					name:    f.Name,
@@ -183,6 +293,8 @@ func (r *EPUBReader) readFallback(zipReader *zip.ReadCloser, book *Book) (*Book,
 			Title:   filepath.Base(f.name),
 			Content: f.content,
 			Order:   i,
+			HTML:    true,
+			Href:    f.name,
 		})
 	}
 
@@ -201,7 +313,7 @@ func findOPFPath(zipReader *zip.ReadCloser) (string, error) {
 	}
 
 	var cont container
-	if err := xml.Unmarshal(data, &cont); err != nil {
+	if err := unmarshalXML(data, &cont); err != nil {
 		return "", err
 	}
 
@@ -220,7 +332,7 @@ func parseOPF(zipReader *zip.ReadCloser, opfPath string) (*opfPackage, error) {
 	}
 
 	var opf opfPackage
-	if err := xml.Unmarshal(data, &opf); err != nil {
+	if err := unmarshalXML(data, &opf); err != nil {
 		return nil, err
 	}
 
@@ -228,20 +340,62 @@ func parseOPF(zipReader *zip.ReadCloser, opfPath string) (*opfPackage, error) {
 }
 
 // readFileFromZip reads a file from the ZIP archive
-func readFileFromZip(zipReader *zip.ReadCloser, path string) ([]byte, error) {
-	path = filepath.Clean(path)
-	for _, f := range zipReader.File {
-		if filepath.Clean(f.Name) == path {
-			rc, err := f.Open()
-			if err != nil {
-				return nil, err
-			}
-			defer rc.Close()
+func readFileFromZip(zipReader *zip.ReadCloser, name string) ([]byte, error) {
+	match := findFileInZip(zipReader, name)
+	if match == nil {
+		return nil, fmt.Errorf("file not found: %s", path.Clean(strings.TrimPrefix(name, "/")))
+	}
+	return readZipFile(match, -1)
+}
+
+// findFileInZip returns the archive entry for a path, or nil
+func findFileInZip(zipReader *zip.ReadCloser, name string) *zip.File {
+	name = path.Clean(strings.TrimPrefix(name, "/"))
 
-			return io.ReadAll(rc)
+	// Some archives percent-encode their entry names or disagree with the
+	// manifest on case, so fall back to looser matches
+	var match *zip.File
+	for _, f := range zipReader.File {
+		entry := path.Clean(f.Name)
+		if entry == name {
+			match = f
+			break
 		}
+		if decoded, err := url.PathUnescape(entry); err == nil && decoded == name {
+			match = f
+		} else if match == nil && strings.EqualFold(entry, name) {
+			match = f
+		}
+	}
+
+	return match
+}
+
+// readZipFile reads an archive entry, or only its first limit bytes when
+// limit is not negative
+func readZipFile(file *zip.File, limit int64) ([]byte, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	if limit >= 0 {
+		return io.ReadAll(io.LimitReader(rc, limit))
+	}
+	return io.ReadAll(rc)
+}
+
+// resolveHref resolves a manifest href against the OPF location, dropping
+// any fragment and decoding percent-escapes
+func resolveHref(opfPath, href string) string {
+	if i := strings.IndexAny(href, "#?"); i >= 0 {
+		href = href[:i]
+	}
+	if decoded, err := url.PathUnescape(href); err == nil {
+		href = decoded
 	}
-	return nil, fmt.Errorf("file not found: %s", path)
+	return path.Join(path.Dir(opfPath), href)
 }
 
 // extractTitle extracts the title from HTML content
@@ -251,7 +405,7 @@ func extractTitle(html string) string {
 	if titleStart != -1 {
 		titleEnd := strings.Index(html[titleStart:], "</title>")
 		if titleEnd != -1 {
-			return strings.TrimSpace(html[titleStart+7 : titleStart+titleEnd])
+			return strings.TrimSpace(htmlpkg.UnescapeString(html[titleStart+7 : titleStart+titleEnd]))
 		}
 	}
 
@@ -261,7 +415,7 @@ func extractTitle(html string) string {
 		if contentStart != -1 {
 			h1End := strings.Index(html[h1Start:], "</h1>")
 			if h1End != -1 {
-				return stripHTMLTags(html[h1Start+contentStart+1 : h1Start+h1End])
+				return htmlpkg.UnescapeString(stripHTMLTags(html[h1Start+contentStart+1 : h1Start+h1End]))
 			}
 		}
 	}
@@ -279,8 +433,8 @@ func htmlToText(html string) string {
 		result = strings.ReplaceAll(result, elem, elem+"\n")
 	}
 
-	// Strip all HTML tags
-	result = stripHTMLTags(result)
+	// Strip all HTML tags, then decode entities such as &nbsp; and &#8217;
+	result = htmlpkg.UnescapeString(stripHTMLTags(result))
 
 	// Clean up excessive whitespace
 	lines := strings.Split(result, "\n")
@@ -0,0 +1,226 @@
+package ebook
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrDuplicate is returned when a book is already present at the destination
+var ErrDuplicate = errors.New("book already exists at destination")
+
+// ExportOptions controls how a book is copied by ExportBook
+type ExportOptions struct {
+	ConvertText bool    // Convert plain text books to EPUB
+	Book        Options // Reader options used when converting
+}
+
+// ExportBook copies a book into destDir and returns the path of the new
+// file. Files are named "Author - Title" after the book's metadata. If an
+// identical file already exists anywhere under destDir, ErrDuplicate is
+// returned along with the path of the existing copy.
+func ExportBook(path, destDir string, options ExportOptions) (string, error) {
+	info, err := os.Stat(destDir)
+	if err != nil {
+		return "", fmt.Errorf("destination not available: %w", err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("destination is not a directory: %s", destDir)
+	}
+
+	book, err := OpenBook(path, options.Book)
+	if err != nil {
+		return "", err
+	}
+	defer book.Close()
+
+	convert := options.ConvertText && book.Format == "txt"
+	ext := strings.ToLower(filepath.Ext(path))
+	if convert {
+		ext = ".epub"
+	}
+
+	name := BookFileName(book.Title, book.Author) + ext
+	target := filepath.Join(destDir, name)
+
+	if convert {
+		// Converted output differs from the source, so match on name only
+		if _, err := os.Stat(target); err == nil {
+			return target, ErrDuplicate
+		}
+		if err := WriteEPUB(book, target); err != nil {
+			return "", err
+		}
+		return target, nil
+	}
+
+	if existing, err := findDuplicate(path, destDir); err != nil {
+		return "", err
+	} else if existing != "" {
+		return existing, ErrDuplicate
+	}
+
+	target = uniquePath(target)
+	if err := copyFile(path, target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// BookFileName builds a file name (without extension) from a title and
+// author, dropping characters that are not allowed on common filesystems
+func BookFileName(title, author string) string {
+	name := title
+	if author != "" {
+		name = author + " - " + title
+	}
+
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r < 0x20, strings.ContainsRune(`<>:"/\|?*`, r):
+			return -1
+		}
+		return r
+	}, name)
+
+	name = strings.Trim(strings.Join(strings.Fields(name), " "), ". ")
+	if name == "" {
+		name = "Untitled"
+	}
+	return name
+}
+
+// findDuplicate looks under dir for a file with the same content as path
+func findDuplicate(path, dir string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read book: %w", err)
+	}
+
+	var sourceHash []byte
+	var found string
+
+	err = filepath.WalkDir(dir, func(candidate string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			// Skip unreadable directories rather than aborting the export
+			return nil
+		}
+
+		// Only hash files whose size already matches
+		candidateInfo, err := d.Info()
+		if err != nil || candidateInfo.Size() != info.Size() {
+			return nil
+		}
+
+		if sourceHash == nil {
+			if sourceHash, err = hashFile(path); err != nil {
+				return err
+			}
+		}
+		hash, err := hashFile(candidate)
+		if err == nil && string(hash) == string(sourceHash) {
+			found = candidate
+			return fs.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to check destination: %w", err)
+	}
+
+	return found, nil
+}
+
+// hashFile returns the SHA-256 digest of a file's content
+func hashFile(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
+
+// uniquePath appends " (2)", " (3)", ... until the path is unused
+func uniquePath(path string) string {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return path
+	}
+
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		if _, err := os.Stat(candidate); errors.Is(err, fs.ErrNotExist) {
+			return candidate
+		}
+	}
+}
+
+// MoveBook moves a book into dir, renaming it if a file of the same name is
+// already there, and returns its new path
+func MoveBook(path, dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("destination not available: %w", err)
+	}
+	target := uniquePath(filepath.Join(dir, filepath.Base(path)))
+	if err := RenameBook(path, target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// RenameBook moves a book file to a new path, which must not exist yet
+func RenameBook(from, to string) error {
+	if _, err := os.Stat(to); err == nil {
+		return fmt.Errorf("%s already exists", filepath.Base(to))
+	}
+	if err := os.Rename(from, to); err == nil {
+		return nil
+	}
+
+	// Across file systems the book has to be copied
+	if err := copyFile(from, to); err != nil {
+		return err
+	}
+	if err := os.Remove(from); err != nil {
+		os.Remove(to)
+		return fmt.Errorf("failed to remove book: %w", err)
+	}
+	return nil
+}
+
+// copyFile copies src to dst, removing dst if the copy fails
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to read book: %w", err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("failed to create copy: %w", err)
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return fmt.Errorf("failed to copy book: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(dst)
+		return fmt.Errorf("failed to copy book: %w", err)
+	}
+	return nil
+}
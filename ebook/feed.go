@@ -0,0 +1,235 @@
+package ebook
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+// News feeds come as RSS 2.0, RSS 1.0 (RDF) or Atom. All three are read
+// into a Feed: a title and items with a title, link, author, date and HTML
+// content, which is the full text when the feed has it and a summary
+// otherwise.
+
+// Feed is a news feed
+type Feed struct {
+	Title string
+	URL   string
+	Items []FeedItem
+}
+
+// FeedItem is an article of a feed
+type FeedItem struct {
+	Title     string
+	Link      string
+	Author    string
+	Published time.Time // Zero if the feed doesn't say
+	Content   string    // HTML
+}
+
+// feedXML holds the elements of all three formats; each feed fills in
+// its own
+type feedXML struct {
+	XMLName xml.Name
+	Title   string      `xml:"title"`   // Atom
+	Entries []atomEntry `xml:"entry"`   // Atom
+	Channel rssChannel  `xml:"channel"` // RSS
+	Items   []rssItem   `xml:"item"`    // RSS 1.0, next to the channel
+}
+
+type rssChannel struct {
+	Title string    `xml:"title"`
+	Items []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	Content     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PubDate     string `xml:"pubDate"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Author      string `xml:"author"`
+}
+
+type atomEntry struct {
+	Title     atomText   `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Summary   atomText   `xml:"summary"`
+	Content   atomText   `xml:"content"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Authors   []struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+// atomText is Atom text, which is plain text, escaped HTML, or XHTML
+// markup depending on its type
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+// html returns Atom text as HTML
+func (t atomText) html() string {
+	switch t.Type {
+	case "xhtml":
+		return t.Inner
+	case "html":
+		return t.Text
+	}
+	return html.EscapeString(t.Text)
+}
+
+// text returns Atom text as plain text
+func (t atomText) text() string {
+	if t.Type == "html" || t.Type == "xhtml" {
+		return htmlToText(t.html())
+	}
+	return strings.TrimSpace(t.Text)
+}
+
+// Date layouts seen in feeds, RFC 822 with and without the weekday and
+// seconds, and RFC 3339
+var feedDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// FetchFeed downloads and reads a news feed
+func FetchFeed(feedURL string) (*Feed, error) {
+	request, err := http.NewRequest(http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download feed: %w", err)
+	}
+	request.Header.Set("User-Agent", fetchUserAgent)
+	request.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml, text/xml")
+	response, err := fetchClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download feed: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download feed: %s", response.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(response.Body, maxArticleSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download feed: %w", err)
+	}
+
+	feed, err := ParseFeed(data)
+	if err != nil {
+		return nil, err
+	}
+	feed.URL = feedURL
+	return feed, nil
+}
+
+// ParseFeed reads an RSS or Atom feed
+func ParseFeed(data []byte) (*Feed, error) {
+	// Feeds are often sloppy XML with HTML entities in them
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	var parsed feedXML
+	if err := decoder.Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse feed: %w", err)
+	}
+
+	feed := &Feed{}
+	switch strings.ToLower(parsed.XMLName.Local) {
+	case "feed":
+		feed.Title = strings.TrimSpace(parsed.Title)
+		for _, entry := range parsed.Entries {
+			feed.Items = append(feed.Items, entry.item())
+		}
+	case "rss", "rdf":
+		feed.Title = strings.TrimSpace(parsed.Channel.Title)
+		for _, item := range append(parsed.Channel.Items, parsed.Items...) {
+			feed.Items = append(feed.Items, item.item())
+		}
+	default:
+		return nil, fmt.Errorf("not an RSS or Atom feed")
+	}
+	return feed, nil
+}
+
+// item converts an RSS item
+func (i rssItem) item() FeedItem {
+	content := i.Content
+	if strings.TrimSpace(content) == "" {
+		content = i.Description
+	}
+	author := i.Creator
+	if author == "" {
+		author = i.Author
+	}
+	date := i.PubDate
+	if date == "" {
+		date = i.Date
+	}
+	return FeedItem{
+		Title:     htmlToText(i.Title),
+		Link:      strings.TrimSpace(i.Link),
+		Author:    strings.TrimSpace(author),
+		Published: parseFeedDate(date),
+		Content:   content,
+	}
+}
+
+// item converts an Atom entry
+func (e atomEntry) item() FeedItem {
+	item := FeedItem{Title: e.Title.text(), Content: e.Content.html()}
+	if strings.TrimSpace(item.Content) == "" {
+		item.Content = e.Summary.html()
+	}
+	for _, link := range e.Links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			item.Link = link.Href
+			break
+		}
+	}
+	if len(e.Authors) > 0 {
+		item.Author = strings.TrimSpace(e.Authors[0].Name)
+	}
+	item.Published = parseFeedDate(e.Published)
+	if item.Published.IsZero() {
+		item.Published = parseFeedDate(e.Updated)
+	}
+	return item
+}
+
+// parseFeedDate reads the date of an item, returning the zero time if it
+// can't
+func parseFeedDate(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range feedDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,114 @@
+package ebook
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Sites like Standard Ebooks and Project Gutenberg ask clients to identify
+// themselves
+const fetchUserAgent = "cozy-ebook-reader (https://github.com/cbrasser/cozy)"
+
+var fetchClient = &http.Client{Timeout: 5 * time.Minute}
+
+// bookTypes maps the content types servers send books with to extensions
+var bookTypes = map[string]string{
+	"application/epub+zip": ".epub",
+	"text/plain":           ".txt",
+}
+
+// FetchBook downloads a book from a URL into a library folder, organized
+// as Author/Title.ext after its metadata like ImportBook, and returns the
+// path of the new file. If the library already has an identical file,
+// ErrDuplicate is returned along with its path.
+func FetchBook(rawURL, libraryDir string, options ExportOptions) (string, error) {
+	link, err := url.Parse(rawURL)
+	if err != nil || (link.Scheme != "http" && link.Scheme != "https") || link.Host == "" {
+		return "", fmt.Errorf("not a web address: %s", rawURL)
+	}
+
+	request, err := http.NewRequest(http.MethodGet, link.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to download book: %w", err)
+	}
+	request.Header.Set("User-Agent", fetchUserAgent)
+	response, err := fetchClient.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to download book: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download book: %s", response.Status)
+	}
+
+	name := downloadName(response)
+	if !IsBookFile(name) {
+		mediaType, _, _ := mime.ParseMediaType(response.Header.Get("Content-Type"))
+		if mediaType == "text/html" {
+			return "", fmt.Errorf("%s is a web page, not a book; use the link of its EPUB download", rawURL)
+		}
+		return "", fmt.Errorf("not an ebook: %s", rawURL)
+	}
+
+	// Download to a temporary folder, so a failed import leaves nothing
+	// behind, under the download's own name, which books without a title
+	// are named after
+	dir, err := os.MkdirTemp("", "cozy-fetch-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to download book: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	temp, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return "", fmt.Errorf("failed to download book: %w", err)
+	}
+	_, err = io.Copy(temp, response.Body)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to download book: %w", err)
+	}
+
+	return ImportBook(temp.Name(), libraryDir, options)
+}
+
+// downloadName finds the file name of a download: the name the server
+// suggests, or the last part of the address it was downloaded from after
+// redirects, with an extension after its content type if it has none of a
+// book. The name is safe to create a file with.
+func downloadName(response *http.Response) string {
+	if _, params, err := mime.ParseMediaType(response.Header.Get("Content-Disposition")); err == nil {
+		if name := path.Base(filepath.ToSlash(params["filename"])); IsBookFile(name) {
+			return safeFileName(name)
+		}
+	}
+	name := path.Base(response.Request.URL.Path)
+	if IsBookFile(name) {
+		return safeFileName(name)
+	}
+	mediaType, _, _ := mime.ParseMediaType(response.Header.Get("Content-Type"))
+	ext, ok := bookTypes[mediaType]
+	if !ok {
+		return ""
+	}
+	if name == "/" || name == "." {
+		name = response.Request.URL.Hostname()
+	}
+	return BookFileName(strings.TrimSuffix(name, path.Ext(name)), "") + ext
+}
+
+// safeFileName keeps the extension of a file name and makes the rest of it
+// safe to create a file with, see BookFileName
+func safeFileName(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	return BookFileName(strings.TrimSuffix(name, filepath.Ext(name)), "") + ext
+}
@@ -0,0 +1,114 @@
+package ebook
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Golden files pin down how tricky HTML renders: a folder of .html files,
+// each next to a .golden file with the text it should render to. Rendering
+// is made the same on every machine by dropping styles, so a change to the
+// renderer shows up as a plain text diff. `cozy render -golden dir` checks
+// them, and `go test ./ebook` checks testdata/render; both take -update to
+// rewrite them.
+
+// GoldenWidth is the width golden files are rendered at
+const GoldenWidth = 60
+
+// NormalizeRender makes rendered text comparable: colors, styles and
+// other escape sequences are removed, as are spaces at the end of lines
+func NormalizeRender(text string) string {
+	lines := strings.Split(ansi.Strip(text), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// RenderNormalized renders HTML the same way whatever the terminal and the
+// theme, as normalized text
+func RenderNormalized(htmlContent string, width int, options RenderOptions) string {
+	theme := config.CozyDark
+	return NormalizeRender(RenderWithOptions(htmlContent, &theme, width, options).Text)
+}
+
+// GoldenMismatch is a golden file that no longer matches its rendering
+type GoldenMismatch struct {
+	Name string // The HTML file, without the folder
+	Line int    // First line that differs, from 1
+	Want string
+	Got  string
+}
+
+func (m GoldenMismatch) String() string {
+	return fmt.Sprintf("%s:%d: want %q, got %q", m.Name, m.Line, m.Want, m.Got)
+}
+
+// CheckGoldenFiles renders every .html file in a folder with the default
+// options and compares it with its .golden file. With update, golden files
+// are written instead, creating missing ones.
+func CheckGoldenFiles(dir string, update bool) ([]GoldenMismatch, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.html"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no .html files in %s", dir)
+	}
+	slices.Sort(paths)
+
+	var mismatches []GoldenMismatch
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		got := RenderNormalized(string(data), GoldenWidth, DefaultRenderOptions()) + "\n"
+
+		goldenPath := strings.TrimSuffix(path, ".html") + ".golden"
+		if update {
+			if err := os.WriteFile(goldenPath, []byte(got), 0644); err != nil {
+				return nil, fmt.Errorf("failed to write %s: %w", goldenPath, err)
+			}
+			continue
+		}
+
+		want, err := os.ReadFile(goldenPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", goldenPath, err)
+		}
+		if mismatch, ok := compareGolden(string(want), got); !ok {
+			mismatch.Name = filepath.Base(path)
+			mismatches = append(mismatches, mismatch)
+		}
+	}
+	return mismatches, nil
+}
+
+// compareGolden finds the first line where a rendering differs from its
+// golden file
+func compareGolden(want, got string) (GoldenMismatch, bool) {
+	if want == got {
+		return GoldenMismatch{}, true
+	}
+	wantLines, gotLines := strings.Split(want, "\n"), strings.Split(got, "\n")
+	for i := range max(len(wantLines), len(gotLines)) {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g || i >= len(wantLines) || i >= len(gotLines) {
+			return GoldenMismatch{Line: i + 1, Want: w, Got: g}, false
+		}
+	}
+	return GoldenMismatch{}, true
+}
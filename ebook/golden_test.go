@@ -0,0 +1,18 @@
+package ebook
+
+import (
+	"flag"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/render")
+
+func TestGoldenFiles(t *testing.T) {
+	mismatches, err := CheckGoldenFiles("testdata/render", *update)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mismatch := range mismatches {
+		t.Error(mismatch)
+	}
+}
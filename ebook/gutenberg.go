@@ -0,0 +1,154 @@
+package ebook
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	gutenbergStartPattern = regexp.MustCompile(`(?i)^\*{3}\s*START OF (THE|THIS) PROJECT GUTENBERG`)
+	gutenbergEndPattern   = regexp.MustCompile(`(?i)^(\*{3}\s*END OF (THE|THIS) PROJECT GUTENBERG|End of (the )?Project Gutenberg)`)
+	gutenbergFieldPattern = regexp.MustCompile(`^(Title|Author):\s*(.+)$`)
+
+	underscoreEmphasisPattern = regexp.MustCompile(`(^|[\s(\["'])_([^_\n]+?)_([\s.,;:!?)\]"']|$)`)
+	asteriskEmphasisPattern   = regexp.MustCompile(`(^|[\s(\["'])\*([^*\n]+?)\*([\s.,;:!?)\]"']|$)`)
+)
+
+// Lines shorter than this are not treated as hard-wrapped prose
+const minWrappedLineLength = 50
+
+// cleanGutenbergText strips the Project Gutenberg license header and
+// footer and unwraps hard-wrapped paragraphs. It returns the cleaned text
+// and any title and author found in the header.
+func cleanGutenbergText(content string) (string, string, string) {
+	lines := strings.Split(content, "\n")
+	title, author := "", ""
+
+	start, end := 0, len(lines)
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if start == 0 && gutenbergStartPattern.MatchString(trimmed) {
+			// Header fields only count if we actually found the header
+			for _, headerLine := range lines[:i] {
+				if match := gutenbergFieldPattern.FindStringSubmatch(strings.TrimSpace(headerLine)); match != nil {
+					if match[1] == "Title" && title == "" {
+						title = match[2]
+					} else if match[1] == "Author" && author == "" {
+						author = match[2]
+					}
+				}
+			}
+			start = i + 1
+			continue
+		}
+		if gutenbergEndPattern.MatchString(trimmed) {
+			end = i
+			break
+		}
+	}
+
+	return unwrapParagraphs(lines[start:end]), title, author
+}
+
+// unwrapParagraphs joins the lines of hard-wrapped paragraphs, keeping
+// line breaks in paragraphs that look deliberate (verse, lists, tables)
+func unwrapParagraphs(lines []string) string {
+	var out strings.Builder
+	var paragraph []string
+
+	flush := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		if isHardWrapped(paragraph) {
+			for i, line := range paragraph {
+				paragraph[i] = strings.TrimSpace(line)
+			}
+			out.WriteString(strings.Join(paragraph, " "))
+		} else {
+			out.WriteString(strings.Join(paragraph, "\n"))
+		}
+		out.WriteString("\n")
+		paragraph = nil
+	}
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			flush()
+			out.WriteString("\n")
+			continue
+		}
+		paragraph = append(paragraph, strings.TrimRight(line, " \t"))
+	}
+	flush()
+
+	return strings.Trim(out.String(), "\n") + "\n"
+}
+
+// isHardWrapped reports whether a paragraph's line breaks are just wrapping
+func isHardWrapped(lines []string) bool {
+	if len(lines) < 2 {
+		return false
+	}
+	for _, line := range lines[:len(lines)-1] {
+		// Indented or short lines suggest verse or other deliberate layout
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") ||
+			len([]rune(line)) < minWrappedLineLength {
+			return false
+		}
+	}
+	return true
+}
+
+// plainTextToHTML converts cleaned plain text into simple HTML, turning
+// _underscore_ and *asterisk* emphasis into <em> and chapter headings
+// into <h2>
+func plainTextToHTML(content string) string {
+	var out strings.Builder
+	out.WriteString("<html><body>\n")
+
+	for _, paragraph := range strings.Split(content, "\n\n") {
+		paragraph = strings.Trim(paragraph, "\n")
+		if strings.TrimSpace(paragraph) == "" {
+			continue
+		}
+
+		lines := strings.Split(paragraph, "\n")
+		for i, line := range lines {
+			lines[i] = convertEmphasis(strings.TrimSpace(line))
+		}
+
+		// A heading may be followed directly by its title line
+		first := strings.TrimSpace(strings.Split(paragraph, "\n")[0])
+		if len(lines) <= 2 && len(first) < 80 &&
+			(chapterHeadingPattern.MatchString(first) || numeralHeadingPattern.MatchString(first)) {
+			if len(lines) == 2 {
+				lines[0] = strings.TrimSuffix(lines[0], ".")
+			}
+			out.WriteString("<h2>" + strings.Join(lines, ": ") + "</h2>\n")
+			continue
+		}
+
+		out.WriteString("<p>" + strings.Join(lines, "<br/>") + "</p>\n")
+	}
+
+	out.WriteString("</body></html>\n")
+	return out.String()
+}
+
+// convertEmphasis HTML-escapes a line and replaces _text_ and *text*
+// markers with <em> tags
+func convertEmphasis(line string) string {
+	// Mark emphasis with control characters first so escaping leaves it alone;
+	// each pattern runs twice because adjacent matches share a boundary
+	for _, pattern := range []*regexp.Regexp{underscoreEmphasisPattern, asteriskEmphasisPattern} {
+		for range 2 {
+			line = pattern.ReplaceAllString(line, "$1\x01$2\x02$3")
+		}
+	}
+
+	line = html.EscapeString(line)
+	line = strings.ReplaceAll(line, "\x01", "<em>")
+	return strings.ReplaceAll(line, "\x02", "</em>")
+}
@@ -0,0 +1,179 @@
+package ebook
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Just enough of IMAP4rev1 (RFC 3501) to collect unread mail: log in,
+// select a mailbox, search for unseen messages, fetch them whole and mark
+// them seen. The connection is always TLS.
+
+const imapTimeout = time.Minute
+
+// imapClient is a logged-in connection to an IMAP server
+type imapClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	tag    int
+}
+
+// imapResponse is an untagged server response. Literals, such as fetched
+// messages, are kept apart from the text of the line.
+type imapResponse struct {
+	text     string
+	literals [][]byte
+}
+
+// dialIMAP connects to a server given as host or host:port and logs in
+func dialIMAP(server, user, password string) (*imapClient, error) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "993")
+	}
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: imapTimeout}, "tcp", server, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", server, err)
+	}
+	client := &imapClient{conn: conn, reader: bufio.NewReader(conn)}
+
+	conn.SetDeadline(time.Now().Add(imapTimeout))
+	greeting, err := client.readLine()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to connect to %s: %w", server, err)
+	}
+	if !strings.HasPrefix(greeting, "* OK") && !strings.HasPrefix(greeting, "* PREAUTH") {
+		conn.Close()
+		return nil, fmt.Errorf("%s refused the connection: %s", server, greeting)
+	}
+	if _, err := client.command("LOGIN %s %s", imapQuote(user), imapQuote(password)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to log in to %s: %w", server, err)
+	}
+	return client, nil
+}
+
+// close logs out and closes the connection
+func (c *imapClient) close() {
+	c.command("LOGOUT")
+	c.conn.Close()
+}
+
+// unseen selects a mailbox and returns the UIDs of its unseen messages
+func (c *imapClient) unseen(mailbox string) ([]string, error) {
+	if _, err := c.command("SELECT %s", imapQuote(mailbox)); err != nil {
+		return nil, fmt.Errorf("failed to open mailbox %s: %w", mailbox, err)
+	}
+	responses, err := c.command("UID SEARCH UNSEEN")
+	if err != nil {
+		return nil, fmt.Errorf("failed to search mailbox %s: %w", mailbox, err)
+	}
+	var uids []string
+	for _, response := range responses {
+		if rest, ok := strings.CutPrefix(response.text, "SEARCH"); ok {
+			uids = append(uids, strings.Fields(rest)...)
+		}
+	}
+	return uids, nil
+}
+
+// fetch returns a whole message without marking it seen
+func (c *imapClient) fetch(uid string) ([]byte, error) {
+	responses, err := c.command("UID FETCH %s BODY.PEEK[]", uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch message %s: %w", uid, err)
+	}
+	for _, response := range responses {
+		if strings.Contains(response.text, "FETCH") && len(response.literals) > 0 {
+			return response.literals[0], nil
+		}
+	}
+	return nil, fmt.Errorf("message %s not found", uid)
+}
+
+// markSeen marks a message seen, so it isn't collected again
+func (c *imapClient) markSeen(uid string) error {
+	if _, err := c.command(`UID STORE %s +FLAGS.SILENT (\Seen)`, uid); err != nil {
+		return fmt.Errorf("failed to mark message %s seen: %w", uid, err)
+	}
+	return nil
+}
+
+// command sends a command and returns the untagged responses, or an error
+// if the server doesn't answer OK
+func (c *imapClient) command(format string, args ...any) ([]imapResponse, error) {
+	c.tag++
+	tag := "A" + strconv.Itoa(c.tag)
+	c.conn.SetDeadline(time.Now().Add(imapTimeout))
+	if _, err := fmt.Fprintf(c.conn, "%s %s\r\n", tag, fmt.Sprintf(format, args...)); err != nil {
+		return nil, err
+	}
+
+	var responses []imapResponse
+	for {
+		response, err := c.readResponse()
+		if err != nil {
+			return nil, err
+		}
+		if rest, ok := strings.CutPrefix(response.text, "* "); ok {
+			response.text = rest
+			responses = append(responses, response)
+			continue
+		}
+		rest, ok := strings.CutPrefix(response.text, tag+" ")
+		if !ok {
+			continue // Continuation requests and the like
+		}
+		if !strings.HasPrefix(rest, "OK") {
+			return nil, fmt.Errorf("%s", rest)
+		}
+		return responses, nil
+	}
+}
+
+// readResponse reads a response, which goes on past the end of a line
+// ending in a literal: {size} announces that many bytes after the line
+// break, then more of the response
+func (c *imapClient) readResponse() (imapResponse, error) {
+	var response imapResponse
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return response, err
+		}
+		response.text += line
+		open := strings.LastIndex(line, "{")
+		if open < 0 || !strings.HasSuffix(line, "}") {
+			return response, nil
+		}
+		size, err := strconv.Atoi(strings.TrimSuffix(line[open+1:], "}"))
+		if err != nil {
+			return response, nil
+		}
+		literal := make([]byte, size)
+		if _, err := io.ReadFull(c.reader, literal); err != nil {
+			return response, err
+		}
+		response.literals = append(response.literals, literal)
+	}
+}
+
+// readLine reads a line without its line break
+func (c *imapClient) readLine() (string, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// imapQuote quotes a string for a command
+func imapQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,84 @@
+package ebook
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ImportBook copies a book into a library folder, organized as
+// Author/Title.ext after its metadata, and returns the path of the new
+// file. If the library already has an identical file, ErrDuplicate is
+// returned along with its path; another book of the same name gets a
+// numbered file name.
+func ImportBook(path, libraryDir string, options ExportOptions) (string, error) {
+	book, err := OpenBook(path, options.Book)
+	if err != nil {
+		return "", err
+	}
+	defer book.Close()
+
+	convert := options.ConvertText && book.Format == FormatText
+	ext := strings.ToLower(filepath.Ext(path))
+	if convert {
+		ext = ".epub"
+	}
+
+	author := book.Author
+	if author == "" {
+		author = "Unknown Author"
+	}
+	dir := filepath.Join(libraryDir, BookFileName(author, ""))
+	target := filepath.Join(dir, BookFileName(book.Title, "")+ext)
+
+	if !convert {
+		if existing, err := findDuplicate(path, libraryDir); err != nil {
+			return "", err
+		} else if existing != "" {
+			return existing, ErrDuplicate
+		}
+	} else if _, err := os.Stat(target); err == nil {
+		// Converted output differs from the source, so match on name only
+		return target, ErrDuplicate
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	target = uniquePath(target)
+	if convert {
+		return target, WriteEPUB(book, target)
+	}
+	return target, copyFile(path, target)
+}
+
+// FindBookFiles expands a list of files and folders into the book files
+// they contain
+func FindBookFiles(paths []string) ([]string, error) {
+	var books []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			books = append(books, path)
+			continue
+		}
+		err = filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil // Skip unreadable folders
+			}
+			if !d.IsDir() && IsBookFile(file) {
+				books = append(books, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return books, nil
+}
@@ -0,0 +1,483 @@
+package ebook
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/reflow/wordwrap"
+	"golang.org/x/net/html"
+)
+
+// Text is laid out a paragraph at a time. The text nodes of a block are
+// collected as inline runs, each with the styles of the elements around
+// it, and joined with the spaces between them as HTML collapses them. The
+// paragraph is wrapped as one text, and the styles put back on the words
+// of each line afterwards, so "some<em>thing</em> else" reads as
+// "something else".
+
+// columnPlaceholder stands in for text already on the line a paragraph
+// starts on, such as a list bullet, while the paragraph is wrapped
+const columnPlaceholder = '\ue001' // Private use
+
+// inlineRun is text with the styles of the elements around it
+type inlineRun struct {
+	text string
+	ctx  renderContext
+}
+
+// inlineElements don't break the text they are in
+var inlineElements = map[string]bool{
+	"a": true, "abbr": true, "acronym": true, "b": true, "bdi": true, "bdo": true,
+	"big": true, "cite": true, "code": true, "data": true, "del": true, "dfn": true,
+	"em": true, "font": true, "i": true, "img": true, "ins": true, "kbd": true,
+	"label": true, "mark": true, "q": true, "ruby": true, "s": true, "samp": true,
+	"small": true, "span": true, "strike": true, "strong": true, "sub": true,
+	"sup": true, "time": true, "tt": true, "u": true, "var": true, "wbr": true,
+}
+
+// isInline reports whether an element flows with the text around it
+func isInline(n *html.Node) bool {
+	if localName(n) == "math" {
+		return attribute(n, "display") != "block"
+	}
+	return inlineElements[n.Data]
+}
+
+// addText adds a text node to the paragraph. Runs of whitespace become a
+// single space, or a line break in verse.
+func (r *Renderer) addText(n *html.Node, ctx *renderContext) {
+	text := n.Data
+	if !ctx.inPre {
+		text = collapseSpace(text, ctx.inVerse)
+	}
+	if strings.TrimSpace(text) != "" {
+		if r.options.SmartTypography && !ctx.inCode {
+			text = r.smarten(text, strings.HasPrefix(text, " "))
+		}
+		if r.options.ASCII {
+			text = Transliterate(text)
+		}
+		if !ctx.inCode {
+			text = spaceText(text, r.options)
+		}
+		r.lastRune = lastRuneOf(text)
+	}
+	r.addRun(text, ctx)
+}
+
+// addRun adds text to the paragraph as it is
+func (r *Renderer) addRun(text string, ctx *renderContext) {
+	if text != "" {
+		r.list.runs = append(r.list.runs, inlineRun{text: text, ctx: *ctx})
+	}
+}
+
+// collapseSpace turns each run of whitespace into a space, keeping line
+// breaks in verse
+func collapseSpace(text string, verse bool) string {
+	if !verse {
+		return collapseLine(text)
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		// Spaces around line breaks go, the ones next to other runs stay
+		line = collapseLine(line)
+		if i > 0 {
+			line = strings.TrimLeft(line, " ")
+		}
+		if i < len(lines)-1 {
+			line = strings.TrimRight(line, " ")
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// collapseLine turns each run of whitespace into a space. No-break spaces
+// are kept.
+func collapseLine(text string) string {
+	var b strings.Builder
+	space := false
+	for _, c := range text {
+		if unicode.IsSpace(c) && c != nbsp && c != '　' {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(c)
+	}
+	if space {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
+
+// writeStyledText adds text as a paragraph of its own
+func (r *Renderer) writeStyledText(text string, ctx *renderContext) {
+	r.addRun(text, ctx)
+	r.flush()
+}
+
+// flush ends the paragraph collected so far
+func (r *Renderer) flush() {
+	r.lastRune = ' ' // Whatever follows starts a new block
+	if len(r.list.runs) == 0 {
+		return
+	}
+	runs := r.list.runs
+	r.list.runs = nil
+	r.list.add(block{kind: paragraphBlock, runs: runs})
+}
+
+// writeBlock lays out and writes a paragraph, and returns where each of
+// its runs starts and ends in the output, or -1 for runs left out
+func (r *Renderer) writeBlock(out *strings.Builder, runs []inlineRun) (starts, ends []int) {
+	start := out.Len()
+	starts, ends = make([]int, len(runs)), make([]int, len(runs))
+	for i := range runs {
+		starts[i], ends[i] = -1, -1
+	}
+	if runs[0].ctx.inPre {
+		r.writePre(out, runs)
+		for i := range runs {
+			starts[i], ends[i] = start, out.Len()
+		}
+	} else {
+		r.writeParagraph(out, runs, starts, ends)
+	}
+	return starts, ends
+}
+
+// writePre writes preformatted text as one block, keeping its whitespace
+func (r *Renderer) writePre(out *strings.Builder, runs []inlineRun) {
+	var text strings.Builder
+	for _, run := range runs {
+		text.WriteString(run.text)
+	}
+	style := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(r.theme.CodeTextColor)).
+		Background(lipgloss.Color(r.theme.CodeBgColor)).
+		Padding(0, 1)
+	// Wrap code blocks (account for padding = 2 chars)
+	wrapped := wordwrap.String(text.String(), max(r.textWidth()-2, 40))
+	out.WriteString(style.Render(wrapped))
+}
+
+// textWidth returns the width text is wrapped at
+func (r *Renderer) textWidth() int {
+	if r.width <= 0 {
+		return 80
+	}
+	return r.width
+}
+
+// atLineStart reports whether the next text starts a new line
+func atLineStart(out *strings.Builder) bool {
+	return out.Len() == 0 || strings.HasSuffix(out.String(), "\n")
+}
+
+// isLayoutSpace reports whether a character may be added or removed while
+// laying out a paragraph
+func isLayoutSpace(c rune) bool {
+	return unicode.IsSpace(c) || c == nbspPlaceholder
+}
+
+// writeParagraph wraps the runs of a paragraph and writes them styled,
+// recording where each run starts and ends in the output
+func (r *Renderer) writeParagraph(out *strings.Builder, runs []inlineRun, starts, ends []int) {
+	level := runs[0].ctx.inHeading
+	if level == 0 {
+		r.writeRuns(out, runs, starts, ends)
+		return
+	}
+
+	// The heading's level goes first, as a run of its own
+	prefix := inlineRun{text: strings.Repeat("#", level) + " ", ctx: runs[0].ctx}
+	headingStarts, headingEnds := append([]int{-1}, starts...), append([]int{-1}, ends...)
+	r.writeRuns(out, append([]inlineRun{prefix}, runs...), headingStarts, headingEnds)
+	copy(starts, headingStarts[1:])
+	copy(ends, headingEnds[1:])
+}
+
+// writeRuns lays out the runs of a paragraph and writes them
+func (r *Renderer) writeRuns(out *strings.Builder, runs []inlineRun, starts, ends []int) {
+	ctx := runs[0].ctx
+
+	// Join the runs, with a single space where they meet, and remember
+	// which run each character that isn't a space belongs to
+	var text strings.Builder
+	var owners []int
+	for i, run := range runs {
+		t := run.text
+		if joined := text.String(); joined == "" || strings.HasSuffix(joined, " ") || strings.HasSuffix(joined, "\n") {
+			t = strings.TrimLeft(t, " ")
+		}
+		if text.Len() == 0 {
+			t = strings.TrimLeft(t, " \n")
+		}
+		for _, c := range t {
+			if !isLayoutSpace(c) {
+				owners = append(owners, i)
+			}
+		}
+		text.WriteString(t)
+	}
+	paragraph := strings.TrimRight(text.String(), " \n")
+	if strings.TrimSpace(paragraph) == "" {
+		return
+	}
+	// Keep words joined by no-break spaces on one line
+	paragraph = strings.ReplaceAll(paragraph, string(nbsp), string(nbspPlaceholder))
+
+	width := r.textWidth()
+	var laid string
+	switch {
+	case ctx.inBlockquote:
+		// Account for the border and padding
+		width = max(width-4, 40)
+		if ctx.inVerse {
+			laid = verseText(paragraph, width, true)
+		} else {
+			laid = WrapText(paragraph, width)
+		}
+	case ctx.inHeading > 0:
+		laid = WrapText(paragraph, width)
+	case ctx.inVerse:
+		laid = verseText(paragraph, width, atLineStart(out))
+	default:
+		// The first line goes on after what is already on the line. Lines
+		// of a list item line up after its bullet.
+		column, indent := 0, 0
+		if ctx.inListItem && !ctx.rtl {
+			indent = min(ctx.listIndent, width/2)
+		}
+		startsLine := atLineStart(out)
+		if !startsLine && !ctx.rtl {
+			current := out.String()
+			column = max(ansi.StringWidth(current[strings.LastIndexByte(current, '\n')+1:])-indent, 0)
+		}
+		marker := strings.Repeat(string(columnPlaceholder), column)
+		laid = WrapText(marker+paragraph, width-indent)
+		if r.options.Justify && (ctx.align == "" || ctx.align == "left") {
+			laid = justifyText(laid, width-indent)
+		}
+		laid = strings.Replace(laid, marker, "", 1)
+		if indent > 0 {
+			padding := strings.Repeat(" ", indent)
+			laid = strings.ReplaceAll(laid, "\n", "\n"+padding)
+			if startsLine {
+				laid = padding + laid
+			}
+		}
+	}
+
+	// Put the styles back on the characters of each line
+	styles := make([]*lipgloss.Style, len(runs))
+	owner := 0
+	for i, line := range strings.Split(laid, "\n") {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		cells := make([]styledCell, 0, len(line))
+		for _, c := range line {
+			cell := styledCell{r: c, run: -1}
+			if !isLayoutSpace(c) && owner < len(owners) {
+				cell.run = owners[owner]
+				owner++
+			}
+			cells = append(cells, cell)
+		}
+		cells = orderCells(cells, ctx.rtl, width)
+		if !ctx.inBlockquote {
+			cells = alignCells(cells, ctx.align, width)
+		}
+		if len(cells) == 0 {
+			continue
+		}
+		if ctx.inBlockquote {
+			border := lipgloss.NewStyle().Foreground(lipgloss.Color(r.theme.QuoteBorderColor))
+			out.WriteString(border.Render(r.quoteBorder().Left) + " ")
+		}
+		r.writeCells(out, cells, runs, styles, starts, ends)
+	}
+}
+
+// styledCell is a character of a laid out line and the run it belongs to,
+// or -1 for spaces between runs
+type styledCell struct {
+	r   rune
+	run int
+}
+
+// orderCells puts a line into display order, aligning it to the right
+// edge in right-to-left paragraphs
+func orderCells(cells []styledCell, rtl bool, width int) []styledCell {
+	runes := make([]rune, len(cells))
+	for i, cell := range cells {
+		runes[i] = cell.r
+	}
+	if !rtl && !hasRTL(string(runes)) {
+		return cells
+	}
+	if rtl {
+		cells = trimCells(cells)
+		if len(cells) == 0 {
+			return cells
+		}
+		runes = runes[:0]
+		for _, cell := range cells {
+			runes = append(runes, cell.r)
+		}
+	}
+
+	order, levels := displayOrder(runes, rtl)
+	ordered := make([]styledCell, len(cells))
+	for i, j := range order {
+		ordered[i] = styledCell{r: mirror(runes[j], levels[j]), run: cells[j].run}
+	}
+	if rtl {
+		ordered = padCells(ordered, width-cellsWidth(ordered))
+	}
+	return ordered
+}
+
+// alignCells centers a line or aligns it to the right edge
+func alignCells(cells []styledCell, align string, width int) []styledCell {
+	if align != "center" && align != "right" {
+		return cells
+	}
+	cells = trimCells(cells)
+	if len(cells) == 0 {
+		return cells
+	}
+	padding := max(0, width-cellsWidth(cells))
+	if align == "center" {
+		padding /= 2
+	}
+	return padCells(cells, padding)
+}
+
+// trimCells removes the spaces around a line
+func trimCells(cells []styledCell) []styledCell {
+	for len(cells) > 0 && unicode.IsSpace(cells[0].r) {
+		cells = cells[1:]
+	}
+	for len(cells) > 0 && unicode.IsSpace(cells[len(cells)-1].r) {
+		cells = cells[:len(cells)-1]
+	}
+	return cells
+}
+
+// padCells puts spaces before a line
+func padCells(cells []styledCell, padding int) []styledCell {
+	padded := make([]styledCell, 0, len(cells)+max(padding, 0))
+	for range padding {
+		padded = append(padded, styledCell{r: ' ', run: -1})
+	}
+	return append(padded, cells...)
+}
+
+// cellsWidth returns the display width of a line
+func cellsWidth(cells []styledCell) int {
+	var b strings.Builder
+	for _, cell := range cells {
+		b.WriteRune(cell.r)
+	}
+	return ansi.StringWidth(b.String())
+}
+
+// writeCells writes a line, each stretch of one run in its style. Spaces
+// between words of a run take its style, so links stay underlined.
+func (r *Renderer) writeCells(out *strings.Builder, cells []styledCell, runs []inlineRun, styles []*lipgloss.Style, starts, ends []int) {
+	space := r.glyph(string(nbsp), " ")
+	for i := 0; i < len(cells); {
+		run := cells[i].run
+		j := i + 1
+		if run < 0 {
+			for j < len(cells) && cells[j].run < 0 {
+				j++
+			}
+		} else {
+			// Take spaces as long as the same run follows them
+			for k := j; k < len(cells); k++ {
+				if cells[k].run == run {
+					j = k + 1
+				} else if cells[k].run >= 0 {
+					break
+				}
+			}
+		}
+
+		var chunk strings.Builder
+		for _, cell := range cells[i:j] {
+			chunk.WriteRune(cell.r)
+		}
+		text := strings.ReplaceAll(chunk.String(), string(nbspPlaceholder), space)
+
+		if run < 0 {
+			out.WriteString(text)
+		} else {
+			if styles[run] == nil {
+				style := r.runStyle(&runs[run].ctx)
+				styles[run] = &style
+			}
+			if starts[run] < 0 {
+				starts[run] = out.Len()
+			}
+			out.WriteString(styles[run].Render(text))
+			ends[run] = out.Len()
+		}
+		i = j
+	}
+}
+
+// runStyle returns the style of text in a context
+func (r *Renderer) runStyle(ctx *renderContext) lipgloss.Style {
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color(r.theme.TextColor))
+
+	switch {
+	case ctx.inHeading > 0:
+		style = style.
+			Foreground(lipgloss.Color(r.theme.HeadingColor)).
+			Bold(true)
+	case ctx.inBlockquote:
+		style = style.
+			Foreground(lipgloss.Color(r.theme.MutedTextColor)).
+			Italic(true)
+	}
+
+	if ctx.inCode {
+		return style.
+			Foreground(lipgloss.Color(r.theme.CodeTextColor)).
+			Background(lipgloss.Color(r.theme.CodeBgColor))
+	}
+
+	// Apply inline formatting
+	if ctx.inEmphasis {
+		style = style.
+			Foreground(lipgloss.Color(r.theme.EmphasisColor)).
+			Italic(true)
+	}
+
+	if ctx.inStrong {
+		style = style.
+			Foreground(lipgloss.Color(r.theme.StrongColor)).
+			Bold(true)
+	}
+
+	if ctx.inMath {
+		style = style.Foreground(lipgloss.Color(r.theme.CodeTextColor))
+	}
+
+	if ctx.inLink {
+		style = style.
+			Foreground(lipgloss.Color(r.theme.LinkColor)).
+			Underline(true)
+	}
+	return style
+}
@@ -0,0 +1,74 @@
+package ebook
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ReadingState is how far another reader got in a book, for importing
+// into cozy's progress
+type ReadingState struct {
+	Path     string  // The book file the state was recorded for
+	Percent  float64 // 0-100
+	Finished bool
+}
+
+// KOReader keeps the state of each book in a sidecar folder next to it,
+// Book.sdr/metadata.epub.lua, holding a Lua table. Only a few fields of it
+// are needed, so they are picked out with patterns rather than parsing Lua.
+var (
+	koreaderPercent = regexp.MustCompile(`\["percent_finished"\]\s*=\s*([0-9.eE+-]+)`)
+	koreaderStatus  = regexp.MustCompile(`\["status"\]\s*=\s*"(\w+)"`)
+)
+
+// FindKOReaderStates looks for KOReader sidecars in a folder and the
+// folders inside it, such as a copy of an e-reader's book folder
+func FindKOReaderStates(dir string) ([]ReadingState, error) {
+	var states []ReadingState
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip unreadable folders
+		}
+		if !entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sdr") {
+			return nil
+		}
+		if state, ok := readKOReaderSidecar(path); ok {
+			states = append(states, state)
+		}
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s: %w", dir, err)
+	}
+	return states, nil
+}
+
+// readKOReaderSidecar reads the state in a sidecar folder. The book's
+// extension is only known from the name of the metadata file.
+func readKOReaderSidecar(sdr string) (ReadingState, bool) {
+	matches, _ := filepath.Glob(filepath.Join(sdr, "metadata.*.lua"))
+	for _, metadata := range matches {
+		ext := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(metadata), "metadata."), ".lua")
+		data, err := os.ReadFile(metadata)
+		if err != nil {
+			continue
+		}
+		state := ReadingState{Path: strings.TrimSuffix(sdr, ".sdr") + "." + ext}
+		if match := koreaderPercent.FindSubmatch(data); match != nil {
+			fraction, _ := strconv.ParseFloat(string(match[1]), 64)
+			state.Percent = clampPercent(fraction * 100)
+		}
+		if match := koreaderStatus.FindSubmatch(data); match != nil {
+			state.Finished = string(match[1]) == "complete"
+		}
+		if state.Percent > 0 || state.Finished {
+			return state, true
+		}
+	}
+	return ReadingState{}, false
+}
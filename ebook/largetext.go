@@ -0,0 +1,257 @@
+package ebook
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"golang.org/x/net/html"
+)
+
+// Large text is for reading from a distance. Terminals that support the
+// VT100 line sizes, such as xterm, Konsole and mlterm, can draw a line at
+// double width, or double width and height over two rows. The "double"
+// style uses them for headings and, with large text everywhere, for every
+// line. The "banner" style draws headings in block letters instead, which
+// works in any terminal but leaves the running text as it is.
+
+// VT100 line sizes, written at the start of a line
+const (
+	DoubleTop    = "\x1b#3" // Upper half of a double height line
+	DoubleBottom = "\x1b#4" // Lower half of a double height line
+	SingleWidth  = "\x1b#5"
+	DoubleWidth  = "\x1b#6"
+)
+
+// DoubleWidth reports whether every line is drawn at double width
+func (o RenderOptions) DoubleWidth() bool {
+	return o.LargeText == "all" && o.LargeTextStyle != "banner"
+}
+
+// largeHeadings reports whether headings are drawn large
+func (o RenderOptions) largeHeadings() bool {
+	return o.LargeText == "headings" || o.LargeText == "all"
+}
+
+// widenLines draws the lines of rendered text at double width
+func widenLines(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" && !strings.HasPrefix(line, "\x1b#") {
+			lines[i] = DoubleWidth + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderLargeHeading collects the content of a heading drawn large as a
+// block of its own
+func (r *Renderer) renderLargeHeading(n *html.Node, ctx *renderContext) {
+	list := r.list
+	r.list = newBlockList()
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.renderNode(c, ctx)
+	}
+	r.flush()
+	children := r.list
+	r.list = list
+	r.list.add(block{kind: largeHeadingBlock, text: textContent(n), children: children})
+}
+
+// writeLargeHeading lays out a large heading and returns where its
+// anchors and links ended up
+func (r *Renderer) writeLargeHeading(out *strings.Builder, b block) laidOut {
+	if r.options.LargeTextStyle == "banner" {
+		if text, ok := bannerText(b.text, r.width); ok {
+			style := lipgloss.NewStyle().Foreground(lipgloss.Color(r.theme.HeadingColor))
+			out.WriteString(style.Render(text))
+			return laidOut{}
+		}
+	}
+
+	// The heading is laid out on its own, at half the width unless all
+	// text already is, and each line written as a top and a bottom half
+	width := r.width
+	if !r.options.DoubleWidth() {
+		r.width = max(width/2, 10)
+	}
+	var heading strings.Builder
+	placed := r.layout(b.children, &heading)
+	r.width = width
+
+	lines := strings.Split(heading.String(), "\n")
+	headingStarts, outStarts := make([]int, len(lines)), make([]int, len(lines))
+	offset := 0
+	for i, line := range lines {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		headingStarts[i], outStarts[i] = offset, out.Len()
+		offset += len(line) + 1
+		if strings.TrimSpace(ansi.Strip(line)) == "" {
+			out.WriteString(line)
+			continue
+		}
+		outStarts[i] += len(DoubleTop)
+		out.WriteString(DoubleTop + line + "\n" + DoubleBottom + line)
+	}
+
+	// Anchors and links in the heading point into the top halves
+	position := func(offset int) int {
+		line := 0
+		for line+1 < len(lines) && headingStarts[line+1] <= offset {
+			line++
+		}
+		return outStarts[line] + offset - headingStarts[line]
+	}
+	for id, offset := range placed.anchors {
+		placed.anchors[id] = position(offset)
+	}
+	for i, link := range placed.links {
+		placed.links[i].start, placed.links[i].end = position(link.start), position(link.end)
+	}
+	return placed
+}
+
+// Block letters are drawn from five rows of pixels, two to a line of
+// half blocks
+var bannerFont = map[rune][5]string{
+	'A':  {".###.", "#...#", "#####", "#...#", "#...#"},
+	'B':  {"####.", "#...#", "####.", "#...#", "####."},
+	'C':  {".####", "#....", "#....", "#....", ".####"},
+	'D':  {"####.", "#...#", "#...#", "#...#", "####."},
+	'E':  {"#####", "#....", "####.", "#....", "#####"},
+	'F':  {"#####", "#....", "####.", "#....", "#...."},
+	'G':  {".####", "#....", "#..##", "#...#", ".####"},
+	'H':  {"#...#", "#...#", "#####", "#...#", "#...#"},
+	'I':  {"#####", "..#..", "..#..", "..#..", "#####"},
+	'J':  {"..###", "...#.", "...#.", "#..#.", ".##.."},
+	'K':  {"#...#", "#..#.", "###..", "#..#.", "#...#"},
+	'L':  {"#....", "#....", "#....", "#....", "#####"},
+	'M':  {"#...#", "##.##", "#.#.#", "#...#", "#...#"},
+	'N':  {"#...#", "##..#", "#.#.#", "#..##", "#...#"},
+	'O':  {".###.", "#...#", "#...#", "#...#", ".###."},
+	'P':  {"####.", "#...#", "####.", "#....", "#...."},
+	'Q':  {".###.", "#...#", "#.#.#", "#..#.", ".##.#"},
+	'R':  {"####.", "#...#", "####.", "#..#.", "#...#"},
+	'S':  {".####", "#....", ".###.", "....#", "####."},
+	'T':  {"#####", "..#..", "..#..", "..#..", "..#.."},
+	'U':  {"#...#", "#...#", "#...#", "#...#", ".###."},
+	'V':  {"#...#", "#...#", "#...#", ".#.#.", "..#.."},
+	'W':  {"#...#", "#...#", "#.#.#", "##.##", "#...#"},
+	'X':  {"#...#", ".#.#.", "..#..", ".#.#.", "#...#"},
+	'Y':  {"#...#", ".#.#.", "..#..", "..#..", "..#.."},
+	'Z':  {"#####", "...#.", "..#..", ".#...", "#####"},
+	'0':  {".###.", "#..##", "#.#.#", "##..#", ".###."},
+	'1':  {"..#..", ".##..", "..#..", "..#..", ".###."},
+	'2':  {".###.", "#...#", "..##.", ".#...", "#####"},
+	'3':  {"####.", "....#", ".###.", "....#", "####."},
+	'4':  {"#..#.", "#..#.", "#####", "...#.", "...#."},
+	'5':  {"#####", "#....", "####.", "....#", "####."},
+	'6':  {".###.", "#....", "####.", "#...#", ".###."},
+	'7':  {"#####", "....#", "...#.", "..#..", "..#.."},
+	'8':  {".###.", "#...#", ".###.", "#...#", ".###."},
+	'9':  {".###.", "#...#", ".####", "....#", ".###."},
+	' ':  {"...", "...", "...", "...", "..."},
+	'.':  {".", ".", ".", ".", "#"},
+	',':  {"..", "..", "..", ".#", "#."},
+	':':  {".", "#", ".", "#", "."},
+	';':  {"..", ".#", "..", ".#", "#."},
+	'!':  {"#", "#", "#", ".", "#"},
+	'?':  {".###.", "#...#", "..##.", ".....", "..#.."},
+	'-':  {"...", "...", "###", "...", "..."},
+	'\'': {"#", "#", ".", ".", "."},
+	'"':  {"#.#", "#.#", "...", "...", "..."},
+	'(':  {".#", "#.", "#.", "#.", ".#"},
+	')':  {"#.", ".#", ".#", ".#", "#."},
+	'&':  {".##..", "#..#.", ".##.#", "#..#.", ".##.#"},
+	'/':  {"....#", "...#.", "..#..", ".#...", "#...."},
+}
+
+// Typographic marks drawn as their plain counterparts
+var bannerSubstitutes = strings.NewReplacer("‘", "'", "’", "'", "“", `"`, "”", `"`, "–", "-", "—", "-", "…", "...")
+
+// bannerText draws text in block letters, wrapped at width. It reports
+// false when the text has letters the font lacks.
+func bannerText(text string, width int) (string, bool) {
+	text = strings.ToUpper(bannerSubstitutes.Replace(strings.Join(strings.Fields(text), " ")))
+	if text == "" {
+		return "", false
+	}
+	for _, r := range text {
+		if _, ok := bannerFont[r]; !ok {
+			return "", false
+		}
+	}
+
+	// Words are fitted to the width by the columns of their letters
+	var lines [][]rune
+	var line []rune
+	for _, word := range strings.Fields(text) {
+		candidate := append(append([]rune{}, line...), []rune(word)...)
+		if len(line) > 0 {
+			candidate = append(append(append([]rune{}, line...), ' '), []rune(word)...)
+		}
+		if len(line) > 0 && bannerWidth(candidate) > width {
+			lines = append(lines, line)
+			candidate = []rune(word)
+		}
+		line = candidate
+	}
+	lines = append(lines, line)
+
+	var b strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(bannerLine(line))
+	}
+	return b.String(), true
+}
+
+// bannerWidth returns the columns a line of block letters takes
+func bannerWidth(text []rune) int {
+	width := 0
+	for _, r := range text {
+		width += len(bannerFont[r][0]) + 1
+	}
+	return max(width-1, 0)
+}
+
+// bannerLine draws one line of block letters as three lines of half blocks
+func bannerLine(text []rune) string {
+	var rows [5]strings.Builder
+	for i, r := range text {
+		for row, pixels := range bannerFont[r] {
+			if i > 0 {
+				rows[row].WriteByte('.')
+			}
+			rows[row].WriteString(pixels)
+		}
+	}
+
+	var lines []string
+	for row := 0; row < len(rows); row += 2 {
+		top, bottom := rows[row].String(), strings.Repeat(".", rows[row].Len())
+		if row+1 < len(rows) {
+			bottom = rows[row+1].String()
+		}
+		var line strings.Builder
+		for col := range top {
+			switch {
+			case top[col] == '#' && bottom[col] == '#':
+				line.WriteRune('█')
+			case top[col] == '#':
+				line.WriteRune('▀')
+			case bottom[col] == '#':
+				line.WriteRune('▄')
+			default:
+				line.WriteRune(' ')
+			}
+		}
+		lines = append(lines, strings.TrimRightFunc(line.String(), unicode.IsSpace))
+	}
+	return strings.Join(lines, "\n")
+}
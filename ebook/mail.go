@@ -0,0 +1,324 @@
+package ebook
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cbrasser/cozy/config"
+)
+
+// Books can be sent to cozy by email, like sending to a Kindle: mail
+// delivered to a maildir or an IMAP mailbox is checked for book
+// attachments, which are imported into the library under a folder named
+// after the sender. Folder names are tags, so the books can be found by
+// who sent them. Mail that was looked at is marked seen and not looked at
+// again.
+
+// Largest email read, attachments included
+const maxMailSize = 50 << 20
+
+// Mail is an email with the books attached to it
+type Mail struct {
+	From    *mail.Address
+	Subject string
+	Books   []MailBook
+}
+
+// MailBook is a book attached to an email
+type MailBook struct {
+	Name string // File name the attachment was sent with
+	Data []byte
+}
+
+// MailResult reports a check for books sent by email
+type MailResult struct {
+	Imported   []string // Paths of the books imported
+	Duplicates int      // Books the library already had
+	Ignored    int      // Mail from senders not in allowed_senders
+	Failed     []error  // Mail or books that could not be read
+}
+
+// ParseMail reads an email and collects its book attachments
+func ParseMail(r io.Reader) (*Mail, error) {
+	message, err := mail.ReadMessage(io.LimitReader(r, maxMailSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read email: %w", err)
+	}
+	decoder := &mime.WordDecoder{}
+	parsed := &Mail{}
+	parsed.Subject, err = decoder.DecodeHeader(message.Header.Get("Subject"))
+	if err != nil {
+		parsed.Subject = message.Header.Get("Subject")
+	}
+	if from, err := message.Header.AddressList("From"); err == nil && len(from) > 0 {
+		parsed.From = from[0]
+	}
+
+	part := mailPart{header: textproto.MIMEHeader(message.Header), body: message.Body}
+	if err := parsed.collectBooks(part, decoder); err != nil {
+		return nil, fmt.Errorf("failed to read email: %w", err)
+	}
+	return parsed, nil
+}
+
+// mailPart is the header and body of a message or of a part of one
+type mailPart struct {
+	header textproto.MIMEHeader
+	body   io.Reader
+}
+
+// collectBooks walks the parts of a message, nested multiparts included,
+// and keeps the attachments that are books
+func (m *Mail) collectBooks(part mailPart, decoder *mime.WordDecoder) error {
+	mediaType, params, err := mime.ParseMediaType(part.header.Get("Content-Type"))
+	if err != nil {
+		mediaType = "text/plain"
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(part.body, params["boundary"])
+		for {
+			// NextPart undoes quoted-printable, but leaves base64 alone
+			next, err := reader.NextPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := m.collectBooks(mailPart{header: next.Header, body: next}, decoder); err != nil {
+				return err
+			}
+		}
+	}
+
+	name := attachmentName(part, params, decoder)
+	if !IsBookFile(name) {
+		return nil
+	}
+	data, err := io.ReadAll(part.body)
+	if err != nil {
+		return fmt.Errorf("failed to read attachment %s: %w", name, err)
+	}
+	switch strings.ToLower(strings.TrimSpace(part.header.Get("Content-Transfer-Encoding"))) {
+	case "base64":
+		// Base64 bodies are wrapped into lines
+		data, err = base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(data)), ""))
+	case "quoted-printable":
+		data, err = io.ReadAll(quotedprintable.NewReader(bytes.NewReader(data)))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read attachment %s: %w", name, err)
+	}
+	m.Books = append(m.Books, MailBook{Name: filepath.Base(name), Data: data})
+	return nil
+}
+
+// attachmentName returns the file name of a part, from Content-Disposition
+// or else the name in Content-Type
+func attachmentName(part mailPart, params map[string]string, decoder *mime.WordDecoder) string {
+	name := params["name"]
+	if _, disposition, err := mime.ParseMediaType(part.header.Get("Content-Disposition")); err == nil && disposition["filename"] != "" {
+		name = disposition["filename"]
+	}
+	if decoded, err := decoder.DecodeHeader(name); err == nil {
+		name = decoded
+	}
+	return strings.ReplaceAll(name, "\\", "/")
+}
+
+// CheckMail imports the books attached to new mail in the maildir and
+// IMAP mailbox set in [mail] into the library. Mail that can't be read is
+// listed in the result and left to be tried again.
+func CheckMail(cfg *config.Config, options ExportOptions) (MailResult, error) {
+	var result MailResult
+	dir := cfg.MailPath()
+	if dir == "" {
+		return result, errors.New("no mail folder: set library.path and mail.folder")
+	}
+	if cfg.Mail.Maildir == "" && cfg.Mail.IMAPServer == "" {
+		return result, errors.New("no mailbox: set mail.maildir or mail.imap_server")
+	}
+
+	importMail := func(data []byte) error {
+		message, err := ParseMail(bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		return importMailBooks(cfg, message, dir, options, &result)
+	}
+	if cfg.Mail.Maildir != "" {
+		if err := checkMaildir(expandHome(cfg.Mail.Maildir), importMail, &result); err != nil {
+			return result, err
+		}
+	}
+	if cfg.Mail.IMAPServer != "" {
+		if err := checkIMAP(cfg.Mail, importMail, &result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// importMailBooks imports the books of an email from an allowed sender
+// into the sender's folder
+func importMailBooks(cfg *config.Config, message *Mail, dir string, options ExportOptions, result *MailResult) error {
+	if len(message.Books) == 0 {
+		return nil
+	}
+	if !allowedSender(cfg.Mail.AllowedSenders, message.From) {
+		result.Ignored++
+		return nil
+	}
+	sender := "Unknown Sender"
+	if message.From != nil {
+		sender = message.From.Name
+		if sender == "" {
+			sender = message.From.Address
+		}
+	}
+	senderDir := filepath.Join(dir, BookFileName(sender, ""))
+
+	var failed []error
+	for _, book := range message.Books {
+		target, err := importMailBook(book, cfg.Library.Path, senderDir, options)
+		switch {
+		case errors.Is(err, ErrDuplicate):
+			result.Duplicates++
+		case err != nil:
+			failed = append(failed, fmt.Errorf("%s: %w", book.Name, err))
+		default:
+			result.Imported = append(result.Imported, target)
+		}
+	}
+	return errors.Join(failed...)
+}
+
+// importMailBook saves an attachment to a temporary folder under its own
+// name, which books without a title are named after, and imports it,
+// unless the library already has it
+func importMailBook(book MailBook, libraryDir, dir string, options ExportOptions) (string, error) {
+	temp, err := os.MkdirTemp("", "cozy-mail-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to save attachment: %w", err)
+	}
+	defer os.RemoveAll(temp)
+	path := filepath.Join(temp, safeFileName(filepath.Base(filepath.FromSlash(book.Name))))
+	if err := os.WriteFile(path, book.Data, 0644); err != nil {
+		return "", fmt.Errorf("failed to save attachment: %w", err)
+	}
+
+	// Look through the whole library, not just the sender's folder
+	if existing, err := findDuplicate(path, libraryDir); err != nil {
+		return "", err
+	} else if existing != "" {
+		return existing, ErrDuplicate
+	}
+	return ImportBook(path, dir, options)
+}
+
+// allowedSender reports whether books are taken from a sender: anyone if
+// no senders are listed, otherwise a listed address or anyone at a listed
+// @domain
+func allowedSender(allowed []string, from *mail.Address) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	if from == nil {
+		return false
+	}
+	address := strings.ToLower(from.Address)
+	for _, entry := range allowed {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == address || (strings.HasPrefix(entry, "@") && strings.HasSuffix(address, entry)) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkMaildir reads the mail delivered to new/ and moves it to cur/
+// marked seen, as mail clients do. Mail that fails to import stays in
+// new/ to be tried again.
+func checkMaildir(maildir string, importMail func([]byte) error, result *MailResult) error {
+	newDir := filepath.Join(maildir, "new")
+	entries, err := os.ReadDir(newDir)
+	if err != nil {
+		return fmt.Errorf("failed to read maildir: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(newDir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			result.Failed = append(result.Failed, err)
+			continue
+		}
+		if err := importMail(data); err != nil {
+			result.Failed = append(result.Failed, fmt.Errorf("%s: %w", entry.Name(), err))
+			continue
+		}
+		seen := filepath.Join(maildir, "cur", entry.Name()+":2,S")
+		if err := os.Rename(path, seen); err != nil {
+			return fmt.Errorf("failed to mark mail seen: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkIMAP reads the unseen mail in the IMAP mailbox and marks it seen.
+// Mail that fails to import stays unseen to be tried again.
+func checkIMAP(settings config.MailConfig, importMail func([]byte) error, result *MailResult) error {
+	client, err := dialIMAP(settings.IMAPServer, settings.IMAPUser, settings.IMAPPassword)
+	if err != nil {
+		return err
+	}
+	defer client.close()
+
+	mailbox := settings.IMAPMailbox
+	if mailbox == "" {
+		mailbox = "INBOX"
+	}
+	uids, err := client.unseen(mailbox)
+	if err != nil {
+		return err
+	}
+	for _, uid := range uids {
+		data, err := client.fetch(uid)
+		if err != nil {
+			return err
+		}
+		if err := importMail(data); err != nil {
+			result.Failed = append(result.Failed, fmt.Errorf("message %s: %w", uid, err))
+			continue
+		}
+		if err := client.markSeen(uid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expandHome expands a leading ~ to the home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
@@ -0,0 +1,192 @@
+package ebook
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// MathML can't be typeset in a terminal, so equations are written out on
+// one line the way they would be typed: x² + 1, (a + b)/2, √(x), ∑_(i=1)^n.
+
+// Operators written with a space on both sides
+const mathInfix = "+-−=≠<>≤≥≈≡×÷·±∓→←↔⇒⇔∈∉⊂⊆∪∩∧∨"
+
+var superscripts = map[rune]rune{
+	'0': '⁰', '1': '¹', '2': '²', '3': '³', '4': '⁴', '5': '⁵', '6': '⁶', '7': '⁷', '8': '⁸', '9': '⁹',
+	'+': '⁺', '-': '⁻', '−': '⁻', '=': '⁼', '(': '⁽', ')': '⁾', 'n': 'ⁿ', 'i': 'ⁱ',
+}
+
+var subscripts = map[rune]rune{
+	'0': '₀', '1': '₁', '2': '₂', '3': '₃', '4': '₄', '5': '₅', '6': '₆', '7': '₇', '8': '₈', '9': '₉',
+	'+': '₊', '-': '₋', '−': '₋', '=': '₌', '(': '₍', ')': '₎',
+	'a': 'ₐ', 'e': 'ₑ', 'i': 'ᵢ', 'j': 'ⱼ', 'k': 'ₖ', 'm': 'ₘ', 'n': 'ₙ', 'o': 'ₒ', 'x': 'ₓ',
+}
+
+// Operators with limits written under and over them
+var largeOperators = []string{"∑", "∏", "∐", "∫", "∮", "⋃", "⋂", "lim", "max", "min", "sup", "inf"}
+
+// mathText writes a <math> element as linear text, using its alttext when
+// the markup has nothing to show
+func mathText(n *html.Node) string {
+	text := strings.Join(strings.Fields(linearMath(n)), " ")
+	if text == "" {
+		text = strings.TrimSpace(attribute(n, "alttext"))
+	}
+	return text
+}
+
+// linearMath converts a MathML element and its children to text
+func linearMath(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return strings.TrimSpace(n.Data)
+	}
+	if n.Type != html.ElementNode {
+		return ""
+	}
+
+	args := mathArgs(n)
+	arg := func(i int) string {
+		if i < len(args) {
+			return args[i]
+		}
+		return ""
+	}
+
+	switch localName(n) {
+	case "mo":
+		op := strings.TrimSpace(textContent(n))
+		switch {
+		case op == ",", op == ";":
+			return op + " "
+		case len([]rune(op)) == 1 && strings.Contains(mathInfix, op):
+			return " " + op + " "
+		}
+		return op
+	case "mi", "mn", "mtext", "ms":
+		return strings.TrimSpace(textContent(n))
+	case "mspace":
+		return " "
+	case "mphantom", "annotation", "annotation-xml", "none", "mprescripts":
+		return ""
+	case "semantics":
+		return arg(0)
+	case "msup":
+		return arg(0) + raise(arg(1), superscripts, "^")
+	case "msub":
+		return arg(0) + raise(arg(1), subscripts, "_")
+	case "msubsup":
+		return arg(0) + raise(arg(1), subscripts, "_") + raise(arg(2), superscripts, "^")
+	case "munder":
+		return arg(0) + "_" + group(arg(1)) + operand(arg(0))
+	case "mover":
+		return arg(0) + "^" + group(arg(1))
+	case "munderover":
+		return arg(0) + "_" + group(arg(1)) + "^" + group(arg(2)) + operand(arg(0))
+	case "mfrac":
+		return group(arg(0)) + "/" + group(arg(1))
+	case "msqrt":
+		return "√" + group(strings.Join(args, ""))
+	case "mroot":
+		if index := arg(1); index == "3" {
+			return "∛" + group(arg(0))
+		} else if index == "4" {
+			return "∜" + group(arg(0))
+		}
+		return "root" + group(arg(1)) + group(arg(0))
+	case "mfenced":
+		open, close := "(", ")"
+		if value, ok := attributeValue(n, "open"); ok {
+			open = value
+		}
+		if value, ok := attributeValue(n, "close"); ok {
+			close = value
+		}
+		separator := ","
+		if value, ok := attributeValue(n, "separators"); ok {
+			separator = strings.TrimSpace(value)
+		}
+		return open + strings.Join(args, separator+" ") + close
+	case "mtable":
+		return "[" + strings.Join(args, "; ") + "]"
+	case "mtr", "mlabeledtr":
+		return strings.Join(args, ", ")
+	}
+	// mrow, mstyle, mtd, menclose and others just hold their children. An
+	// operator at the start is a sign, as in -b.
+	if len(args) > 0 && strings.HasPrefix(args[0], " ") {
+		args[0] = strings.TrimSpace(args[0])
+	}
+	return strings.Join(args, "")
+}
+
+// mathArgs converts the element children of a MathML element
+func mathArgs(n *html.Node) []string {
+	var args []string
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode || strings.TrimSpace(c.Data) != "" {
+			args = append(args, linearMath(c))
+		}
+	}
+	return args
+}
+
+// raise writes a superscript or subscript with Unicode characters when it
+// has them, and with ^ or _ otherwise
+func raise(text string, characters map[rune]rune, marker string) string {
+	text = strings.TrimSpace(text)
+	var b strings.Builder
+	for _, r := range text {
+		raised, ok := characters[r]
+		if !ok && len([]rune(text)) > 1 && !strings.HasPrefix(text, "(") {
+			return marker + "(" + text + ")"
+		} else if !ok {
+			return marker + text
+		}
+		b.WriteRune(raised)
+	}
+	return b.String()
+}
+
+// group puts parentheses around text that is more than one name or number
+func group(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.ContainsFunc(text, isMathOperator) || (strings.HasPrefix(text, "(") && strings.HasSuffix(text, ")")) {
+		return text
+	}
+	return "(" + text + ")"
+}
+
+// operand separates a large operator with limits from what follows it
+func operand(base string) string {
+	if slices.Contains(largeOperators, strings.TrimSpace(base)) {
+		return " "
+	}
+	return ""
+}
+
+func isMathOperator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.'
+}
+
+// localName returns an element name without its namespace prefix, as in
+// <m:mi> in XHTML
+func localName(n *html.Node) string {
+	_, name, found := strings.Cut(n.Data, ":")
+	if !found {
+		return n.Data
+	}
+	return name
+}
+
+// attributeValue returns an attribute and whether the element has it
+func attributeValue(n *html.Node, name string) (string, bool) {
+	for _, attr := range n.Attr {
+		if attr.Key == name {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,197 @@
+package ebook
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EPUB 3 books can come with a recording of the text, read-aloud books as
+// publishers call them. A media overlay, a SMIL file named by the
+// media-overlay attribute of a chapter's manifest item, pairs the elements
+// of the chapter with clips of the audio files in the book: paragraph p12
+// is read from 1:02.5 to 1:09 of chapter1.mp3. The book's total running
+// time and narrator are in its metadata as media:duration and
+// media:narrator, kept as "media_duration" and "narrator".
+
+// MediaOverlay is the read-aloud audio of a chapter
+type MediaOverlay struct {
+	Href  string      // Path of the SMIL file inside the EPUB
+	Clips []AudioClip // In reading order
+}
+
+// AudioClip is the stretch of audio an element of the chapter is read in
+type AudioClip struct {
+	Fragment string        // Id of the element, as in the anchors of RenderResult
+	Audio    string        // Path of the audio file inside the EPUB
+	Begin    time.Duration // Start in the audio file
+	End      time.Duration // End in the audio file, 0 if it runs to the end of the file
+}
+
+// Duration returns how long the clips of the overlay play, not counting
+// clips that run to the end of their file
+func (o *MediaOverlay) Duration() time.Duration {
+	var total time.Duration
+	for _, clip := range o.Clips {
+		if clip.End > clip.Begin {
+			total += clip.End - clip.Begin
+		}
+	}
+	return total
+}
+
+// smilNode is an element of a SMIL file. Only <par> elements, holding the
+// <text> and <audio> of a clip, matter; <seq> and <body> group them.
+type smilNode struct {
+	XMLName   xml.Name
+	Src       string     `xml:"src,attr"`
+	ClipBegin string     `xml:"clipBegin,attr"`
+	ClipEnd   string     `xml:"clipEnd,attr"`
+	Children  []smilNode `xml:",any"`
+}
+
+// HasMediaOverlays reports whether any chapter of the book has read-aloud
+// audio
+func (b *Book) HasMediaOverlays() bool {
+	for _, chapter := range b.Chapters {
+		if chapter.Overlay != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// MediaOverlay reads the read-aloud audio of a chapter. It returns nil
+// for chapters without one.
+func (b *Book) MediaOverlay(index int) (*MediaOverlay, error) {
+	chapter := b.GetChapter(index)
+	if chapter == nil || chapter.Overlay == "" {
+		return nil, nil
+	}
+	zipReader, ok := b.archive.(*zip.ReadCloser)
+	if !ok {
+		return nil, fmt.Errorf("book is closed")
+	}
+	data, err := readFileFromZip(zipReader, chapter.Overlay)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read media overlay: %w", err)
+	}
+	overlay, err := parseSMIL(data, chapter.Overlay)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse media overlay: %w", err)
+	}
+	return overlay, nil
+}
+
+// OpenResource opens a file of the book by its path inside the EPUB, such
+// as the audio of a media overlay
+func (b *Book) OpenResource(name string) (io.ReadCloser, error) {
+	zipReader, ok := b.archive.(*zip.ReadCloser)
+	if !ok {
+		return nil, fmt.Errorf("book is closed")
+	}
+	file := findFileInZip(zipReader, name)
+	if file == nil {
+		return nil, fmt.Errorf("file not found: %s", name)
+	}
+	return file.Open()
+}
+
+// parseSMIL reads the clips of a SMIL file. Paths in it are relative to
+// the file.
+func parseSMIL(data []byte, smilPath string) (*MediaOverlay, error) {
+	var root smilNode
+	if err := unmarshalXML(data, &root); err != nil {
+		return nil, err
+	}
+
+	overlay := &MediaOverlay{Href: smilPath}
+	var walk func(node smilNode)
+	walk = func(node smilNode) {
+		if node.XMLName.Local != "par" {
+			for _, child := range node.Children {
+				walk(child)
+			}
+			return
+		}
+
+		var clip AudioClip
+		var hasAudio bool
+		for _, child := range node.Children {
+			switch child.XMLName.Local {
+			case "text":
+				if i := strings.Index(child.Src, "#"); i >= 0 {
+					clip.Fragment = child.Src[i+1:]
+				}
+			case "audio":
+				clip.Audio = resolveHref(smilPath, child.Src)
+				clip.Begin, _ = ParseClockValue(child.ClipBegin)
+				clip.End, _ = ParseClockValue(child.ClipEnd)
+				hasAudio = child.Src != ""
+			}
+		}
+		if hasAudio {
+			overlay.Clips = append(overlay.Clips, clip)
+		}
+	}
+	walk(root)
+	return overlay, nil
+}
+
+// ParseClockValue parses a SMIL clock value: "1:02:03.5", "02:03.5",
+// "3.5s", "350ms", "2min", "1h", or plain seconds. An empty value is 0.
+func ParseClockValue(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	if strings.Contains(value, ":") {
+		parts := strings.Split(value, ":")
+		if len(parts) > 3 {
+			return 0, fmt.Errorf("invalid clock value: %s", value)
+		}
+		var seconds float64
+		for _, part := range parts {
+			number, err := strconv.ParseFloat(part, 64)
+			if err != nil || number < 0 {
+				return 0, fmt.Errorf("invalid clock value: %s", value)
+			}
+			seconds = seconds*60 + number
+		}
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+
+	unit := time.Second
+	for _, metric := range []struct {
+		suffix string
+		unit   time.Duration
+	}{{"ms", time.Millisecond}, {"min", time.Minute}, {"h", time.Hour}, {"s", time.Second}} {
+		if strings.HasSuffix(value, metric.suffix) {
+			value, unit = strings.TrimSuffix(value, metric.suffix), metric.unit
+			break
+		}
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid clock value: %s", value)
+	}
+	return time.Duration(number * float64(unit)), nil
+}
+
+// overlayPath returns the SMIL file of a manifest item, or "" if it has
+// no media overlay
+func overlayPath(opfPath string, item opfItem, manifest map[string]opfItem) string {
+	if item.MediaOverlay == "" {
+		return ""
+	}
+	overlay, ok := manifest[item.MediaOverlay]
+	if !ok || overlay.MediaType != "application/smil+xml" {
+		return ""
+	}
+	return resolveHref(opfPath, overlay.Href)
+}
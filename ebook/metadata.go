@@ -0,0 +1,224 @@
+package ebook
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// extractMetadata copies the descriptive OPF metadata into the book's metadata map
+func extractMetadata(metadata map[string]string, opf *opfMetadata) {
+	if len(opf.Publisher) > 0 {
+		metadata["publisher"] = strings.TrimSpace(opf.Publisher[0])
+	}
+
+	if len(opf.Date) > 0 {
+		// Dates are often full timestamps; the day is all we display
+		date := strings.TrimSpace(opf.Date[0])
+		if i := strings.Index(date, "T"); i > 0 {
+			date = date[:i]
+		}
+		metadata["date"] = date
+	}
+
+	// Where the book came from, such as the page of a saved article
+	if len(opf.Source) > 0 {
+		metadata["source"] = strings.TrimSpace(opf.Source[0])
+	}
+
+	if len(opf.Description) > 0 {
+		// Descriptions frequently contain escaped HTML markup
+		metadata["description"] = htmlToText(opf.Description[0])
+	}
+
+	if subjects := cleanList(opf.Subject); len(subjects) > 0 {
+		metadata["subjects"] = strings.Join(subjects, ", ")
+	}
+
+	var contributors []string
+	for _, contributor := range parseContributors(opf) {
+		if contributor.Role != RoleAuthor && contributor.RoleName() != "" {
+			contributors = append(contributors, fmt.Sprintf("%s (%s)", contributor.Name, contributor.RoleName()))
+		}
+	}
+	if len(contributors) > 0 {
+		metadata["contributors"] = strings.Join(contributors, ", ")
+	}
+
+	// Identifiers, picking out the ISBN when there is one
+	var identifiers []string
+	for _, identifier := range opf.Identifier {
+		value := strings.TrimSpace(identifier.Value)
+		if value == "" {
+			continue
+		}
+		identifiers = append(identifiers, value)
+
+		if _, exists := metadata["isbn"]; !exists {
+			if isbn := parseISBN(identifier.Scheme, value); isbn != "" {
+				metadata["isbn"] = isbn
+			}
+		}
+	}
+	if len(identifiers) > 0 {
+		metadata["identifiers"] = strings.Join(identifiers, ", ")
+	}
+
+	// Running time and narrator of read-aloud books, see mediaoverlay.go
+	for _, meta := range opf.Meta {
+		if meta.Refines != "" || strings.TrimSpace(meta.Value) == "" {
+			continue
+		}
+		switch meta.Property {
+		case "media:duration":
+			metadata["media_duration"] = strings.TrimSpace(meta.Value)
+		case "media:narrator":
+			metadata["narrator"] = strings.TrimSpace(meta.Value)
+		}
+	}
+
+	series, index := parseSeries(opf.Meta)
+	if series != "" {
+		metadata["series"] = series
+		if index != "" {
+			metadata["series_index"] = index
+		}
+	}
+}
+
+// parseContributors collects all creators and contributors with their
+// roles, from opf:role attributes (EPUB 2) or role refinements (EPUB 3)
+func parseContributors(opf *opfMetadata) []Contributor {
+	refinedRoles := make(map[string]string)
+	for _, meta := range opf.Meta {
+		if meta.Property == "role" && strings.HasPrefix(meta.Refines, "#") {
+			refinedRoles[strings.TrimPrefix(meta.Refines, "#")] = strings.TrimSpace(meta.Value)
+		}
+	}
+
+	var contributors []Contributor
+	add := func(entries []opfCreator, defaultRole string) {
+		for _, entry := range entries {
+			name := strings.TrimSpace(entry.Value)
+			if name == "" {
+				continue
+			}
+			role := strings.ToLower(strings.TrimSpace(entry.Role))
+			if role == "" && entry.ID != "" {
+				role = strings.ToLower(refinedRoles[entry.ID])
+			}
+			if role == "" {
+				role = defaultRole
+			}
+			contributors = append(contributors, Contributor{Name: name, Role: role})
+		}
+	}
+
+	// Creators without a role are authors; contributors without one are
+	// of unknown role (calibre, for instance, lists itself as one)
+	add(opf.Creator, RoleAuthor)
+	add(opf.Contributor, "")
+
+	return contributors
+}
+
+// firstCreator returns the name of the first creator, whatever their role
+func firstCreator(opf *opfMetadata) string {
+	for _, creator := range opf.Creator {
+		if name := strings.TrimSpace(creator.Value); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// parseSeries reads the series name and position from calibre (EPUB 2)
+// or belongs-to-collection (EPUB 3) metadata
+func parseSeries(metas []opfMeta) (string, string) {
+	var series, index string
+
+	for _, meta := range metas {
+		switch meta.Name {
+		case "calibre:series":
+			series = strings.TrimSpace(meta.Content)
+		case "calibre:series_index":
+			index = strings.TrimSpace(meta.Content)
+		}
+	}
+	if series != "" {
+		return series, formatSeriesIndex(index)
+	}
+
+	for _, meta := range metas {
+		if meta.Property != "belongs-to-collection" || meta.ID == "" {
+			continue
+		}
+
+		collectionType := ""
+		position := ""
+		for _, refinement := range metas {
+			if refinement.Refines != "#"+meta.ID {
+				continue
+			}
+			switch refinement.Property {
+			case "collection-type":
+				collectionType = strings.TrimSpace(refinement.Value)
+			case "group-position":
+				position = strings.TrimSpace(refinement.Value)
+			}
+		}
+
+		// Collections without a type are treated as series too
+		if collectionType == "" || collectionType == "series" {
+			return strings.TrimSpace(meta.Value), formatSeriesIndex(position)
+		}
+	}
+
+	return "", ""
+}
+
+// formatSeriesIndex drops the trailing ".0" calibre writes for whole numbers
+func formatSeriesIndex(index string) string {
+	return strings.TrimSuffix(index, ".0")
+}
+
+// parseISBN returns the bare ISBN if the identifier is one
+func parseISBN(scheme, value string) string {
+	lower := strings.ToLower(value)
+	isISBN := strings.EqualFold(scheme, "isbn") || strings.HasPrefix(lower, "urn:isbn:") || strings.HasPrefix(lower, "isbn:")
+
+	value = strings.TrimPrefix(lower, "urn:")
+	value = strings.TrimPrefix(value, "isbn:")
+
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) || r == 'x' {
+			return unicode.ToUpper(r)
+		}
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return '?'
+	}, value)
+
+	if strings.Contains(digits, "?") || (len(digits) != 10 && len(digits) != 13) {
+		return ""
+	}
+
+	// Bare 13-digit identifiers are only trusted with the ISBN-13 prefix
+	if !isISBN && !(len(digits) == 13 && (strings.HasPrefix(digits, "978") || strings.HasPrefix(digits, "979"))) {
+		return ""
+	}
+
+	return digits
+}
+
+// cleanList trims entries and drops empty ones
+func cleanList(values []string) []string {
+	var cleaned []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			cleaned = append(cleaned, value)
+		}
+	}
+	return cleaned
+}
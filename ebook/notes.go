@@ -0,0 +1,133 @@
+package ebook
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cbrasser/cozy/config"
+)
+
+// ExportHighlights writes the highlights and notes of a book, and the
+// journal kept on it, to a Markdown file named after the book in destDir,
+// replacing an earlier export. The front matter makes it a note Obsidian
+// can search by title, author and tag. It returns the path of the file.
+func ExportHighlights(path, destDir string, highlights []config.Highlight, journal string, options Options, wordsPerPage int) (string, error) {
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return "", fmt.Errorf("destination not available: %w", err)
+	}
+
+	book, err := OpenBook(path, options)
+	if err != nil {
+		return "", err
+	}
+	defer book.Close()
+
+	target := filepath.Join(destDir, BookFileName(book.Title, book.Author)+".md")
+	if err := os.WriteFile(target, []byte(HighlightsMarkdown(book, highlights, journal, wordsPerPage)), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", target, err)
+	}
+	return target, nil
+}
+
+// HighlightsMarkdown formats highlights as Markdown, grouped under the
+// chapters they are in, each with its position in the book and the page
+// it is on, for pages of wordsPerPage words. The journal, if any, comes
+// first.
+func HighlightsMarkdown(book *Book, highlights []config.Highlight, journal string, wordsPerPage int) string {
+	pages := Pages(book.WordCount(), wordsPerPage)
+
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "title: %s\n", strconv.Quote(book.Title))
+	if book.Author != "" {
+		fmt.Fprintf(&b, "author: %s\n", strconv.Quote(book.Author))
+	}
+	b.WriteString("tags: [book, highlights]\n")
+	fmt.Fprintf(&b, "highlights: %d\n", len(highlights))
+	fmt.Fprintf(&b, "exported: %s\n", time.Now().Format("2006-01-02"))
+	b.WriteString("---\n\n")
+
+	fmt.Fprintf(&b, "# %s\n\n", book.Title)
+	if byline := book.Byline(); byline != "" {
+		fmt.Fprintf(&b, "*by %s*\n\n", byline)
+	}
+
+	if journal = strings.TrimSpace(journal); journal != "" {
+		fmt.Fprintf(&b, "## Notes\n\n%s\n\n", journal)
+	}
+
+	chapter := -1
+	for _, highlight := range sortedHighlights(highlights) {
+		if highlight.Chapter != chapter {
+			chapter = highlight.Chapter
+			title := fmt.Sprintf("Chapter %d", chapter+1)
+			if c := book.GetChapter(chapter); c != nil && c.Title != title {
+				title += ": " + c.Title
+			}
+			fmt.Fprintf(&b, "## %s\n\n", title)
+		}
+
+		for _, line := range strings.Split(strings.TrimSpace(highlight.Text), "\n") {
+			b.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+		}
+		b.WriteString("\n")
+		if note := strings.TrimSpace(highlight.Note); note != "" {
+			fmt.Fprintf(&b, "**Note:** %s\n\n", note)
+		}
+
+		percent := highlightPercent(book, highlight)
+		location := fmt.Sprintf("Chapter %d, %d%%, page %d", chapter+1, percent, PageAt(float64(percent), pages))
+		if !highlight.Created.IsZero() {
+			location += " · " + highlight.Created.Format("2006-01-02")
+		}
+		fmt.Fprintf(&b, "*%s*\n\n", location)
+	}
+	return b.String()
+}
+
+// sortedHighlights orders highlights by chapter, keeping the order they
+// were made in within a chapter
+func sortedHighlights(highlights []config.Highlight) []config.Highlight {
+	sorted := append([]config.Highlight(nil), highlights...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Chapter < sorted[j].Chapter })
+	return sorted
+}
+
+// highlightPercent estimates how far into the book a highlight is, from
+// the chapter lengths and where the text is found in its chapter
+func highlightPercent(book *Book, highlight config.Highlight) int {
+	total, before := 0, 0
+	for i := range book.Chapters {
+		length := book.Chapters[i].Length()
+		if i < highlight.Chapter {
+			before += length
+		}
+		total += length
+	}
+	if total == 0 {
+		return 0
+	}
+
+	if chapter := book.GetChapter(highlight.Chapter); chapter != nil {
+		text := chapter.Text()
+		if chapter.HTML {
+			text = ExtractPlainText(text)
+		}
+		words := strings.Fields(highlight.Text)
+		if len(words) > 0 && len(text) > 0 {
+			index := strings.Index(text, strings.Join(words[:min(5, len(words))], " "))
+			if index < 0 {
+				index = strings.Index(text, words[0])
+			}
+			if index >= 0 {
+				before += chapter.Length() * index / len(text)
+			}
+		}
+	}
+	return min(100, before*100/total)
+}
@@ -0,0 +1,254 @@
+package ebook
+
+import (
+	"encoding/hex"
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Position is a place in a book: a chapter (zero-based) and the number of
+// letters and digits before the line, see TextOffsets
+type Position struct {
+	Chapter    int
+	TextOffset int
+}
+
+// ProgressStore keeps reading positions between sessions.
+// FileProgressStore shares them with cozy; programs can keep them
+// elsewhere by implementing the interface.
+type ProgressStore interface {
+	// LoadPosition returns the saved position of a book, and false if
+	// there is none
+	LoadPosition(bookPath string) (Position, bool, error)
+	SavePosition(bookPath string, position Position) error
+}
+
+// FileProgressStore keeps positions in cozy's progress file, in the data
+// directory of a config
+type FileProgressStore struct {
+	Config *config.Config
+}
+
+// LoadPosition returns the position cozy saved for a book
+func (s FileProgressStore) LoadPosition(bookPath string) (Position, bool, error) {
+	progress, err := config.LoadProgress(s.Config)
+	if err != nil {
+		return Position{}, false, err
+	}
+	book, ok := progress.GetBookProgress(bookPath)
+	return Position{Chapter: book.CurrentChapter, TextOffset: book.TextOffset}, ok, nil
+}
+
+// SavePosition saves the position of a book, keeping its bookmarks and
+// finished status
+func (s FileProgressStore) SavePosition(bookPath string, position Position) error {
+	progress, err := config.LoadProgress(s.Config)
+	if err != nil {
+		return err
+	}
+	book, _ := progress.GetBookProgress(bookPath)
+	progress.SetBookProgress(bookPath, position.Chapter, 0, position.TextOffset, book.TotalChapters)
+	return config.SaveProgress(s.Config, progress)
+}
+
+// MemoryProgressStore keeps positions in memory, e.g. for tests or
+// programs that save them with their own data
+type MemoryProgressStore struct {
+	mu        sync.Mutex
+	positions map[string]Position
+}
+
+// LoadPosition returns the position stored for a book
+func (s *MemoryProgressStore) LoadPosition(bookPath string) (Position, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	position, ok := s.positions[bookPath]
+	return position, ok, nil
+}
+
+// SavePosition stores the position of a book
+func (s *MemoryProgressStore) SavePosition(bookPath string, position Position) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.positions == nil {
+		s.positions = make(map[string]Position)
+	}
+	s.positions[bookPath] = position
+	return nil
+}
+
+// PositionAtPercent finds the position a percentage into a book, weighting
+// chapters by length like the reader's percentage. The offset within the
+// chapter is estimated from its plain text.
+func PositionAtPercent(book *Book, percent float64) Position {
+	percent = clampPercent(percent)
+	total := 0
+	for i := range book.Chapters {
+		total += book.Chapters[i].Length()
+	}
+	target := int(percent / 100 * float64(total))
+
+	for i := range book.Chapters {
+		chapter := &book.Chapters[i]
+		length := chapter.Length()
+		if target > length && i < len(book.Chapters)-1 {
+			target -= length
+			continue
+		}
+		position := Position{Chapter: i}
+		if length > 0 {
+			text := chapter.Text()
+			if chapter.HTML {
+				text = ExtractPlainText(text)
+			}
+			fraction := float64(min(target, length)) / float64(length)
+			position.TextOffset = int(fraction * float64(countLetters(text)))
+		}
+		return position
+	}
+	return Position{}
+}
+
+// clampPercent keeps a percentage between 0 and 100
+func clampPercent(percent float64) float64 {
+	return math.Max(0, math.Min(percent, 100))
+}
+
+// A position is only good for the file it was saved in. When a book is
+// replaced by another edition, the letters at the saved position are
+// looked for in the new file instead: all of them first, then pieces of
+// them, in case the new edition changed a word or two.
+
+// Letters kept of the text at a position, and the length of the pieces
+// they are split into when the whole doesn't match
+const (
+	contextLength = 64
+	contextPiece  = 16
+)
+
+// FileHash returns the SHA-256 digest of a book file in hex, to tell when
+// the file was replaced
+func FileHash(path string) (string, error) {
+	hash, err := hashFile(path)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash), nil
+}
+
+// PositionContext returns the letters and digits at the start of rendered
+// lines, lowercased, for RelocatePosition to find in another edition
+func PositionContext(lines []string) string {
+	var b strings.Builder
+	count := 0
+	for _, line := range lines {
+		for _, r := range ansi.Strip(line) {
+			if count == contextLength {
+				return b.String()
+			}
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				b.WriteRune(unicode.ToLower(r))
+				count++
+			}
+		}
+	}
+	return b.String()
+}
+
+// RelocatePosition finds the text of a PositionContext in a book, looking
+// in the chapter of the saved position first and then further and further
+// away. Within that chapter the match nearest the saved offset wins. It
+// reports false if neither the text nor a piece of it is in the book.
+func RelocatePosition(book *Book, context string, saved Position) (Position, bool) {
+	if context == "" || len(book.Chapters) == 0 {
+		return Position{}, false
+	}
+
+	// Chapters by distance from the one the position was in
+	chapter := max(min(saved.Chapter, len(book.Chapters)-1), 0)
+	order := []int{chapter}
+	for distance := 1; distance < len(book.Chapters); distance++ {
+		for _, i := range []int{chapter + distance, chapter - distance} {
+			if i >= 0 && i < len(book.Chapters) {
+				order = append(order, i)
+			}
+		}
+	}
+
+	type piece struct {
+		text  string
+		start int // Letters of the context before the piece
+	}
+	pieces := []piece{{text: context}}
+	runes := []rune(context)
+	for start := 0; start+contextPiece <= len(runes) && len(runes) > contextPiece; start += contextPiece {
+		pieces = append(pieces, piece{text: string(runes[start : start+contextPiece]), start: start})
+	}
+
+	texts := make(map[int]string)
+	for _, piece := range pieces {
+		for _, i := range order {
+			text, ok := texts[i]
+			if !ok {
+				text = chapterLetters(&book.Chapters[i])
+				texts[i] = text
+			}
+			target := 0
+			if i == saved.Chapter {
+				target = saved.TextOffset + piece.start
+			}
+			if at, ok := indexNearest(text, piece.text, target); ok {
+				return Position{Chapter: i, TextOffset: max(at-piece.start, 0)}, true
+			}
+		}
+	}
+	return Position{}, false
+}
+
+// indexNearest returns the match of a piece of text nearest a rune offset,
+// as a rune offset
+func indexNearest(text, piece string, target int) (int, bool) {
+	distance := func(offset int) int {
+		return max(offset-target, target-offset)
+	}
+	best, found := 0, false
+	runes, from := 0, 0
+	for {
+		at := strings.Index(text[from:], piece)
+		if at < 0 {
+			return best, found
+		}
+		runes += utf8.RuneCountInString(text[from : from+at])
+		if !found || distance(runes) < distance(best) {
+			best, found = runes, true
+		}
+		if runes > target {
+			return best, found
+		}
+		_, size := utf8.DecodeRuneInString(text[from+at:])
+		from += at + size
+		runes++
+	}
+}
+
+// chapterLetters returns the letters and digits of a chapter's text,
+// lowercased, so their index is a text offset
+func chapterLetters(chapter *Chapter) string {
+	text := chapter.Text()
+	if chapter.HTML {
+		text = ExtractPlainText(text)
+	}
+	var b strings.Builder
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
@@ -1,77 +1,167 @@
 package ebook
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/cbrasser/cozy/config"
-	"github.com/charmbracelet/lipgloss"
-	"github.com/muesli/reflow/wordwrap"
+	"github.com/charmbracelet/x/ansi"
 	"golang.org/x/net/html"
 )
 
 // RenderResult contains the rendered text and metadata
 type RenderResult struct {
-	Text            string
-	HeadingPositions []int // Line numbers where H2/H3 headings start
+	Text     string
+	Headings []Heading      // Headings of every level, in order
+	Links    []Link         // Hyperlinks, one entry per rendered line they cover
+	Anchors  map[string]int // Line number of each element id
 }
 
-// Renderer converts HTML to styled terminal text
+// Heading is a heading of a chapter and the line it starts on
+type Heading struct {
+	Level int // 1 for h1 to 6 for h6
+	Title string
+	Line  int // Line of the final, wrapped text
+}
+
+// Link is the part of a hyperlink on one rendered line
+type Link struct {
+	Href     string
+	Line     int
+	StartCol int // First display column
+	EndCol   int // Column after the last character
+}
+
+// textSpan is a byte range of the render output
+type textSpan struct {
+	href       string
+	start, end int
+}
+
+// RenderOptions controls how HTML is laid out
+type RenderOptions struct {
+	Justify         bool        // Stretch wrapped lines to the full width
+	SmartTypography bool        // Curly quotes, em dashes and ellipses, see typography.go
+	Language        string      // Language of the book, for language-specific typography
+	Stylesheet      *Stylesheet // CSS of the book, see css.go
+
+	// Extra room for easier reading, see spacing.go
+	LetterSpacing bool // A space between the letters of each word
+	WordSpacing   int  // Spaces added between words
+
+	Plain bool // No rules or bullets, for screen readers
+	ASCII bool // Only ASCII characters, see ascii.go
+
+	// Large text for reading from a distance, see largetext.go
+	LargeText      string // "headings" or "all"
+	LargeTextStyle string // "double" or "banner"
+}
+
+// DefaultRenderOptions returns the default layout options
+func DefaultRenderOptions() RenderOptions {
+	return RenderOptions{
+		Justify: true,
+	}
+}
+
+// Renderer converts HTML to styled terminal text. It parses HTML into
+// blocks, which need no theme or width, and lays them out, see blocks.go.
 type Renderer struct {
-	theme            *config.Theme
-	width            int
-	headingPositions []int
+	theme   *config.Theme
+	width   int
+	options RenderOptions
+	styles  *Stylesheet // Book and chapter CSS
+	list    *blockList  // Blocks being parsed
+
+	// The last character of text, for quotes
+	lastRune rune
 }
 
 // NewRenderer creates a new HTML renderer
 func NewRenderer(theme *config.Theme, width int) *Renderer {
+	return NewRendererWithOptions(theme, width, DefaultRenderOptions())
+}
+
+// NewRendererWithOptions creates a new HTML renderer with layout options
+func NewRendererWithOptions(theme *config.Theme, width int, options RenderOptions) *Renderer {
 	return &Renderer{
-		theme:            theme,
-		width:            width,
-		headingPositions: []int{},
+		theme:   theme,
+		width:   width,
+		options: options,
 	}
 }
 
 // Render converts HTML to styled text
 func (r *Renderer) Render(htmlContent string) string {
+	return r.RenderWithHeadings(htmlContent).Text
+}
+
+// RenderWithHeadings converts HTML to styled text and returns its headings
+func (r *Renderer) RenderWithHeadings(htmlContent string) RenderResult {
+	list := r.parse(htmlContent)
+	if list == nil {
+		// Fallback to simple text stripping
+		return RenderResult{
+			Text: htmlToText(htmlContent),
+		}
+	}
+	return r.layoutText(list)
+}
+
+// parse reads HTML into blocks, or returns nil if it can't be parsed
+func (r *Renderer) parse(htmlContent string) *blockList {
 	doc, err := html.Parse(strings.NewReader(htmlContent))
 	if err != nil {
-		// Fallback to simple text stripping
-		return htmlToText(htmlContent)
+		return nil
 	}
 
-	var result strings.Builder
-	r.renderNode(doc, &result, &renderContext{})
+	r.list = newBlockList()
+	r.styles = r.documentStyles(doc)
+	r.renderNode(doc, r.rootContext())
+	r.flush()
+	return r.list
+}
 
-	return strings.TrimSpace(result.String())
+// trimOutput removes the blank lines around rendered text, keeping the
+// indentation of the first line, which right-aligns right-to-left text. It
+// returns the text and the number of bytes removed from the start.
+func trimOutput(raw string) (string, int) {
+	content := strings.TrimLeft(raw, " \t\n\r")
+	leading := strings.LastIndexByte(raw[:len(raw)-len(content)], '\n') + 1
+	return strings.TrimRight(raw[leading:], " \t\n\r"), leading
 }
 
-// RenderWithHeadings converts HTML to styled text and returns heading positions
-func (r *Renderer) RenderWithHeadings(htmlContent string) RenderResult {
-	r.headingPositions = []int{} // Reset heading positions
+// linkLines splits the byte range of a link into per-line column spans
+func linkLines(text, href string, start, end int) []Link {
+	var links []Link
+	line := strings.Count(text[:start], "\n")
+	lineStart := strings.LastIndex(text[:start], "\n") + 1
 
-	doc, err := html.Parse(strings.NewReader(htmlContent))
-	if err != nil {
-		// Fallback to simple text stripping
-		return RenderResult{
-			Text:            htmlToText(htmlContent),
-			HeadingPositions: []int{},
+	for start < end {
+		lineEnd := strings.IndexByte(text[start:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(text)
+		} else {
+			lineEnd += start
 		}
-	}
+		segmentEnd := min(end, lineEnd)
 
-	var result strings.Builder
-	r.renderNode(doc, &result, &renderContext{})
-
-	text := strings.TrimSpace(result.String())
+		startCol := ansi.StringWidth(text[lineStart:start])
+		endCol := ansi.StringWidth(text[lineStart:segmentEnd])
+		if endCol > startCol {
+			links = append(links, Link{Href: href, Line: line, StartCol: startCol, EndCol: endCol})
+		}
 
-	return RenderResult{
-		Text:            text,
-		HeadingPositions: r.headingPositions,
+		start = lineEnd + 1
+		lineStart = start
+		line++
 	}
+	return links
 }
 
 // renderContext tracks the current rendering state
 type renderContext struct {
-	inHeading    int  // 0 = none, 1-6 = h1-h6
+	inHeading    int // 0 = none, 1-6 = h1-h6
 	inBlockquote bool
 	inPre        bool
 	inCode       bool
@@ -79,6 +169,18 @@ type renderContext struct {
 	inStrong     bool
 	listLevel    int
 	inListItem   bool // true when inside a <li> element
+	listIndent   int  // Columns wrapped lines of a list item are indented by
+	inLink       bool
+	inMath       bool
+	align        string // text-align from CSS
+	inVerse      bool   // Keep line breaks, see verse.go
+	rtl          bool   // Right-to-left text, see bidi.go
+}
+
+// rootContext returns the context a document starts in. Books in Arabic,
+// Hebrew and other right-to-left languages start right to left.
+func (r *Renderer) rootContext() *renderContext {
+	return &renderContext{rtl: isRTLLanguage(r.options.Language)}
 }
 
 // clone creates a copy of the context
@@ -88,46 +190,95 @@ func (ctx *renderContext) clone() *renderContext {
 }
 
 // renderNode recursively renders an HTML node
-func (r *Renderer) renderNode(n *html.Node, out *strings.Builder, ctx *renderContext) {
+func (r *Renderer) renderNode(n *html.Node, ctx *renderContext) {
 	switch n.Type {
 	case html.TextNode:
-		text := n.Data
-
-		// Preserve whitespace in <pre> tags
-		if !ctx.inPre {
-			text = strings.TrimSpace(text)
-		}
-
-		if text != "" {
-			r.writeStyledText(out, text, ctx)
-		}
+		r.addText(n, ctx)
 
 	case html.ElementNode:
-		r.renderElement(n, out, ctx)
+		r.renderElement(n, ctx)
 
 	case html.DocumentNode:
 		// Process all children of document
 		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			r.renderNode(c, out, ctx)
+			r.renderNode(c, ctx)
 		}
 	}
 }
 
 // renderElement renders an HTML element
-func (r *Renderer) renderElement(n *html.Node, out *strings.Builder, ctx *renderContext) {
+func (r *Renderer) renderElement(n *html.Node, ctx *renderContext) {
 	newCtx := ctx.clone()
 
+	// Blocks end the paragraph before them
+	blockLevel := !isInline(n)
+	if blockLevel {
+		r.flush()
+	}
+
+	// Remember where elements with ids start so links can target them
+	if id := attribute(n, "id"); id != "" {
+		r.list.anchors[id] = r.list.position()
+	}
+
+	switch strings.ToLower(attribute(n, "dir")) {
+	case "rtl":
+		newCtx.rtl = true
+	case "ltr":
+		newCtx.rtl = false
+	case "auto":
+		newCtx.rtl = startsRTL(textContent(n))
+	}
+
+	hints := r.styles.styleOf(n)
+	if hints.hidden != nil && *hints.hidden {
+		return
+	}
+	if hints.italic != nil {
+		newCtx.inEmphasis = *hints.italic
+	}
+	if hints.bold != nil {
+		newCtx.inStrong = *hints.bold
+	}
+	if hints.align != "" {
+		newCtx.align = hints.align
+	}
+	if hints.breakBefore {
+		r.flush()
+		r.list.write("\n\n")
+	}
+	if !ctx.inCode && isVerse(n, hints) {
+		newCtx.inVerse = true
+		// Poems are often divs of stanzas; each starts a new block
+		if n.Data == "div" {
+			r.list.blankLine()
+		}
+	}
+
+	switch localName(n) {
+	case "math":
+		r.renderMath(n, newCtx)
+		return
+	case "svg":
+		r.renderSVG(n, newCtx)
+		return
+	}
+
 	// Handle element-specific behavior
 	switch n.Data {
 	case "h1", "h2", "h3", "h4", "h5", "h6":
-		out.WriteString("\n\n")
-
-		// Record position of H2 and H3 headings
-		if n.Data == "h2" || n.Data == "h3" {
-			currentText := out.String()
-			lineCount := strings.Count(currentText, "\n")
-			r.headingPositions = append(r.headingPositions, lineCount)
+		// Record the position, level and text of every heading. The
+		// line is only known once the whole chapter is wrapped.
+		title := strings.Join(strings.Fields(textContent(n)), " ")
+		if r.options.ASCII {
+			title = Transliterate(title)
 		}
+		r.list.starts = append(r.list.starts, r.list.position())
+		r.list.headings = append(r.list.headings, Heading{
+			Level: int(n.Data[1] - '0'),
+			Title: title,
+		})
+		r.list.write("\n\n")
 
 		switch n.Data {
 		case "h1":
@@ -145,17 +296,22 @@ func (r *Renderer) renderElement(n *html.Node, out *strings.Builder, ctx *render
 		}
 
 	case "p":
-		// Don't add extra newlines for paragraphs inside list items
-		if !ctx.inListItem {
-			out.WriteString("\n\n")
+		// Don't add extra newlines for paragraphs inside list items. In
+		// verse, paragraphs are often single lines.
+		if ctx.inVerse {
+			if !r.list.atLineStart() {
+				r.list.write("\n")
+			}
+		} else if !ctx.inListItem {
+			r.list.write("\n\n")
 		}
 
 	case "blockquote":
-		out.WriteString("\n\n")
+		r.list.write("\n\n")
 		newCtx.inBlockquote = true
 
 	case "pre":
-		out.WriteString("\n\n")
+		r.list.write("\n\n")
 		newCtx.inPre = true
 		newCtx.inCode = true
 
@@ -171,132 +327,144 @@ func (r *Renderer) renderElement(n *html.Node, out *strings.Builder, ctx *render
 		newCtx.inStrong = true
 
 	case "br":
-		out.WriteString("\n")
+		r.list.write("\n")
+		return
+
+	case "style", "script":
+		return
+
+	case "ruby":
+		r.addRun(rubyText(n), ctx)
 		return
 
 	case "hr":
-		out.WriteString("\n\n")
-		style := lipgloss.NewStyle().Foreground(lipgloss.Color(r.theme.MutedTextColor))
-		out.WriteString(style.Render(strings.Repeat("─", min(r.width, 80))))
-		out.WriteString("\n\n")
+		r.list.write("\n\n")
+		if r.options.Plain {
+			return
+		}
+		r.list.add(block{kind: ruleBlock})
+		r.list.write("\n\n")
 		return
 
 	case "ul", "ol":
-		out.WriteString("\n")
+		r.list.write("\n")
 		newCtx.listLevel++
 
 	case "li":
-		indent := strings.Repeat("  ", ctx.listLevel-1)
-		out.WriteString("\n" + indent + "• ")
+		prefix := strings.Repeat("  ", ctx.listLevel-1)
+		if marker, ok := listNumber(n); ok {
+			prefix += marker + " "
+		} else if !r.options.Plain {
+			prefix += r.glyph("•", "*") + " "
+		}
+		r.list.write("\n" + prefix)
 		newCtx.inListItem = true
+		newCtx.listIndent = ansi.StringWidth(prefix)
+
+	case "a":
+		if href := attribute(n, "href"); href != "" {
+			newCtx.inLink = true
+			link := len(r.list.links)
+			r.list.links = append(r.list.links, linkPosition{href: href, start: r.list.position()})
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				r.renderNode(c, newCtx)
+			}
+			r.list.links[link].end = r.list.positionAfter()
+			return
+		}
+		if name := attribute(n, "name"); name != "" {
+			r.list.anchors[name] = r.list.position()
+		}
 
-	case "div", "span", "a":
+	case "span":
 		// Pass through, just render children
+
+	default:
+		// Other blocks, such as divs and table cells, start a new line
+		if blockLevel && !r.list.atLineStart() {
+			r.list.write("\n")
+		}
 	}
 
-	// Render children with new context
-	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		r.renderNode(c, out, newCtx)
+	// Render children with new context. A large heading is a block of its
+	// own, with the elements in it.
+	if newCtx.inHeading > 0 && ctx.inHeading == 0 && r.options.largeHeadings() {
+		r.renderLargeHeading(n, newCtx)
+	} else {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			r.renderNode(c, newCtx)
+		}
 	}
 
 	// Post-element formatting
+	if blockLevel {
+		r.flush()
+	}
 	switch n.Data {
 	case "h1", "h2", "h3", "h4", "h5", "h6":
-		out.WriteString("\n")
+		r.list.write("\n")
 	case "blockquote", "pre":
-		out.WriteString("\n")
+		r.list.write("\n")
 	case "ul", "ol":
-		out.WriteString("\n")
+		r.list.write("\n")
 	}
-}
-
-// writeStyledText applies styling and writes text
-func (r *Renderer) writeStyledText(out *strings.Builder, text string, ctx *renderContext) {
-	style := lipgloss.NewStyle().Foreground(lipgloss.Color(r.theme.TextColor))
-
-	// Calculate effective width (accounting for borders and padding)
-	effectiveWidth := r.width
-	if effectiveWidth <= 0 {
-		effectiveWidth = 80
+	if hints.breakAfter {
+		r.flush()
+		r.list.write("\n\n")
 	}
+}
 
-	// Apply context-specific styling
-	if ctx.inHeading > 0 {
-		style = style.
-			Foreground(lipgloss.Color(r.theme.HeadingColor)).
-			Bold(true)
-
-		// Add heading prefix
-		prefix := strings.Repeat("#", ctx.inHeading) + " "
-		text = prefix + text
-
-		// Wrap heading text
-		text = wordwrap.String(text, effectiveWidth)
+// renderMath writes an equation as linear text, on its own line when it
+// is displayed as a block
+func (r *Renderer) renderMath(n *html.Node, ctx *renderContext) {
+	text := mathText(n)
+	if text == "" {
+		return
 	}
-
-	if ctx.inBlockquote {
-		// Wrap text before styling (account for border + padding = 4 chars)
-		wrappedText := wordwrap.String(text, max(effectiveWidth-4, 40))
-
-		// Format blockquote with left border and faded text
-		lines := strings.Split(wrappedText, "\n")
-		for i, line := range lines {
-			if strings.TrimSpace(line) == "" {
-				continue
-			}
-
-			quoteStyle := lipgloss.NewStyle().
-				Foreground(lipgloss.Color(r.theme.MutedTextColor)).
-				Italic(true).
-				BorderLeft(true).
-				BorderStyle(lipgloss.ThickBorder()).
-				BorderForeground(lipgloss.Color(r.theme.QuoteBorderColor)).
-				PaddingLeft(1)
-
-			out.WriteString(quoteStyle.Render(line))
-			if i < len(lines)-1 {
-				out.WriteString("\n")
-			}
-		}
+	ctx.inMath = true
+	if attribute(n, "display") != "block" {
+		r.addRun(text, ctx)
 		return
 	}
+	r.list.write("\n\n")
+	r.writeStyledText(text, ctx)
+	r.list.write("\n")
+}
 
-	if ctx.inCode {
-		style = style.
-			Foreground(lipgloss.Color(r.theme.CodeTextColor)).
-			Background(lipgloss.Color(r.theme.CodeBgColor))
-
-		if ctx.inPre {
-			style = style.Padding(0, 1)
-			// Wrap code blocks (account for padding = 2 chars)
-			text = wordwrap.String(text, max(effectiveWidth-2, 40))
-		} else {
-			style = style.Padding(0, 1)
+// listNumber returns the number of an item of an ordered list, such as
+// "3.", counting from the list's start attribute or the item's value
+func listNumber(n *html.Node) (string, bool) {
+	list := n.Parent
+	if list == nil || list.Data != "ol" {
+		return "", false
+	}
+	number, err := strconv.Atoi(attribute(list, "start"))
+	if err != nil {
+		number = 1
+	}
+	for item := list.FirstChild; item != nil; item = item.NextSibling {
+		if item.Type != html.ElementNode || item.Data != "li" {
+			continue
 		}
-	} else {
-		// Wrap regular text
-		text = wordwrap.String(text, effectiveWidth)
-
-		// Justify wrapped text (except for headings)
-		if ctx.inHeading == 0 && len(strings.TrimSpace(text)) > 0 {
-			text = justifyText(text, effectiveWidth)
+		if value, err := strconv.Atoi(attribute(item, "value")); err == nil {
+			number = value
 		}
-
-		// Apply inline formatting
-		if ctx.inEmphasis {
-			style = style.
-				Foreground(lipgloss.Color(r.theme.EmphasisColor)).
-				Italic(true)
+		if item == n {
+			break
 		}
+		number++
+	}
+	return strconv.Itoa(number) + ".", true
+}
 
-		if ctx.inStrong {
-			style = style.
-				Foreground(lipgloss.Color(r.theme.StrongColor)).
-				Bold(true)
+// attribute returns the value of an HTML attribute, or ""
+func attribute(n *html.Node, name string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == name {
+			return attr.Val
 		}
 	}
-
-	out.WriteString(style.Render(text))
+	return ""
 }
 
 func max(a, b int) int {
@@ -321,18 +489,13 @@ func RenderToStyledText(htmlContent string, theme *config.Theme, width int) stri
 
 // RenderToStyledTextWithHeadings renders HTML and returns heading positions
 func RenderToStyledTextWithHeadings(htmlContent string, theme *config.Theme, width int) RenderResult {
-	renderer := NewRenderer(theme, width)
-	result := renderer.RenderWithHeadings(htmlContent)
-
-	// If rendering produced no output, fall back to simple text extraction
-	if strings.TrimSpace(result.Text) == "" {
-		return RenderResult{
-			Text:            htmlToText(htmlContent),
-			HeadingPositions: []int{},
-		}
-	}
+	return RenderWithOptions(htmlContent, theme, width, DefaultRenderOptions())
+}
 
-	return result
+// RenderWithOptions renders HTML with the given layout options and returns
+// its headings
+func RenderWithOptions(htmlContent string, theme *config.Theme, width int, options RenderOptions) RenderResult {
+	return ParseDocument(htmlContent, options).render(theme, width)
 }
 
 func min(a, b int) int {
@@ -367,7 +530,7 @@ func justifyText(text string, width int) string {
 		// 2. It's a single-line paragraph (only one line total)
 		// 3. It has only one word
 		// 4. The line is significantly shorter than width (likely already a last line)
-		lineLen := len(line)
+		lineLen := ansi.StringWidth(line)
 		if isLastLine || len(lines) == 1 || len(words) <= 1 || lineLen < int(float64(width)*0.75) {
 			justified = append(justified, line)
 			continue
@@ -376,7 +539,7 @@ func justifyText(text string, width int) string {
 		// Calculate total word length
 		wordLen := 0
 		for _, word := range words {
-			wordLen += len(word)
+			wordLen += ansi.StringWidth(word)
 		}
 
 		// Calculate how many spaces we need to distribute
@@ -0,0 +1,142 @@
+package ebook
+
+import (
+	"crypto/sha1"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// Searching a whole book would mean reading every chapter, so the first
+// time a book is opened its words are indexed, and the index is cached.
+// Like cover thumbnails, the cache is keyed on the file's size and
+// modification time, so a changed book is indexed again.
+
+// Bumped when the index format changes, so old caches are not read
+const searchIndexVersion = 1
+
+// SearchIndex lists where each word of a book is
+type SearchIndex struct {
+	Words map[string][]WordPosition // Key is the lowercased word
+}
+
+// WordPosition is a word of a chapter, counting from 0
+type WordPosition struct {
+	Chapter int
+	Word    int
+}
+
+// LoadSearchIndex returns the search index of a book, from cacheDir or
+// by opening the book and indexing it
+func LoadSearchIndex(path string, options Options, cacheDir string) (*SearchIndex, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// The options decide how the book is split into chapters
+	hash := sha1.Sum([]byte(fmt.Sprintf("%d|%s|%d|%d|%+v", searchIndexVersion, path, info.Size(), info.ModTime().UnixNano(), options)))
+	cachePath := filepath.Join(cacheDir, hex.EncodeToString(hash[:])+".gob")
+
+	if file, err := os.Open(cachePath); err == nil {
+		defer file.Close()
+		var index SearchIndex
+		if err := gob.NewDecoder(file).Decode(&index); err == nil {
+			return &index, nil
+		}
+	}
+
+	book, err := OpenBook(path, options)
+	if err != nil {
+		return nil, err
+	}
+	defer book.Close()
+	index := BuildSearchIndex(book)
+
+	// Caching is best effort; a failed write just means indexing next time
+	if err := os.MkdirAll(cacheDir, 0755); err == nil {
+		if file, err := os.Create(cachePath); err == nil {
+			gob.NewEncoder(file).Encode(index)
+			file.Close()
+		}
+	}
+
+	return index, nil
+}
+
+// BuildSearchIndex indexes the words of every chapter of a book
+func BuildSearchIndex(book *Book) *SearchIndex {
+	index := &SearchIndex{Words: make(map[string][]WordPosition)}
+	for i := range book.Chapters {
+		text := book.Chapters[i].Text()
+		if book.Chapters[i].HTML {
+			text = ExtractPlainText(text)
+		}
+		for n, word := range searchWords(text) {
+			index.Words[word] = append(index.Words[word], WordPosition{Chapter: i, Word: n})
+		}
+	}
+	return index
+}
+
+// searchWords splits text into lowercased words of letters and digits
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.Is(unicode.Mn, r)
+	})
+}
+
+// Chapters counts the places each chapter has the words of a query, in
+// order. Unless wholeWords is set, the last word may be the start of a
+// longer one, as it is often still being typed.
+func (i *SearchIndex) Chapters(query string, wholeWords bool) map[int]int {
+	words := searchWords(query)
+	if len(words) == 0 {
+		return nil
+	}
+
+	// Where the first word is, and where each word after it has to follow
+	var starts []WordPosition
+	var following []map[WordPosition]bool
+	for n, word := range words {
+		var positions []WordPosition
+		if n == len(words)-1 && !wholeWords {
+			for candidate, found := range i.Words {
+				if strings.HasPrefix(candidate, word) {
+					positions = append(positions, found...)
+				}
+			}
+		} else {
+			positions = i.Words[word]
+		}
+
+		if n == 0 {
+			starts = positions
+			continue
+		}
+		set := make(map[WordPosition]bool, len(positions))
+		for _, position := range positions {
+			set[WordPosition{Chapter: position.Chapter, Word: position.Word - n}] = true
+		}
+		following = append(following, set)
+	}
+
+	counts := make(map[int]int)
+	for _, start := range starts {
+		matched := true
+		for _, set := range following {
+			if !set[start] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			counts[start.Chapter]++
+		}
+	}
+	return counts
+}
@@ -0,0 +1,56 @@
+package ebook
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Some readers, many with dyslexia, find text easier to follow with more
+// room between words and letters. Terminal cells can't be narrowed, so
+// letters are spaced by a whole cell: a no-break space, which keeps each
+// word on one line when wrapping. Letter spacing widens the gaps between
+// words by a space too, so words still stand apart.
+
+// spaced reports whether the options spread text out
+func (o RenderOptions) spaced() bool {
+	return o.LetterSpacing || o.WordSpacing > 0
+}
+
+// spaceText spreads out the words, and with letter spacing the letters,
+// of text
+func spaceText(text string, options RenderOptions) string {
+	if !options.spaced() {
+		return text
+	}
+	gap := strings.Repeat(" ", 1+max(options.WordSpacing, 0)+boolInt(options.LetterSpacing))
+
+	var b strings.Builder
+	var prev rune
+	for _, r := range text {
+		switch {
+		case r == ' ':
+			b.WriteString(gap)
+		case options.LetterSpacing && prev != 0 && !unicode.IsSpace(prev) && !unicode.IsSpace(r) &&
+			!unicode.Is(unicode.Mn, r) && !isCJK(r) && !isCJK(prev):
+			b.WriteRune(nbsp)
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+		prev = r
+	}
+	return b.String()
+}
+
+// wrapSpaced wraps spaced out plain text without breaking inside words
+func wrapSpaced(text string, width int) string {
+	text = strings.ReplaceAll(text, string(nbsp), string(nbspPlaceholder))
+	return strings.ReplaceAll(WrapText(text, width), string(nbspPlaceholder), string(nbsp))
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,119 @@
+package ebook
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/net/html"
+)
+
+// svgLine is the text of one <text> element and where it is drawn
+type svgLine struct {
+	text string
+	x, y float64
+}
+
+// renderSVG writes the text drawn by an SVG, top to bottom, or a
+// placeholder when the SVG is only graphics. Title pages and covers are
+// often an SVG of text over an image.
+func (r *Renderer) renderSVG(n *html.Node, ctx *renderContext) {
+	lines := svgLines(n)
+	r.list.write("\n\n")
+	if len(lines) == 0 {
+		r.list.add(block{kind: placeholderBlock, text: svgTitle(n)})
+		r.list.write("\n")
+		return
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool {
+		if lines[i].y != lines[j].y {
+			return lines[i].y < lines[j].y
+		}
+		return lines[i].x < lines[j].x
+	})
+	for i, line := range lines {
+		if i > 0 {
+			r.list.write("\n")
+		}
+		r.writeStyledText(line.text, ctx)
+	}
+	r.list.write("\n")
+}
+
+// writePlaceholder stands in for an image that can't be shown
+func (r *Renderer) writePlaceholder(out *strings.Builder, label string) {
+	text := "[Image]"
+	if label != "" {
+		text = "[Image: " + label + "]"
+	}
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color(r.theme.MutedTextColor)).Italic(true)
+	out.WriteString(style.Render(WrapText(text, max(r.width, 20))))
+}
+
+// svgLines collects the <text> elements of an SVG
+func svgLines(n *html.Node) []svgLine {
+	var lines []svgLine
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			switch localName(c) {
+			case "text":
+				lines = append(lines, textLines(c)...)
+			case "title", "desc", "style", "script", "defs", "metadata":
+			default:
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	return lines
+}
+
+// textLines returns the lines of a <text> element. Text set on several lines
+// puts each line in a <tspan> with its own position.
+func textLines(n *html.Node) []svgLine {
+	var lines []svgLine
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && localName(c) == "tspan" && attribute(c, "y") != "" {
+			if text := strings.Join(strings.Fields(textContent(c)), " "); text != "" {
+				lines = append(lines, svgLine{text: text, x: svgCoordinate(c, "x"), y: svgCoordinate(c, "y")})
+			}
+		}
+	}
+	if len(lines) > 0 {
+		return lines
+	}
+	if text := strings.Join(strings.Fields(textContent(n)), " "); text != "" {
+		lines = append(lines, svgLine{text: text, x: svgCoordinate(n, "x"), y: svgCoordinate(n, "y")})
+	}
+	return lines
+}
+
+// svgTitle describes a graphical SVG from its title or label
+func svgTitle(n *html.Node) string {
+	if label := attribute(n, "aria-label"); label != "" {
+		return label
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && localName(c) == "title" {
+			return strings.Join(strings.Fields(textContent(c)), " ")
+		}
+	}
+	return ""
+}
+
+// svgCoordinate reads the first value of a coordinate attribute, which may
+// be a list such as x="10 20 30"
+func svgCoordinate(n *html.Node, name string) float64 {
+	fields := strings.FieldsFunc(attribute(n, name), func(r rune) bool { return r == ' ' || r == ',' })
+	if len(fields) == 0 {
+		return 0
+	}
+	value, _ := strconv.ParseFloat(strings.TrimRight(fields[0], "px"), 64)
+	return value
+}
@@ -1,56 +1,236 @@
 package ebook
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 )
 
 // TextReader reads plain text files
-type TextReader struct{}
+type TextReader struct {
+	Options Options
+}
 
 const charsPerPage = 2000 // Approximate characters per page
 
+// TextSplitMode controls how plain text files are divided into chapters
+type TextSplitMode string
+
+const (
+	TextSplitAuto     TextSplitMode = "auto"     // Headings, then blank-line runs, then sections
+	TextSplitHeadings TextSplitMode = "headings" // Lines like "Chapter 12" or "XIV"
+	TextSplitBlank    TextSplitMode = "blank"    // Runs of several blank lines
+	TextSplitSections TextSplitMode = "sections" // Fixed-size sections
+	TextSplitNone     TextSplitMode = "none"     // Whole file as one chapter
+)
+
+// Minimum number of consecutive blank lines treated as a chapter break
+const blankRunLength = 3
+
+var (
+	chapterHeadingPattern = regexp.MustCompile(`(?i)^(chapter|chap\.|book|part|letter|section|canto|stave)\s+([0-9]+|[ivxlcdm]+|one|two|three|four|five|six|seven|eight|nine|ten|eleven|twelve|thirteen|fourteen|fifteen|sixteen|seventeen|eighteen|nineteen|twenty|the\s+\w+)\b`)
+	numeralHeadingPattern = regexp.MustCompile(`^([IVXLCDM]+|[0-9]{1,3})\.?$`)
+)
+
 // Read reads a plain text file
 func (r *TextReader) Read(path string) (*Book, error) {
-	file, err := os.Open(path)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open text file: %w", err)
 	}
-	defer file.Close()
 
 	book := &Book{
 		Title:    strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
 		Metadata: make(map[string]string),
 	}
 
-	// Read entire file
-	var fullText strings.Builder
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		fullText.WriteString(scanner.Text())
-		fullText.WriteString("\n")
+	// Convert to UTF-8 and normalize line endings
+	content := decodeText(data, false)
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.ReplaceAll(content, "\r", "\n")
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("failed to read text file: %w", err)
+	if r.Options.GutenbergCleanup {
+		cleaned, title, author := cleanGutenbergText(content)
+		content = cleaned
+		if title != "" {
+			book.Title = title
+		}
+		if author != "" {
+			book.Author = author
+			book.Contributors = []Contributor{{Name: author, Role: RoleAuthor}}
+		}
 	}
 
-	// For plain text, treat the entire file as one chapter
-	content := fullText.String()
-	book.Chapters = []Chapter{
-		{
-			Title:   book.Title,
-			Content: content,
-			Order:   0,
-		},
+	book.Chapters = splitTextChapters(content, book.Title, r.Options)
+
+	// Cleaned text is rendered like EPUB content so emphasis gets styled
+	if r.Options.GutenbergCleanup {
+		for i := range book.Chapters {
+			book.Chapters[i].Content = plainTextToHTML(book.Chapters[i].Content)
+			book.Chapters[i].HTML = true
+		}
 	}
 
 	return book, nil
 }
 
+// splitTextChapters divides plain text into chapters according to the options
+func splitTextChapters(content, title string, options Options) []Chapter {
+	mode := options.TextSplit
+	if mode == "" {
+		mode = TextSplitAuto
+	}
+
+	var chapters []Chapter
+	switch mode {
+	case TextSplitHeadings:
+		chapters = splitOnHeadings(content, title)
+	case TextSplitBlank:
+		chapters = splitOnBlankRuns(content)
+	case TextSplitSections:
+		chapters = splitIntoSections(content, options.TextSectionSize)
+	case TextSplitAuto:
+		chapters = splitOnHeadings(content, title)
+		if len(chapters) < 2 {
+			chapters = splitOnBlankRuns(content)
+		}
+		if len(chapters) < 2 {
+			chapters = splitIntoSections(content, options.TextSectionSize)
+		}
+	}
+
+	// For unsplittable text, treat the entire file as one chapter
+	if len(chapters) < 2 {
+		return []Chapter{
+			{
+				Title:   title,
+				Content: content,
+				Order:   0,
+			},
+		}
+	}
+
+	for i := range chapters {
+		chapters[i].Order = i
+	}
+	return chapters
+}
+
+// splitOnHeadings starts a new chapter at every heading-like line that
+// stands on its own after a blank line
+func splitOnHeadings(content, title string) []Chapter {
+	lines := strings.Split(content, "\n")
+
+	var chapters []Chapter
+	var current strings.Builder
+	currentTitle := title
+	headings := 0
+
+	flush := func() {
+		if strings.TrimSpace(current.String()) != "" {
+			chapters = append(chapters, Chapter{Title: currentTitle, Content: current.String()})
+		}
+		current.Reset()
+	}
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		previousBlank := i == 0 || strings.TrimSpace(lines[i-1]) == ""
+		nextBlank := i == len(lines)-1 || strings.TrimSpace(lines[i+1]) == ""
+
+		isHeading := previousBlank && len(trimmed) > 0 && len(trimmed) < 80 &&
+			(chapterHeadingPattern.MatchString(trimmed) ||
+				(nextBlank && numeralHeadingPattern.MatchString(trimmed)))
+
+		if isHeading {
+			flush()
+			currentTitle = trimmed
+			headings++
+		}
+
+		current.WriteString(line)
+		current.WriteString("\n")
+	}
+	flush()
+
+	if headings < 2 {
+		return nil
+	}
+	return chapters
+}
+
+// splitOnBlankRuns starts a new chapter after several consecutive blank lines
+func splitOnBlankRuns(content string) []Chapter {
+	lines := strings.Split(content, "\n")
+
+	var chapters []Chapter
+	var current strings.Builder
+	blanks := 0
+
+	flush := func() {
+		text := current.String()
+		if strings.TrimSpace(text) != "" {
+			chapters = append(chapters, Chapter{Title: firstLine(text), Content: text})
+		}
+		current.Reset()
+	}
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			blanks++
+			continue
+		}
+
+		if blanks >= blankRunLength {
+			flush()
+		} else {
+			current.WriteString(strings.Repeat("\n", blanks))
+		}
+		blanks = 0
+
+		current.WriteString(line)
+		current.WriteString("\n")
+	}
+	flush()
+
+	return chapters
+}
+
+// splitIntoSections groups pages into fixed-size sections
+func splitIntoSections(content string, sectionSize int) []Chapter {
+	if sectionSize <= 0 {
+		sectionSize = 10 * charsPerPage
+	}
+
+	var chapters []Chapter
+	for i, section := range splitIntoPages(content, sectionSize) {
+		chapters = append(chapters, Chapter{
+			Title:   fmt.Sprintf("Section %d", i+1),
+			Content: section,
+		})
+	}
+	return chapters
+}
+
+// firstLine returns the first non-blank line of text, shortened for use as a title
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			runes := []rune(line)
+			if len(runes) > 60 {
+				return string(runes[:59]) + "…"
+			}
+			return line
+		}
+	}
+	return ""
+}
+
 // splitIntoPages splits text into pages of approximately equal size
 func splitIntoPages(text string, charsPerPage int) []string {
 	if len(text) == 0 {
@@ -0,0 +1,104 @@
+package ebook
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	nbsp = '\u00a0'
+	// Stands in for no-break spaces while wrapping, since the wrapper
+	// breaks lines at every Unicode space. It has the same width.
+	nbspPlaceholder = '\ue000' // Private use
+)
+
+var typographyReplacer = strings.NewReplacer(
+	"---", "—",
+	"--", "—",
+	"...", "…",
+	". . .", "…",
+)
+
+// French puts a no-break space before these and inside guillemets
+var frenchSpacing = strings.NewReplacer(
+	" ;", "\u00a0;",
+	" :", "\u00a0:",
+	" !", "\u00a0!",
+	" ?", "\u00a0?",
+	" »", "\u00a0»",
+	"« ", "«\u00a0",
+)
+
+// smarten applies typographic conventions to a text node: curly quotes,
+// em dashes and ellipses, and French punctuation spacing. Quotes are
+// decided by the character before them, which may be in an earlier node.
+func (r *Renderer) smarten(text string, spaceBefore bool) string {
+	text = typographyReplacer.Replace(text)
+
+	previous := r.lastRune
+	if spaceBefore {
+		previous = ' '
+	}
+
+	var b strings.Builder
+	runes := []rune(text)
+	for i, c := range runes {
+		var next rune
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+		switch c {
+		case '"':
+			if opensQuote(previous) {
+				c = '“'
+			} else {
+				c = '”'
+			}
+		case '\'':
+			// Apostrophes and closing quotes look the same
+			if opensQuote(previous) && next != 0 && !unicode.IsSpace(next) {
+				c = '‘'
+			} else {
+				c = '’'
+			}
+		}
+		b.WriteRune(c)
+		previous = c
+	}
+	text = b.String()
+
+	if strings.HasPrefix(strings.ToLower(r.options.Language), "fr") {
+		text = frenchPunctuation(text)
+	}
+	return text
+}
+
+// opensQuote reports whether a quote after this character opens a quotation
+func opensQuote(previous rune) bool {
+	return previous == 0 || unicode.IsSpace(previous) || strings.ContainsRune("([{—–-/“‘«", previous)
+}
+
+// frenchPunctuation joins ; : ! ? and guillemets to their words with a
+// no-break space, adding one where the text has none
+func frenchPunctuation(text string) string {
+	text = frenchSpacing.Replace(text)
+
+	var b strings.Builder
+	runes := []rune(text)
+	for i, c := range runes {
+		if i > 0 && strings.ContainsRune(";!?", c) && unicode.IsLetter(runes[i-1]) {
+			b.WriteRune(nbsp)
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// lastRuneOf returns the last character of text, or 0 if it is empty
+func lastRuneOf(text string) rune {
+	runes := []rune(text)
+	if len(runes) == 0 {
+		return 0
+	}
+	return runes[len(runes)-1]
+}
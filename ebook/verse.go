@@ -0,0 +1,97 @@
+package ebook
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Poems keep their line breaks. Verse is indented instead of justified, and
+// a line too long for the screen continues on an indented line below.
+const (
+	verseIndent     = 4
+	verseHangIndent = 2  // Extra indent of a verse line's continuation
+	maxVerseLine    = 70 // Longer lines between <br>s are prose, not verse
+)
+
+var verseClass = regexp.MustCompile(`(?i)poem|poetry|verse|stanza|lyric|song`)
+
+// isVerse reports whether an element holds verse: it has a poetry class,
+// keeps its whitespace through CSS, or is short lines separated by <br>
+func isVerse(n *html.Node, hints styleHints) bool {
+	if n.Data == "pre" {
+		return false
+	}
+	if verseClass.MatchString(attribute(n, "class")) {
+		return true
+	}
+	switch hints.whiteSpace {
+	case "pre", "pre-line", "pre-wrap":
+		return true
+	}
+	return hasBrokenLines(n)
+}
+
+// hasBrokenLines reports whether an element is three or more lines
+// separated by <br>, most of them short
+func hasBrokenLines(n *html.Node) bool {
+	var lines []string
+	var line strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == "br" {
+			lines = append(lines, line.String())
+			line.Reset()
+			continue
+		}
+		if c.Type == html.ElementNode && isBlockElement(c.Data) {
+			return false
+		}
+		line.WriteString(textContent(c))
+	}
+	lines = append(lines, line.String())
+
+	long := 0
+	for _, line := range lines {
+		if len([]rune(strings.TrimSpace(line))) > maxVerseLine {
+			long++
+		}
+	}
+	return len(lines) >= 3 && long <= len(lines)/3
+}
+
+func isBlockElement(tag string) bool {
+	switch tag {
+	case "p", "div", "blockquote", "ul", "ol", "table", "h1", "h2", "h3", "h4", "h5", "h6", "pre":
+		return true
+	}
+	return false
+}
+
+// verseText lays out lines of verse: each line is indented and wrapped
+// with a hanging indent. atLineStart is false when the text continues a
+// line that is already indented.
+func verseText(text string, width int, atLineStart bool) string {
+	indent := strings.Repeat(" ", verseIndent)
+	hang := indent + strings.Repeat(" ", verseHangIndent)
+	lineWidth := max(width-verseIndent-verseHangIndent, 20)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			lines[i] = ""
+			continue
+		}
+		wrapped := strings.Split(WrapText(strings.TrimSpace(line), lineWidth), "\n")
+		for j := range wrapped {
+			switch {
+			case j > 0:
+				wrapped[j] = hang + wrapped[j]
+			case i > 0 || atLineStart:
+				wrapped[j] = indent + wrapped[j]
+			}
+		}
+		lines[i] = strings.Join(wrapped, "\n")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,66 @@
+package ebook
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cbrasser/cozy/config"
+)
+
+// ExportVocabulary writes the vocabulary list of a language to an Anki
+// TSV file named after the language in destDir, replacing an earlier
+// export, and returns its path
+func ExportVocabulary(destDir, language string, words []config.VocabularyWord) (string, error) {
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return "", fmt.Errorf("destination not available: %w", err)
+	}
+
+	target := filepath.Join(destDir, "vocabulary-"+BookFileName(language, "")+".tsv")
+	if err := os.WriteFile(target, []byte(VocabularyTSV(language, words)), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", target, err)
+	}
+	return target, nil
+}
+
+// VocabularyTSV formats a vocabulary list for Anki's text import: a note
+// per word with the word, the sentence it was found in with the word in
+// bold, and the book, tagged with the language. The header lines tell
+// Anki how to read the file.
+func VocabularyTSV(language string, words []config.VocabularyWord) string {
+	var b strings.Builder
+	b.WriteString("#separator:tab\n")
+	b.WriteString("#html:true\n")
+	b.WriteString("#columns:Word\tContext\tBook\tTags\n")
+	b.WriteString("#tags column:4\n")
+
+	tags := "cozy vocabulary::" + strings.ReplaceAll(language, " ", "_")
+	for _, word := range words {
+		fields := []string{
+			html.EscapeString(tsvField(word.Word)),
+			boldWord(tsvField(word.Context), tsvField(word.Word)),
+			html.EscapeString(tsvField(word.Book)),
+			tags,
+		}
+		b.WriteString(strings.Join(fields, "\t") + "\n")
+	}
+	return b.String()
+}
+
+// tsvField puts text on one line without tabs
+func tsvField(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// boldWord escapes a sentence for HTML with the first occurrence of a word
+// in bold
+func boldWord(sentence, word string) string {
+	i := strings.Index(strings.ToLower(sentence), strings.ToLower(word))
+	if word == "" || i < 0 || len(strings.ToLower(sentence)) != len(sentence) {
+		return html.EscapeString(sentence)
+	}
+	return html.EscapeString(sentence[:i]) + "<b>" + html.EscapeString(sentence[i:i+len(word)]) + "</b>" +
+		html.EscapeString(sentence[i+len(word):])
+}
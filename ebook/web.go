@@ -0,0 +1,127 @@
+package ebook
+
+import (
+	"fmt"
+	htmlpkg "html"
+	"net/url"
+	"path"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Chapters can be shown in a browser as well, for cozy serve. The chapter
+// HTML is cut down to its body without scripts, styles, forms, embedded
+// content or event handlers, so a book can't run code in the page, and
+// links and images are pointed at the URLs serving the book.
+
+// WebLinks maps the links of a chapter to URLs of the server
+type WebLinks struct {
+	Chapter  func(index int, fragment string) string // Another place in the book
+	Resource func(name string) string                // A file of the book, by its path inside the EPUB
+}
+
+// Elements dropped with their content
+var webDropped = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Link:     true,
+	atom.Meta:     true,
+	atom.Title:    true,
+	atom.Iframe:   true,
+	atom.Object:   true,
+	atom.Embed:    true,
+	atom.Form:     true,
+	atom.Input:    true,
+	atom.Button:   true,
+	atom.Textarea: true,
+	atom.Select:   true,
+	atom.Frame:    true,
+	atom.Frameset: true,
+	atom.Base:     true,
+}
+
+// WebChapter returns the body of a chapter as HTML for a browser. Plain
+// text chapters become paragraphs.
+func (b *Book) WebChapter(index int, links WebLinks) (string, error) {
+	chapter := b.GetChapter(index)
+	if chapter == nil {
+		return "", fmt.Errorf("chapter %d not found", index+1)
+	}
+	if !chapter.HTML {
+		var out strings.Builder
+		for _, paragraph := range strings.Split(strings.ReplaceAll(chapter.Text(), "\r\n", "\n"), "\n\n") {
+			if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+				out.WriteString("<p>" + strings.ReplaceAll(htmlpkg.EscapeString(paragraph), "\n", "<br>") + "</p>\n")
+			}
+		}
+		return out.String(), nil
+	}
+
+	doc, err := html.Parse(strings.NewReader(chapter.Text()))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse chapter: %w", err)
+	}
+	body := findElement(doc, "body")
+	if body == nil {
+		body = doc
+	}
+	b.cleanWebNode(body, index, links)
+
+	var out strings.Builder
+	for child := body.FirstChild; child != nil; child = child.NextSibling {
+		if err := html.Render(&out, child); err != nil {
+			return "", fmt.Errorf("failed to render chapter: %w", err)
+		}
+	}
+	return out.String(), nil
+}
+
+// cleanWebNode drops what a browser shouldn't run from the children of a
+// node and rewrites their links
+func (b *Book) cleanWebNode(n *html.Node, index int, links WebLinks) {
+	for child := n.FirstChild; child != nil; {
+		next := child.NextSibling
+		switch {
+		case child.Type == html.CommentNode || (child.Type == html.ElementNode && webDropped[child.DataAtom]):
+			n.RemoveChild(child)
+		case child.Type == html.ElementNode:
+			b.cleanWebAttributes(child, index, links)
+			b.cleanWebNode(child, index, links)
+		}
+		child = next
+	}
+}
+
+// cleanWebAttributes drops event handlers and styles, and points links
+// and images at the server
+func (b *Book) cleanWebAttributes(n *html.Node, index int, links WebLinks) {
+	from := b.Chapters[index].Href
+	var kept []html.Attribute
+	for _, attr := range n.Attr {
+		key := strings.ToLower(attr.Key)
+		// SVG images name their file with href or xlink:href
+		resource := key == "src" || key == "poster" || (key == "href" && (attr.Namespace == "xlink" || n.Data == "image"))
+		switch {
+		case strings.HasPrefix(key, "on") || key == "style" || key == "srcset":
+			continue
+		case resource:
+			target, err := url.Parse(attr.Val)
+			if err != nil || target.Scheme != "" || target.Path == "" {
+				continue
+			}
+			attr.Val = links.Resource(path.Join(path.Dir(from), target.Path))
+		case key == "href" && n.DataAtom == atom.A:
+			if chapter, fragment, ok := b.ResolveLink(index, attr.Val); ok {
+				attr.Val = links.Chapter(chapter, fragment)
+			} else if target, err := url.Parse(attr.Val); err != nil || (target.Scheme != "http" && target.Scheme != "https" && target.Scheme != "mailto") {
+				continue
+			}
+		case key == "href" || key == "action" || key == "formaction":
+			continue
+		}
+		kept = append(kept, attr)
+	}
+	n.Attr = kept
+}
@@ -0,0 +1,205 @@
+package ebook
+
+import (
+	"archive/zip"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"os"
+	"strings"
+	"time"
+
+	nethtml "golang.org/x/net/html"
+)
+
+// WriteEPUB writes the book as a minimal EPUB 3 file. Plain text chapters
+// are converted to XHTML paragraphs.
+func WriteEPUB(book *Book, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create EPUB file: %w", err)
+	}
+
+	if err := writeEPUB(book, file); err != nil {
+		file.Close()
+		os.Remove(path)
+		return err
+	}
+
+	return file.Close()
+}
+
+// writeEPUB writes the EPUB archive to an open file
+func writeEPUB(book *Book, file *os.File) error {
+	archive := zip.NewWriter(file)
+
+	// The mimetype entry must come first and be stored uncompressed
+	mimetype, err := archive.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return fmt.Errorf("failed to write EPUB: %w", err)
+	}
+	if _, err := mimetype.Write([]byte("application/epub+zip")); err != nil {
+		return fmt.Errorf("failed to write EPUB: %w", err)
+	}
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"META-INF/container.xml", epubContainerXML},
+		{"OEBPS/content.opf", epubPackage(book)},
+		{"OEBPS/nav.xhtml", epubNav(book)},
+	}
+	for i, chapter := range book.Chapters {
+		files = append(files, struct {
+			name    string
+			content string
+		}{fmt.Sprintf("OEBPS/chapter%03d.xhtml", i+1), chapterXHTML(chapter)})
+	}
+
+	modified := time.Now()
+	for _, f := range files {
+		w, err := archive.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return fmt.Errorf("failed to write EPUB: %w", err)
+		}
+		if _, err := w.Write([]byte(f.content)); err != nil {
+			return fmt.Errorf("failed to write EPUB: %w", err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to write EPUB: %w", err)
+	}
+	return nil
+}
+
+const epubContainerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+// epubPackage builds the OPF package document
+func epubPackage(book *Book) string {
+	esc := html.EscapeString
+
+	// A stable identifier derived from title and author
+	hash := sha1.Sum([]byte(book.Title + "|" + book.Author))
+	identifier := "urn:cozy:" + hex.EncodeToString(hash[:8])
+
+	language := book.Metadata["language"]
+	if language == "" {
+		language = "en"
+	}
+
+	var metadata strings.Builder
+	fmt.Fprintf(&metadata, "    <dc:identifier id=\"book-id\">%s</dc:identifier>\n", identifier)
+	fmt.Fprintf(&metadata, "    <dc:title>%s</dc:title>\n", esc(book.Title))
+	fmt.Fprintf(&metadata, "    <dc:language>%s</dc:language>\n", esc(language))
+
+	contributors := book.Contributors
+	if len(contributors) == 0 && book.Author != "" {
+		contributors = []Contributor{{Name: book.Author, Role: RoleAuthor}}
+	}
+	for i, contributor := range contributors {
+		element := "dc:contributor"
+		if contributor.Role == RoleAuthor {
+			element = "dc:creator"
+		}
+		fmt.Fprintf(&metadata, "    <%s id=\"contributor%d\">%s</%s>\n", element, i, esc(contributor.Name), element)
+		if contributor.Role != "" {
+			fmt.Fprintf(&metadata, "    <meta refines=\"#contributor%d\" property=\"role\" scheme=\"marc:relators\">%s</meta>\n", i, esc(contributor.Role))
+		}
+	}
+
+	for _, field := range []string{"publisher", "description", "date", "source"} {
+		if value := book.Metadata[field]; value != "" {
+			fmt.Fprintf(&metadata, "    <dc:%s>%s</dc:%s>\n", field, esc(value), field)
+		}
+	}
+	fmt.Fprintf(&metadata, "    <meta property=\"dcterms:modified\">%s</meta>\n", time.Now().UTC().Format("2006-01-02T15:04:05Z"))
+
+	var manifest, spine strings.Builder
+	manifest.WriteString("    <item id=\"nav\" href=\"nav.xhtml\" media-type=\"application/xhtml+xml\" properties=\"nav\"/>\n")
+	for i := range book.Chapters {
+		fmt.Fprintf(&manifest, "    <item id=\"chapter%03d\" href=\"chapter%03d.xhtml\" media-type=\"application/xhtml+xml\"/>\n", i+1, i+1)
+		fmt.Fprintf(&spine, "    <itemref idref=\"chapter%03d\"/>\n", i+1)
+	}
+
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+%s  </metadata>
+  <manifest>
+%s  </manifest>
+  <spine>
+%s  </spine>
+</package>
+`, metadata.String(), manifest.String(), spine.String())
+}
+
+// epubNav builds the EPUB 3 navigation document
+func epubNav(book *Book) string {
+	var items strings.Builder
+	for i, chapter := range book.Chapters {
+		fmt.Fprintf(&items, "      <li><a href=\"chapter%03d.xhtml\">%s</a></li>\n", i+1, html.EscapeString(chapter.Title))
+	}
+
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<head><title>%s</title></head>
+<body>
+  <nav epub:type="toc">
+    <ol>
+%s    </ol>
+  </nav>
+</body>
+</html>
+`, html.EscapeString(book.Title), items.String())
+}
+
+// chapterXHTML converts a chapter into a standalone XHTML document
+func chapterXHTML(chapter Chapter) string {
+	content := chapter.Text()
+	if !chapter.HTML {
+		content = plainTextToHTML(content)
+	}
+
+	// Re-serialize the body so HTML content becomes well-formed markup
+	var body strings.Builder
+	if doc, err := nethtml.Parse(strings.NewReader(content)); err == nil {
+		if bodyNode := findElement(doc, "body"); bodyNode != nil {
+			for c := bodyNode.FirstChild; c != nil; c = c.NextSibling {
+				nethtml.Render(&body, c)
+			}
+		}
+	}
+
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml">
+<head><title>%s</title></head>
+<body>
+%s
+</body>
+</html>
+`, html.EscapeString(chapter.Title), body.String())
+}
+
+// findElement returns the first element with the given tag name
+func findElement(n *nethtml.Node, tag string) *nethtml.Node {
+	if n.Type == nethtml.ElementNode && n.Data == tag {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findElement(c, tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
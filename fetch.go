@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/cbrasser/cozy/ebook"
+	"github.com/cbrasser/cozy/tui"
+)
+
+// runFetch downloads a book into the library, organized as Author/Title,
+// and opens it in the reader if asked to
+func runFetch(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("fetch", flag.ContinueOnError)
+	dest := flags.String("to", cfg.Library.Path, "library folder (defaults to library.path)")
+	convert := flags.Bool("convert", false, "convert plain text books to EPUB")
+	open := flags.Bool("open", false, "open the book once it is downloaded")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: cozy fetch [-to dir] [-convert] [-open] url")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("give one url")
+	}
+	if *dest == "" {
+		return errors.New("no library folder: set library.path or use -to")
+	}
+
+	options := ebook.ExportOptions{ConvertText: *convert, Book: tui.BookOptions(cfg)}
+	target, err := ebook.FetchBook(flags.Arg(0), *dest, options)
+	switch {
+	case errors.Is(err, ebook.ErrDuplicate):
+		fmt.Printf("already in the library as %s\n", target)
+	case err != nil:
+		return err
+	default:
+		fmt.Printf("downloaded %s -> %s\n", flags.Arg(0), target)
+	}
+
+	if !*open {
+		return nil
+	}
+	cfg.Reading.CurrentBook = target
+	return runReader(cfg, true)
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/cbrasser/cozy/ebook"
+)
+
+// historyEntry is one book with reading progress, for export
+type historyEntry struct {
+	info     ebook.BookInfo
+	progress config.BookProgress
+	review   config.Review
+}
+
+// runExportHistory writes the reading history as a CSV file that Goodreads
+// or StoryGraph can import
+func runExportHistory(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("export-history", flag.ContinueOnError)
+	format := flags.String("format", "goodreads-csv", "goodreads-csv or storygraph-csv")
+	output := flags.String("o", "", "write to this file instead of standard output")
+	finishedOnly := flags.Bool("finished", false, "only export finished books")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: cozy export-history [-format goodreads-csv|storygraph-csv] [-o file] [-finished]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var write func(io.Writer, []historyEntry) error
+	switch *format {
+	case "goodreads-csv":
+		write = writeGoodreadsCSV
+	case "storygraph-csv":
+		write = writeStoryGraphCSV
+	default:
+		return fmt.Errorf("unknown format %q (available: goodreads-csv, storygraph-csv)", *format)
+	}
+
+	entries, err := readingHistory(cfg, *finishedOnly)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return errors.New("no reading history to export")
+	}
+
+	if *output == "" {
+		return write(os.Stdout, entries)
+	}
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := write(file, entries); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "exported %d books to %s\n", len(entries), *output)
+	return nil
+}
+
+// readingHistory collects the books with saved progress, with metadata
+// from the library. Books no longer in the library are named after their
+// file.
+func readingHistory(cfg *config.Config, finishedOnly bool) ([]historyEntry, error) {
+	progress, err := config.LoadProgress(cfg)
+	if err != nil {
+		return nil, err
+	}
+	reviews, err := config.LoadReviews(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	library := make(map[string]ebook.BookInfo)
+	if books, err := ebook.ListBooks(cfg.Library.Path); err == nil {
+		for _, book := range books {
+			library[book.Path] = book
+		}
+	}
+
+	var entries []historyEntry
+	for path, bookProgress := range progress.Books {
+		if finishedOnly && !bookProgress.Finished {
+			continue
+		}
+		info, ok := library[path]
+		if !ok {
+			info = ebook.BookInfo{Path: path, Title: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))}
+		}
+		entries = append(entries, historyEntry{info: info, progress: bookProgress, review: reviews.Books[path]})
+	}
+
+	// Most recently finished first, then the books still being read
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i].progress, entries[j].progress
+		if !a.FinishedAt.Equal(b.FinishedAt) {
+			return a.FinishedAt.After(b.FinishedAt)
+		}
+		return entries[i].info.Title < entries[j].info.Title
+	})
+	return entries, nil
+}
+
+// shelf returns the Goodreads exclusive shelf of a book
+func (e historyEntry) shelf() string {
+	if e.progress.Finished {
+		return "read"
+	}
+	return "currently-reading"
+}
+
+// dateRead returns the finish date in the form both sites import
+func (e historyEntry) dateRead() string {
+	if e.progress.FinishedAt.IsZero() {
+		return ""
+	}
+	return e.progress.FinishedAt.Format("2006/01/02")
+}
+
+// tagShelves turns library folders into shelf names such as science-fiction
+func (e historyEntry) tagShelves() []string {
+	var shelves []string
+	for _, tag := range e.info.Tags {
+		shelves = append(shelves, strings.ToLower(strings.Join(strings.Fields(tag), "-")))
+	}
+	return shelves
+}
+
+// rating returns the reader's own rating, or else the one in the book's
+// metadata
+func (e historyEntry) rating() string {
+	if e.review.Rating > 0 {
+		return strconv.Itoa(e.review.Rating)
+	}
+	return e.info.Metadata["rating"]
+}
+
+func (e historyEntry) author() string {
+	if authors := e.info.Contributors; len(authors) > 0 {
+		var names []string
+		for _, contributor := range authors {
+			if contributor.Role == ebook.RoleAuthor {
+				names = append(names, contributor.Name)
+			}
+		}
+		if len(names) > 0 {
+			return strings.Join(names, ", ")
+		}
+	}
+	return e.info.Author
+}
+
+// writeGoodreadsCSV writes the columns of a Goodreads library export
+func writeGoodreadsCSV(w io.Writer, entries []historyEntry) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"Title", "Author", "ISBN", "My Rating", "Publisher", "Year Published",
+		"Date Read", "Bookshelves", "Exclusive Shelf", "My Review"})
+	for _, e := range entries {
+		year, _, _ := strings.Cut(e.info.Metadata["date"], "-")
+		out.Write([]string{
+			e.info.Title,
+			e.author(),
+			e.info.Metadata["isbn"],
+			e.rating(),
+			e.info.Metadata["publisher"],
+			year,
+			e.dateRead(),
+			strings.Join(append(e.tagShelves(), e.shelf()), ", "),
+			e.shelf(),
+			e.review.Text,
+		})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// writeStoryGraphCSV writes the columns StoryGraph's import reads
+func writeStoryGraphCSV(w io.Writer, entries []historyEntry) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"Title", "Authors", "ISBN/UID", "Format", "Read Status", "Last Date Read", "Star Rating", "Review", "Tags"})
+	for _, e := range entries {
+		out.Write([]string{
+			e.info.Title,
+			e.author(),
+			e.info.Metadata["isbn"],
+			"digital",
+			e.shelf(),
+			e.dateRead(),
+			e.rating(),
+			e.review.Text,
+			strings.Join(e.tagShelves(), ", "),
+		})
+	}
+	out.Flush()
+	return out.Error()
+}
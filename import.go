@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/cbrasser/cozy/ebook"
+	"github.com/cbrasser/cozy/tui"
+)
+
+// runImport copies books into the library, organized as Author/Title
+func runImport(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	dest := flags.String("to", cfg.Library.Path, "library folder (defaults to library.path)")
+	convert := flags.Bool("convert", false, "convert plain text books to EPUB")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: cozy import [-to dir] [-convert] file-or-folder...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("no books given")
+	}
+	books, err := ebook.FindBookFiles(flags.Args())
+	if err != nil {
+		return err
+	}
+	if len(books) == 0 {
+		return errors.New("no books found")
+	}
+
+	options := ebook.ExportOptions{ConvertText: *convert, Book: tui.BookOptions(cfg)}
+	failed := 0
+	for _, path := range books {
+		target, err := ebook.ImportBook(path, *dest, options)
+		switch {
+		case errors.Is(err, ebook.ErrDuplicate):
+			fmt.Printf("skipped %s: already in the library as %s\n", path, target)
+		case err != nil:
+			fmt.Printf("failed %s: %v\n", path, err)
+			failed++
+		default:
+			fmt.Printf("imported %s -> %s\n", path, target)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d books could not be imported", failed, len(books))
+	}
+	return nil
+}
+
+// runImportArticle saves web articles into the articles folder of the
+// library to read later
+func runImportArticle(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("import-article", flag.ContinueOnError)
+	dest := flags.String("to", cfg.ArticlesPath(), "folder to save to (defaults to library.articles_folder)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: cozy import-article [-to dir] url...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("no articles given")
+	}
+	if *dest == "" {
+		return errors.New("no articles folder: set library.path and library.articles_folder or use -to")
+	}
+
+	failed := 0
+	for _, url := range flags.Args() {
+		target, err := ebook.ImportArticle(url, *dest)
+		switch {
+		case errors.Is(err, ebook.ErrDuplicate):
+			fmt.Printf("skipped %s: already saved as %s\n", url, target)
+		case err != nil:
+			fmt.Printf("failed %s: %v\n", url, err)
+			failed++
+		default:
+			fmt.Printf("saved %s -> %s\n", url, target)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d articles could not be saved", failed, flags.NArg())
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/cbrasser/cozy/ebook"
+	"github.com/cbrasser/cozy/tui"
+)
+
+// runMail imports the books attached to new mail in the mailbox set in
+// [mail], once or every mail.check_minutes until stopped
+func runMail(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("mail", flag.ContinueOnError)
+	convert := flags.Bool("convert", false, "convert plain text books to EPUB")
+	watch := flags.Bool("watch", false, "keep checking every mail.check_minutes")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: cozy mail [-convert] [-watch]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *watch && cfg.Mail.CheckMinutes <= 0 {
+		return fmt.Errorf("mail.check_minutes must be greater than 0 to watch")
+	}
+
+	options := ebook.ExportOptions{ConvertText: *convert, Book: tui.BookOptions(cfg)}
+	for {
+		result, err := ebook.CheckMail(cfg, options)
+		for _, path := range result.Imported {
+			fmt.Printf("imported %s\n", path)
+		}
+		for _, failed := range result.Failed {
+			fmt.Printf("failed %v\n", failed)
+		}
+		if result.Duplicates > 0 {
+			fmt.Printf("skipped %d book(s) already in the library\n", result.Duplicates)
+		}
+		if result.Ignored > 0 {
+			fmt.Printf("skipped %d email(s) from senders not in mail.allowed_senders\n", result.Ignored)
+		}
+		if !*watch {
+			return err
+		}
+		if err != nil {
+			fmt.Printf("failed %v\n", err)
+		}
+		time.Sleep(time.Duration(cfg.Mail.CheckMinutes) * time.Minute)
+	}
+}
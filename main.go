@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
@@ -10,6 +11,24 @@ import (
 )
 
 func main() {
+	resume := flag.Bool("resume", false, "reopen the last book instead of the library")
+	checkConfig := flag.Bool("check-config", false, "report problems in config.toml and exit")
+	configPath := flag.String("config", "", "use this config file instead of the default one")
+	libraryPath := flag.String("library", "", "read books from this folder for this run only")
+	screenReader := flag.Bool("screen-reader", false, "plain output for terminal screen readers")
+	restricted := flag.Bool("restricted", false, "only show the books allowed in [restricted] and hide the settings")
+	flag.Parse()
+
+	if *configPath != "" {
+		config.SetConfigPath(*configPath)
+	}
+	if *libraryPath != "" {
+		config.SetLibraryPath(*libraryPath)
+	}
+	if *restricted {
+		config.SetRestricted()
+	}
+
 	// Load config
 	cfg, err := config.Load()
 	if err != nil {
@@ -17,13 +36,89 @@ func main() {
 		os.Exit(1)
 	}
 
+	if *screenReader {
+		cfg.Display.ScreenReader = true
+	}
+
+	if *checkConfig {
+		if len(cfg.Warnings) == 0 {
+			fmt.Println("config.toml is valid")
+			return
+		}
+		for _, warning := range cfg.Warnings {
+			fmt.Println(warning)
+		}
+		os.Exit(1)
+	}
+
+	// Subcommands run without the TUI
+	if flag.NArg() > 0 {
+		if err := runCommand(cfg, flag.Arg(0), flag.Args()[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Walk new users through the main settings
+	if cfg.FirstRun && !cfg.Restricted.Enabled {
+		if err := tui.RunSetup(cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Error running setup: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if err := runReader(cfg, *resume); err != nil {
+		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runReader runs the TUI, reopening the last book if resume is set
+func runReader(cfg *config.Config, resume bool) error {
 	// Create TUI model
 	model := tui.NewModel(cfg)
+	if resume {
+		model.ResumeLastBook()
+	}
 
 	// Start the program
-	p := tea.NewProgram(model, tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
-		os.Exit(1)
+	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	_, err := p.Run()
+	model.Close()
+	return err
+}
+
+// runCommand dispatches a CLI subcommand
+func runCommand(cfg *config.Config, name string, args []string) error {
+	switch name {
+	case "send":
+		return runSend(cfg, args)
+	case "import":
+		return runImport(cfg, args)
+	case "import-article":
+		return runImportArticle(cfg, args)
+	case "fetch":
+		return runFetch(cfg, args)
+	case "ao3":
+		return runAO3(cfg, args)
+	case "digest":
+		return runDigest(cfg, args)
+	case "mail":
+		return runMail(cfg, args)
+	case "export-history":
+		return runExportHistory(cfg, args)
+	case "export-highlights":
+		return runExportHighlights(cfg, args)
+	case "export-vocabulary":
+		return runExportVocabulary(cfg, args)
+	case "remote":
+		return runRemote(cfg, args)
+	case "render":
+		return runRender(args)
+	case "serve":
+		return runServe(cfg, args)
+	default:
+		return fmt.Errorf("unknown command %q (available: send, import, import-article, fetch, ao3, digest, mail, export-history, export-highlights, export-vocabulary, remote, render, serve)", name)
 	}
 }
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/cbrasser/cozy/ebook"
+	"github.com/cbrasser/cozy/tui"
+)
+
+// runExportHighlights writes the highlights and notes of books to Markdown
+// files, one per book. Without books it exports every book that has
+// highlights or notes.
+func runExportHighlights(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("export-highlights", flag.ContinueOnError)
+	dest := flags.String("to", cfg.HighlightsExportDir(), "destination folder (defaults to notes.export_dir)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: cozy export-highlights [-to dir] [book...]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	highlights, err := config.LoadHighlights(cfg)
+	if err != nil {
+		return err
+	}
+
+	journal, err := config.LoadJournal(cfg)
+	if err != nil {
+		return err
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		for path, bookHighlights := range highlights.Books {
+			if len(bookHighlights) > 0 {
+				paths = append(paths, path)
+			}
+		}
+		for path := range journal.Books {
+			if len(highlights.Books[path]) == 0 {
+				paths = append(paths, path)
+			}
+		}
+		sort.Strings(paths)
+		if len(paths) == 0 {
+			return fmt.Errorf("no highlights or notes to export")
+		}
+	}
+
+	failed := 0
+	for _, path := range paths {
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		bookHighlights := highlights.Books[path]
+		if len(bookHighlights) == 0 && journal.Text(path) == "" {
+			fmt.Printf("skipped %s: no highlights or notes\n", path)
+			continue
+		}
+		target, err := ebook.ExportHighlights(path, *dest, bookHighlights, journal.Text(path), tui.BookOptions(cfg), cfg.Reading.WordsPerPage)
+		if err != nil {
+			fmt.Printf("failed %s: %v\n", path, err)
+			failed++
+			continue
+		}
+		fmt.Printf("exported %d highlights from %s -> %s\n", len(bookHighlights), path, target)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d books could not be exported", failed, len(paths))
+	}
+	return nil
+}
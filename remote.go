@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/cbrasser/cozy/tui"
+)
+
+// runRemote sends a command to a running cozy over its remote control
+// socket and prints the answer
+func runRemote(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("remote", flag.ContinueOnError)
+	socket := flags.String("socket", cfg.RemoteSocket(), "remote control socket of the running cozy")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: cozy remote [-socket path] status | open <book> | <command> [args...]")
+		fmt.Fprintln(flags.Output(), "Commands are those of the : command line, e.g. chapter next or goto 50%.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("no command given")
+	}
+
+	request := tui.RemoteRequest{Command: flags.Arg(0), Args: flags.Args()[1:]}
+	if request.Command == "open" && len(request.Args) > 0 {
+		// Paths are relative to this shell, not to the running cozy
+		if abs, err := filepath.Abs(strings.Join(request.Args, " ")); err == nil {
+			request.Args = []string{abs}
+		}
+	}
+
+	response, err := tui.SendRemote(*socket, request)
+	if err != nil {
+		return err
+	}
+	if response.Status != nil {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(response.Status)
+	}
+	if response.Message != "" {
+		fmt.Println(response.Message)
+	}
+	return nil
+}
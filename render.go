@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/cbrasser/cozy/ebook"
+)
+
+// runRender prints HTML files as the reader renders them, without styles,
+// or checks a folder of golden files against the renderer
+func runRender(args []string) error {
+	flags := flag.NewFlagSet("render", flag.ContinueOnError)
+	width := flags.Int("width", ebook.GoldenWidth, "width to render at")
+	golden := flags.String("golden", "", "compare the .html files in this folder with their .golden files")
+	update := flags.Bool("update", false, "with -golden, rewrite the .golden files")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: cozy render [-width n] file.html... | cozy render -golden dir [-update]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *golden != "" {
+		mismatches, err := ebook.CheckGoldenFiles(*golden, *update)
+		if err != nil {
+			return err
+		}
+		for _, mismatch := range mismatches {
+			fmt.Println(mismatch)
+		}
+		if len(mismatches) > 0 {
+			return fmt.Errorf("%d golden files don't match", len(mismatches))
+		}
+		return nil
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("no files given")
+	}
+	if *width <= 0 {
+		return errors.New("width must be greater than 0")
+	}
+
+	// The default layout, whatever config.toml says, so output can be
+	// compared between machines
+	for _, path := range flags.Args() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		fmt.Println(ebook.RenderNormalized(string(data), *width, ebook.DefaultRenderOptions()))
+	}
+	return nil
+}
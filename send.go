@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/cbrasser/cozy/ebook"
+	"github.com/cbrasser/cozy/tui"
+)
+
+// runSend copies books to the e-reader configured in config.toml
+func runSend(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("send", flag.ContinueOnError)
+	dest := flags.String("to", cfg.Device.Path, "destination directory (defaults to device.path)")
+	convert := flags.Bool("convert", cfg.Device.ConvertText, "convert plain text books to EPUB")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: cozy send [-to dir] [-convert] book...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("no books given")
+	}
+	if *dest == "" {
+		return errors.New("no destination: set device.path in config.toml or pass -to")
+	}
+
+	options := ebook.ExportOptions{ConvertText: *convert, Book: tui.BookOptions(cfg)}
+	failed := 0
+	for _, path := range flags.Args() {
+		target, err := ebook.ExportBook(path, *dest, options)
+		switch {
+		case errors.Is(err, ebook.ErrDuplicate):
+			fmt.Printf("skipped %s: already on device as %s\n", path, target)
+		case err != nil:
+			fmt.Printf("failed %s: %v\n", path, err)
+			failed++
+		default:
+			fmt.Printf("sent %s -> %s\n", path, target)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d books could not be sent", failed, flags.NArg())
+	}
+	return nil
+}
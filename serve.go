@@ -0,0 +1,474 @@
+package main
+
+import (
+	"crypto/subtle"
+	"errors"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/cbrasser/cozy/ebook"
+	"github.com/cbrasser/cozy/tui"
+)
+
+// cozy serve shows the library and its books in a browser, e.g. a phone's
+// on the same network. Positions are kept in the progress file, so reading
+// continues in the terminal where it stopped in the browser and the other
+// way round. Books are named in URLs by their path in the library. Only
+// this machine can connect unless a password is set, as anyone who can
+// reach the server may read the library and change progress.
+
+// runServe serves the library over HTTP until interrupted
+func runServe(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := flags.String("addr", "localhost:8080", "address to listen on, e.g. :8080 for the network (needs -password)")
+	password := flags.String("password", "", "ask browsers for this password")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: cozy serve [-addr host:port] [-password secret]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if cfg.Library.Path == "" {
+		return errors.New("no library: set library.path in config.toml or pass -library")
+	}
+
+	server := &webServer{config: cfg, password: *password, chapters: make(map[string]int)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", server.library)
+	mux.HandleFunc("GET /read", server.read)
+	mux.HandleFunc("GET /contents", server.contents)
+	mux.HandleFunc("GET /resource", server.resource)
+	mux.HandleFunc("GET /reader.js", server.script)
+	mux.HandleFunc("POST /progress", server.saveProgress)
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	if tcp, ok := listener.Addr().(*net.TCPAddr); ok && !tcp.IP.IsLoopback() && *password == "" {
+		listener.Close()
+		return fmt.Errorf("%s can be reached from the network; set -password to serve on it", *addr)
+	}
+	for _, address := range serveAddresses(listener.Addr()) {
+		fmt.Printf("Serving %s on http://%s\n", cfg.Library.Path, address)
+	}
+	return http.Serve(listener, server.authorize(mux))
+}
+
+// serveAddresses returns the addresses browsers can reach the server on:
+// the one it listens on, or those of the machine's network interfaces
+func serveAddresses(listening net.Addr) []string {
+	tcp, ok := listening.(*net.TCPAddr)
+	if !ok || !tcp.IP.IsUnspecified() {
+		return []string{listening.String()}
+	}
+	addresses := []string{net.JoinHostPort("localhost", strconv.Itoa(tcp.Port))}
+	interfaces, _ := net.InterfaceAddrs()
+	for _, address := range interfaces {
+		if network, ok := address.(*net.IPNet); ok && network.IP.To4() != nil && !network.IP.IsLoopback() {
+			addresses = append(addresses, net.JoinHostPort(network.IP.String(), strconv.Itoa(tcp.Port)))
+		}
+	}
+	return addresses
+}
+
+// webServer serves the library of a config
+type webServer struct {
+	config   *config.Config
+	password string
+
+	mu       sync.Mutex
+	chapters map[string]int // Chapter count of books opened, for saving progress
+}
+
+// authorize asks for the password, if there is one, with basic auth
+func (s *webServer) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.password != "" {
+			_, password, ok := r.BasicAuth()
+			if !ok || subtle.ConstantTimeCompare([]byte(password), []byte(s.password)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Basic realm="cozy"`)
+				http.Error(w, "password required", http.StatusUnauthorized)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// bookPath returns the book a request names by its path in the library.
+// Books outside the library, and those restricted mode hides, are not
+// served.
+func (s *webServer) bookPath(r *http.Request) (string, bool) {
+	name := r.FormValue("book")
+	if name == "" {
+		return "", false
+	}
+	bookPath := filepath.Join(s.config.Library.Path, filepath.FromSlash(path.Clean("/"+name)))
+	if !ebook.IsBookFile(bookPath) || !s.config.BookAllowed(bookPath) {
+		return "", false
+	}
+	if _, err := os.Stat(bookPath); err != nil {
+		return "", false
+	}
+	return bookPath, true
+}
+
+// bookName returns the name a book is served under
+func (s *webServer) bookName(bookPath string) string {
+	rel, err := filepath.Rel(s.config.Library.Path, bookPath)
+	if err != nil {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+// openBook opens the book a request names
+func (s *webServer) openBook(w http.ResponseWriter, r *http.Request) (*ebook.Book, bool) {
+	bookPath, ok := s.bookPath(r)
+	if !ok {
+		http.NotFound(w, r)
+		return nil, false
+	}
+	book, err := ebook.OpenBook(bookPath, tui.BookOptions(s.config))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	s.mu.Lock()
+	s.chapters[bookPath] = book.ChapterCount()
+	s.mu.Unlock()
+	return book, true
+}
+
+// webBook is a book in the library page
+type webBook struct {
+	Name     string
+	Title    string
+	Byline   string
+	Percent  int
+	Finished bool
+}
+
+// library lists the books, those being read first
+func (s *webServer) library(w http.ResponseWriter, r *http.Request) {
+	books, err := ebook.ListBooks(s.config.Library.Path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	progress, err := config.LoadProgress(s.config)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var reading, rest []webBook
+	for _, info := range books {
+		if info.Error != nil || !s.config.BookAllowed(info.Path) {
+			continue
+		}
+		book := webBook{Name: s.bookName(info.Path), Title: info.Title, Byline: info.Byline()}
+		if book.Title == "" {
+			book.Title = filepath.Base(info.Path)
+		}
+		saved, ok := progress.GetBookProgress(info.Path)
+		book.Finished = saved.Finished
+		book.Percent = int(saved.GetCompletionPercentage())
+		if ok && !saved.Finished {
+			reading = append(reading, book)
+		} else {
+			rest = append(rest, book)
+		}
+	}
+	byTitle := func(a, b webBook) int {
+		return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	}
+	slices.SortFunc(reading, byTitle)
+	slices.SortFunc(rest, byTitle)
+
+	s.render(w, libraryPage, map[string]any{
+		"Title":   "Library",
+		"Reading": reading,
+		"Books":   rest,
+	})
+}
+
+// read shows a chapter. Without a chapter, the saved position is opened.
+func (s *webServer) read(w http.ResponseWriter, r *http.Request) {
+	book, ok := s.openBook(w, r)
+	if !ok {
+		return
+	}
+	defer book.Close()
+
+	chapter, restore := 0, -1
+	if value := r.FormValue("chapter"); value != "" {
+		number, err := strconv.Atoi(value)
+		if err != nil || number < 1 || number > book.ChapterCount() {
+			http.NotFound(w, r)
+			return
+		}
+		chapter = number - 1
+	} else if progress, err := config.LoadProgress(s.config); err == nil {
+		if saved, ok := progress.GetBookProgress(book.Path); ok && saved.CurrentChapter < book.ChapterCount() {
+			chapter, restore = saved.CurrentChapter, saved.TextOffset
+		}
+	}
+
+	name := s.bookName(book.Path)
+	content, err := book.WebChapter(chapter, ebook.WebLinks{
+		Chapter:  func(index int, fragment string) string { return chapterURL(name, index, fragment) },
+		Resource: func(file string) string { return "/resource?" + url.Values{"book": {name}, "file": {file}}.Encode() },
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data := map[string]any{
+		"Title":    book.Chapters[chapter].Title + " - " + book.Title,
+		"Book":     name,
+		"Chapter":  chapter,
+		"Number":   chapter + 1,
+		"Chapters": book.ChapterCount(),
+		"Heading":  book.Chapters[chapter].Title,
+		"Content":  template.HTML(content),
+		"Restore":  restore,
+		"Contents": "/contents?" + url.Values{"book": {name}}.Encode(),
+	}
+	if chapter > 0 {
+		data["Previous"] = chapterURL(name, chapter-1, "")
+	}
+	if chapter < book.ChapterCount()-1 {
+		data["Next"] = chapterURL(name, chapter+1, "")
+	}
+	s.render(w, readerPage, data)
+}
+
+// chapterURL returns the URL of a chapter, counted from 0, and a place in
+// it
+func chapterURL(name string, chapter int, fragment string) string {
+	link := "/read?" + url.Values{"book": {name}, "chapter": {strconv.Itoa(chapter + 1)}}.Encode()
+	if fragment != "" {
+		link += "#" + url.PathEscape(fragment)
+	}
+	return link
+}
+
+// contents lists the chapters of a book
+func (s *webServer) contents(w http.ResponseWriter, r *http.Request) {
+	book, ok := s.openBook(w, r)
+	if !ok {
+		return
+	}
+	defer book.Close()
+
+	name := s.bookName(book.Path)
+	type entry struct {
+		Title string
+		URL   string
+	}
+	var chapters []entry
+	for i, chapter := range book.Chapters {
+		chapters = append(chapters, entry{Title: chapter.Title, URL: chapterURL(name, i, "")})
+	}
+	s.render(w, contentsPage, map[string]any{
+		"Title":    book.Title,
+		"Book":     "/read?" + url.Values{"book": {name}}.Encode(),
+		"Chapters": chapters,
+	})
+}
+
+// resource serves a file of a book, such as an image. It can't run
+// scripts, even if it is a page or an SVG image.
+func (s *webServer) resource(w http.ResponseWriter, r *http.Request) {
+	book, ok := s.openBook(w, r)
+	if !ok {
+		return
+	}
+	defer book.Close()
+
+	file, err := book.OpenResource(r.FormValue("file"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer file.Close()
+	if kind := mime.TypeByExtension(path.Ext(r.FormValue("file"))); kind != "" {
+		w.Header().Set("Content-Type", kind)
+	}
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; img-src 'self'; style-src 'unsafe-inline'; sandbox")
+	w.Header().Set("Cache-Control", "max-age=3600")
+	io.Copy(w, file)
+}
+
+// saveProgress saves the position the browser is at
+func (s *webServer) saveProgress(w http.ResponseWriter, r *http.Request) {
+	bookPath, ok := s.bookPath(r)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	chapter, errChapter := strconv.Atoi(r.FormValue("chapter"))
+	offset, errOffset := strconv.Atoi(r.FormValue("offset"))
+	if errChapter != nil || errOffset != nil || chapter < 0 || offset < 0 {
+		http.Error(w, "invalid position", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	chapters, ok := s.chapters[bookPath]
+	if !ok || chapter >= chapters {
+		http.Error(w, "invalid position", http.StatusBadRequest)
+		return
+	}
+	progress, err := config.LoadProgress(s.config)
+	if err == nil {
+		progress.SetBookProgress(bookPath, chapter, 0, offset, chapters)
+		err = config.SaveProgress(s.config, progress)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// script serves the script of the reader page
+func (s *webServer) script(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+	io.WriteString(w, readerScript)
+}
+
+// render writes a page in the colors of the theme. Pages only run the
+// reader's own script.
+func (s *webServer) render(w http.ResponseWriter, page *template.Template, data map[string]any) {
+	colors := map[string]string{"Background": "#fdfaf4", "Text": "#2b2b2b", "Muted": "#777777", "Accent": "#8a5a44", "Heading": "#3b2f2f"}
+	if theme := s.config.ActiveTheme; theme != nil {
+		for name, color := range map[string]string{
+			"Background": theme.BackgroundColor,
+			"Text":       theme.TextColor,
+			"Muted":      theme.MutedTextColor,
+			"Accent":     theme.LinkColor,
+			"Heading":    theme.HeadingColor,
+		} {
+			if strings.HasPrefix(color, "#") {
+				colors[name] = color
+			}
+		}
+	}
+	data["Colors"] = colors
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'; img-src 'self' data:; style-src 'self' 'unsafe-inline'; script-src 'self'")
+	if err := page.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Pages share a layout, in the style block and the reader's look
+const pageLayout = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { background: {{index .Colors "Background"}}; color: {{index .Colors "Text"}}; font: 1.1rem/1.6 Georgia, serif; margin: 0 auto; max-width: 40rem; padding: 1rem 1.25rem 4rem; }
+h1, h2, h3, h4 { color: {{index .Colors "Heading"}}; line-height: 1.3; }
+a { color: {{index .Colors "Accent"}}; }
+img, svg { max-width: 100%; height: auto; }
+nav { display: flex; justify-content: space-between; gap: 1rem; margin: 1.5rem 0; font-family: sans-serif; font-size: .95rem; }
+.muted { color: {{index .Colors "Muted"}}; font-size: .9rem; }
+ul.books { list-style: none; padding: 0; }
+ul.books li { margin: 0 0 1rem; }
+</style>
+</head>
+<body>
+{{template "page" .}}
+</body>
+</html>`
+
+var (
+	libraryPage = template.Must(template.Must(template.New("layout").Parse(pageLayout)).New("page").Parse(`
+<h1>Library</h1>
+{{if .Reading}}<h2>Reading</h2>
+<ul class="books">{{range .Reading}}
+<li><a href="/read?book={{.Name}}">{{.Title}}</a><br><span class="muted">{{with .Byline}}{{.}} • {{end}}{{.Percent}}%</span></li>{{end}}
+</ul>{{end}}
+<h2>Books</h2>
+<ul class="books">{{range .Books}}
+<li><a href="/read?book={{.Name}}">{{.Title}}</a><br><span class="muted">{{.Byline}}{{if .Finished}} • ✓{{end}}</span></li>{{else}}
+<li class="muted">No books found.</li>{{end}}
+</ul>`)).Lookup("layout")
+
+	readerPage = template.Must(template.Must(template.New("layout").Parse(pageLayout)).New("page").Parse(`
+<nav><a href="/">Library</a><a href="{{.Contents}}">Contents</a><span class="muted">{{.Number}}/{{.Chapters}}</span></nav>
+<main id="text" data-book="{{.Book}}" data-chapter="{{.Chapter}}" data-restore="{{.Restore}}">
+{{.Content}}
+</main>
+<nav>{{with .Previous}}<a href="{{.}}">← Previous</a>{{else}}<span></span>{{end}}{{with .Next}}<a href="{{.}}">Next →</a>{{end}}</nav>
+<script src="/reader.js"></script>`)).Lookup("layout")
+
+	contentsPage = template.Must(template.Must(template.New("layout").Parse(pageLayout)).New("page").Parse(`
+<nav><a href="/">Library</a><a href="{{.Book}}">Continue reading</a></nav>
+<h1>{{.Title}}</h1>
+<ol>{{range .Chapters}}
+<li><a href="{{.URL}}">{{.Title}}</a></li>{{end}}
+</ol>`)).Lookup("layout")
+)
+
+// readerScript keeps the position in the progress file: the letters and
+// digits before the first line in view, as the terminal reader counts them
+const readerScript = `(function () {
+  var text = document.getElementById("text");
+  if (!text) return;
+  var letters = /[\p{L}\p{N}]/gu;
+  function count(node) { var m = node.data.match(letters); return m ? m.length : 0; }
+  function walker() { return document.createTreeWalker(text, NodeFilter.SHOW_TEXT); }
+
+  var restore = parseInt(text.dataset.restore, 10);
+  if (restore > 0 && !location.hash) {
+    var seen = 0, node, w = walker();
+    while ((node = w.nextNode())) {
+      seen += count(node);
+      if (seen > restore) { node.parentElement.scrollIntoView(); break; }
+    }
+  }
+
+  function offset() {
+    var seen = 0, node, w = walker();
+    while ((node = w.nextNode())) {
+      if (node.parentElement.getBoundingClientRect().bottom > 0) return seen;
+      seen += count(node);
+    }
+    return seen;
+  }
+  function save() {
+    var body = new URLSearchParams({book: text.dataset.book, chapter: text.dataset.chapter, offset: offset()});
+    navigator.sendBeacon("/progress", body);
+  }
+
+  var timer;
+  addEventListener("scroll", function () { clearTimeout(timer); timer = setTimeout(save, 1000); });
+  addEventListener("pagehide", save);
+  save();
+})();
+`
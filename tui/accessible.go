@@ -0,0 +1,26 @@
+package tui
+
+// The accessible layout gathers the settings that make text easier to
+// follow for readers with dyslexia: letter and word spacing, lines of
+// accessibleLineLength at most and no justification. It is switched with
+// "A" or display.accessible.
+
+const (
+	accessibleLineLength  = 50
+	accessibleWordSpacing = 1
+)
+
+// toggleAccessible switches the accessible layout and remembers it in the
+// config
+func (m *ReaderModel) toggleAccessible() string {
+	m.config.Display.Accessible = !m.config.Display.Accessible
+	m.layout()
+	m.Refresh()
+	if err := saveToggle(m.config); err != nil {
+		return trf("Could not save settings: %v", err)
+	}
+	if m.config.Display.Accessible {
+		return tr("Accessible layout on")
+	}
+	return tr("Accessible layout off")
+}
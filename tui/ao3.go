@@ -0,0 +1,85 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/cbrasser/cozy/ebook"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ao3CheckedMsg carries the saved AO3 works that got new chapters
+type ao3CheckedMsg struct {
+	updated []config.AO3Work
+	err     error
+	manual  bool // Asked for with :ao3 check, so no news is worth saying
+}
+
+// runAO3 saves a work from Archive of Our Own into the library in the
+// background, or checks the saved works in progress for new chapters
+func runAO3(m *Model, args []string) (string, tea.Cmd, error) {
+	if len(args) != 1 {
+		return "", nil, errorf("usage: %s", "ao3 <work url or id>|check")
+	}
+	cfg := m.config
+	if args[0] == "check" {
+		check := func() tea.Msg {
+			updated, err := ebook.CheckAO3Works(cfg)
+			return ao3CheckedMsg{updated: updated, err: err, manual: true}
+		}
+		return tr("Checking AO3 for new chapters..."), check, nil
+	}
+
+	work := args[0]
+	if _, err := ebook.ParseAO3Work(work); err != nil {
+		return "", nil, err
+	}
+	saveWork := func() tea.Msg {
+		saved, err := ebook.SaveAO3Work(cfg, work)
+		switch {
+		case errors.Is(err, ebook.ErrDuplicate):
+			return toastMsg{text: trf("Already in the library: %s", saved.Title), kind: toastInfo}
+		case err != nil:
+			return toastMsg{text: trf("Could not save AO3 work: %v", err), kind: toastError}
+		}
+		return toastMsg{text: trf("Saved %s", saved.Title), kind: toastSuccess}
+	}
+	return tr("Downloading from AO3..."), tea.Sequence(saveWork, m.library.loadBooks()), nil
+}
+
+// checkAO3 looks for new chapters of the saved AO3 works in progress in
+// the background, when ao3.check_hours have passed since the last check
+func (m Model) checkAO3() tea.Cmd {
+	cfg := m.config
+	if cfg.AO3.CheckHours <= 0 || cfg.Restricted.Enabled {
+		return nil
+	}
+	return func() tea.Msg {
+		works, err := config.LoadAO3(cfg)
+		if err != nil || !cfg.AO3CheckDue(works) || len(works.InProgress()) == 0 {
+			return nil
+		}
+		updated, err := ebook.CheckAO3Works(cfg)
+		return ao3CheckedMsg{updated: updated, err: err}
+	}
+}
+
+// applyAO3Check reports the works that got new chapters and rescans the
+// library for them
+func (m *Model) applyAO3Check(msg ao3CheckedMsg) tea.Cmd {
+	var cmds []tea.Cmd
+	if msg.err != nil {
+		cmds = append(cmds, notify(toastError, "AO3 check: %v", msg.err))
+	}
+	if len(msg.updated) > 0 {
+		var titles []string
+		for _, work := range msg.updated {
+			titles = append(titles, work.Title)
+		}
+		cmds = append(cmds, notify(toastSuccess, "New chapters: %s", strings.Join(titles, ", ")), m.library.loadBooks())
+	} else if msg.manual && msg.err == nil {
+		cmds = append(cmds, notify(toastInfo, "No new chapters"))
+	}
+	return tea.Batch(cmds...)
+}
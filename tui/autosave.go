@@ -0,0 +1,26 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Progress is saved this long after the reader stops moving, so a crash
+// loses at most the last few seconds of reading
+const autosaveDelay = 2 * time.Second
+
+// autosaveMsg saves progress once the reader has settled
+type autosaveMsg struct {
+	timer int
+}
+
+// scheduleAutosave (re)starts the autosave countdown. Earlier countdowns
+// are superseded, so scrolling through a chapter writes the file once.
+func (m *ReaderModel) scheduleAutosave() tea.Cmd {
+	m.autosaveTimer++
+	timer := m.autosaveTimer
+	return tea.Tick(autosaveDelay, func(time.Time) tea.Msg {
+		return autosaveMsg{timer: timer}
+	})
+}
@@ -0,0 +1,54 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/cbrasser/cozy/ebook"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// The header shows where the top of the page is in the chapter, as the
+// trail of headings above it, e.g. "Part II › Chapter 7 › The Long Night"
+
+// breadcrumb returns the chapter title followed by the headings the top
+// line is under, shortened from the left to fit width
+func (m *ReaderModel) breadcrumb(width int) string {
+	var trail []string
+	if chapter := m.book.GetChapter(m.currentChapter); chapter != nil {
+		trail = append(trail, chapter.Title)
+	}
+	for _, heading := range m.headingTrail() {
+		// The chapter title is often taken from its first heading
+		if heading.Title == "" || len(trail) > 0 && strings.EqualFold(heading.Title, trail[len(trail)-1]) {
+			continue
+		}
+		trail = append(trail, heading.Title)
+	}
+
+	separator := " › "
+	if screenReader {
+		separator = ", "
+	}
+	text := strings.Join(trail, separator)
+	for len(trail) > 1 && ansi.StringWidth(text) > width {
+		trail = trail[1:]
+		text = "…" + separator + strings.Join(trail, separator)
+	}
+	return ansi.Truncate(text, max(width, 1), "…")
+}
+
+// headingTrail returns the headings the top line of the page is under, from
+// the outermost
+func (m *ReaderModel) headingTrail() []ebook.Heading {
+	var trail []ebook.Heading
+	for _, heading := range m.headings {
+		if heading.Line > m.viewport.YOffset {
+			break
+		}
+		for len(trail) > 0 && trail[len(trail)-1].Level >= heading.Level {
+			trail = trail[:len(trail)-1]
+		}
+		trail = append(trail, heading)
+	}
+	return trail
+}
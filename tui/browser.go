@@ -0,0 +1,224 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cbrasser/cozy/ebook"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// browserKeyMap defines key bindings for browsing folders
+type browserKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Open   key.Binding
+	Parent key.Binding
+	Home   key.Binding
+	Hidden key.Binding
+}
+
+func (k browserKeyMap) ShortHelp() []key.Binding {
+	return localized(k.Up, k.Down, k.Open, k.Parent, k.Home, k.Hidden)
+}
+
+func (k browserKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+var browserKeys = browserKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "down"),
+	),
+	Open: key.NewBinding(
+		key.WithKeys("right", "l"),
+		key.WithHelp("→/l", "open"),
+	),
+	Parent: key.NewBinding(
+		key.WithKeys("left", "h", "backspace"),
+		key.WithHelp("←/h", "parent"),
+	),
+	Home: key.NewBinding(
+		key.WithKeys("~"),
+		key.WithHelp("~", "home"),
+	),
+	Hidden: key.NewBinding(
+		key.WithKeys("."),
+		key.WithHelp(".", "show hidden"),
+	),
+}
+
+// dirBrowser lists the folders inside a folder, for picking one, and
+// optionally the books in it
+type dirBrowser struct {
+	dir        string
+	entries    []browserEntry // Folders first, then books if files is set
+	books      int            // Book files directly in dir
+	files      bool
+	cursor     int
+	top        int // First visible row
+	showHidden bool
+	err        error
+}
+
+// browserEntry is a folder or book in the browsed folder
+type browserEntry struct {
+	name string
+	dir  bool
+}
+
+// newDirBrowser starts browsing at a folder, or the closest one that
+// exists above it
+func newDirBrowser(dir string, files bool) dirBrowser {
+	for {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	b := dirBrowser{files: files}
+	b.open(dir)
+	return b
+}
+
+// open shows the contents of a folder
+func (b *dirBrowser) open(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		b.err = err
+		return
+	}
+	b.dir, b.err = dir, nil
+	b.entries, b.books = nil, 0
+	b.cursor, b.top = 0, 0
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") && !b.showHidden {
+			continue
+		}
+		// Links to folders are followed
+		isDir := entry.IsDir()
+		if entry.Type()&os.ModeSymlink != 0 {
+			if info, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				isDir = info.IsDir()
+			}
+		}
+		switch {
+		case isDir:
+			b.entries = append(b.entries, browserEntry{name: name, dir: true})
+		case ebook.IsBookFile(name):
+			b.books++
+			if b.files {
+				b.entries = append(b.entries, browserEntry{name: name})
+			}
+		}
+	}
+	sort.SliceStable(b.entries, func(i, j int) bool {
+		if b.entries[i].dir != b.entries[j].dir {
+			return b.entries[i].dir
+		}
+		return strings.ToLower(b.entries[i].name) < strings.ToLower(b.entries[j].name)
+	})
+}
+
+// selected returns the path of the selected folder or book
+func (b *dirBrowser) selected() (browserEntry, string, bool) {
+	if len(b.entries) == 0 {
+		return browserEntry{}, "", false
+	}
+	entry := b.entries[b.cursor]
+	return entry, filepath.Join(b.dir, entry.name), true
+}
+
+// update moves through the folders, reporting whether the key was one of
+// the browser's
+func (b *dirBrowser) update(msg tea.KeyMsg) bool {
+	switch {
+	case key.Matches(msg, browserKeys.Up):
+		b.cursor = max(b.cursor-1, 0)
+	case key.Matches(msg, browserKeys.Down):
+		b.cursor = min(b.cursor+1, max(len(b.entries)-1, 0))
+	case key.Matches(msg, browserKeys.Open):
+		if entry, path, ok := b.selected(); ok && entry.dir {
+			b.open(path)
+		}
+	case key.Matches(msg, browserKeys.Parent):
+		child := filepath.Base(b.dir)
+		b.open(filepath.Dir(b.dir))
+		// Keep the folder just left selected
+		for i, entry := range b.entries {
+			if entry.name == child {
+				b.cursor = i
+			}
+		}
+	case key.Matches(msg, browserKeys.Home):
+		if home, err := os.UserHomeDir(); err == nil {
+			b.open(home)
+		}
+	case key.Matches(msg, browserKeys.Hidden):
+		b.showHidden = !b.showHidden
+		b.open(b.dir)
+	default:
+		return false
+	}
+	return true
+}
+
+// view lists the folders and books in rows of the given height, the
+// selected one highlighted
+func (b *dirBrowser) view(height int, selected, normal, muted lipgloss.Style) string {
+	var lines []string
+	header := b.dir
+	switch b.books {
+	case 0:
+	case 1:
+		header += tr(" (1 book)")
+	default:
+		header += trf(" (%d books)", b.books)
+	}
+	lines = append(lines, muted.Render(header))
+	if b.err != nil {
+		lines = append(lines, muted.Render(b.err.Error()))
+	}
+	if len(b.entries) == 0 {
+		empty := tr("No folders here")
+		if b.files {
+			empty = tr("No folders or books here")
+		}
+		lines = append(lines, muted.Render("  "+empty))
+		return strings.Join(lines, "\n")
+	}
+
+	rows := max(height-len(lines), 1)
+	if b.cursor < b.top {
+		b.top = b.cursor
+	}
+	if b.cursor >= b.top+rows {
+		b.top = b.cursor - rows + 1
+	}
+	for i := b.top; i < min(b.top+rows, len(b.entries)); i++ {
+		name := b.entries[i].name
+		if b.entries[i].dir {
+			name += "/"
+		}
+		if i == b.cursor {
+			lines = append(lines, selected.Render("> "+name))
+		} else {
+			lines = append(lines, normal.Render("  "+name))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,151 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cbrasser/cozy/ebook"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// chapterPicker is the filterable chapter list opened with "g". The
+// headings of the current chapter down to section_depth are listed under
+// it.
+type chapterPicker struct {
+	active bool
+	list   list.Model
+}
+
+type chapterItem struct {
+	index   int
+	title   string
+	words   int
+	read    bool
+	current bool
+	heading *ebook.Heading // Set for a heading in the current chapter
+}
+
+func (i chapterItem) Title() string {
+	if i.heading != nil {
+		return strings.Repeat("  ", i.heading.Level) + i.title
+	}
+	return fmt.Sprintf("%d. %s", i.index+1, i.title)
+}
+func (i chapterItem) Description() string {
+	if i.heading != nil {
+		return strings.Repeat("  ", i.heading.Level) + trf("Heading %d", i.heading.Level)
+	}
+	parts := []string{trf("%d words", i.words)}
+	if i.current {
+		parts = append(parts, "▶ "+tr("Reading"))
+	}
+	if i.read {
+		parts = append(parts, "✓ "+tr("Read"))
+	}
+	return plain(strings.Join(parts, " • "))
+}
+func (i chapterItem) FilterValue() string { return i.title }
+
+// openChapterPicker shows the chapter list with the filter already focused,
+// so typing narrows it down right away
+func (m *ReaderModel) openChapterPicker() {
+	// Word counts are cached for {time_left}
+	m.wordsLeft()
+
+	saved, _ := m.progress.GetBookProgress(m.book.Path)
+	var items []list.Item
+	selected := 0
+	for i, chapter := range m.book.Chapters {
+		if i == m.currentChapter {
+			selected = len(items)
+		}
+		items = append(items, chapterItem{
+			index:   i,
+			title:   chapter.Title,
+			words:   m.chapterWords[i],
+			read:    saved.ChapterRead(i),
+			current: i == m.currentChapter,
+		})
+		if i != m.currentChapter {
+			continue
+		}
+		for _, heading := range m.headings {
+			// Chapters are often titled by their first heading
+			if heading.Level > m.config.Reading.SectionDepth || heading.Title == "" || strings.EqualFold(heading.Title, chapter.Title) {
+				continue
+			}
+			// The section being read is selected
+			if heading.Line <= m.viewport.YOffset {
+				selected = len(items)
+			}
+			items = append(items, chapterItem{index: i, title: heading.Title, heading: &heading})
+		}
+	}
+
+	width, height := m.chapterPickerSize()
+	l := list.New(items, list.NewDefaultDelegate(), width, height)
+	plainList(&l)
+	l.Title = tr("Chapters")
+	l.SetShowStatusBar(false)
+	l.SetShowHelp(false)
+	// An empty filter matches every chapter
+	l.SetFilterText("")
+	l.SetFilterState(list.Filtering)
+	l.Select(selected)
+
+	m.picker = chapterPicker{active: true, list: l}
+}
+
+// chapterPickerSize returns the size of the list inside the overlay border
+func (m *ReaderModel) chapterPickerSize() (int, int) {
+	return max(20, min(70, m.viewport.Width-4)) - 4, max(5, m.viewport.Height-4)
+}
+
+// updateChapterPicker handles keys while the chapter picker is open. Keys
+// other than navigation go to the filter input.
+func (m *ReaderModel) updateChapterPicker(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.picker.active = false
+		return nil
+	case "enter":
+		if item, ok := m.picker.list.SelectedItem().(chapterItem); ok {
+			m.picker.active = false
+			if item.index != m.currentChapter || item.heading == nil {
+				m.GotoChapter(item.index)
+			}
+			if item.heading != nil {
+				m.viewport.SetYOffset(item.heading.Line)
+			}
+		}
+		return nil
+	case "up", "ctrl+p", "ctrl+k":
+		m.picker.list.CursorUp()
+		return nil
+	case "down", "ctrl+n", "ctrl+j":
+		m.picker.list.CursorDown()
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.picker.list, cmd = m.picker.list.Update(msg)
+	return cmd
+}
+
+// chapterPickerView renders the chapter picker overlay
+func (m *ReaderModel) chapterPickerView() string {
+	width, height := m.chapterPickerSize()
+	m.picker.list.SetSize(width, height)
+
+	hint := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.config.ActiveTheme.MutedTextColor)).
+		Render(tr("type to filter • ↑/↓ move • enter jump • esc close"))
+
+	return lipgloss.NewStyle().
+		Border(panelBorder()).
+		BorderForeground(lipgloss.Color(m.config.ActiveTheme.PrimaryColor)).
+		Padding(0, 1).
+		Render(m.picker.list.View() + "\n" + hint)
+}
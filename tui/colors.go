@@ -0,0 +1,28 @@
+package tui
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Color profiles for the color_profile setting. Theme colors are written
+// as hex and converted to the nearest color the profile supports.
+var colorProfiles = map[string]termenv.Profile{
+	"truecolor": termenv.TrueColor,
+	"256":       termenv.ANSI256,
+	"16":        termenv.ANSI,
+	"none":      termenv.Ascii,
+}
+
+// detectedProfile is what lipgloss found out about the terminal at startup
+var detectedProfile = lipgloss.ColorProfile()
+
+// applyColorProfile switches the colors used for drawing. "auto" uses the
+// detected profile, for terminals that report their support correctly.
+func applyColorProfile(setting string) {
+	profile, ok := colorProfiles[setting]
+	if !ok {
+		profile = detectedProfile
+	}
+	lipgloss.SetColorProfile(profile)
+}
@@ -0,0 +1,929 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// command is an Ex-style command run from the ':' command line
+type command struct {
+	name    string
+	aliases []string
+	usage   string
+	views   []View // Views the command works in; nil means all
+	admin   bool   // Changes settings or files, so restricted mode hides it
+
+	run func(m *Model, args []string) (string, tea.Cmd, error)
+
+	// complete returns candidates for the last of args
+	complete func(m *Model, args []string) []string
+}
+
+// commands lists every Ex command
+var commands = []command{
+	{
+		name:  "chapter",
+		usage: "chapter <number>|next|prev",
+		views: []View{ViewReader},
+		run: func(m *Model, args []string) (string, tea.Cmd, error) {
+			if len(args) != 1 {
+				return "", nil, errorf("usage: %s", "chapter <number>|next|prev")
+			}
+			switch args[0] {
+			case "next":
+				return "", nil, m.reader.GotoChapter(m.reader.currentChapter + 1)
+			case "prev":
+				return "", nil, m.reader.GotoChapter(m.reader.currentChapter - 1)
+			}
+			n, err := strconv.Atoi(args[0])
+			if err != nil {
+				return "", nil, errorf("not a chapter number: %s", args[0])
+			}
+			return "", nil, m.reader.GotoChapter(n - 1)
+		},
+	},
+	{
+		name:  "goto",
+		usage: "goto <percent>%",
+		views: []View{ViewReader},
+		run: func(m *Model, args []string) (string, tea.Cmd, error) {
+			if len(args) != 1 {
+				return "", nil, errorf("usage: %s", "goto <percent>%")
+			}
+			percent, err := strconv.ParseFloat(strings.TrimSuffix(args[0], "%"), 64)
+			if err != nil {
+				return "", nil, errorf("not a percentage: %s", args[0])
+			}
+			return "", nil, m.reader.GotoPercent(percent)
+		},
+	},
+	{
+		name:  "furthest",
+		usage: "furthest",
+		views: []View{ViewReader},
+		run: func(m *Model, args []string) (string, tea.Cmd, error) {
+			return "", nil, m.reader.GotoFurthest()
+		},
+	},
+	{
+		name:  "focus",
+		usage: "focus [on|off]",
+		views: []View{ViewReader},
+		run: func(m *Model, args []string) (string, tea.Cmd, error) {
+			on := !m.reader.focus.active
+			if len(args) > 0 {
+				if err := parseSwitch(args[0], &on); err != nil {
+					return "", nil, err
+				}
+			}
+			if !on {
+				m.reader.stopFocus()
+				return tr("Focus mode off"), nil, nil
+			}
+			if m.reader.focus.active {
+				return tr("Focus mode is already on"), nil, nil
+			}
+			return trf("Focus mode on: %d min reading, %d min breaks",
+				m.config.Reading.FocusMinutes, m.config.Reading.BreakMinutes), m.reader.startFocus(), nil
+		},
+		complete: func(m *Model, args []string) []string {
+			if len(args) == 1 {
+				return []string{"on", "off"}
+			}
+			return nil
+		},
+	},
+	{
+		name:  "marks",
+		usage: "marks",
+		views: []View{ViewReader},
+		run: func(m *Model, args []string) (string, tea.Cmd, error) {
+			return m.reader.markList(), nil, nil
+		},
+	},
+	{
+		name:  "bookmark",
+		usage: "bookmark add|go|del|list [name]",
+		views: []View{ViewReader},
+		run:   runBookmark,
+		complete: func(m *Model, args []string) []string {
+			if len(args) == 1 {
+				return []string{"add", "go", "del", "list"}
+			}
+			if len(args) == 2 && (args[0] == "go" || args[0] == "del") {
+				var names []string
+				for _, bookmark := range m.reader.Bookmarks() {
+					names = append(names, bookmark.Name)
+				}
+				return names
+			}
+			return nil
+		},
+	},
+	{
+		name:  "theme",
+		usage: "theme <name>",
+		admin: true,
+		run: func(m *Model, args []string) (string, tea.Cmd, error) {
+			if len(args) != 1 {
+				return trf("Theme: %s", m.config.ThemeName), nil, nil
+			}
+			theme, err := config.LoadTheme(args[0])
+			if err != nil {
+				return "", nil, err
+			}
+			m.config.ThemeName = args[0]
+			m.config.ActiveTheme = theme
+			m.applySettings()
+			return "", nil, config.Save(m.config)
+		},
+		complete: func(m *Model, args []string) []string {
+			if len(args) != 1 {
+				return nil
+			}
+			themes, _ := config.ListThemes()
+			return themes
+		},
+	},
+	{
+		name:  "set",
+		usage: "set <option> <value>",
+		admin: true,
+		run:   runSet,
+		complete: func(m *Model, args []string) []string {
+			switch len(args) {
+			case 1:
+				var names []string
+				for _, s := range settings {
+					names = append(names, s.name)
+				}
+				return names
+			case 2:
+				for _, s := range settings {
+					if s.name == args[0] {
+						return s.values
+					}
+				}
+			}
+			return nil
+		},
+	},
+	{
+		name:  "view",
+		usage: "view list|gallery",
+		views: []View{ViewLibrary},
+		run: func(m *Model, args []string) (string, tea.Cmd, error) {
+			if len(args) != 1 || (args[0] != "list" && args[0] != "gallery") {
+				return "", nil, errorf("usage: %s", "view list|gallery")
+			}
+			if (args[0] == "gallery") != m.library.gallery {
+				return "", m.library.toggleGallery(), nil
+			}
+			return "", nil, nil
+		},
+		complete: func(m *Model, args []string) []string {
+			if len(args) == 1 {
+				return []string{"list", "gallery"}
+			}
+			return nil
+		},
+	},
+	{
+		name:  "send",
+		usage: "send",
+		views: []View{ViewLibrary},
+		run: func(m *Model, args []string) (string, tea.Cmd, error) {
+			item, ok := m.library.list.SelectedItem().(bookItem)
+			if !ok {
+				return "", nil, errorf("no book selected")
+			}
+			return "", m.library.sendToDevice(item), nil
+		},
+	},
+	{
+		name:  "duplicates",
+		usage: "duplicates",
+		views: []View{ViewLibrary},
+		run: func(m *Model, args []string) (string, tea.Cmd, error) {
+			return m.library.duplicatesSummary(), nil, nil
+		},
+	},
+	{
+		name:  "queue",
+		usage: "queue",
+		views: []View{ViewLibrary},
+		run: func(m *Model, args []string) (string, tea.Cmd, error) {
+			return m.library.queueSummary(), nil, nil
+		},
+	},
+	{
+		name:  "dedupe",
+		usage: "dedupe",
+		admin: true,
+		views: []View{ViewLibrary},
+		run: func(m *Model, args []string) (string, tea.Cmd, error) {
+			return m.dedupe()
+		},
+	},
+	{
+		name:  "open",
+		usage: "open [book file or folder]",
+		admin: true,
+		run:   runOpen,
+		complete: func(m *Model, args []string) []string {
+			return completePath(args[len(args)-1])
+		},
+	},
+	{
+		name:  "import",
+		usage: "import [-convert] <file or folder>",
+		admin: true,
+		views: []View{ViewLibrary},
+		run:   runImport,
+		complete: func(m *Model, args []string) []string {
+			return completePath(args[len(args)-1])
+		},
+	},
+	{
+		name:  "fetch",
+		usage: "fetch [-convert] [-open] <url>",
+		admin: true,
+		views: []View{ViewLibrary},
+		run:   runFetch,
+	},
+	{
+		name:  "import-article",
+		usage: "import-article <url>",
+		admin: true,
+		views: []View{ViewLibrary},
+		run:   runImportArticle,
+	},
+	{
+		name:  "ao3",
+		usage: "ao3 <work url or id>|check",
+		admin: true,
+		views: []View{ViewLibrary},
+		run:   runAO3,
+		complete: func(m *Model, args []string) []string {
+			if len(args) == 1 {
+				return []string{"check"}
+			}
+			return nil
+		},
+	},
+	{
+		name:  "digest",
+		usage: "digest [-replace] [-open]",
+		admin: true,
+		views: []View{ViewLibrary},
+		run:   runDigest,
+		complete: func(m *Model, args []string) []string {
+			return []string{"-replace", "-open"}
+		},
+	},
+	{
+		name:  "mail",
+		usage: "mail",
+		admin: true,
+		views: []View{ViewLibrary},
+		run:   runMail,
+	},
+	{
+		name:  "import-progress",
+		usage: "import-progress koreader|calibre [folder]",
+		admin: true,
+		views: []View{ViewLibrary},
+		run:   runImportProgress,
+		complete: func(m *Model, args []string) []string {
+			if len(args) == 1 {
+				return []string{"koreader", "calibre"}
+			}
+			return completePath(args[len(args)-1])
+		},
+	},
+	{
+		name:  "rename",
+		usage: "rename <new file name>",
+		admin: true,
+		views: []View{ViewLibrary},
+		run:   runRename,
+		complete: func(m *Model, args []string) []string {
+			if item, err := m.library.selectedBook(); err == nil && len(args) == 1 {
+				return []string{strings.TrimSuffix(filepath.Base(item.path), filepath.Ext(item.path))}
+			}
+			return nil
+		},
+	},
+	{
+		name:  "move",
+		usage: "move <folder>",
+		admin: true,
+		views: []View{ViewLibrary},
+		run:   runMove,
+		complete: func(m *Model, args []string) []string {
+			if len(args) == 1 {
+				return m.libraryFolders()
+			}
+			return nil
+		},
+	},
+	{
+		name:  "delete",
+		usage: "delete",
+		admin: true,
+		views: []View{ViewLibrary},
+		run:   runDelete,
+	},
+	{
+		name:  "clear-progress",
+		usage: "clear-progress",
+		admin: true,
+		views: []View{ViewLibrary},
+		run: func(m *Model, args []string) (string, tea.Cmd, error) {
+			item, ok := m.library.list.SelectedItem().(bookItem)
+			if !ok {
+				return "", nil, errorf("no book selected")
+			}
+			return m.clearProgress(item)
+		},
+	},
+	{
+		name:  "undo",
+		usage: "undo",
+		run: func(m *Model, args []string) (string, tea.Cmd, error) {
+			return m.undo()
+		},
+	},
+	{
+		name:  "trash",
+		usage: "trash [empty]",
+		admin: true,
+		run:   runTrash,
+		complete: func(m *Model, args []string) []string {
+			if len(args) == 1 {
+				return []string{"empty"}
+			}
+			return nil
+		},
+	},
+	{
+		name:  "journal",
+		usage: "journal",
+		views: []View{ViewReader, ViewLibrary},
+		run: func(m *Model, args []string) (string, tea.Cmd, error) {
+			if m.currentView() == ViewReader && m.reader.book != nil {
+				book := m.reader.book
+				return "", func() tea.Msg { return JournalMsg{Path: book.Path, Title: book.Title} }, nil
+			}
+			item, ok := m.library.list.SelectedItem().(bookItem)
+			if !ok {
+				return "", nil, errorf("no book selected")
+			}
+			return "", func() tea.Msg { return JournalMsg{Path: item.path, Title: item.title} }, nil
+		},
+	},
+	{
+		name:  "export-highlights",
+		usage: "export-highlights [all]",
+		views: []View{ViewReader, ViewLibrary},
+		run: func(m *Model, args []string) (string, tea.Cmd, error) {
+			if len(args) == 1 && args[0] == "all" {
+				var paths []string
+				for _, book := range m.library.books {
+					paths = append(paths, book.Path)
+				}
+				return "", exportHighlights(m.config, paths), nil
+			}
+			if len(args) > 0 {
+				return "", nil, errorf("usage: %s", "export-highlights [all]")
+			}
+			if m.currentView() == ViewReader && m.reader.book != nil {
+				return "", exportHighlights(m.config, []string{m.reader.book.Path}), nil
+			}
+			item, ok := m.library.list.SelectedItem().(bookItem)
+			if !ok {
+				return "", nil, errorf("no book selected")
+			}
+			return "", exportHighlights(m.config, []string{item.path}), nil
+		},
+		complete: func(m *Model, args []string) []string {
+			if len(args) == 1 {
+				return []string{"all"}
+			}
+			return nil
+		},
+	},
+	{
+		name:  "export-vocabulary",
+		usage: "export-vocabulary [language]",
+		views: []View{ViewReader, ViewLibrary},
+		run: func(m *Model, args []string) (string, tea.Cmd, error) {
+			switch {
+			case len(args) > 1:
+				return "", nil, errorf("usage: %s", "export-vocabulary [language]")
+			case len(args) == 1:
+				return "", exportVocabulary(m.config, []string{config.VocabularyLanguage(args[0])}), nil
+			case m.currentView() == ViewReader && m.reader.book != nil:
+				return "", exportVocabulary(m.config, []string{m.reader.bookLanguage()}), nil
+			}
+			return "", exportVocabulary(m.config, nil), nil
+		},
+		complete: func(m *Model, args []string) []string {
+			if len(args) == 1 {
+				return m.reader.vocabulary.LanguageList()
+			}
+			return nil
+		},
+	},
+	{
+		name:  "sync",
+		usage: "sync",
+		run: func(m *Model, args []string) (string, tea.Cmd, error) {
+			return "", m.syncWebDAV(), nil
+		},
+	},
+	{
+		name:  "help",
+		usage: "help [search]",
+		run: func(m *Model, args []string) (string, tea.Cmd, error) {
+			query := strings.Join(args, " ")
+			return "", func() tea.Msg { return HelpMsg{Query: query} }, nil
+		},
+	},
+	{
+		name:    "quit",
+		aliases: []string{"q"},
+		usage:   "quit",
+		run: func(m *Model, args []string) (string, tea.Cmd, error) {
+			return "", m.quit(), nil
+		},
+	},
+}
+
+// runBookmark implements the bookmark subcommands
+func runBookmark(m *Model, args []string) (string, tea.Cmd, error) {
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+	name := strings.Join(args[1:], " ")
+
+	switch args[0] {
+	case "add":
+		if name == "" {
+			name = trf("bookmark %d", len(m.reader.Bookmarks())+1)
+		}
+		return trf("Bookmark %q added", name), nil, m.reader.AddBookmark(name)
+	case "go":
+		return "", nil, m.reader.GotoBookmark(name)
+	case "del":
+		return m.removeBookmark(name)
+	case "list":
+		var names []string
+		for _, bookmark := range m.reader.Bookmarks() {
+			names = append(names, bookmark.Name)
+		}
+		if len(names) == 0 {
+			return tr("No bookmarks"), nil, nil
+		}
+		return trf("Bookmarks: %s", strings.Join(names, ", ")), nil, nil
+	}
+	return "", nil, errorf("usage: %s", "bookmark add|go|del|list [name]")
+}
+
+// saveToggle remembers a setting switched with a key in the reader.
+// Restricted mode keeps the settings in the config file as they are, so
+// there the switch lasts until cozy is closed.
+func saveToggle(cfg *config.Config) error {
+	if cfg.Restricted.Enabled {
+		return nil
+	}
+	return config.Save(cfg)
+}
+
+// setting is an option that can be changed with :set
+type setting struct {
+	name   string
+	values []string // Completion candidates
+	apply  func(cfg *config.Config, value string) error
+}
+
+var settings = []setting{
+	{"justify", []string{"on", "off"}, func(cfg *config.Config, value string) error {
+		return parseSwitch(value, &cfg.Display.Justify)
+	}},
+	{"zen", []string{"on", "off"}, func(cfg *config.Config, value string) error {
+		return parseSwitch(value, &cfg.Display.Zen)
+	}},
+	{"smart_typography", []string{"on", "off"}, func(cfg *config.Config, value string) error {
+		return parseSwitch(value, &cfg.Display.SmartTypography)
+	}},
+	{"smooth_scroll", []string{"on", "off"}, func(cfg *config.Config, value string) error {
+		return parseSwitch(value, &cfg.Display.SmoothScroll)
+	}},
+	{"paged", []string{"on", "off"}, func(cfg *config.Config, value string) error {
+		return parseSwitch(value, &cfg.Display.Paged)
+	}},
+	{"scroll_lines", nil, func(cfg *config.Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return errorf("scroll lines must be a number greater than 0")
+		}
+		cfg.Display.ScrollLines = n
+		return nil
+	}},
+	{"page_overlap", nil, func(cfg *config.Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return errorf("page overlap must be 0 or more")
+		}
+		cfg.Display.PageOverlap = n
+		return nil
+	}},
+	{"section_depth", []string{"1", "2", "3", "4", "5", "6"}, func(cfg *config.Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > 6 {
+			return errorf("section depth must be a heading level from 1 to 6")
+		}
+		cfg.Reading.SectionDepth = n
+		return nil
+	}},
+	{"accessible", []string{"on", "off"}, func(cfg *config.Config, value string) error {
+		return parseSwitch(value, &cfg.Display.Accessible)
+	}},
+	{"letter_spacing", []string{"on", "off"}, func(cfg *config.Config, value string) error {
+		return parseSwitch(value, &cfg.Display.LetterSpacing)
+	}},
+	{"word_spacing", nil, func(cfg *config.Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 || n > 4 {
+			return errorf("word spacing must be a number from 0 to 4")
+		}
+		cfg.Display.WordSpacing = n
+		return nil
+	}},
+	{"progress_bar", []string{"on", "off"}, func(cfg *config.Config, value string) error {
+		return parseSwitch(value, &cfg.Display.ProgressBar)
+	}},
+	{"scrollbar", []string{"on", "off"}, func(cfg *config.Config, value string) error {
+		return parseSwitch(value, &cfg.Display.Scrollbar)
+	}},
+	{"screen_reader", []string{"on", "off"}, func(cfg *config.Config, value string) error {
+		return parseSwitch(value, &cfg.Display.ScreenReader)
+	}},
+	{"ascii", []string{"on", "off"}, func(cfg *config.Config, value string) error {
+		return parseSwitch(value, &cfg.Display.ASCII)
+	}},
+	{"large_text", []string{"off", "headings", "all"}, func(cfg *config.Config, value string) error {
+		cfg.Display.LargeText = value
+		return nil
+	}},
+	{"large_text_style", []string{"double", "banner"}, func(cfg *config.Config, value string) error {
+		cfg.Display.LargeTextStyle = value
+		return nil
+	}},
+	{"ruler", []string{"off", "line", "band"}, func(cfg *config.Config, value string) error {
+		cfg.Display.Ruler = value
+		return nil
+	}},
+	{"color_profile", []string{"auto", "truecolor", "256", "16", "none"}, func(cfg *config.Config, value string) error {
+		cfg.Display.ColorProfile = value
+		return nil
+	}},
+	{"margin_mode", []string{"cells", "percent", "auto"}, func(cfg *config.Config, value string) error {
+		cfg.Display.MarginMode = value
+		return nil
+	}},
+	{"line_length", nil, func(cfg *config.Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 20 {
+			return errorf("line length must be a number of at least 20")
+		}
+		cfg.Display.LineLength = n
+		return nil
+	}},
+	{"margin_left", nil, func(cfg *config.Config, value string) error {
+		return parseMargin(value, &cfg.Display.MarginLeft)
+	}},
+	{"margin_right", nil, func(cfg *config.Config, value string) error {
+		return parseMargin(value, &cfg.Display.MarginRight)
+	}},
+	{"online_lookups", []string{"on", "off"}, func(cfg *config.Config, value string) error {
+		return parseSwitch(value, &cfg.Dictionary.OnlineLookups)
+	}},
+	{"flow_chapters", []string{"on", "off"}, func(cfg *config.Config, value string) error {
+		return parseSwitch(value, &cfg.Reading.FlowChapters)
+	}},
+	{"continuous", []string{"on", "off"}, func(cfg *config.Config, value string) error {
+		return parseSwitch(value, &cfg.Reading.Continuous)
+	}},
+	{"vocabulary", []string{"on", "off"}, func(cfg *config.Config, value string) error {
+		return parseSwitch(value, &cfg.Reading.Vocabulary)
+	}},
+	{"gutenberg_cleanup", []string{"on", "off"}, func(cfg *config.Config, value string) error {
+		return parseSwitch(value, &cfg.Reading.GutenbergCleanup)
+	}},
+	{"text_split", []string{"auto", "headings", "blank", "sections", "none"}, func(cfg *config.Config, value string) error {
+		cfg.Reading.TextSplit = value
+		return nil
+	}},
+	{"non_linear", []string{"include", "end", "skip"}, func(cfg *config.Config, value string) error {
+		cfg.Reading.NonLinear = value
+		return nil
+	}},
+	{"resume_last_book", []string{"on", "off"}, func(cfg *config.Config, value string) error {
+		return parseSwitch(value, &cfg.Reading.ResumeLastBook)
+	}},
+	{"session_summary", []string{"on", "off"}, func(cfg *config.Config, value string) error {
+		return parseSwitch(value, &cfg.Reading.SessionSummary)
+	}},
+	{"language", nil, func(cfg *config.Config, value string) error {
+		if _, err := config.LoadLocale(value); err != nil {
+			return err
+		}
+		cfg.Language = value
+		return nil
+	}},
+}
+
+// runSet changes a setting, applies it and saves the config
+func runSet(m *Model, args []string) (string, tea.Cmd, error) {
+	if len(args) != 2 {
+		return "", nil, errorf("usage: %s", "set <option> <value>")
+	}
+	for _, s := range settings {
+		if s.name != args[0] {
+			continue
+		}
+		if len(s.values) > 0 && !contains(s.values, args[1]) {
+			return "", nil, errorf("%s must be one of: %s", s.name, strings.Join(s.values, ", "))
+		}
+		if err := s.apply(m.config, args[1]); err != nil {
+			return "", nil, err
+		}
+		m.applySettings()
+		return fmt.Sprintf("%s = %s", s.name, args[1]), nil, config.Save(m.config)
+	}
+	return "", nil, errorf("unknown option: %s", args[0])
+}
+
+// applySettings brings the views up to date after a config change
+func (m *Model) applySettings() {
+	applyColorProfile(m.config.Display.ColorProfile)
+	applyScreenReader(m.config)
+	applyLanguage(m.config)
+	plainList(&m.library.list)
+	m.library.localize()
+	m.reader.online = onlineSources(m.config)
+	m.reader.SetSize(m.width, m.height)
+	m.library.coverViews = nil
+}
+
+func parseSwitch(value string, target *bool) error {
+	switch value {
+	case "on", "true", "yes":
+		*target = true
+	case "off", "false", "no":
+		*target = false
+	default:
+		return errorf("expected on or off, got %q", value)
+	}
+	return nil
+}
+
+func parseMargin(value string, target *int) error {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 || n > 40 {
+		return errorf("margin must be a number from 0 to 40")
+	}
+	*target = n
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Themes have no error color, so errors use a red that reads on light and dark backgrounds
+const errorColor = "#DC4B4B"
+
+// commandLine is the ':' prompt shared by all views
+type commandLine struct {
+	active  bool
+	input   textinput.Model
+	message string // Result or error of the last command
+	isError bool
+
+	// Run when the question in message is answered with y
+	confirm func() (string, tea.Cmd, error)
+
+	// Tab completion cycles through these candidates
+	completions []string
+	completion  int
+	prefix      string // Input before the completed word
+}
+
+// openCommandLine shows the ':' prompt
+func (m *Model) openCommandLine() tea.Cmd {
+	input := textinput.New()
+	input.Prompt = ":"
+	input.CharLimit = 200
+	m.command = commandLine{active: true, input: input}
+	return m.command.input.Focus()
+}
+
+// updateCommandLine handles keys while the command line is open
+func (m *Model) updateCommandLine(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.command.active = false
+		return nil
+	case tea.KeyEnter:
+		m.command.active = false
+		return m.execute(m.command.input.Value())
+	case tea.KeyTab:
+		m.complete()
+		return nil
+	}
+
+	m.command.completions = nil
+	var cmd tea.Cmd
+	m.command.input, cmd = m.command.input.Update(msg)
+	return cmd
+}
+
+// execute parses and runs a command line
+func (m *Model) execute(line string) tea.Cmd {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	c, err := m.findCommand(fields[0])
+	if err == nil {
+		var message string
+		var cmd tea.Cmd
+		message, cmd, err = c.run(m, fields[1:])
+		if err == nil {
+			m.command.message, m.command.isError = message, false
+			return cmd
+		}
+	}
+
+	m.command.message, m.command.isError = err.Error(), true
+	return nil
+}
+
+// confirm asks a yes or no question on the command line, running action
+// if it is answered with y
+func (m *Model) confirm(question string, action func() (string, tea.Cmd, error)) (string, tea.Cmd, error) {
+	m.command.confirm = action
+	return question + " " + tr("(y/n)"), nil, nil
+}
+
+// answerConfirm handles the key answering a confirm question
+func (m *Model) answerConfirm(msg tea.KeyMsg) tea.Cmd {
+	action := m.command.confirm
+	m.command.confirm = nil
+	if msg.String() != "y" && msg.String() != "Y" {
+		m.command.message, m.command.isError = tr("Cancelled"), false
+		return nil
+	}
+
+	message, cmd, err := action()
+	if err != nil {
+		m.command.message, m.command.isError = err.Error(), true
+		return nil
+	}
+	m.command.message, m.command.isError = message, false
+	return cmd
+}
+
+// findCommand resolves a command name, alias or unique prefix
+func (m *Model) findCommand(name string) (command, error) {
+	var matches []command
+	for _, c := range m.availableCommands() {
+		if c.name == name || contains(c.aliases, name) {
+			return c, nil
+		}
+		if strings.HasPrefix(c.name, name) {
+			matches = append(matches, c)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return command{}, errorf("unknown command: %s", name)
+	case 1:
+		return matches[0], nil
+	}
+	return command{}, errorf("ambiguous command: %s", name)
+}
+
+// availableCommands returns the commands usable in the current view
+func (m *Model) availableCommands() []command {
+	var available []command
+	for _, c := range commands {
+		if c.admin && m.config.Restricted.Enabled {
+			continue
+		}
+		if c.views == nil {
+			available = append(available, c)
+			continue
+		}
+		for _, v := range c.views {
+			if v == m.currentView() && (v != ViewReader || m.reader.book != nil) {
+				available = append(available, c)
+			}
+		}
+	}
+	return available
+}
+
+// complete fills in the word before the cursor, cycling through the
+// candidates on repeated presses
+func (m *Model) complete() {
+	cl := &m.command
+
+	if cl.completions == nil {
+		value := cl.input.Value()
+		fields := strings.Fields(value)
+		if strings.HasSuffix(value, " ") || len(fields) == 0 {
+			fields = append(fields, "")
+		}
+		word := fields[len(fields)-1]
+		cl.prefix = strings.TrimSuffix(value, word)
+
+		var candidates []string
+		if len(fields) == 1 {
+			for _, c := range m.availableCommands() {
+				candidates = append(candidates, c.name)
+			}
+		} else if c, err := m.findCommand(fields[0]); err == nil && c.complete != nil {
+			candidates = c.complete(m, fields[1:])
+		}
+
+		for _, candidate := range candidates {
+			if strings.HasPrefix(candidate, word) {
+				cl.completions = append(cl.completions, candidate)
+			}
+		}
+		sort.Strings(cl.completions)
+		if len(cl.completions) == 0 {
+			return
+		}
+		cl.completion = 0
+	} else {
+		cl.completion = (cl.completion + 1) % len(cl.completions)
+	}
+
+	completed := cl.prefix + cl.completions[cl.completion]
+	if len(cl.completions) == 1 {
+		// A unique match is final; the next tab completes the next word
+		completed += " "
+		cl.completions = nil
+	}
+	cl.input.SetValue(completed)
+	cl.input.CursorEnd()
+}
+
+// commandLineView renders the prompt or the last command's message
+func (m *Model) commandLineView() string {
+	theme := m.config.ActiveTheme
+	if m.command.active {
+		line := m.command.input.View()
+		if len(m.command.completions) > 1 {
+			hint := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.MutedTextColor))
+			line += "  " + hint.Render(strings.Join(m.command.completions, " "))
+		}
+		return line
+	}
+
+	color := theme.SecondaryColor
+	if m.command.isError {
+		color = errorColor
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render(m.command.message)
+}
+
+// withCommandLine replaces the last line of a view with the command line
+func (m *Model) withCommandLine(view string) string {
+	if !m.command.active && m.command.message == "" {
+		return view
+	}
+	lines := strings.Split(view, "\n")
+	lines[len(lines)-1] = m.commandLineView()
+	return strings.Join(lines, "\n")
+}
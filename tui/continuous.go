@@ -0,0 +1,187 @@
+package tui
+
+import "strings"
+
+// In continuous mode the chapters around the current one are joined into
+// one text, so scrolling runs on from one chapter into the next without a
+// jump. That suits books whose chapters are wherever the publisher split
+// the files. Only a window of chapters is joined: the one before, the
+// current one and enough after it to fill a screen. When the top of the
+// view moves into another chapter, that one becomes the current chapter
+// and the window is joined again around it.
+//
+// Lines are lines of the joined text. Positions saved to disk are still
+// relative to the current chapter, see textOffset.
+
+// chapterWindow is the chapters joined in the viewport
+type chapterWindow struct {
+	first  int   // First chapter of the window
+	starts []int // Line each chapter starts on, then the number of lines
+}
+
+// start returns the line a chapter of the window starts on
+func (w chapterWindow) start(chapter int) int {
+	if i := chapter - w.first; i >= 0 && i < len(w.starts) {
+		return w.starts[i]
+	}
+	return 0
+}
+
+// end returns the line after the last line of a chapter of the window
+func (w chapterWindow) end(chapter int) int {
+	if i := chapter - w.first + 1; i > 0 && i < len(w.starts) {
+		return w.starts[i]
+	}
+	return 0
+}
+
+// chapterAt returns the chapter of the window a line is in
+func (w chapterWindow) chapterAt(line int) int {
+	chapter := w.first
+	for i := 1; i < len(w.starts)-1 && w.starts[i] <= line; i++ {
+		chapter = w.first + i
+	}
+	return chapter
+}
+
+// contains reports whether a chapter is in the window
+func (w chapterWindow) contains(chapter int) bool {
+	return chapter >= w.first && chapter < w.next()
+}
+
+// next returns the chapter after the window
+func (w chapterWindow) next() int {
+	return w.first + max(len(w.starts)-1, 0)
+}
+
+// renderedWindow returns the current chapter as rendered for the
+// viewport, joined with the chapters around it in continuous mode
+func (m *ReaderModel) renderedWindow() renderedChapter {
+	current := m.renderedChapter(m.currentChapter)
+	if !m.config.Reading.Continuous {
+		m.window = chapterWindow{first: m.currentChapter, starts: []int{0, strings.Count(current.text, "\n") + 1}}
+		return current
+	}
+
+	// A screen of text after the current chapter lets its last line
+	// scroll to the top of the view, which moves the window on
+	first, last := max(m.currentChapter-1, 0), m.currentChapter
+	after := 0
+	for last+1 < m.book.ChapterCount() && (last == m.currentChapter || after < m.viewport.Height) {
+		last++
+		after += strings.Count(m.renderedChapter(last).text, "\n") + 2
+	}
+
+	// Chapters are joined with a blank line, which belongs to the chapter
+	// before it
+	m.window = chapterWindow{first: first}
+	var texts []string
+	var joined renderedChapter
+	line := 0
+	for index := first; index <= last; index++ {
+		rendered := current
+		if index != m.currentChapter {
+			rendered = m.renderedChapter(index)
+		}
+		m.window.starts = append(m.window.starts, line)
+		texts = append(texts, rendered.text)
+
+		for _, link := range rendered.links {
+			link.Line += line
+			joined.links = append(joined.links, link)
+		}
+		// Headings and anchors are those of the current chapter
+		if index == m.currentChapter {
+			for _, heading := range rendered.headings {
+				heading.Line += line
+				joined.headings = append(joined.headings, heading)
+			}
+			joined.anchors = make(map[string]int, len(rendered.anchors))
+			for id, anchor := range rendered.anchors {
+				joined.anchors[id] = anchor + line
+			}
+		}
+		line += strings.Count(rendered.text, "\n") + 2
+	}
+	m.window.starts = append(m.window.starts, line-1)
+	joined.text = strings.Join(texts, "\n\n")
+	return joined
+}
+
+// followChapter makes the chapter at the top of the view the current one
+// in continuous mode, joining the window again around it without moving
+// the text on screen
+func (m *ReaderModel) followChapter() {
+	if !m.config.Reading.Continuous || m.book == nil {
+		return
+	}
+	chapter := m.window.chapterAt(m.viewport.YOffset)
+	if chapter == m.currentChapter {
+		return
+	}
+
+	top := m.viewport.YOffset - m.window.start(chapter)
+	before := m.window.start(chapter)
+	ruler, scroll, lookup := m.ruler, m.scroll, m.lookup
+	m.currentChapter = chapter
+	m.updateViewport()
+	m.viewport.SetYOffset(m.window.start(chapter) + top)
+
+	// The ruler, a running scroll and the word cursor stay where they were
+	// on the text
+	shift := m.window.start(chapter) - before
+	if ruler >= 0 {
+		m.ruler = ruler + shift
+	}
+	if scroll.animating {
+		scroll.target += shift
+		m.scroll = scroll
+	}
+	if lookup.active {
+		lookup.line += shift
+		lookup.anchorLine += shift
+		m.lookup = lookup
+	}
+}
+
+// chapterStart returns the line the current chapter starts on
+func (m *ReaderModel) chapterStart() int {
+	return m.window.start(m.currentChapter)
+}
+
+// chapterPercent returns how far the view is scrolled through the current
+// chapter, from 0 to 1, like viewport.ScrollPercent for the whole text
+func (m *ReaderModel) chapterPercent() float64 {
+	start, end := m.chapterStart(), m.window.end(m.currentChapter)
+	span := end - start - m.viewport.Height
+	if span <= 0 {
+		return 1
+	}
+	return min(max(float64(m.viewport.YOffset-start)/float64(span), 0), 1)
+}
+
+// atChapterEnd reports whether the last line of the current chapter is in
+// view
+func (m *ReaderModel) atChapterEnd() bool {
+	return m.viewport.AtBottom() || m.viewport.YOffset+m.viewport.Height >= m.window.end(m.currentChapter)
+}
+
+// chapterLines returns the lines of the current chapter
+func (m *ReaderModel) chapterLines() (int, int) {
+	return m.chapterStart(), min(m.window.end(m.currentChapter), len(m.lines))
+}
+
+// lineInChapter returns a line of the joined text as a line of the current
+// chapter, for positions saved to disk
+func (m *ReaderModel) lineInChapter(line int) int {
+	return max(line-m.chapterStart(), 0)
+}
+
+// windowChapters returns the chapters joined in the viewport
+func (m *ReaderModel) windowChapters() []int {
+	var chapters []int
+	for chapter := m.window.first; chapter < m.window.next(); chapter++ {
+		chapters = append(chapters, chapter)
+	}
+	return chapters
+}
@@ -0,0 +1,196 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/cbrasser/cozy/ebook"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// coverProtocol is the method used to draw cover images in the terminal
+type coverProtocol string
+
+const (
+	coverKitty  coverProtocol = "kitty"
+	coverITerm  coverProtocol = "iterm"
+	coverBlocks coverProtocol = "blocks"
+	coverNone   coverProtocol = "none"
+)
+
+// detectCoverProtocol resolves the configured protocol, probing the
+// environment when it is set to "auto"
+func detectCoverProtocol(setting string) coverProtocol {
+	switch coverProtocol(setting) {
+	case coverKitty, coverITerm, coverBlocks, coverNone:
+		return coverProtocol(setting)
+	}
+
+	term := os.Getenv("TERM")
+	termProgram := os.Getenv("TERM_PROGRAM")
+
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "" || strings.Contains(term, "kitty") || termProgram == "ghostty":
+		return coverKitty
+	case termProgram == "iTerm.app" || termProgram == "WezTerm":
+		return coverITerm
+	default:
+		return coverBlocks
+	}
+}
+
+// renderCover draws a cover image into a cols x rows cell area
+func renderCover(img image.Image, cols, rows int, protocol coverProtocol) string {
+	switch protocol {
+	case coverKitty:
+		return reserveCells(kittyImage(img, cols, rows), cols, rows)
+	case coverITerm:
+		return reserveCells(itermImage(img, cols, rows), cols, rows)
+	case coverBlocks:
+		return blockImage(img, cols, rows)
+	default:
+		return ""
+	}
+}
+
+// blockImage renders an image with half-block characters, two pixels per cell
+func blockImage(img image.Image, cols, rows int) string {
+	scaled := ebook.ResizeImage(img, cols, rows*2)
+
+	var out strings.Builder
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			style := lipgloss.NewStyle().
+				Foreground(lipgloss.Color(hexColor(scaled, x, y*2))).
+				Background(lipgloss.Color(hexColor(scaled, x, y*2+1)))
+			out.WriteString(style.Render("▀"))
+		}
+		if y < rows-1 {
+			out.WriteString("\n")
+		}
+	}
+	return out.String()
+}
+
+// hexColor returns the color of a pixel as a hex string
+func hexColor(img *image.RGBA, x, y int) string {
+	c := img.RGBAAt(x, y)
+	return fmt.Sprintf("#%02X%02X%02X", c.R, c.G, c.B)
+}
+
+// Kitty keeps an image on screen until it is deleted, even once the text
+// it was drawn over is gone. Every cover gets an image id, and drawing it
+// again replaces its one placement; covers no longer in the view are
+// deleted, see deleteKittyImages.
+var (
+	kittyImageID      int          // Last id given to an image
+	kittyImagesShown  map[int]bool // Images in the last view
+	kittyImagePattern = regexp.MustCompile("\x1b_Gi=([0-9]+),a=T")
+)
+
+// kittyImage encodes an image using the kitty graphics protocol
+func kittyImage(img image.Image, cols, rows int) string {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return ""
+	}
+	payload := base64.StdEncoding.EncodeToString(buf.Bytes())
+	kittyImageID++
+	id := kittyImageID
+
+	// Payloads must be sent in chunks of at most 4096 bytes
+	var out strings.Builder
+	first := true
+	for len(payload) > 0 {
+		chunk := payload[:min(len(payload), 4096)]
+		payload = payload[len(chunk):]
+		more := 0
+		if len(payload) > 0 {
+			more = 1
+		}
+		if first {
+			fmt.Fprintf(&out, "\x1b_Gi=%d,a=T,p=1,f=100,q=2,C=1,c=%d,r=%d,m=%d;%s\x1b\\", id, cols, rows, more, chunk)
+			first = false
+		} else {
+			fmt.Fprintf(&out, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	return out.String()
+}
+
+// deleteKittyImages deletes the images of the last view that are not in
+// this one, such as the covers of a gallery that was left or scrolled
+func deleteKittyImages(view string) string {
+	if kittyImageID == 0 {
+		return view
+	}
+	shown := make(map[int]bool)
+	for _, match := range kittyImagePattern.FindAllStringSubmatch(view, -1) {
+		id, _ := strconv.Atoi(match[1])
+		shown[id] = true
+	}
+	var deletes strings.Builder
+	for id := range kittyImagesShown {
+		if !shown[id] {
+			fmt.Fprintf(&deletes, "\x1b_Ga=d,d=I,i=%d,q=2\x1b\\", id)
+		}
+	}
+	kittyImagesShown = shown
+	return deletes.String() + view
+}
+
+// itermImage encodes an image using the iTerm2 inline image protocol
+func itermImage(img image.Image, cols, rows int) string {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return ""
+	}
+	return fmt.Sprintf("\x1b]1337;File=inline=1;width=%d;height=%d;preserveAspectRatio=1:%s\a",
+		cols, rows, base64.StdEncoding.EncodeToString(buf.Bytes()))
+}
+
+// reserveCells pads an inline image escape sequence with blank cells so
+// the surrounding layout leaves room for the image
+func reserveCells(sequence string, cols, rows int) string {
+	lines := make([]string, rows)
+	for i := range lines {
+		lines[i] = strings.Repeat(" ", cols)
+	}
+	lines[0] = sequence + lines[0]
+	return strings.Join(lines, "\n")
+}
+
+// coverPlaceholder draws a text box standing in for a missing cover
+func coverPlaceholder(title string, cols, rows int, theme *config.Theme) string {
+	return lipgloss.NewStyle().
+		Border(panelBorder()).
+		BorderForeground(lipgloss.Color(theme.PrimaryColor)).
+		Foreground(lipgloss.Color(theme.MutedTextColor)).
+		Width(cols-2).
+		Height(rows-2).
+		Align(lipgloss.Center, lipgloss.Center).
+		Render(truncate(title, (cols-2)*(rows-2)))
+}
+
+// truncate shortens a string to at most n runes, adding an ellipsis
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if n <= 0 {
+		return ""
+	}
+	if len(runes) <= n {
+		return s
+	}
+	if n == 1 {
+		return "…"
+	}
+	return string(runes[:n-1]) + "…"
+}
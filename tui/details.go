@@ -0,0 +1,297 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/cbrasser/cozy/ebook"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+// detailsKeyMap defines key bindings for the book details view
+type detailsKeyMap struct {
+	Open       key.Binding
+	ScrollUp   key.Binding
+	ScrollDown key.Binding
+	Back       key.Binding
+	Quit       key.Binding
+}
+
+func (k detailsKeyMap) ShortHelp() []key.Binding {
+	return localized(k.Open, k.ScrollUp, k.ScrollDown, k.Back, k.Quit, appKeys.Help)
+}
+
+func (k detailsKeyMap) FullHelp() [][]key.Binding {
+	return localizedGroups([][]key.Binding{{k.Open, k.ScrollUp, k.ScrollDown, k.Back}})
+}
+
+var detailsKeys = detailsKeyMap{
+	Open: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "read"),
+	),
+	ScrollUp: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "scroll up"),
+	),
+	ScrollDown: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "scroll down"),
+	),
+	Back: key.NewBinding(
+		key.WithKeys("esc", "i"),
+		key.WithHelp("esc", "back to library"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys("q", "ctrl+c"),
+		key.WithHelp("q", "quit"),
+	),
+}
+
+// DetailsModel shows the full metadata of a single book
+type DetailsModel struct {
+	config      *config.Config
+	book        *ebook.Book
+	progress    config.BookProgress
+	hasProgress bool
+	fileSize    int64
+	wordCount   int
+	cover       string // Rendered cover image or placeholder
+	viewport    viewport.Model
+	help        help.Model
+	keys        detailsKeyMap
+	width       int
+	height      int
+}
+
+// NewDetailsModel creates a new book details model
+func NewDetailsModel(cfg *config.Config) *DetailsModel {
+	return &DetailsModel{
+		config:   cfg,
+		viewport: viewport.New(0, 0),
+		help:     help.New(),
+		keys:     detailsKeys,
+	}
+}
+
+// Init initializes the details view
+func (m *DetailsModel) Init() tea.Cmd {
+	return nil
+}
+
+// SetBook shows the given book and its reading progress
+func (m *DetailsModel) SetBook(book *ebook.Book, progress config.BookProgress, hasProgress bool) {
+	if m.book != nil && m.book != book {
+		m.book.Close()
+	}
+	m.book = book
+	m.progress = progress
+	m.hasProgress = hasProgress
+	m.fileSize = 0
+	if info, err := os.Stat(book.Path); err == nil {
+		m.fileSize = info.Size()
+	}
+	m.wordCount = book.WordCount()
+	m.cover = ""
+	if img, err := ebook.LoadCoverThumbnail(book.Path, m.config.CoverCacheDir()); err == nil {
+		m.cover = renderCover(img, coverCols, coverRows, detectCoverProtocol(m.config.Display.CoverProtocol))
+	}
+	m.updateViewport()
+	m.viewport.GotoTop()
+}
+
+// SetSize updates the size of the details view
+func (m *DetailsModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.help.Width = width
+	m.viewport.Width = width - 4
+	m.viewport.Height = height - 4 // Account for title and help
+	m.updateViewport()
+}
+
+// updateViewport renders the metadata into the viewport
+func (m *DetailsModel) updateViewport() {
+	if m.book == nil || m.config.ActiveTheme == nil {
+		return
+	}
+
+	theme := m.config.ActiveTheme
+	width := m.viewport.Width
+	if width <= 0 {
+		width = 80
+	}
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.SecondaryColor)).
+		Bold(true).
+		Width(14)
+	valueStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.TextColor))
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.HeadingColor)).
+		Bold(true)
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.MutedTextColor))
+
+	// Cover image (or a placeholder box) next to the title block
+	cover := m.cover
+	if cover == "" {
+		cover = coverPlaceholder(tr("no cover"), coverCols, coverRows, theme)
+	}
+
+	headerLines := []string{titleStyle.Render(ebook.WrapText(m.book.Title, max(width-20, 20)))}
+	if byline := m.book.Byline(); byline != "" {
+		headerLines = append(headerLines, valueStyle.Render(wordwrap.String(byline, max(width-20, 20))))
+	}
+	headerLines = append(headerLines, mutedStyle.Render(strings.ToUpper(string(m.book.Format))))
+	header := lipgloss.JoinHorizontal(lipgloss.Top, cover, "  ", strings.Join(headerLines, "\n"))
+
+	// Metadata fields, skipping empty ones
+	series := m.book.Metadata["series"]
+	if index := m.book.Metadata["series_index"]; series != "" && index != "" {
+		series += " #" + index
+	}
+
+	fields := [][2]string{
+		{"Series", series},
+		{"Rating", formatRating(m.book.Metadata["rating"])},
+		{"Contributors", m.book.Metadata["contributors"]},
+		{"Language", m.book.Metadata["language"]},
+		{"Publisher", m.book.Metadata["publisher"]},
+		{"Published", m.book.Metadata["date"]},
+		{"ISBN", m.book.Metadata["isbn"]},
+		{"Identifiers", m.book.Metadata["identifiers"]},
+		{"Subjects", m.book.Metadata["subjects"]},
+		{"Source", m.book.Metadata["source"]},
+		{"Tags", strings.Join(m.book.Tags, " / ")},
+		{"Chapters", fmt.Sprintf("%d", m.book.ChapterCount())},
+		{"Words", fmt.Sprintf("%d", m.wordCount)},
+		{"Pages", fmt.Sprintf("%d", ebook.Pages(m.wordCount, m.config.Reading.WordsPerPage))},
+		{"Read-aloud", m.readAloudText()},
+		{"File size", formatFileSize(m.fileSize)},
+		{"Progress", m.progressText()},
+		{"Path", m.book.Path},
+	}
+
+	var rows []string
+	for _, field := range fields {
+		if field[1] == "" {
+			continue
+		}
+		value := wordwrap.String(field[1], max(width-14, 20))
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, labelStyle.Render(tr(field[0])), valueStyle.Render(value)))
+	}
+
+	content := header + "\n\n" + strings.Join(rows, "\n")
+
+	if description := m.book.Metadata["description"]; description != "" {
+		content += "\n\n" + labelStyle.Render(tr("Description")) + "\n" +
+			valueStyle.Render(ebook.WrapText(description, width))
+	}
+
+	m.viewport.SetContent(content)
+}
+
+// progressText describes the saved reading progress
+func (m *DetailsModel) progressText() string {
+	if !m.hasProgress {
+		return tr("Not started")
+	}
+	if m.progress.Finished && !m.progress.FinishedAt.IsZero() {
+		return "✓ " + trf("Finished %s", m.progress.FinishedAt.Format(tr("January 2, 2006")))
+	}
+	if m.progress.Finished {
+		return "✓ " + tr("Finished")
+	}
+	return trf("%.0f%% (chapter %d/%d)",
+		m.progress.GetCompletionPercentage(),
+		m.progress.CurrentChapter+1,
+		m.book.ChapterCount())
+}
+
+// readAloudText describes the read-aloud audio of books with media
+// overlays: its running time and narrator
+func (m *DetailsModel) readAloudText() string {
+	if !m.book.HasMediaOverlays() {
+		return ""
+	}
+	text := tr("Included")
+	if duration, err := ebook.ParseClockValue(m.book.Metadata["media_duration"]); err == nil && duration > 0 {
+		minutes := int(duration.Round(time.Minute).Minutes())
+		text = trf("%d min", minutes)
+		if minutes >= 60 {
+			text = trf("%dh %02dm", minutes/60, minutes%60)
+		}
+	}
+	if narrator := m.book.Metadata["narrator"]; narrator != "" {
+		text = trf("%s, read by %s", text, narrator)
+	}
+	return text
+}
+
+// formatFileSize formats a byte count for display
+func formatFileSize(size int64) string {
+	switch {
+	case size <= 0:
+		return ""
+	case size < 1024:
+		return fmt.Sprintf("%d B", size)
+	case size < 1024*1024:
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	default:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+	}
+}
+
+// Update handles messages for the details view
+func (m *DetailsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.book == nil {
+		return m, nil
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.keys.Back):
+			return m, func() tea.Msg { return BackMsg{} }
+
+		case key.Matches(msg, m.keys.Open):
+			// The reader takes over the open book
+			book := m.book
+			m.book = nil
+			return m, func() tea.Msg { return BookSelectedMsg{Book: book} }
+		}
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+// View renders the details view
+func (m *DetailsModel) View() string {
+	if m.book == nil || m.config.ActiveTheme == nil {
+		return tr("No book selected")
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color(m.config.ActiveTheme.PrimaryColor)).
+		Padding(0, 1)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		titleStyle.Render(tr("Book Details")),
+		lipgloss.NewStyle().Padding(0, 2).Render(m.viewport.View()),
+		plain(m.help.View(m.keys)),
+	)
+}
@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cbrasser/cozy/ebook"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// digestMsg reports a daily edition built in the background
+type digestMsg struct {
+	digest ebook.Digest
+	open   bool // Open the edition once the library has it
+	err    error
+}
+
+// runDigest builds today's edition from the feeds in [digest] in the
+// background
+func runDigest(m *Model, args []string) (string, tea.Cmd, error) {
+	var replace, open bool
+	for _, arg := range args {
+		switch arg {
+		case "-replace":
+			replace = true
+		case "-open":
+			open = true
+		default:
+			return "", nil, errorf("usage: %s", "digest [-replace] [-open]")
+		}
+	}
+	cfg := m.config
+	dir := cfg.DigestPath()
+	if dir == "" {
+		return "", nil, errorf("no digest folder: set digest.folder")
+	}
+	if len(cfg.Digest.Feeds) == 0 {
+		return "", nil, errorf("no feeds: add some to digest.feeds")
+	}
+
+	makeDigest := func() tea.Msg {
+		now := time.Now()
+		options := ebook.DigestOptions{
+			Since:      now.Add(-24 * time.Hour),
+			MaxPerFeed: cfg.Digest.MaxPerFeed,
+			FullText:   cfg.Digest.FullText,
+			Replace:    replace,
+		}
+		digest, err := ebook.MakeDigest(cfg.Digest.Feeds, dir, now, options)
+		return digestMsg{digest: digest, open: open, err: err}
+	}
+	return tr("Building today's edition..."), makeDigest, nil
+}
+
+// applyDigest reports a daily edition and rescans the library for it,
+// opening it if asked to. Asked to open an edition that was already
+// built, it opens that one.
+func (m *Model) applyDigest(msg digestMsg) tea.Cmd {
+	openBook := func() tea.Msg { return openFileMsg{path: msg.digest.Path} }
+	switch {
+	case errors.Is(msg.err, ebook.ErrDuplicate) && msg.open:
+		return openBook
+	case errors.Is(msg.err, ebook.ErrDuplicate):
+		return notify(toastInfo, "Today's edition is already in the library; use :digest -replace to build it again")
+	case msg.err != nil:
+		return notify(toastError, "Could not build today's edition: %v", msg.err)
+	}
+
+	toast := notify(toastSuccess, "Today's edition: %d article(s)", msg.digest.Articles)
+	if len(msg.digest.Failed) > 0 {
+		toast = notify(toastError, "Today's edition: %d article(s), %d feed(s) could not be read", msg.digest.Articles, len(msg.digest.Failed))
+	}
+	if !msg.open {
+		return tea.Batch(toast, m.library.loadBooks())
+	}
+	return tea.Batch(toast, tea.Sequence(m.library.loadBooks(), openBook))
+}
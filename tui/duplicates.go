@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/cbrasser/cozy/ebook"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// duplicatesSummary lists the duplicated books with the formats found
+func (m *LibraryModel) duplicatesSummary() string {
+	if len(m.duplicates) == 0 {
+		return tr("No duplicate books")
+	}
+	var names []string
+	for _, group := range m.duplicates {
+		var formats []string
+		for _, book := range group.Books {
+			formats = append(formats, strings.TrimPrefix(strings.ToLower(filepath.Ext(book.Path)), "."))
+		}
+		title := group.Books[0].Title
+		if title == "" {
+			title = filepath.Base(group.Books[0].Path)
+		}
+		names = append(names, fmt.Sprintf("%s (%s)", title, strings.Join(formats, ", ")))
+	}
+	return trf("%d duplicated: %s", len(m.duplicates), strings.Join(names, "; "))
+}
+
+// dedupe moves every copy of a duplicated book but the preferred one out of
+// the library, into the duplicates folder of the data directory, and
+// rescans the library. Progress and highlights go to the copy kept. Books
+// managed by Calibre are left alone, as moving them would break its
+// database.
+func (m *Model) dedupe() (string, tea.Cmd, error) {
+	if len(m.library.duplicates) == 0 {
+		return tr("No duplicate books"), nil, nil
+	}
+	dest := filepath.Join(m.config.DataDirectory(), "duplicates")
+
+	moved := 0
+	var failed []string
+	var errs []error
+	for _, group := range m.library.duplicates {
+		keep := group.Preferred(m.config.Library.PreferredFormats)
+		for _, book := range group.Books {
+			if book.Path == keep.Path || book.Calibre {
+				continue
+			}
+			if m.reader.book != nil && m.reader.book.Path == book.Path {
+				continue // Open in the reader
+			}
+			if _, err := ebook.MoveBook(book.Path, dest); err != nil {
+				failed = append(failed, filepath.Base(book.Path))
+				continue
+			}
+			errs = append(errs, m.moveBookData(book.Path, keep.Path))
+			moved++
+		}
+	}
+
+	if err := errors.Join(append(errs, m.saveBookData())...); err != nil {
+		return "", nil, err
+	}
+
+	message := trf("Moved %d duplicate(s) to %s", moved, dest)
+	if len(failed) > 0 {
+		return "", m.library.loadBooks(), errorf("%s; could not move %s", message, strings.Join(failed, ", "))
+	}
+	return message, m.library.loadBooks(), nil
+}
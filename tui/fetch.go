@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/cbrasser/cozy/ebook"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// bookFetchedMsg reports a book downloaded in the background
+type bookFetchedMsg struct {
+	path      string
+	open      bool // Open the book once the library has it
+	duplicate bool // The library already had it at path
+	err       error
+}
+
+// runFetch downloads a book from a URL into the library in the background,
+// organized as Author/Title like an import
+func runFetch(m *Model, args []string) (string, tea.Cmd, error) {
+	var convert, open bool
+	for len(args) > 0 && (args[0] == "-convert" || args[0] == "-open") {
+		convert = convert || args[0] == "-convert"
+		open = open || args[0] == "-open"
+		args = args[1:]
+	}
+	if len(args) != 1 {
+		return "", nil, errorf("usage: %s", "fetch [-convert] [-open] <url>")
+	}
+	url := args[0]
+
+	cfg := m.config
+	fetchBook := func() tea.Msg {
+		options := ebook.ExportOptions{ConvertText: convert, Book: BookOptions(cfg)}
+		path, err := ebook.FetchBook(url, cfg.Library.Path, options)
+		if errors.Is(err, ebook.ErrDuplicate) {
+			return bookFetchedMsg{path: path, open: open, duplicate: true}
+		}
+		return bookFetchedMsg{path: path, open: open, err: err}
+	}
+	return trf("Downloading %s...", url), fetchBook, nil
+}
+
+// applyFetchedBook rescans the library for a downloaded book and opens it
+// if asked to
+func (m *Model) applyFetchedBook(msg bookFetchedMsg) tea.Cmd {
+	if msg.err != nil {
+		return notify(toastError, "Download failed: %v", msg.err)
+	}
+
+	toast := notify(toastSuccess, "Downloaded %s", filepath.Base(msg.path))
+	if msg.duplicate {
+		toast = notify(toastInfo, "Already in the library as %s", filepath.Base(msg.path))
+	}
+	if !msg.open {
+		return tea.Batch(toast, m.library.loadBooks())
+	}
+	openBook := func() tea.Msg { return openFileMsg{path: msg.path} }
+	return tea.Batch(toast, tea.Sequence(m.library.loadBooks(), openBook))
+}
+
+// runImportArticle saves a web article into the articles folder of the
+// library in the background, then rescans it
+func runImportArticle(m *Model, args []string) (string, tea.Cmd, error) {
+	if len(args) != 1 {
+		return "", nil, errorf("usage: %s", "import-article <url>")
+	}
+	dir := m.config.ArticlesPath()
+	if dir == "" {
+		return "", nil, errorf("no articles folder: set library.articles_folder")
+	}
+	url := args[0]
+
+	importArticle := func() tea.Msg {
+		path, err := ebook.ImportArticle(url, dir)
+		switch {
+		case errors.Is(err, ebook.ErrDuplicate):
+			return toastMsg{text: trf("Already saved as %s", filepath.Base(path)), kind: toastInfo}
+		case err != nil:
+			return toastMsg{text: trf("Could not save article: %v", err), kind: toastError}
+		}
+		return toastMsg{text: trf("Saved %s", filepath.Base(path)), kind: toastSuccess}
+	}
+	return trf("Saving %s...", url), tea.Sequence(importArticle, m.library.loadBooks()), nil
+}
@@ -0,0 +1,237 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// FilesMsg opens the file browser in Dir, or with the recent files if Dir
+// is empty
+type FilesMsg struct {
+	Dir string
+}
+
+// openFileMsg asks the model to open a book file
+type openFileMsg struct {
+	path string
+}
+
+// filesKeyMap defines key bindings for the file browser
+type filesKeyMap struct {
+	Open   key.Binding
+	Switch key.Binding
+	Forget key.Binding
+	Back   key.Binding
+}
+
+func (k filesKeyMap) ShortHelp() []key.Binding {
+	return localized(k.Open, k.Switch, k.Forget, k.Back)
+}
+
+func (k filesKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+var filesKeys = filesKeyMap{
+	Open: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "open"),
+	),
+	Switch: key.NewBinding(
+		key.WithKeys("tab"),
+		key.WithHelp("tab", "recent/browse"),
+	),
+	Forget: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "forget"),
+	),
+	Back: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "back"),
+	),
+}
+
+// FilesModel picks a book file to open from anywhere, without adding it
+// to the library. It lists the books opened this way recently, and browses
+// folders for others.
+type FilesModel struct {
+	config       *config.Config
+	browser      dirBrowser
+	recent       *config.RecentData
+	recentCursor int
+	showRecent   bool
+	help         help.Model
+	width        int
+	height       int
+}
+
+// NewFilesModel creates a new file browser
+func NewFilesModel(cfg *config.Config) *FilesModel {
+	recent, err := config.LoadRecent(cfg)
+	if err != nil {
+		recent = &config.RecentData{}
+	}
+	return &FilesModel{
+		config: cfg,
+		recent: recent,
+		help:   help.New(),
+	}
+}
+
+// Init initializes the file browser
+func (m *FilesModel) Init() tea.Cmd {
+	return nil
+}
+
+// Show starts browsing in a folder. Without one, the recent files are
+// shown if there are any, and browsing starts in the home folder.
+func (m *FilesModel) Show(dir string) {
+	m.showRecent = dir == "" && len(m.recent.Files) > 0
+	m.recentCursor = 0
+	if dir == "" {
+		dir, _ = os.UserHomeDir()
+	}
+	m.browser = newDirBrowser(dir, true)
+}
+
+// remember adds a book opened from outside the library to the recent files
+func (m *FilesModel) remember(path string) tea.Cmd {
+	m.recent.Add(path)
+	if err := config.SaveRecent(m.config, m.recent); err != nil {
+		return notify(toastError, "Could not save recent files: %v", err)
+	}
+	return nil
+}
+
+// SetSize updates the size of the file browser
+func (m *FilesModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.help.Width = width
+}
+
+// Update handles keys in the file browser
+func (m *FilesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, filesKeys.Back):
+		return m, func() tea.Msg { return BackMsg{} }
+	case key.Matches(keyMsg, filesKeys.Switch):
+		m.showRecent = !m.showRecent
+		return m, nil
+	}
+
+	if m.showRecent {
+		return m, m.updateRecent(keyMsg)
+	}
+
+	if key.Matches(keyMsg, filesKeys.Open) {
+		entry, path, ok := m.browser.selected()
+		switch {
+		case !ok:
+		case entry.dir:
+			m.browser.open(path)
+		default:
+			return m, func() tea.Msg { return openFileMsg{path: path} }
+		}
+		return m, nil
+	}
+	m.browser.update(keyMsg)
+	return m, nil
+}
+
+// updateRecent handles keys in the list of recent files
+func (m *FilesModel) updateRecent(msg tea.KeyMsg) tea.Cmd {
+	files := m.recent.Files
+	if len(files) == 0 {
+		return nil
+	}
+	switch {
+	case key.Matches(msg, browserKeys.Up):
+		m.recentCursor = max(m.recentCursor-1, 0)
+	case key.Matches(msg, browserKeys.Down):
+		m.recentCursor = min(m.recentCursor+1, len(files)-1)
+	case key.Matches(msg, filesKeys.Open):
+		path := files[m.recentCursor]
+		return func() tea.Msg { return openFileMsg{path: path} }
+	case key.Matches(msg, filesKeys.Forget):
+		m.recent.Remove(files[m.recentCursor])
+		m.recentCursor = max(min(m.recentCursor, len(m.recent.Files)-1), 0)
+		if err := config.SaveRecent(m.config, m.recent); err != nil {
+			return notify(toastError, "Could not save recent files: %v", err)
+		}
+	}
+	return nil
+}
+
+// View renders the file browser
+func (m *FilesModel) View() string {
+	if m.config.ActiveTheme == nil {
+		return ""
+	}
+	theme := m.config.ActiveTheme
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(theme.PrimaryColor)).Padding(0, 1)
+	textStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.TextColor))
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.MutedTextColor))
+	selectedStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(theme.SecondaryColor))
+
+	tab := func(name string, active bool) string {
+		if active {
+			return selectedStyle.Underline(true).Render(name)
+		}
+		return mutedStyle.Render(name)
+	}
+	tabs := tab(tr("Recent"), m.showRecent) + "   " + tab(tr("Browse"), !m.showRecent)
+
+	rows := max(m.height-5, 1) // Title, tabs, spacing and help
+	var body string
+	if m.showRecent {
+		body = m.recentView(rows, selectedStyle, textStyle, mutedStyle)
+	} else {
+		body = m.browser.view(rows, selectedStyle, textStyle, mutedStyle)
+	}
+
+	keys := append(filesKeys.ShortHelp(), browserKeys.Parent, browserKeys.Home)
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		titleStyle.Render(tr("Open a book")),
+		lipgloss.NewStyle().Padding(0, 2).Render(tabs),
+		"",
+		lipgloss.NewStyle().Padding(0, 2).Height(rows).Render(body),
+	)
+	return lipgloss.JoinVertical(lipgloss.Left, content, plain(m.help.ShortHelpView(keys)))
+}
+
+// recentView lists the recent files, newest first, with their folders
+func (m *FilesModel) recentView(rows int, selected, normal, muted lipgloss.Style) string {
+	files := m.recent.Files
+	if len(files) == 0 {
+		return muted.Render(tr("No recent files; books opened from outside the library are listed here"))
+	}
+	first := max(0, min(m.recentCursor-rows/2, len(files)-rows))
+	var lines []string
+	for i := first; i < min(first+rows, len(files)); i++ {
+		name := filepath.Base(files[i])
+		folder := " " + filepath.Dir(files[i])
+		if _, err := os.Stat(files[i]); err != nil {
+			folder += " " + tr("(missing)")
+		}
+		if i == m.recentCursor {
+			lines = append(lines, selected.Render("> "+name)+muted.Render(folder))
+		} else {
+			lines = append(lines, normal.Render("  "+name)+muted.Render(folder))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
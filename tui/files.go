@@ -0,0 +1,261 @@
+package tui
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/cbrasser/cozy/ebook"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// selectedBook returns the book under the cursor, which can be renamed,
+// moved or deleted as long as Calibre doesn't manage it
+func (m *LibraryModel) selectedBook() (bookItem, error) {
+	item, ok := m.list.SelectedItem().(bookItem)
+	if !ok {
+		return bookItem{}, errorf("no book selected")
+	}
+	if item.calibre {
+		return bookItem{}, errorf("%s is managed by Calibre; change it there", item.title)
+	}
+	return item, nil
+}
+
+// runRename gives the selected book's file a new name, keeping its
+// extension
+func runRename(m *Model, args []string) (string, tea.Cmd, error) {
+	item, err := m.library.selectedBook()
+	if err != nil {
+		return "", nil, err
+	}
+	name := strings.Join(args, " ")
+	if name == "" || strings.ContainsRune(name, filepath.Separator) {
+		return "", nil, errorf("usage: %s", "rename <new file name>")
+	}
+	ext := filepath.Ext(item.path)
+	if !strings.EqualFold(filepath.Ext(name), ext) {
+		name += ext
+	}
+	target := filepath.Join(filepath.Dir(item.path), name)
+	return m.confirm(trf("Rename %s to %s?", filepath.Base(item.path), name), func() (string, tea.Cmd, error) {
+		return m.relocateBook(item.path, target)
+	})
+}
+
+// runMove moves the selected book into a folder of the library, which
+// changes its tags
+func runMove(m *Model, args []string) (string, tea.Cmd, error) {
+	item, err := m.library.selectedBook()
+	if err != nil {
+		return "", nil, err
+	}
+	folder := strings.Join(args, " ")
+	if folder == "" {
+		return "", nil, errorf("usage: %s", "move <folder in the library>")
+	}
+	dir := filepath.Join(m.config.Library.Path, folder)
+	if rel, err := filepath.Rel(m.config.Library.Path, dir); err != nil || strings.HasPrefix(rel, "..") {
+		return "", nil, errorf("%s is outside the library", folder)
+	}
+	target := filepath.Join(dir, filepath.Base(item.path))
+	return m.confirm(trf("Move %s to %s?", filepath.Base(item.path), folder), func() (string, tea.Cmd, error) {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", nil, err
+		}
+		return m.relocateBook(item.path, target)
+	})
+}
+
+// runDelete moves the selected book's file to the trash, see trash.go
+func runDelete(m *Model, args []string) (string, tea.Cmd, error) {
+	item, err := m.library.selectedBook()
+	if err != nil {
+		return "", nil, err
+	}
+	return m.confirm(trf("Move %s to the trash?", filepath.Base(item.path)), func() (string, tea.Cmd, error) {
+		return m.trashBook(item)
+	})
+}
+
+// relocateBook moves a book file within the library, taking its progress
+// and highlights along, and rescans the library
+func (m *Model) relocateBook(from, to string) (string, tea.Cmd, error) {
+	if err := ebook.RenameBook(from, to); err != nil {
+		return "", nil, err
+	}
+	if err := errors.Join(m.moveBookData(from, to), m.saveBookData()); err != nil {
+		return "", nil, err
+	}
+	rel, err := filepath.Rel(m.config.Library.Path, to)
+	if err != nil {
+		rel = to
+	}
+	return trf("Moved to %s", rel), m.library.loadBooks(), nil
+}
+
+// moveBookData gives the progress, highlights, queue place, review and
+// notes of a book to its new path. The reader's copies are changed, as it saves them.
+// The old path's sync file goes; the new path gets one when saved. The
+// config is saved if the book was the current one.
+func (m *Model) moveBookData(from, to string) error {
+	config.RemoveSyncedBook(m.config, from)
+	m.reader.progress.MoveBook(from, to)
+	m.reader.highlights.MoveBook(from, to)
+	m.library.queue.MoveBook(from, to)
+	m.library.reviews.MoveBook(from, to)
+	m.journal.journal.MoveBook(from, to)
+	if m.config.Reading.CurrentBook == from {
+		m.config.Reading.CurrentBook = to
+		return config.Save(m.config)
+	}
+	return nil
+}
+
+// saveBookData saves the book data changed by moveBookData
+func (m *Model) saveBookData() error {
+	if err := config.SaveProgress(m.config, m.reader.progress); err != nil {
+		return err
+	}
+	if err := config.SaveHighlights(m.config, m.reader.highlights); err != nil {
+		return err
+	}
+	if err := config.SaveQueue(m.config, m.library.queue); err != nil {
+		return err
+	}
+	if err := config.SaveReviews(m.config, m.library.reviews); err != nil {
+		return err
+	}
+	if err := config.SaveJournal(m.config, m.journal.journal); err != nil {
+		return err
+	}
+	m.library.reloadProgress()
+	return nil
+}
+
+// libraryFolders lists the folders of the library, for completing :move
+func (m *Model) libraryFolders() []string {
+	root := m.config.Library.Path
+	var folders []string
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || path == root {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if rel, err := filepath.Rel(root, path); err == nil {
+			folders = append(folders, rel)
+		}
+		return nil
+	})
+	return folders
+}
+
+// runImport copies books into the library in the background, organized
+// as Author/Title, then rescans it
+func runImport(m *Model, args []string) (string, tea.Cmd, error) {
+	convert := len(args) > 0 && args[0] == "-convert"
+	if convert {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return "", nil, errorf("usage: %s", "import [-convert] <file or folder>")
+	}
+	path := expandHome(strings.Join(args, " "))
+
+	cfg := m.config
+	importBooks := func() tea.Msg {
+		books, err := ebook.FindBookFiles([]string{path})
+		if err != nil {
+			return toastMsg{text: trf("Import failed: %v", err), kind: toastError}
+		}
+
+		options := ebook.ExportOptions{ConvertText: convert, Book: BookOptions(cfg)}
+		imported, skipped, failed := 0, 0, 0
+		for _, book := range books {
+			_, err := ebook.ImportBook(book, cfg.Library.Path, options)
+			switch {
+			case errors.Is(err, ebook.ErrDuplicate):
+				skipped++
+			case err != nil:
+				failed++
+			default:
+				imported++
+			}
+		}
+
+		text := trf("Imported %d book(s)", imported)
+		if skipped > 0 {
+			text += trf(", %d already in the library", skipped)
+		}
+		if failed > 0 {
+			return toastMsg{text: text + trf(", %d failed", failed), kind: toastError}
+		}
+		return toastMsg{text: text, kind: toastSuccess}
+	}
+	return trf("Importing %s...", path), tea.Sequence(importBooks, m.library.loadBooks()), nil
+}
+
+// runOpen opens a book file, in the library or not. Given a folder, or
+// nothing, it shows the file browser instead.
+func runOpen(m *Model, args []string) (string, tea.Cmd, error) {
+	if len(args) == 0 {
+		return "", func() tea.Msg { return FilesMsg{} }, nil
+	}
+	path := expandHome(strings.Join(args, " "))
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return "", func() tea.Msg { return FilesMsg{Dir: path} }, nil
+	}
+	return m.openFile(path)
+}
+
+// openFile opens a book, whether in the library or not, saving the
+// progress of the one being read first
+func (m *Model) openFile(path string) (string, tea.Cmd, error) {
+	if !m.config.BookAllowed(path) {
+		return "", nil, errorf("not allowed in restricted mode")
+	}
+	item, ok := m.library.findItem(path)
+	if !ok {
+		if _, err := os.Stat(path); err != nil {
+			return "", nil, err
+		}
+		item = bookItem{path: path, title: filepath.Base(path)}
+	}
+	if m.currentView() == ViewReader {
+		if err := m.reader.SaveProgress(); err != nil {
+			return "", nil, err
+		}
+	}
+	return trf("Opening %s", item.title), m.library.openBook(item), nil
+}
+
+// expandHome replaces a leading ~ with the home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// completePath completes a file or folder name
+func completePath(prefix string) []string {
+	matches, _ := filepath.Glob(expandHome(prefix) + "*")
+	for i, match := range matches {
+		if info, err := os.Stat(match); err == nil && info.IsDir() {
+			matches[i] += string(filepath.Separator)
+		}
+	}
+	return matches
+}
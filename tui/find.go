@@ -0,0 +1,469 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cbrasser/cozy/ebook"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// findKeyMap defines key bindings while typing a search
+type findKeyMap struct {
+	Next   key.Binding
+	Prev   key.Binding
+	Case   key.Binding
+	Word   key.Binding
+	Regex  key.Binding
+	Accept key.Binding
+	Cancel key.Binding
+}
+
+func (k findKeyMap) ShortHelp() []key.Binding {
+	return localized(k.Next, k.Prev, k.Case, k.Word, k.Regex, k.Accept, k.Cancel)
+}
+
+func (k findKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+var findKeys = findKeyMap{
+	Next: key.NewBinding(
+		key.WithKeys("ctrl+n", "down", "tab"),
+		key.WithHelp("tab/↓", "next match"),
+	),
+	Prev: key.NewBinding(
+		key.WithKeys("ctrl+p", "up", "shift+tab"),
+		key.WithHelp("shift+tab/↑", "previous match"),
+	),
+	Case: key.NewBinding(
+		key.WithKeys("alt+c"),
+		key.WithHelp("alt+c", "match case"),
+	),
+	Word: key.NewBinding(
+		key.WithKeys("alt+w"),
+		key.WithHelp("alt+w", "whole words"),
+	),
+	Regex: key.NewBinding(
+		key.WithKeys("alt+r"),
+		key.WithHelp("alt+r", "regular expression"),
+	),
+	Accept: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "keep highlights"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "cancel"),
+	),
+}
+
+// findState tracks the incremental search in the current chapter. With
+// the book's search index loaded, moving past the first or last match goes
+// on to the next chapter with matches.
+type findState struct {
+	active        bool // Typing a search
+	input         textinput.Model
+	query         string
+	origin        int // Scroll offset when the search started
+	originChapter int
+	matches       []findMatch
+	current       int
+	chapters      map[int]int // Matches per chapter, from the search index
+	err           error       // Invalid regular expression
+
+	// Modes, kept from one search to the next
+	matchCase  bool
+	wholeWords bool
+	regex      bool
+}
+
+// searchIndexMsg delivers the search index of a book, see
+// ebook/search.go
+type searchIndexMsg struct {
+	path  string
+	index *ebook.SearchIndex
+}
+
+// loadSearchIndex reads or builds the search index of the open book in the
+// background. Without it, searches stay within the chapter.
+func (m *ReaderModel) loadSearchIndex() tea.Cmd {
+	if m.book == nil {
+		return nil
+	}
+	path, options, dir := m.book.Path, BookOptions(m.config), m.config.SearchIndexDir()
+	return func() tea.Msg {
+		index, err := ebook.LoadSearchIndex(path, options, dir)
+		if err != nil {
+			return nil
+		}
+		return searchIndexMsg{path: path, index: index}
+	}
+}
+
+// findMatch is one occurrence of the search, which may go on over the
+// following rendered lines when the text wrapped inside it
+type findMatch struct {
+	line  int // First line
+	parts []matchPart
+}
+
+// matchPart is the part of a match on one rendered line
+type matchPart struct {
+	line int
+	columnRange
+}
+
+// startFind opens the search input
+func (m *ReaderModel) startFind() tea.Cmd {
+	input := textinput.New()
+	input.Prompt = m.findPrompt()
+	input.CharLimit = 100
+	input.SetValue(m.find.query)
+	m.find.input = input
+	m.find.active = true
+	m.find.origin = m.lineInChapter(m.viewport.YOffset)
+	m.find.originChapter = m.currentChapter
+	return m.find.input.Focus()
+}
+
+// updateFind handles keys while typing a search. Matches are highlighted
+// and the view follows the nearest one as the query changes.
+func (m *ReaderModel) updateFind(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, findKeys.Cancel):
+		// Cancel: clear the search and go back to where it started
+		m.find.active = false
+		m.find.query = ""
+		m.find.matches = nil
+		m.find.chapters = nil
+		if m.currentChapter != m.find.originChapter {
+			m.GotoChapter(m.find.originChapter)
+		}
+		m.viewport.SetYOffset(m.chapterStart() + m.find.origin)
+		return nil
+	case key.Matches(msg, findKeys.Accept):
+		// Keep the matches highlighted while reading
+		m.find.active = false
+		return nil
+	case key.Matches(msg, findKeys.Next):
+		m.cycleMatch(1)
+		return nil
+	case key.Matches(msg, findKeys.Prev):
+		m.cycleMatch(-1)
+		return nil
+	case key.Matches(msg, findKeys.Case):
+		m.find.matchCase = !m.find.matchCase
+		m.refind()
+		return nil
+	case key.Matches(msg, findKeys.Word):
+		m.find.wholeWords = !m.find.wholeWords
+		m.refind()
+		return nil
+	case key.Matches(msg, findKeys.Regex):
+		m.find.regex = !m.find.regex
+		m.refind()
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.find.input, cmd = m.find.input.Update(msg)
+	if m.find.input.Value() != m.find.query {
+		m.refind()
+	}
+	return cmd
+}
+
+// refind searches again after the query or a mode changed
+func (m *ReaderModel) refind() {
+	m.find.query = m.find.input.Value()
+	m.find.input.Prompt = m.findPrompt()
+	m.find.chapters = nil
+	// The index holds lowercased words, so it can only tell where a
+	// case-sensitive search might match, and knows nothing of patterns
+	if m.index != nil && !m.find.regex {
+		m.find.chapters = m.index.Chapters(m.find.query, m.find.wholeWords)
+	}
+	m.locateMatches()
+	m.scrollToNearestMatch()
+}
+
+// findPrompt shows the search modes that are on before the input
+func (m *ReaderModel) findPrompt() string {
+	var modes []string
+	if m.find.matchCase {
+		modes = append(modes, tr("case"))
+	}
+	if m.find.wholeWords {
+		modes = append(modes, tr("words"))
+	}
+	if m.find.regex {
+		modes = append(modes, tr("regex"))
+	}
+	if len(modes) == 0 {
+		return "/"
+	}
+	return "[" + strings.Join(modes, " ") + "] /"
+}
+
+// findPattern compiles the search. Spaces in a plain query match any run
+// of whitespace, so justified and spaced out lines still match.
+func (m *ReaderModel) findPattern() (*regexp.Regexp, error) {
+	flags := "(?i)"
+	if m.find.matchCase {
+		flags = ""
+	}
+	if m.find.regex {
+		// ^ and $ match at the ends of rendered lines, see locateMatches
+		return regexp.Compile(flags + "(?m)" + m.find.query)
+	}
+
+	words := strings.Fields(m.find.query)
+	for i, word := range words {
+		words[i] = regexp.QuoteMeta(word)
+		// Letters may be spaced apart, see ebook/spacing.go
+		if renderOptions(m.config).LetterSpacing {
+			var letters []string
+			for _, r := range word {
+				letters = append(letters, regexp.QuoteMeta(string(r)))
+			}
+			words[i] = strings.Join(letters, `\x{00a0}?`)
+		}
+	}
+	return regexp.Compile(flags + strings.Join(words, `[\s\x{00a0}]+`))
+}
+
+// locateMatches finds the search in the text of the chapter. Its rendered
+// lines are searched as one text, so phrases that wrap are found too, but
+// not phrases that run over into the next paragraph.
+func (m *ReaderModel) locateMatches() {
+	m.find.matches = nil
+	m.find.current = 0
+	m.find.err = nil
+
+	if strings.TrimSpace(m.find.query) == "" {
+		return
+	}
+	pattern, err := m.findPattern()
+	if err != nil {
+		m.find.err = err
+		return
+	}
+
+	first, last := m.chapterLines()
+	lines := make([]string, 0, last-first)
+	for line := first; line < last; line++ {
+		lines = append(lines, ansi.Strip(m.lines[line]))
+	}
+	text := strings.Join(lines, "\n")
+
+	for _, loc := range pattern.FindAllStringIndex(text, -1) {
+		if loc[0] == loc[1] || m.find.wholeWords && !wholeWord(text, loc[0], loc[1]) {
+			continue
+		}
+		if blankLine.MatchString(text[loc[0]:loc[1]]) {
+			continue
+		}
+		if match := matchLines(lines, first, loc[0], loc[1]); len(match.parts) > 0 {
+			m.find.matches = append(m.find.matches, match)
+		}
+	}
+}
+
+// blankLine finds an empty line between paragraphs
+var blankLine = regexp.MustCompile(`\n[ \t\x{00a0}]*\n`)
+
+// matchLines maps a byte range of the chapter's lines, joined by newlines,
+// to the columns it covers on each line. The spaces a match takes in at
+// the end or start of a line are left out.
+func matchLines(lines []string, first, start, end int) findMatch {
+	match := findMatch{line: -1}
+	offset := 0
+	for i, line := range lines {
+		lineEnd := offset + len(line)
+		if lineEnd >= start && offset < end {
+			from, to := max(start, offset)-offset, min(end, lineEnd)-offset
+			text := line[from:to]
+			from += len(text) - len(strings.TrimLeft(text, " "))
+			to -= len(text) - len(strings.TrimRight(text, " "))
+			if from < to {
+				column := ansi.StringWidth(line[:from])
+				if match.line < 0 {
+					match.line = first + i
+				}
+				match.parts = append(match.parts, matchPart{
+					line:        first + i,
+					columnRange: columnRange{start: column, end: column + ansi.StringWidth(line[from:to])},
+				})
+			}
+		}
+		if lineEnd >= end {
+			break
+		}
+		offset = lineEnd + 1 // The newline
+	}
+	return match
+}
+
+// wholeWord reports whether text[start:end] is not part of a longer word
+func wholeWord(text string, start, end int) bool {
+	before, _ := utf8.DecodeLastRuneInString(text[:start])
+	after, _ := utf8.DecodeRuneInString(text[end:])
+	return !isWordRune(before) && !isWordRune(after)
+}
+
+// scrollToNearestMatch selects the first match at or after the position
+// where the search started and brings it into view
+func (m *ReaderModel) scrollToNearestMatch() {
+	if m.currentChapter != m.find.originChapter {
+		m.GotoChapter(m.find.originChapter)
+	}
+	origin := m.chapterStart() + m.find.origin
+	if len(m.find.matches) == 0 {
+		m.viewport.SetYOffset(origin)
+		return
+	}
+	m.find.current = 0
+	for i, match := range m.find.matches {
+		if match.line >= origin {
+			m.find.current = i
+			break
+		}
+	}
+	m.showMatch()
+}
+
+// cycleMatch selects the next or previous match. Past the first or last
+// one it goes on to the next chapter with matches, or wraps around.
+func (m *ReaderModel) cycleMatch(step int) {
+	next := m.find.current + step
+	if len(m.find.matches) > 0 && next >= 0 && next < len(m.find.matches) {
+		m.find.current = next
+		m.showMatch()
+		return
+	}
+	if !m.nextMatchingChapter(step) {
+		return
+	}
+	m.find.current = 0
+	if step < 0 {
+		m.find.current = len(m.find.matches) - 1
+	}
+	m.showMatch()
+}
+
+// nextMatchingChapter goes to the next chapter in a direction that has
+// matches, coming round to the current one last. The index narrows down
+// the chapters to look in, except for regular expressions, which are
+// looked for in every chapter. It reports whether there are matches.
+func (m *ReaderModel) nextMatchingChapter(step int) bool {
+	if m.find.err != nil || m.find.chapters == nil && !m.find.regex {
+		return len(m.find.matches) > 0
+	}
+	from, count := m.currentChapter, m.book.ChapterCount()
+	for i := 1; i <= count; i++ {
+		chapter := (from + i*step + count*i) % count
+		if m.find.regex || m.find.chapters[chapter] > 0 {
+			if chapter != m.currentChapter {
+				m.GotoChapter(chapter)
+			}
+			if len(m.find.matches) > 0 {
+				return true
+			}
+		}
+	}
+	if m.currentChapter != from {
+		m.GotoChapter(from)
+	}
+	return false
+}
+
+// showMatch scrolls so the current match is visible
+func (m *ReaderModel) showMatch() {
+	line := m.find.matches[m.find.current].line
+	if line < m.viewport.YOffset || line >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(max(0, line-m.viewport.Height/3))
+	}
+}
+
+// applyFind marks the search matches in the viewport view
+func (m *ReaderModel) applyFind(view string) string {
+	if len(m.find.matches) == 0 {
+		return view
+	}
+
+	theme := m.config.ActiveTheme
+	style := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.BackgroundColor)).
+		Background(lipgloss.Color(theme.SecondaryColor))
+	currentStyle := style.Background(lipgloss.Color(theme.PrimaryColor))
+
+	lines := strings.Split(view, "\n")
+	for i, match := range m.find.matches {
+		matchStyle := style
+		if i == m.find.current {
+			matchStyle = currentStyle
+		}
+		for _, part := range match.parts {
+			row := part.line - m.viewport.YOffset
+			if row < 0 || row >= len(lines) {
+				continue
+			}
+			lines[row] = styleColumns(lines[row], part.start, part.end, matchStyle)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// matchingChapters returns the number of chapters with matches, by the
+// search index. The index matches whole words and their starts rather than
+// any part of a word, so it is not asked for a count of matches.
+func (m *ReaderModel) matchingChapters() int {
+	chapters := 0
+	for _, count := range m.find.chapters {
+		if count > 0 {
+			chapters++
+		}
+	}
+	return chapters
+}
+
+// patternError describes what is wrong with a regular expression, without
+// repeating it
+func patternError(err error) string {
+	var syntax *syntax.Error
+	if errors.As(err, &syntax) {
+		return string(syntax.Code)
+	}
+	return err.Error()
+}
+
+// findFooter shows the search input with the match count
+func (m *ReaderModel) findFooter() string {
+	count := tr("no matches")
+	if len(m.find.matches) > 0 {
+		count = fmt.Sprintf("%d/%d", m.find.current+1, len(m.find.matches))
+	}
+	// Case-sensitive counts from the index would include other cases
+	if chapters := m.matchingChapters(); chapters > 0 && !m.find.matchCase {
+		if len(m.find.matches) == 0 {
+			count = tr("none in this chapter")
+		}
+		count += " • " + trf("in %d of %d chapters", chapters, m.book.ChapterCount())
+	}
+	if m.find.err != nil {
+		count = trf("invalid pattern: %s", patternError(m.find.err))
+	}
+	if m.find.query == "" {
+		count = ""
+	}
+	return m.find.input.View() + "  " + count
+}
@@ -0,0 +1,117 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// focusState tracks focus mode: reading intervals of focus_minutes with
+// breaks of break_minutes in between, like a pomodoro timer
+type focusState struct {
+	active  bool
+	onBreak bool
+	ends    time.Time // End of the current interval
+	rounds  int       // Reading intervals completed
+	timer   int       // Identifies the current interval so stale ticks are ignored
+}
+
+// focusMsg fires when a focus interval or break runs out
+type focusMsg struct {
+	timer int
+}
+
+// startFocus starts focus mode with a reading interval
+func (m *ReaderModel) startFocus() tea.Cmd {
+	m.focus = focusState{active: true, timer: m.focus.timer}
+	return m.focusInterval(false)
+}
+
+// stopFocus ends focus mode, and a break if one is running
+func (m *ReaderModel) stopFocus() {
+	if m.focus.onBreak {
+		m.resumeSession()
+	}
+	m.focus.active = false
+	m.focus.onBreak = false
+	m.focus.timer++
+}
+
+// focusInterval starts a reading interval or a break
+func (m *ReaderModel) focusInterval(onBreak bool) tea.Cmd {
+	minutes := m.config.Reading.FocusMinutes
+	if onBreak {
+		minutes = m.config.Reading.BreakMinutes
+	}
+	m.focus.onBreak = onBreak
+	m.focus.ends = time.Now().Add(time.Duration(minutes) * time.Minute)
+	m.focus.timer++
+	timer := m.focus.timer
+	return tea.Tick(time.Duration(minutes)*time.Minute, func(time.Time) tea.Msg {
+		return focusMsg{timer: timer}
+	})
+}
+
+// updateFocusTimer switches between reading and break when an interval
+// runs out. Time on a break doesn't count towards the session.
+func (m *ReaderModel) updateFocusTimer(msg focusMsg) tea.Cmd {
+	if msg.timer != m.focus.timer || !m.focus.active {
+		return nil
+	}
+	if m.focus.onBreak {
+		m.resumeSession()
+		return tea.Batch(m.focusInterval(false), notify(toastInfo, "Break over, back to reading"))
+	}
+	m.focus.rounds++
+	m.pauseSession()
+	return m.focusInterval(true)
+}
+
+// updateFocusBreak handles keys while the break overlay is shown. Quitting
+// is left to the main model.
+func (m *ReaderModel) updateFocusBreak(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter", "esc":
+		// Skip the rest of the break
+		m.resumeSession()
+		return m.focusInterval(false)
+	}
+	return nil
+}
+
+// focusStatus is the timer shown in the footer in focus mode
+func (m *ReaderModel) focusStatus() string {
+	if !m.focus.active {
+		return ""
+	}
+	minutes := int(time.Until(m.focus.ends).Minutes()) + 1
+	if m.focus.onBreak {
+		return trf("break %dm", minutes)
+	}
+	return trf("focus %dm", minutes)
+}
+
+// focusBreakView renders the break overlay
+func (m *ReaderModel) focusBreakView() string {
+	theme := m.config.ActiveTheme
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(theme.HeadingColor))
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.MutedTextColor))
+
+	minutes := int(time.Until(m.focus.ends).Minutes()) + 1
+	rounds := tr("1 focus round")
+	if m.focus.rounds != 1 {
+		rounds = trf("%d focus rounds", m.focus.rounds)
+	}
+
+	content := titleStyle.Render(tr("Break")) + "\n\n" +
+		trf("%s done. Rest your eyes for %d min;\nreading resumes when the break is over.", rounds, minutes) + "\n\n" +
+		mutedStyle.Render(plain(tr("enter skip break • q save and quit")))
+
+	return lipgloss.NewStyle().
+		Border(panelBorder()).
+		BorderForeground(lipgloss.Color(theme.PrimaryColor)).
+		Padding(1, 2).
+		Render(content)
+}
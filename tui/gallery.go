@@ -0,0 +1,259 @@
+package tui
+
+import (
+	"image"
+	"strings"
+
+	"github.com/cbrasser/cozy/ebook"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Gallery cell layout; covers are roughly 2:3, and a terminal cell is
+// about twice as tall as it is wide
+const (
+	coverCols         = 12
+	coverRows         = 9
+	galleryCellWidth  = coverCols + 4
+	galleryCellHeight = coverRows + 3 // Cover, title, author and spacing
+)
+
+// galleryKeyMap defines key bindings for moving between covers
+type galleryKeyMap struct {
+	Left  key.Binding
+	Right key.Binding
+	Up    key.Binding
+	Down  key.Binding
+	First key.Binding
+	Last  key.Binding
+}
+
+func (k galleryKeyMap) ShortHelp() []key.Binding {
+	return localized(k.Left, k.Right, k.Up, k.Down, k.First, k.Last)
+}
+
+func (k galleryKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+var galleryKeys = galleryKeyMap{
+	Left: key.NewBinding(
+		key.WithKeys("left", "h"),
+		key.WithHelp("←/h", "previous book"),
+	),
+	Right: key.NewBinding(
+		key.WithKeys("right", "l"),
+		key.WithHelp("→/l", "next book"),
+	),
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "row up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "row down"),
+	),
+	First: key.NewBinding(
+		key.WithKeys("home", "g"),
+		key.WithHelp("g/home", "first book"),
+	),
+	Last: key.NewBinding(
+		key.WithKeys("end", "G"),
+		key.WithHelp("G/end", "last book"),
+	),
+}
+
+// CoversLoadedMsg delivers cover thumbnails for the library gallery
+type CoversLoadedMsg struct {
+	Covers map[string]image.Image
+}
+
+// loadCovers loads cover thumbnails for all books in the library
+func (m *LibraryModel) loadCovers() tea.Cmd {
+	books := m.books
+	cacheDir := m.config.CoverCacheDir()
+	return func() tea.Msg {
+		covers := make(map[string]image.Image)
+		for _, book := range books {
+			if img, err := ebook.LoadCoverThumbnail(book.Path, cacheDir); err == nil {
+				covers[book.Path] = img
+			}
+		}
+		return CoversLoadedMsg{Covers: covers}
+	}
+}
+
+// toggleGallery switches between list and gallery mode
+func (m *LibraryModel) toggleGallery() tea.Cmd {
+	m.gallery = !m.gallery
+	m.galleryTop = 0
+	if m.gallery && m.covers == nil {
+		return m.loadCovers()
+	}
+	return nil
+}
+
+// galleryColumns returns how many covers fit side by side
+func (m *LibraryModel) galleryColumns() int {
+	return max(m.width/galleryCellWidth, 1)
+}
+
+// galleryVisibleRows returns how many rows of covers fit on screen
+func (m *LibraryModel) galleryVisibleRows() int {
+	return max((m.height-6)/galleryCellHeight, 1)
+}
+
+// updateGallery handles navigation keys in gallery mode, reporting
+// whether the key was consumed
+func (m *LibraryModel) updateGallery(msg tea.KeyMsg) bool {
+	if m.list.FilterState() == list.Filtering {
+		return false
+	}
+
+	count := len(m.list.VisibleItems())
+	if count == 0 {
+		return false
+	}
+
+	index := m.list.Index()
+	cols := m.galleryColumns()
+
+	switch {
+	case key.Matches(msg, galleryKeys.Left):
+		index--
+	case key.Matches(msg, galleryKeys.Right):
+		index++
+	case key.Matches(msg, galleryKeys.Up):
+		index -= cols
+	case key.Matches(msg, galleryKeys.Down):
+		index += cols
+	case key.Matches(msg, galleryKeys.First):
+		index = 0
+	case key.Matches(msg, galleryKeys.Last):
+		index = count - 1
+	default:
+		return false
+	}
+
+	m.selectIndex(index)
+	return true
+}
+
+// selectIndex selects a visible item, keeping its row on screen in the
+// gallery
+func (m *LibraryModel) selectIndex(index int) {
+	count := len(m.list.VisibleItems())
+	if count == 0 {
+		return
+	}
+	index = max(0, min(index, count-1))
+	m.list.Select(index)
+
+	if !m.gallery {
+		return
+	}
+	row := index / m.galleryColumns()
+	if row < m.galleryTop {
+		m.galleryTop = row
+	} else if row >= m.galleryTop+m.galleryVisibleRows() {
+		m.galleryTop = row - m.galleryVisibleRows() + 1
+	}
+}
+
+// coverView returns the rendered cover for a book, caching the result
+func (m *LibraryModel) coverView(item bookItem) string {
+	if view, ok := m.coverViews[item.path]; ok {
+		return view
+	}
+
+	view := ""
+	if img, ok := m.covers[item.path]; ok {
+		view = renderCover(img, coverCols, coverRows, m.coverProtocol)
+	}
+	if view == "" {
+		view = coverPlaceholder(item.title, coverCols, coverRows, m.config.ActiveTheme)
+	}
+
+	if m.coverViews == nil {
+		m.coverViews = make(map[string]string)
+	}
+	m.coverViews[item.path] = view
+	return view
+}
+
+// galleryView renders the library as a grid of covers
+func (m *LibraryModel) galleryView() string {
+	theme := m.config.ActiveTheme
+	items := m.list.VisibleItems()
+	cols := m.galleryColumns()
+	selected := m.list.Index()
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.TextColor)).
+		Width(galleryCellWidth - 2).
+		PaddingLeft(1)
+	selectedTitleStyle := titleStyle.
+		Foreground(lipgloss.Color(theme.PrimaryColor)).
+		Bold(true)
+	authorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.MutedTextColor)).
+		Width(galleryCellWidth - 2).
+		PaddingLeft(1)
+	cellStyle := lipgloss.NewStyle().
+		Width(galleryCellWidth).
+		Height(galleryCellHeight).
+		PaddingLeft(2)
+	markerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.PrimaryColor))
+
+	var rows []string
+	start := m.galleryTop * cols
+	end := min(start+m.galleryVisibleRows()*cols, len(items))
+	for rowStart := start; rowStart < end; rowStart += cols {
+		var cells []string
+		for i := rowStart; i < min(rowStart+cols, end); i++ {
+			item, ok := items[i].(bookItem)
+			if !ok {
+				continue
+			}
+
+			nameStyle := titleStyle
+			marker := " "
+			if i == selected {
+				nameStyle = selectedTitleStyle
+				marker = markerStyle.Render("▌")
+			}
+
+			cover := m.coverView(item)
+			coverLines := strings.Split(cover, "\n")
+			for j := range coverLines {
+				coverLines[j] = marker + coverLines[j]
+			}
+
+			cell := lipgloss.JoinVertical(
+				lipgloss.Left,
+				strings.Join(coverLines, "\n"),
+				nameStyle.Render(truncate(item.title, galleryCellWidth-3)),
+				authorStyle.Render(truncate(item.author, galleryCellWidth-3)),
+			)
+			cells = append(cells, cellStyle.Render(cell))
+		}
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, cells...))
+	}
+
+	header := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.MutedTextColor)).
+		PaddingLeft(2).
+		Render(plain(tr("Gallery • v list view • i details • / filter")))
+	if m.list.FilterState() != list.Unfiltered {
+		header = lipgloss.NewStyle().PaddingLeft(2).Render(m.list.FilterInput.View())
+	}
+
+	if len(items) == 0 {
+		return header + "\n\n" + authorStyle.Render(tr("No books found."))
+	}
+
+	return header + "\n\n" + strings.Join(rows, "\n")
+}
@@ -0,0 +1,341 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// HelpMsg opens the key reference, searching it for Query if given
+type HelpMsg struct {
+	Query string
+}
+
+// showHelp opens the key reference
+func showHelp() tea.Msg {
+	return HelpMsg{}
+}
+
+// helpKeyMap defines key bindings for the key reference
+type helpKeyMap struct {
+	Search     key.Binding
+	ScrollUp   key.Binding
+	ScrollDown key.Binding
+	Top        key.Binding
+	Bottom     key.Binding
+	Back       key.Binding
+}
+
+func (k helpKeyMap) ShortHelp() []key.Binding {
+	return localized(k.Search, k.ScrollUp, k.ScrollDown, k.Back)
+}
+
+func (k helpKeyMap) FullHelp() [][]key.Binding {
+	return localizedGroups([][]key.Binding{{k.Search, k.ScrollUp, k.ScrollDown, k.Top, k.Bottom, k.Back}})
+}
+
+var helpKeys = helpKeyMap{
+	Search: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "search"),
+	),
+	ScrollUp: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "scroll up"),
+	),
+	ScrollDown: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "scroll down"),
+	),
+	Top: key.NewBinding(
+		key.WithKeys("home", "g"),
+		key.WithHelp("g/home", "top"),
+	),
+	Bottom: key.NewBinding(
+		key.WithKeys("end", "G"),
+		key.WithHelp("G/end", "bottom"),
+	),
+	Back: key.NewBinding(
+		key.WithKeys("esc", "?"),
+		key.WithHelp("esc", "back"),
+	),
+}
+
+// helpSection is one area of the key reference, e.g. the reader
+type helpSection struct {
+	title string
+	rows  []helpRow
+}
+
+// helpRow is one binding or command and what it does
+type helpRow struct {
+	keys string
+	desc string
+}
+
+// bindingRows lists the enabled bindings of a keymap's full help
+func bindingRows(groups ...[]key.Binding) []helpRow {
+	var rows []helpRow
+	for _, group := range groups {
+		for _, binding := range group {
+			if !binding.Enabled() || binding.Help().Key == "" {
+				continue
+			}
+			rows = append(rows, helpRow{keys: binding.Help().Key, desc: binding.Help().Desc})
+		}
+	}
+	return rows
+}
+
+// helpSections gathers the key reference from the keymaps in use and the
+// commands, so it always matches what the keys do
+func (m *Model) helpSections() []helpSection {
+	listKeys := m.library.list.KeyMap
+	// The list enables its filter keys only while filtering
+	filterKeys := []key.Binding{listKeys.ClearFilter, listKeys.AcceptWhileFiltering, listKeys.CancelWhileFiltering}
+	for i := range filterKeys {
+		filterKeys[i].SetEnabled(true)
+	}
+
+	sections := []helpSection{
+		{tr("Everywhere"), bindingRows(appKeys.FullHelp()...)},
+		{tr("Library"), bindingRows(libraryKeys.FullHelp()...)},
+		{tr("Library list"), bindingRows([]key.Binding{
+			listKeys.CursorUp, listKeys.CursorDown, listKeys.PrevPage, listKeys.NextPage,
+			listKeys.GoToStart, listKeys.GoToEnd, listKeys.Filter,
+		}, filterKeys)},
+		{tr("Cover gallery"), bindingRows(galleryKeys.FullHelp()...)},
+		{tr("Book details"), bindingRows(detailsKeys.FullHelp()...)},
+		{tr("File browser"), bindingRows(append(filesKeys.FullHelp(), browserKeys.ShortHelp())...)},
+		{tr("Reader"), bindingRows(m.reader.keys.FullHelp()...)},
+		{tr("Search"), bindingRows(findKeys.FullHelp()...)},
+		{tr("Word lookup"), bindingRows(lookupKeys.FullHelp()...)},
+		{tr("Journal"), bindingRows(m.journal.keys().FullHelp()...)},
+		{tr("Key reference"), bindingRows(helpKeys.FullHelp()...)},
+	}
+
+	var rows []helpRow
+	for _, c := range commands {
+		if c.admin && m.config.Restricted.Enabled {
+			continue
+		}
+		rows = append(rows, helpRow{keys: ":" + c.usage, desc: commandViews(c)})
+	}
+	return append(sections, helpSection{tr("Commands"), rows})
+}
+
+// commandViews describes where a command works
+func commandViews(c command) string {
+	if c.views == nil {
+		return tr("anywhere")
+	}
+	names := make([]string, len(c.views))
+	for i, view := range c.views {
+		names[i] = tr(viewName(view))
+	}
+	return strings.Join(names, ", ")
+}
+
+// viewName names a view as the help and remote control do
+func viewName(view View) string {
+	switch view {
+	case ViewReader:
+		return "reader"
+	case ViewDetails:
+		return "details"
+	case ViewHelp:
+		return "help"
+	case ViewFiles:
+		return "files"
+	case ViewJournal:
+		return "journal"
+	}
+	return "library"
+}
+
+// HelpModel is the full-screen key reference, listing every binding by
+// area. Typing after '/' narrows it to the rows mentioning the search.
+type HelpModel struct {
+	config    *config.Config
+	sections  []helpSection
+	viewport  viewport.Model
+	search    textinput.Model
+	searching bool
+	help      help.Model
+	width     int
+	height    int
+}
+
+// NewHelpModel creates a new key reference
+func NewHelpModel(cfg *config.Config) *HelpModel {
+	search := textinput.New()
+	search.Prompt = "/"
+
+	search.CharLimit = 100
+	return &HelpModel{
+		config:   cfg,
+		viewport: viewport.New(0, 0),
+		search:   search,
+		help:     help.New(),
+	}
+}
+
+// Init initializes the key reference
+func (m *HelpModel) Init() tea.Cmd {
+	return nil
+}
+
+// Show fills the reference with sections and searches it for a query
+func (m *HelpModel) Show(sections []helpSection, query string) {
+	m.sections = sections
+	m.searching = false
+	m.search.Placeholder = tr("search keys and commands")
+	m.search.Blur()
+	m.search.SetValue(query)
+	m.updateViewport()
+	m.viewport.GotoTop()
+}
+
+// SetSize updates the size of the key reference
+func (m *HelpModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.help.Width = width
+	m.search.Width = width - 4
+	m.viewport.Width = width - 4
+	m.viewport.Height = height - 3 // Account for title, search and help
+	m.updateViewport()
+}
+
+// Update handles keys in the key reference
+func (m *HelpModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if ok && m.searching {
+		switch keyMsg.Type {
+		case tea.KeyEsc:
+			m.search.SetValue("")
+			fallthrough
+		case tea.KeyEnter:
+			m.searching = false
+			m.search.Blur()
+			m.updateViewport()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.search, cmd = m.search.Update(msg)
+		m.updateViewport()
+		m.viewport.GotoTop()
+		return m, cmd
+	}
+
+	if ok {
+		switch {
+		case key.Matches(keyMsg, helpKeys.Back):
+			// A search is cleared first
+			if keyMsg.String() == "esc" && m.search.Value() != "" {
+				m.search.SetValue("")
+				m.updateViewport()
+				return m, nil
+			}
+			return m, func() tea.Msg { return BackMsg{} }
+		case key.Matches(keyMsg, helpKeys.Search):
+			m.searching = true
+			return m, m.search.Focus()
+		case key.Matches(keyMsg, helpKeys.Top):
+			m.viewport.GotoTop()
+			return m, nil
+		case key.Matches(keyMsg, helpKeys.Bottom):
+			m.viewport.GotoBottom()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+// updateViewport renders the sections matching the search
+func (m *HelpModel) updateViewport() {
+	if m.config.ActiveTheme == nil {
+		return
+	}
+	theme := m.config.ActiveTheme
+	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(theme.PrimaryColor))
+	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.SecondaryColor))
+	descStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.TextColor))
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.MutedTextColor))
+
+	query := strings.ToLower(strings.TrimSpace(m.search.Value()))
+	var b strings.Builder
+	for _, section := range m.sections {
+		rows := section.rows
+		if query != "" && !strings.Contains(strings.ToLower(section.title), query) {
+			rows = matchingRows(rows, query)
+		}
+		if len(rows) == 0 {
+			continue
+		}
+
+		width := 0
+		for _, row := range rows {
+			width = max(width, lipgloss.Width(row.keys))
+		}
+		width = min(width, max(m.viewport.Width/2, 10))
+
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(sectionStyle.Render(section.title) + "\n")
+		for _, row := range rows {
+			keys := keyStyle.Width(width).Render(row.keys)
+			b.WriteString("  " + lipgloss.JoinHorizontal(lipgloss.Top, keys, "  ", descStyle.Render(row.desc)) + "\n")
+		}
+	}
+	if b.Len() == 0 {
+		b.WriteString(mutedStyle.Render(trf("Nothing matches %q", m.search.Value())))
+	}
+	m.viewport.SetContent(b.String())
+}
+
+// matchingRows returns the rows whose keys or description mention a
+// lowercase query
+func matchingRows(rows []helpRow, query string) []helpRow {
+	var matched []helpRow
+	for _, row := range rows {
+		if strings.Contains(strings.ToLower(row.keys), query) || strings.Contains(strings.ToLower(row.desc), query) {
+			matched = append(matched, row)
+		}
+	}
+	return matched
+}
+
+// View renders the key reference
+func (m *HelpModel) View() string {
+	if m.config.ActiveTheme == nil {
+		return ""
+	}
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color(m.config.ActiveTheme.PrimaryColor)).
+		Padding(0, 1)
+
+	search := ""
+	if m.searching || m.search.Value() != "" {
+		search = m.search.View()
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		titleStyle.Render(tr("Keys")),
+		lipgloss.NewStyle().Padding(0, 2).Render(search),
+		lipgloss.NewStyle().Padding(0, 2).Render(m.viewport.View()),
+		plain(m.help.View(helpKeys)),
+	)
+}
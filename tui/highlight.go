@@ -0,0 +1,166 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/cbrasser/cozy/ebook"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// columnRange is a span of display columns on one rendered line
+type columnRange struct {
+	start, end int
+}
+
+// styleColumns restyles the columns [start, end) of a rendered line
+func styleColumns(line string, start, end int, style lipgloss.Style) string {
+	text := ansi.Strip(ansi.Cut(line, start, end))
+	return ansi.Truncate(line, start, "") + ansi.ResetStyle +
+		style.Render(text) + ansi.TruncateLeft(line, end, "")
+}
+
+// addHighlight stores the selected text as a highlight
+func (m *ReaderModel) addHighlight() tea.Cmd {
+	text := m.selectedText()
+	if text == "" {
+		return nil
+	}
+
+	highlight := config.Highlight{
+		Chapter: m.currentChapter,
+		Text:    text,
+		Created: time.Now(),
+	}
+	m.highlights.AddHighlight(m.book.Path, highlight)
+	if err := config.SaveHighlights(m.config, m.highlights); err != nil {
+		m.status = trf("Could not save highlight: %v", err)
+		return nil
+	}
+
+	m.lookup.active = false
+	m.locateHighlights()
+	m.status = tr("Highlight added")
+	return runHook(m.config, hookEvent{Event: hookHighlightCreated, Book: m.hookBook(), Highlight: &highlight})
+}
+
+// locateHighlights finds where the current chapter's highlights appear in
+// the rendered text. Highlights are matched word by word, so they survive
+// re-wrapping at a different width.
+func (m *ReaderModel) locateHighlights() {
+	m.highlightRanges = make(map[int][]columnRange)
+	if m.book == nil || m.highlights == nil {
+		return
+	}
+
+	// In continuous mode the viewport shows the highlights of every
+	// chapter in it
+	for _, chapter := range m.windowChapters() {
+		m.locateChapterHighlights(chapter, m.window.start(chapter), min(m.window.end(chapter), len(m.lines)))
+	}
+}
+
+// locateChapterHighlights finds the highlights of a chapter in its lines of
+// the viewport
+func (m *ReaderModel) locateChapterHighlights(chapter, start, end int) {
+	highlights := m.highlights.ChapterHighlights(m.book.Path, chapter)
+	if len(highlights) == 0 {
+		return
+	}
+
+	type placedWord struct {
+		line int
+		span wordSpan
+	}
+	var words []placedWord
+	for line := start; line < end; line++ {
+		for _, span := range lineWords(m.lines[line]) {
+			words = append(words, placedWord{line: line, span: span})
+		}
+	}
+
+	for _, highlight := range highlights {
+		target := lineWords(highlight.Text)
+		if len(target) == 0 {
+			continue
+		}
+
+	search:
+		for i := 0; i+len(target) <= len(words); i++ {
+			for j, word := range target {
+				if words[i+j].span.text != word.text {
+					continue search
+				}
+			}
+
+			// Merge the matched words into one range per line
+			matched := words[i : i+len(target)]
+			first := 0
+			for j := range matched {
+				if j == len(matched)-1 || matched[j+1].line != matched[j].line {
+					line := matched[j].line
+					m.highlightRanges[line] = append(m.highlightRanges[line],
+						columnRange{start: matched[first].span.start, end: matched[j].span.end})
+					first = j + 1
+				}
+			}
+			break
+		}
+	}
+}
+
+// applyHighlights marks highlighted passages in the viewport view
+func (m *ReaderModel) applyHighlights(view string) string {
+	if len(m.highlightRanges) == 0 {
+		return view
+	}
+
+	theme := m.config.ActiveTheme
+	style := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.TextColor)).
+		Background(lipgloss.Color(theme.CodeBgColor)).
+		Underline(true)
+
+	lines := strings.Split(view, "\n")
+	for row := range lines {
+		for _, r := range m.highlightRanges[m.viewport.YOffset+row] {
+			lines[row] = styleColumns(lines[row], r.start, r.end, style)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// exportHighlights writes the highlights and journals of books to Markdown
+// files in the background, see ebook.ExportHighlights
+func exportHighlights(cfg *config.Config, paths []string) tea.Cmd {
+	return func() tea.Msg {
+		highlights, err := config.LoadHighlights(cfg)
+		if err != nil {
+			return toastMsg{text: trf("Could not read highlights: %v", err), kind: toastError}
+		}
+		journal, err := config.LoadJournal(cfg)
+		if err != nil {
+			return toastMsg{text: trf("Could not read notes: %v", err), kind: toastError}
+		}
+
+		dest := cfg.HighlightsExportDir()
+		exported := 0
+		for _, path := range paths {
+			if len(highlights.Books[path]) == 0 && journal.Text(path) == "" {
+				continue
+			}
+			if _, err := ebook.ExportHighlights(path, dest, highlights.Books[path], journal.Text(path), BookOptions(cfg), cfg.Reading.WordsPerPage); err != nil {
+				return toastMsg{text: trf("Could not export %s: %v", filepath.Base(path), err), kind: toastError}
+			}
+			exported++
+		}
+		if exported == 0 {
+			return toastMsg{text: tr("No highlights or notes to export"), kind: toastInfo}
+		}
+		return toastMsg{text: trf("Exported highlights of %d book(s) to %s", exported, dest), kind: toastSuccess}
+	}
+}
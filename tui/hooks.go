@@ -0,0 +1,137 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/cbrasser/cozy/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Hook events, named as in the [hooks] section of config.toml
+const (
+	hookBookOpened       = "book_opened"
+	hookBookFinished     = "book_finished"
+	hookHighlightCreated = "highlight_created"
+	hookSessionEnded     = "session_ended"
+)
+
+// Hooks that run longer than this are stopped
+const hookTimeout = 30 * time.Second
+
+// hookEvent is the JSON a hook command reads on standard input
+type hookEvent struct {
+	Event     string            `json:"event"`
+	Time      time.Time         `json:"time"`
+	Book      hookBook          `json:"book"`
+	Highlight *config.Highlight `json:"highlight,omitempty"`
+	Session   *hookSession      `json:"session,omitempty"`
+}
+
+type hookBook struct {
+	Path    string  `json:"path"`
+	Title   string  `json:"title"`
+	Author  string  `json:"author,omitempty"`
+	Percent float64 `json:"percent"`
+	Page    int     `json:"page"`
+	Pages   int     `json:"pages"`
+}
+
+type hookSession struct {
+	Minutes      float64 `json:"minutes"`
+	Words        int     `json:"words"`
+	Pages        int     `json:"pages"` // Pages of words_per_page words
+	StartPercent float64 `json:"start_percent"`
+	EndPercent   float64 `json:"end_percent"`
+}
+
+// hookCommand returns the command configured for an event
+func hookCommand(hooks config.HooksConfig, event string) string {
+	switch event {
+	case hookBookOpened:
+		return hooks.BookOpened
+	case hookBookFinished:
+		return hooks.BookFinished
+	case hookHighlightCreated:
+		return hooks.HighlightCreated
+	case hookSessionEnded:
+		return hooks.SessionEnded
+	}
+	return ""
+}
+
+// runHook starts the command configured for an event, if any, and writes
+// the event to its standard input. The command is started right away, so
+// it runs even when cozy is quitting; the returned command waits for it and
+// reports a failure as a toast.
+func runHook(cfg *config.Config, event hookEvent) tea.Cmd {
+	command := hookCommand(cfg.Hooks, event.Event)
+	if command == "" {
+		return nil
+	}
+	event.Time = time.Now()
+	data, err := json.Marshal(event)
+	if err != nil {
+		return notify(toastError, "Hook %s failed: %v", event.Event, err)
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Env = append(os.Environ(), "COZY_EVENT="+event.Event)
+	cmd.Stdout = io.Discard
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return notify(toastError, "Hook %s failed: %v", event.Event, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return notify(toastError, "Hook %s failed: %v", event.Event, err)
+	}
+	stdin.Write(data)
+	stdin.Close()
+
+	return func() tea.Msg {
+		done := make(chan error, 1)
+		go func() { done <- cmd.Wait() }()
+		var err error
+		select {
+		case err = <-done:
+		case <-time.After(hookTimeout):
+			cmd.Process.Kill()
+			<-done
+			err = errorf("stopped after %s", hookTimeout)
+		}
+		if err == nil {
+			return nil
+		}
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			err = fmt.Errorf("%w: %s", err, message)
+		}
+		return toastMsg{text: trf("Hook %s failed: %v", event.Event, err), kind: toastError}
+	}
+}
+
+// hookBook describes the book open in the reader for a hook
+func (m *ReaderModel) hookBook() hookBook {
+	page, pages := m.page()
+	return hookBook{
+		Path:    m.book.Path,
+		Title:   m.book.Title,
+		Author:  m.book.Author,
+		Percent: m.bookPercent(),
+		Page:    page,
+		Pages:   pages,
+	}
+}
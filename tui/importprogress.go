@@ -0,0 +1,159 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/cbrasser/cozy/ebook"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Reading progress can be brought over from KOReader's sidecar files or a
+// Calibre library's progress columns. Books are matched to the library by
+// path, or by file name for a copy kept elsewhere such as on an e-reader.
+// Progress cozy already has is only replaced when the import is further.
+
+// importedProgress is a position found for a library book
+type importedProgress struct {
+	path     string
+	position ebook.Position
+	chapters int
+	finished bool
+}
+
+// progressImportedMsg carries the positions read in the background
+type progressImportedMsg struct {
+	source    string
+	books     []importedProgress
+	unmatched int // Books not in the library
+	failed    int // Books that could not be opened
+	err       error
+}
+
+// runImportProgress reads the progress of another reader, from a folder
+// that defaults to the library
+func runImportProgress(m *Model, args []string) (string, tea.Cmd, error) {
+	if len(args) == 0 || (args[0] != "koreader" && args[0] != "calibre") {
+		return "", nil, errorf("usage: %s", "import-progress koreader|calibre [folder]")
+	}
+	source := args[0]
+	dir := m.config.Library.Path
+	if len(args) > 1 {
+		dir = expandHome(strings.Join(args[1:], " "))
+	}
+	if source == "calibre" && !ebook.HasCalibreLibrary(dir) {
+		return "", nil, errorf("%s is not a Calibre library", dir)
+	}
+
+	var library []string
+	for _, book := range m.library.books {
+		library = append(library, book.Path)
+	}
+	cfg := m.config
+	importProgress := func() tea.Msg {
+		var states []ebook.ReadingState
+		var err error
+		if source == "calibre" {
+			states, err = ebook.LoadCalibreStates(dir)
+		} else {
+			states, err = ebook.FindKOReaderStates(dir)
+		}
+		if err != nil {
+			return progressImportedMsg{source: source, err: err}
+		}
+		return readImportedProgress(cfg, source, states, library)
+	}
+	return trf("Importing %s progress from %s...", source, dir), importProgress, nil
+}
+
+// readImportedProgress matches reading states to library books and turns
+// their percentages into positions
+func readImportedProgress(cfg *config.Config, source string, states []ebook.ReadingState, library []string) progressImportedMsg {
+	byPath := make(map[string]bool)
+	byName := make(map[string][]string)
+	for _, path := range library {
+		byPath[path] = true
+		byName[filepath.Base(path)] = append(byName[filepath.Base(path)], path)
+	}
+
+	msg := progressImportedMsg{source: source}
+	for _, state := range states {
+		path := state.Path
+		if !byPath[path] {
+			// A file name found more than once can't be told apart
+			if matches := byName[filepath.Base(path)]; len(matches) == 1 {
+				path = matches[0]
+			} else {
+				msg.unmatched++
+				continue
+			}
+		}
+
+		book, err := ebook.OpenBook(path, BookOptions(cfg))
+		if err != nil {
+			msg.failed++
+			continue
+		}
+		msg.books = append(msg.books, importedProgress{
+			path:     path,
+			position: ebook.PositionAtPercent(book, state.Percent),
+			chapters: book.ChapterCount(),
+			finished: state.Finished,
+		})
+		book.Close()
+	}
+	return msg
+}
+
+// applyImportedProgress records the imported positions that are further
+// than cozy's own
+func (m *Model) applyImportedProgress(msg progressImportedMsg) tea.Cmd {
+	if msg.err != nil {
+		return m.addToast(trf("Could not import progress: %v", msg.err), toastError)
+	}
+
+	imported := 0
+	for _, book := range msg.books {
+		if m.reader.book != nil && m.reader.book.Path == book.path {
+			continue // The reader saves its own position over it
+		}
+		existing, _ := m.library.progress.GetBookProgress(book.path)
+		ahead := book.position.Chapter > existing.CurrentChapter ||
+			(book.position.Chapter == existing.CurrentChapter && book.position.TextOffset > existing.TextOffset)
+		if existing.Finished || (!ahead && !book.finished) {
+			continue
+		}
+		for _, progress := range []*config.ProgressData{m.library.progress, m.reader.progress} {
+			if ahead {
+				progress.SetBookProgress(book.path, book.position.Chapter, 0, book.position.TextOffset, book.chapters)
+				for chapter := range book.position.Chapter {
+					progress.MarkChapterRead(book.path, chapter)
+				}
+			}
+			if book.finished {
+				progress.SetBookFinished(book.path, true)
+			}
+		}
+		imported++
+	}
+
+	if imported > 0 {
+		if err := config.SaveProgress(m.config, m.library.progress); err != nil {
+			return m.addToast(trf("Could not save progress: %v", err), toastError)
+		}
+		m.library.updateItems()
+	}
+
+	text := trf("Imported the %s progress of %d book(s)", msg.source, imported)
+	if skipped := len(msg.books) - imported; skipped > 0 {
+		text += trf(", %d already further in cozy", skipped)
+	}
+	if msg.unmatched > 0 {
+		text += trf(", %d not in the library", msg.unmatched)
+	}
+	if msg.failed > 0 {
+		return m.addToast(text+trf(", %d could not be opened", msg.failed), toastError)
+	}
+	return m.addToast(text, toastSuccess)
+}
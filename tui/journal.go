@@ -0,0 +1,234 @@
+package tui
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// The journal is a page of free-form notes per book, for chapter
+// summaries and thoughts that don't belong to one passage. It is written
+// in Markdown, here or in $EDITOR, and exported with the highlights.
+// Restricted mode keeps it in cozy, as an editor can open any file.
+
+// journalKeyMap defines key bindings for the journal
+type journalKeyMap struct {
+	Save   key.Binding
+	Editor key.Binding
+	Back   key.Binding
+}
+
+func (k journalKeyMap) ShortHelp() []key.Binding {
+	return localized(k.Save, k.Editor, k.Back)
+}
+
+func (k journalKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+var journalKeys = journalKeyMap{
+	Save: key.NewBinding(
+		key.WithKeys("ctrl+s"),
+		key.WithHelp("ctrl+s", "save"),
+	),
+	Editor: key.NewBinding(
+		key.WithKeys("ctrl+e"),
+		key.WithHelp("ctrl+e", "open in $EDITOR"),
+	),
+	Back: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "save and close"),
+	),
+}
+
+// JournalMsg opens the journal of a book
+type JournalMsg struct {
+	Path  string
+	Title string
+}
+
+// journalEditedMsg reports that $EDITOR closed the journal file
+type journalEditedMsg struct {
+	path string // The book
+	file string // The temporary file edited
+	err  error
+}
+
+// JournalModel edits the journal of one book
+type JournalModel struct {
+	config  *config.Config
+	journal *config.JournalData
+	path    string
+	title   string
+	editor  textarea.Model
+	help    help.Model
+	width   int
+	height  int
+}
+
+// NewJournalModel creates a new journal view
+func NewJournalModel(cfg *config.Config) *JournalModel {
+	journal, err := config.LoadJournal(cfg)
+	if err != nil {
+		journal = &config.JournalData{Books: make(map[string]config.JournalEntry)}
+	}
+
+	editor := textarea.New()
+	editor.Prompt = ""
+	editor.Placeholder = tr("Chapter summaries, thoughts, questions... (Markdown)")
+	editor.ShowLineNumbers = false
+	editor.CharLimit = 0
+	editor.MaxHeight = 0
+
+	return &JournalModel{
+		config:  cfg,
+		journal: journal,
+		editor:  editor,
+		help:    help.New(),
+	}
+}
+
+// Init initializes the journal view
+func (m *JournalModel) Init() tea.Cmd {
+	return nil
+}
+
+// Show opens the journal of a book for editing
+func (m *JournalModel) Show(path, title string) tea.Cmd {
+	m.path, m.title = path, title
+	m.editor.SetValue(m.journal.Text(path))
+	return m.editor.Focus()
+}
+
+// SetSize updates the size of the journal view
+func (m *JournalModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.help.Width = width
+	m.editor.SetWidth(max(width-4, 10))
+	m.editor.SetHeight(max(height-4, 1)) // Account for title and help
+}
+
+// keys returns the journal's bindings, without the external editor in
+// restricted mode
+func (m *JournalModel) keys() journalKeyMap {
+	keys := journalKeys
+	keys.Editor.SetEnabled(!m.config.Restricted.Enabled)
+	return keys
+}
+
+// save stores the journal if it changed
+func (m *JournalModel) save() error {
+	if m.path == "" || !m.journal.SetText(m.path, m.editor.Value()) {
+		return nil
+	}
+	return config.SaveJournal(m.config, m.journal)
+}
+
+// openEditor saves the journal and hands it to $VISUAL or $EDITOR
+func (m *JournalModel) openEditor() tea.Cmd {
+	if err := m.save(); err != nil {
+		return notify(toastError, "Could not save notes: %v", err)
+	}
+	file, err := os.CreateTemp("", "cozy-notes-*.md")
+	if err != nil {
+		return notify(toastError, "Could not open the editor: %v", err)
+	}
+	_, err = file.WriteString(m.editor.Value())
+	file.Close()
+	if err != nil {
+		os.Remove(file.Name())
+		return notify(toastError, "Could not open the editor: %v", err)
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	args := append(strings.Fields(editor), file.Name())
+	path, name := m.path, file.Name()
+	return tea.ExecProcess(exec.Command(args[0], args[1:]...), func(err error) tea.Msg {
+		return journalEditedMsg{path: path, file: name, err: err}
+	})
+}
+
+// finishEditor takes the journal back from the editor
+func (m *JournalModel) finishEditor(msg journalEditedMsg) tea.Cmd {
+	defer os.Remove(msg.file)
+	if msg.err != nil {
+		return notify(toastError, "Editor failed: %v", msg.err)
+	}
+	data, err := os.ReadFile(msg.file)
+	if err != nil {
+		return notify(toastError, "Could not read notes back: %v", err)
+	}
+	if msg.path != m.path {
+		return nil // Another book's journal was opened meanwhile
+	}
+	m.editor.SetValue(string(data))
+	if err := m.save(); err != nil {
+		return notify(toastError, "Could not save notes: %v", err)
+	}
+	return nil
+}
+
+// Update handles messages for the journal view
+func (m *JournalModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case journalEditedMsg:
+		return m, m.finishEditor(msg)
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, journalKeys.Back):
+			m.editor.Blur()
+			back := func() tea.Msg { return BackMsg{} }
+			if err := m.save(); err != nil {
+				return m, tea.Batch(back, notify(toastError, "Could not save notes: %v", err))
+			}
+			return m, back
+
+		case key.Matches(msg, journalKeys.Save):
+			if err := m.save(); err != nil {
+				return m, notify(toastError, "Could not save notes: %v", err)
+			}
+			return m, notify(toastSuccess, "Notes saved")
+
+		case key.Matches(msg, m.keys().Editor):
+			return m, m.openEditor()
+		}
+	}
+
+	var cmd tea.Cmd
+	m.editor, cmd = m.editor.Update(msg)
+	return m, cmd
+}
+
+// View renders the journal view
+func (m *JournalModel) View() string {
+	if m.config.ActiveTheme == nil {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color(m.config.ActiveTheme.PrimaryColor)).
+		Padding(0, 1)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		titleStyle.Render(trf("Notes on %s", m.title)),
+		lipgloss.NewStyle().Padding(0, 2).Render(m.editor.View()),
+		plain(m.help.View(m.keys())),
+	)
+}
@@ -0,0 +1,54 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/cbrasser/cozy/ebook"
+)
+
+// Large text is drawn by the renderer, see ebook/largetext.go. Here the
+// margin is adjusted for lines drawn at double width, and the other lines
+// of the screen are kept at normal size.
+
+// lineSizesUsed is set once a line has been drawn at another size
+var lineSizesUsed bool
+
+// textIndent returns the spaces before each line of text. Lines drawn at
+// double width double the spaces too, so they get half the margin.
+func (m *ReaderModel) textIndent() int {
+	left, _ := m.margins()
+	if renderOptions(m.config).DoubleWidth() {
+		return left / 2
+	}
+	return left
+}
+
+// lineSizes removes the sizes lines are drawn at
+var lineSizes = strings.NewReplacer(ebook.DoubleTop, "", ebook.DoubleBottom, "", ebook.SingleWidth, "", ebook.DoubleWidth, "")
+
+// lineSize returns the size a line is drawn at, if it sets one
+func lineSize(line string) string {
+	for _, size := range []string{ebook.DoubleTop, ebook.DoubleBottom, ebook.SingleWidth, ebook.DoubleWidth} {
+		if strings.Contains(line, size) {
+			return size
+		}
+	}
+	return ""
+}
+
+// resetLineSizes starts the lines of a view that don't set a size at
+// normal size. A terminal row keeps the size it was last drawn at, and
+// only changed lines are redrawn.
+func resetLineSizes(view string) string {
+	if !lineSizesUsed && !strings.Contains(view, "\x1b#") {
+		return view
+	}
+	lineSizesUsed = true
+	lines := strings.Split(view, "\n")
+	for i, line := range lines {
+		if lineSize(line) == "" {
+			lines[i] = ebook.SingleWidth + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
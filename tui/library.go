@@ -1,7 +1,12 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
+	"image"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/cbrasser/cozy/config"
@@ -10,6 +15,7 @@ import (
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/fsnotify/fsnotify"
 )
 
 // LibraryModel represents the library view
@@ -17,24 +23,71 @@ type LibraryModel struct {
 	config   *config.Config
 	list     list.Model
 	books    []ebook.BookInfo
+	loaded   bool // The first scan of the library finished
 	progress *config.ProgressData
+	queue    *config.QueueData // Books to read next, see queue.go
+	reviews  *config.ReviewData
 	width    int
 	height   int
+
+	// Rows per list item, used to map mouse clicks to items
+	itemHeight  int
+	itemSpacing int
+
+	// Gallery mode state
+	gallery       bool
+	galleryTop    int // First visible row of covers
+	coverProtocol coverProtocol
+	covers        map[string]image.Image
+	coverViews    map[string]string // Rendered covers keyed by book path
+
+	// Finished books are listed under a header that folds them away, as
+	// are saved web articles and books that can't be opened
+	finishedCollapsed bool
+	articlesCollapsed bool
+	brokenCollapsed   bool
+
+	duplicates []ebook.DuplicateGroup // Books found more than once, see duplicates.go
+
+	// Live refresh, see watch.go
+	watcher     *fsnotify.Watcher
+	rescanTimer int // Identifies the pending rescan so superseded ones are ignored
 }
 
 type bookItem struct {
-	title      string
-	author     string
-	path       string
-	tags       []string
-	completion float64
-	finished   bool
+	title        string
+	author       string
+	path         string
+	tags         []string
+	metadata     map[string]string
+	contributors []ebook.Contributor
+	calibre      bool // Metadata comes from the Calibre database
+	completion   float64
+	finished     bool
+	series       string // Place in its series, e.g. "Book 3 of 7 in Dune"
+	duplicate    bool   // Another file in the library holds the same book
+	queued       int    // Place in the up next queue, 0 if not queued
+	review       config.Review
+	err          error // Why the book can't be opened
 }
 
-func (i bookItem) Title() string { return i.title }
+func (i bookItem) Title() string {
+	if i.err != nil {
+		return "⚠ " + i.title
+	}
+	return i.title
+}
 func (i bookItem) Description() string {
+	if i.err != nil {
+		return i.err.Error()
+	}
+
 	parts := []string{}
 
+	if i.queued > 0 {
+		parts = append(parts, "⏭ "+trf("Up next #%d", i.queued))
+	}
+
 	if len(i.tags) > 0 {
 		parts = append(parts, "📁 "+strings.Join(i.tags, " / "))
 	}
@@ -43,15 +96,71 @@ func (i bookItem) Description() string {
 		parts = append(parts, i.author)
 	}
 
+	if i.series != "" {
+		parts = append(parts, i.series)
+	}
+
+	// The reader's own rating comes before the one in the metadata
+	if i.review.Rating > 0 {
+		parts = append(parts, stars(i.review.Rating))
+	} else if rating := formatRating(i.metadata["rating"]); rating != "" {
+		parts = append(parts, rating)
+	}
+
 	// Add completion percentage or finished status
 	if i.finished {
-		parts = append(parts, "✓ Finished")
+		parts = append(parts, "✓ "+tr("Finished"))
 	} else if i.completion > 0 {
 		parts = append(parts, fmt.Sprintf("%.0f%%", i.completion))
 	}
 
-	return strings.Join(parts, " • ")
+	if i.duplicate {
+		parts = append(parts, "⧉ "+tr("Duplicate"))
+	}
+
+	if i.review.Text != "" {
+		parts = append(parts, "“"+i.review.Text+"”")
+	}
+
+	return plain(strings.Join(parts, " • "))
 }
+
+// sectionItem is the header above the articles, finished or unreadable
+// books
+type sectionItem struct {
+	name      string
+	count     int
+	collapsed bool
+}
+
+// Library sections
+const (
+	sectionArticles = "Articles"
+	sectionFinished = "Finished"
+	sectionBroken   = "Unreadable"
+)
+
+func (i sectionItem) Title() string {
+	if i.collapsed {
+		return fmt.Sprintf("▸ %s (%d)", tr(i.name), i.count)
+	}
+	return fmt.Sprintf("▾ %s (%d)", tr(i.name), i.count)
+}
+func (i sectionItem) Description() string {
+	books := tr("finished books")
+	switch i.name {
+	case sectionArticles:
+		books = tr("saved articles")
+	case sectionBroken:
+		books = tr("unreadable books")
+	}
+	if i.collapsed {
+		return trf("enter to show %s", books)
+	}
+	return trf("enter to hide %s", books)
+}
+func (i sectionItem) FilterValue() string { return "" }
+
 func (i bookItem) FilterValue() string {
 	// Allow filtering by title, author, and tags
 	filterValue := i.title
@@ -61,28 +170,103 @@ func (i bookItem) FilterValue() string {
 	if len(i.tags) > 0 {
 		filterValue += " " + strings.Join(i.tags, " ")
 	}
+	// Also match subjects, series and publisher from the book metadata
+	for _, field := range []string{"subjects", "series", "publisher"} {
+		if value := i.metadata[field]; value != "" {
+			filterValue += " " + value
+		}
+	}
 	return filterValue
 }
 
+// libraryKeyMap defines the library's own key bindings, besides those of
+// the list for moving around and filtering
+type libraryKeyMap struct {
+	Open           key.Binding
+	ToggleFinished key.Binding
+	Details        key.Binding
+	Gallery        key.Binding
+	Send           key.Binding
+	Refresh        key.Binding
+	Queue          key.Binding
+	QueueUp        key.Binding
+	QueueDown      key.Binding
+	OpenFile       key.Binding
+	Journal        key.Binding
+}
+
+func (k libraryKeyMap) ShortHelp() []key.Binding {
+	return localized(k.ToggleFinished, k.Details, k.Gallery, k.Send, k.Refresh, k.Queue, k.OpenFile, appKeys.Help)
+}
+
+func (k libraryKeyMap) FullHelp() [][]key.Binding {
+	return localizedGroups([][]key.Binding{
+		{k.Open, k.Details, k.ToggleFinished, k.Gallery, k.Send, k.Refresh},
+		{k.Queue, k.QueueUp, k.QueueDown, k.OpenFile, k.Journal},
+	})
+}
+
+var libraryKeys = libraryKeyMap{
+	Open: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "read, or fold a section"),
+	),
+	ToggleFinished: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "toggle finished"),
+	),
+	Details: key.NewBinding(
+		key.WithKeys("i"),
+		key.WithHelp("i", "details"),
+	),
+	Gallery: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "gallery"),
+	),
+	Send: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "send to device"),
+	),
+	Refresh: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "refresh"),
+	),
+	Queue: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "queue"),
+	),
+	QueueUp: key.NewBinding(
+		key.WithKeys("K"),
+		key.WithHelp("K", "move up the queue"),
+	),
+	QueueDown: key.NewBinding(
+		key.WithKeys("J"),
+		key.WithHelp("J", "move down the queue"),
+	),
+	OpenFile: key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "open a file"),
+	),
+	Journal: key.NewBinding(
+		key.WithKeys("N"),
+		key.WithHelp("N", "notes on the book"),
+	),
+}
+
 // NewLibraryModel creates a new library model
 func NewLibraryModel(cfg *config.Config) *LibraryModel {
 	items := []list.Item{}
 
 	delegate := list.NewDefaultDelegate()
 	l := list.New(items, delegate, 0, 0)
-	l.Title = "Your Library"
 	l.SetShowStatusBar(false)
 	l.SetFilteringEnabled(true)
+	plainList(&l)
 
-	// Add custom help text for the 'f' key
-	l.AdditionalShortHelpKeys = func() []key.Binding {
-		return []key.Binding{
-			key.NewBinding(
-				key.WithKeys("f"),
-				key.WithHelp("f", "toggle finished"),
-			),
-		}
-	}
+	// The list's own "?" gives way to the key reference
+	l.KeyMap.ShowFullHelp.SetEnabled(false)
+	l.KeyMap.CloseFullHelp.SetEnabled(false)
+	l.AdditionalShortHelpKeys = libraryKeys.ShortHelp
 
 	// Load progress data
 	progress, err := config.LoadProgress(cfg)
@@ -93,16 +277,43 @@ func NewLibraryModel(cfg *config.Config) *LibraryModel {
 		}
 	}
 
-	return &LibraryModel{
-		config:   cfg,
-		list:     l,
-		progress: progress,
+	queue, err := config.LoadQueue(cfg)
+	if err != nil {
+		queue = &config.QueueData{}
+	}
+
+	reviews, err := config.LoadReviews(cfg)
+	if err != nil {
+		reviews = &config.ReviewData{Books: make(map[string]config.Review)}
 	}
+
+	m := &LibraryModel{
+		config:            cfg,
+		list:              l,
+		progress:          progress,
+		queue:             queue,
+		reviews:           reviews,
+		finishedCollapsed: true,
+		itemHeight:        delegate.Height() + delegate.Spacing(),
+		itemSpacing:       delegate.Spacing(),
+		gallery:           cfg.Library.View == "gallery",
+		coverProtocol:     detectCoverProtocol(cfg.Display.CoverProtocol),
+	}
+	m.localize()
+	return m
+}
+
+// localize puts the list's own texts in the configured language
+func (m *LibraryModel) localize() {
+	m.list.Title = tr("Your Library")
+	m.list.FilterInput.Prompt = tr("Filter: ")
+	m.list.SetStatusBarItemName(tr("book"), tr("books"))
+	localizeList(&m.list)
 }
 
 // Init initializes the library view
 func (m *LibraryModel) Init() tea.Cmd {
-	return m.loadBooks()
+	return tea.Batch(m.loadBooks(), m.startWatching())
 }
 
 // loadBooks loads books from the library path
@@ -112,7 +323,9 @@ func (m *LibraryModel) loadBooks() tea.Cmd {
 		if err != nil {
 			return BooksLoadedMsg{Error: err}
 		}
-		return BooksLoadedMsg{Books: bookPaths}
+		// Restricted mode leaves out the books that aren't allowed
+		bookPaths = slices.DeleteFunc(bookPaths, func(book ebook.BookInfo) bool { return !m.config.BookAllowed(book.Path) })
+		return BooksLoadedMsg{Books: bookPaths, Duplicates: ebook.FindDuplicates(bookPaths)}
 	}
 }
 
@@ -127,80 +340,383 @@ func (m *LibraryModel) SetSize(width, height int) {
 func (m *LibraryModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case BooksLoadedMsg:
+		m.loaded = true
 		if msg.Error != nil {
 			return m, nil
 		}
 
 		m.books = msg.Books
-		items := make([]list.Item, len(msg.Books))
-		for i, bookInfo := range msg.Books {
-			title := bookInfo.Path
-			author := ""
-			if bookInfo.Title != "" {
-				title = bookInfo.Title
-			}
-			if bookInfo.Author != "" {
-				author = bookInfo.Author
-			}
-
-			// Get progress data for this book
-			completion := 0.0
-			finished := false
-			if bookProgress, exists := m.progress.GetBookProgress(bookInfo.Path); exists {
-				completion = bookProgress.GetCompletionPercentage()
-				finished = bookProgress.Finished
-			}
-
-			items[i] = bookItem{
-				title:      title,
-				author:     author,
-				path:       bookInfo.Path,
-				tags:       bookInfo.Tags,
-				completion: completion,
-				finished:   finished,
-			}
+		m.duplicates = msg.Duplicates
+		m.watchDirectories()
+		m.updateItems()
+		if m.gallery {
+			return m, m.loadCovers()
 		}
-		m.list.SetItems(items)
 		return m, nil
 
+	case CoversLoadedMsg:
+		m.covers = msg.Covers
+		m.coverViews = nil
+		return m, nil
+
+	case libraryChangedMsg, libraryRescanMsg:
+		return m, m.updateWatch(msg)
+
+	case tea.MouseMsg:
+		return m, m.updateMouse(msg)
+
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "enter":
-			// Load the selected book
-			if i, ok := m.list.SelectedItem().(bookItem); ok {
-				return m, m.openBook(i.path)
+		// Let the filter input receive all keys while typing
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+
+		if m.gallery && m.updateGallery(msg) {
+			return m, nil
+		}
+
+		switch {
+		case key.Matches(msg, libraryKeys.Open):
+			// Load the selected book, or fold the finished books
+			switch i := m.list.SelectedItem().(type) {
+			case bookItem:
+				return m, m.openBook(i)
+			case sectionItem:
+				m.toggleSection(i.name)
+				return m, nil
 			}
-		case "f":
+		case key.Matches(msg, libraryKeys.ToggleFinished):
 			// Toggle finished status for the selected book
 			if i, ok := m.list.SelectedItem().(bookItem); ok {
 				m.progress.SetBookFinished(i.path, !i.finished)
-				config.SaveProgress(m.config, m.progress)
-				// Reload the list to reflect changes
-				return m, m.loadBooks()
+				if err := config.SaveProgress(m.config, m.progress); err != nil {
+					return m, notify(toastError, "Could not save progress: %v", err)
+				}
+				if i.finished {
+					m.updateItems()
+					return m, nil
+				}
+				// Newly finished: take it out of the queue and ask for a rating
+				m.dequeue(i.path)
+				m.updateItems()
+				book := hookBook{Path: i.path, Title: i.title, Author: i.author, Percent: 100}
+				return m, tea.Batch(
+					func() tea.Msg { return reviewPromptMsg{path: i.path, title: i.title} },
+					runHook(m.config, hookEvent{Event: hookBookFinished, Book: book}),
+				)
+			}
+		case key.Matches(msg, libraryKeys.Details):
+			// Show metadata for the selected book
+			if i, ok := m.list.SelectedItem().(bookItem); ok {
+				return m, m.showDetails(i)
+			}
+		case key.Matches(msg, libraryKeys.Gallery):
+			// Switch between list and cover gallery
+			return m, m.toggleGallery()
+		case key.Matches(msg, libraryKeys.Send):
+			// Copy the selected book to the configured e-reader
+			if i, ok := m.list.SelectedItem().(bookItem); ok {
+				return m, m.sendToDevice(i)
+			}
+		case key.Matches(msg, libraryKeys.Refresh):
+			// Rescan the library folder
+			return m, m.loadBooks()
+		case key.Matches(msg, libraryKeys.Queue):
+			// Add the selected book to the up next queue, or take it out
+			if i, ok := m.list.SelectedItem().(bookItem); ok {
+				return m, m.toggleQueue(i)
+			}
+		case key.Matches(msg, libraryKeys.Journal):
+			// Write notes on the selected book
+			if i, ok := m.list.SelectedItem().(bookItem); ok {
+				return m, func() tea.Msg { return JournalMsg{Path: i.path, Title: i.title} }
+			}
+		case key.Matches(msg, libraryKeys.OpenFile):
+			// Browse for a book outside the library
+			return m, func() tea.Msg { return FilesMsg{} }
+		case key.Matches(msg, libraryKeys.QueueUp, libraryKeys.QueueDown):
+			// Move the selected book up or down the queue
+			if i, ok := m.list.SelectedItem().(bookItem); ok && i.queued > 0 {
+				delta := 1
+				if key.Matches(msg, libraryKeys.QueueUp) {
+					delta = -1
+				}
+				return m, m.moveInQueue(i, delta)
 			}
 		}
 	}
 
+	// Starting or clearing a filter shows or folds the sections
+	filtering := m.list.FilterState() != list.Unfiltered
 	var cmd tea.Cmd
 	m.list, cmd = m.list.Update(msg)
+	if filtering != (m.list.FilterState() != list.Unfiltered) {
+		cmd = tea.Batch(cmd, m.updateItems())
+	}
 	return m, cmd
 }
 
+// updateItems fills the list from the scanned books and the reading
+// progress, keeping the selection. Queued books come first, in queue
+// order. Unread web articles follow the books under a header of their own.
+// Finished books go last, under a header, followed by the books that can't
+// be opened. While filtering, folded sections are shown so that their
+// books can be found. The returned command refilters the list.
+func (m *LibraryModel) updateItems() tea.Cmd {
+	selected, _ := m.list.SelectedItem().(bookItem)
+
+	duplicated := make(map[string]bool)
+	for _, group := range m.duplicates {
+		for _, book := range group.Books {
+			duplicated[book.Path] = true
+		}
+	}
+
+	seriesLengths := m.seriesLengths()
+
+	var items, articleItems, finishedItems, brokenItems []list.Item
+	queued := make(map[string]bookItem)
+	for _, bookInfo := range m.books {
+		title := bookInfo.Path
+		author := ""
+		if bookInfo.Title != "" {
+			title = bookInfo.Title
+		} else if bookInfo.Error != nil {
+			title = filepath.Base(bookInfo.Path)
+		}
+		if byline := bookInfo.Byline(); byline != "" {
+			author = byline
+		}
+
+		// Get progress data for this book
+		completion := 0.0
+		finished := false
+		if bookProgress, exists := m.progress.GetBookProgress(bookInfo.Path); exists {
+			completion = bookProgress.GetCompletionPercentage()
+			finished = bookProgress.Finished
+		}
+
+		item := bookItem{
+			title:        title,
+			author:       author,
+			path:         bookInfo.Path,
+			tags:         bookInfo.Tags,
+			metadata:     bookInfo.Metadata,
+			contributors: bookInfo.Contributors,
+			calibre:      bookInfo.Calibre,
+			completion:   completion,
+			finished:     finished,
+			series:       seriesLabel(bookInfo.Metadata, seriesLengths),
+			duplicate:    duplicated[bookInfo.Path],
+			queued:       m.queue.Position(bookInfo.Path),
+			review:       m.reviews.Books[bookInfo.Path],
+			err:          bookInfo.Error,
+		}
+		switch {
+		case item.err != nil:
+			brokenItems = append(brokenItems, item)
+		case item.queued > 0:
+			queued[item.path] = item
+		case finished:
+			finishedItems = append(finishedItems, item)
+		case m.config.IsArticle(item.path):
+			articleItems = append(articleItems, item)
+		default:
+			items = append(items, item)
+		}
+	}
+
+	// Books of a series are listed together, in order
+	items, finishedItems = groupSeries(items), groupSeries(finishedItems)
+	items = append(m.queuedItems(queued), items...)
+
+	filtering := m.list.FilterState() != list.Unfiltered
+	articlesCollapsed := m.articlesCollapsed && !filtering
+	finishedCollapsed := m.finishedCollapsed && !filtering
+	brokenCollapsed := m.brokenCollapsed && !filtering
+
+	if len(articleItems) > 0 {
+		items = append(items, sectionItem{name: sectionArticles, count: len(articleItems), collapsed: articlesCollapsed})
+		if !articlesCollapsed {
+			items = append(items, articleItems...)
+		}
+	}
+	if len(finishedItems) > 0 {
+		items = append(items, sectionItem{name: sectionFinished, count: len(finishedItems), collapsed: finishedCollapsed})
+		if !finishedCollapsed {
+			items = append(items, finishedItems...)
+		}
+	}
+	if len(brokenItems) > 0 {
+		items = append(items, sectionItem{name: sectionBroken, count: len(brokenItems), collapsed: brokenCollapsed})
+		if !brokenCollapsed {
+			items = append(items, brokenItems...)
+		}
+	}
+	m.list.Filter = queryFilter(items)
+	cmd := m.list.SetItems(items)
+	m.reselect(selected.path)
+	return cmd
+}
+
+// toggleSection shows or hides the books of a section, keeping the header
+// selected
+func (m *LibraryModel) toggleSection(name string) {
+	switch name {
+	case sectionArticles:
+		m.articlesCollapsed = !m.articlesCollapsed
+	case sectionFinished:
+		m.finishedCollapsed = !m.finishedCollapsed
+	case sectionBroken:
+		m.brokenCollapsed = !m.brokenCollapsed
+	}
+	m.updateItems()
+	for i, item := range m.list.Items() {
+		if section, ok := item.(sectionItem); ok && section.name == name {
+			m.list.Select(i)
+		}
+	}
+}
+
+// reloadProgress reads the progress saved by the reader, so completion
+// and finished books are up to date when returning to the library
+func (m *LibraryModel) reloadProgress() {
+	if progress, err := config.LoadProgress(m.config); err == nil {
+		m.progress = progress
+		m.updateItems()
+	}
+}
+
 // openBook opens a book and sends a BookSelectedMsg
-func (m *LibraryModel) openBook(path string) tea.Cmd {
+func (m *LibraryModel) openBook(item bookItem) tea.Cmd {
+	if item.err != nil {
+		return notify(toastError, "Can't open %s: %v", item.title, item.err)
+	}
+	if !m.config.BookAllowed(item.path) {
+		return notify(toastError, "Can't open %s in restricted mode", item.title)
+	}
 	return func() tea.Msg {
-		book, err := ebook.Open(path)
+		book, err := ebook.OpenBook(item.path, BookOptions(m.config))
 		if err != nil {
 			return BookLoadErrorMsg{Error: err}
 		}
+		applyLibraryMetadata(book, item)
 		return BookSelectedMsg{Book: book}
 	}
 }
 
+// reselect moves the cursor back to a book after the list was reloaded, as
+// books added or removed before it shift its position
+func (m *LibraryModel) reselect(path string) {
+	if path == "" {
+		return
+	}
+	for i, listItem := range m.list.VisibleItems() {
+		if item, ok := listItem.(bookItem); ok && item.path == path {
+			m.list.Select(i)
+			return
+		}
+	}
+}
+
+// findItem returns the library item for a book path
+func (m *LibraryModel) findItem(path string) (bookItem, bool) {
+	if path == "" {
+		return bookItem{}, false
+	}
+	for _, listItem := range m.list.Items() {
+		if item, ok := listItem.(bookItem); ok && item.path == path {
+			return item, true
+		}
+	}
+	return bookItem{}, false
+}
+
+// applyLibraryMetadata overrides the metadata read from the file with the
+// Calibre metadata shown in the library, so edits made in Calibre win
+func applyLibraryMetadata(book *ebook.Book, item bookItem) {
+	if !item.calibre {
+		return
+	}
+
+	book.Title = item.title
+	book.Contributors = item.contributors
+	if len(item.contributors) > 0 {
+		book.Author = item.contributors[0].Name
+	}
+	book.Tags = item.tags
+	for key, value := range item.metadata {
+		book.Metadata[key] = value
+	}
+}
+
+// showDetails opens a book and sends a BookDetailsMsg
+func (m *LibraryModel) showDetails(item bookItem) tea.Cmd {
+	if item.err != nil {
+		return notify(toastError, "Can't open %s: %v", item.title, item.err)
+	}
+	bookProgress, hasProgress := m.progress.GetBookProgress(item.path)
+	return func() tea.Msg {
+		book, err := ebook.OpenBook(item.path, BookOptions(m.config))
+		if err != nil {
+			return BookLoadErrorMsg{Error: err}
+		}
+		book.Tags = item.tags
+		applyLibraryMetadata(book, item)
+		return BookDetailsMsg{Book: book, Progress: bookProgress, HasProgress: hasProgress}
+	}
+}
+
+// sendToDevice copies a book to the e-reader configured in the config
+func (m *LibraryModel) sendToDevice(item bookItem) tea.Cmd {
+	device := m.config.Device
+	options := ebook.ExportOptions{ConvertText: device.ConvertText, Book: BookOptions(m.config)}
+	return func() tea.Msg {
+		if device.Path == "" {
+			return BookSentMsg{Title: item.title, Error: errorf("no device path set in config.toml")}
+		}
+		path, err := ebook.ExportBook(item.path, device.Path, options)
+		return BookSentMsg{Title: item.title, Path: path, Error: err}
+	}
+}
+
+// formatRating renders a 1-5 star rating, or an empty string when unrated
+func formatRating(value string) string {
+	rating, err := strconv.Atoi(value)
+	if err != nil || rating <= 0 {
+		return ""
+	}
+	return stars(rating)
+}
+
+// stars renders a rating out of 5 stars
+func stars(rating int) string {
+	rating = max(0, min(rating, 5))
+	return strings.Repeat("★", rating) + strings.Repeat("☆", 5-rating)
+}
+
+// BookOptions builds the ebook reading options from the config
+func BookOptions(cfg *config.Config) ebook.Options {
+	options := ebook.DefaultOptions()
+	switch mode := ebook.NonLinearMode(cfg.Reading.NonLinear); mode {
+	case ebook.NonLinearInclude, ebook.NonLinearEnd, ebook.NonLinearSkip:
+		options.NonLinear = mode
+	}
+	switch mode := ebook.TextSplitMode(cfg.Reading.TextSplit); mode {
+	case ebook.TextSplitAuto, ebook.TextSplitHeadings, ebook.TextSplitBlank, ebook.TextSplitSections, ebook.TextSplitNone:
+		options.TextSplit = mode
+	}
+	if cfg.Reading.TextSectionSize > 0 {
+		options.TextSectionSize = cfg.Reading.TextSectionSize
+	}
+	options.GutenbergCleanup = cfg.Reading.GutenbergCleanup
+	return options
+}
+
 // View renders the library view
 func (m *LibraryModel) View() string {
 	if m.config.ActiveTheme == nil {
-		return "Loading..."
+		return tr("Loading...")
 	}
 
 	theme := m.config.ActiveTheme
@@ -210,15 +726,38 @@ func (m *LibraryModel) View() string {
 		Foreground(lipgloss.Color(theme.PrimaryColor)).
 		Padding(1, 0)
 
-	return titleStyle.Render("Cozy - E-Book Reader") + "\n" + m.list.View()
+	if m.gallery {
+		return titleStyle.Render(tr("Cozy - E-Book Reader")) + "\n" + m.galleryView()
+	}
+
+	return titleStyle.Render(tr("Cozy - E-Book Reader")) + "\n" + m.list.View()
 }
 
 // Messages
 type BooksLoadedMsg struct {
-	Books []ebook.BookInfo
+	Books      []ebook.BookInfo
+	Duplicates []ebook.DuplicateGroup
+	Error      error
+}
+
+// BookSentMsg reports the result of sending a book to the device
+type BookSentMsg struct {
+	Title string
+	Path  string
 	Error error
 }
 
+// Status describes the result for a toast
+func (msg BookSentMsg) Status() string {
+	switch {
+	case errors.Is(msg.Error, ebook.ErrDuplicate):
+		return trf("Already on device: %s", filepath.Base(msg.Path))
+	case msg.Error != nil:
+		return trf("Send failed: %v", msg.Error)
+	}
+	return trf("Sent %s to device", msg.Title)
+}
+
 type BookLoadErrorMsg struct {
 	Error error
 }
@@ -0,0 +1,85 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// catalog translates the interface into the configured language, or is
+// nil for English. Like the screen reader setting it is applied from the
+// config, as it changes how everything is drawn.
+var catalog config.Catalog
+
+// applyLanguage switches the language of the interface. A language that
+// can't be loaded has been reported by the config check, and English is
+// used.
+func applyLanguage(cfg *config.Config) {
+	catalog, _ = config.LoadLocale(cfg.Language)
+}
+
+// tr returns a text of the interface in the configured language
+func tr(text string) string {
+	if translation, ok := catalog[text]; ok && translation != "" {
+		return translation
+	}
+	return text
+}
+
+// trf formats a text of the interface in the configured language
+func trf(format string, args ...any) string {
+	return fmt.Sprintf(tr(format), args...)
+}
+
+// errorf returns an error with a message in the configured language
+func errorf(format string, args ...any) error {
+	if len(args) == 0 {
+		return errors.New(tr(format))
+	}
+	return fmt.Errorf(tr(format), args...)
+}
+
+// localized returns bindings with their help in the configured language,
+// for the ShortHelp and FullHelp of keymaps
+func localized(bindings ...key.Binding) []key.Binding {
+	for i, binding := range bindings {
+		bindings[i].SetHelp(binding.Help().Key, tr(binding.Help().Desc))
+	}
+	return bindings
+}
+
+// localizedGroups returns groups of bindings with their help in the
+// configured language
+func localizedGroups(groups [][]key.Binding) [][]key.Binding {
+	for i := range groups {
+		groups[i] = localized(groups[i]...)
+	}
+	return groups
+}
+
+// localizeList translates the help of a list's own keys, which the list
+// shows itself
+func localizeList(l *list.Model) {
+	defaults := list.DefaultKeyMap()
+	for _, pair := range []struct{ binding, english *key.Binding }{
+		{&l.KeyMap.CursorUp, &defaults.CursorUp},
+		{&l.KeyMap.CursorDown, &defaults.CursorDown},
+		{&l.KeyMap.PrevPage, &defaults.PrevPage},
+		{&l.KeyMap.NextPage, &defaults.NextPage},
+		{&l.KeyMap.GoToStart, &defaults.GoToStart},
+		{&l.KeyMap.GoToEnd, &defaults.GoToEnd},
+		{&l.KeyMap.Filter, &defaults.Filter},
+		{&l.KeyMap.ClearFilter, &defaults.ClearFilter},
+		{&l.KeyMap.CancelWhileFiltering, &defaults.CancelWhileFiltering},
+		{&l.KeyMap.AcceptWhileFiltering, &defaults.AcceptWhileFiltering},
+		{&l.KeyMap.ShowFullHelp, &defaults.ShowFullHelp},
+		{&l.KeyMap.CloseFullHelp, &defaults.CloseFullHelp},
+		{&l.KeyMap.Quit, &defaults.Quit},
+		{&l.KeyMap.ForceQuit, &defaults.ForceQuit},
+	} {
+		pair.binding.SetHelp(pair.english.Help().Key, tr(pair.english.Help().Desc))
+	}
+}
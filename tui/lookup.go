@@ -0,0 +1,504 @@
+package tui
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/cbrasser/cozy/dictionary"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+// lookupKeyMap defines key bindings while the word cursor is shown
+type lookupKeyMap struct {
+	NextWord   key.Binding
+	PrevWord   key.Binding
+	LineDown   key.Binding
+	LineUp     key.Binding
+	Select     key.Binding
+	Define     key.Binding
+	Online     key.Binding
+	Highlight  key.Binding
+	Vocabulary key.Binding
+	Exit       key.Binding
+}
+
+func (k lookupKeyMap) ShortHelp() []key.Binding {
+	return localized(k.NextWord, k.PrevWord, k.LineDown, k.LineUp, k.Select, k.Define, k.Online, k.Highlight, k.Vocabulary, k.Exit)
+}
+
+func (k lookupKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+var lookupKeys = lookupKeyMap{
+	NextWord: key.NewBinding(
+		key.WithKeys("l", "right", "w"),
+		key.WithHelp("l/→/w", "next word"),
+	),
+	PrevWord: key.NewBinding(
+		key.WithKeys("h", "left", "b"),
+		key.WithHelp("h/←/b", "previous word"),
+	),
+	LineDown: key.NewBinding(
+		key.WithKeys("j", "down"),
+		key.WithHelp("↓/j", "line down"),
+	),
+	LineUp: key.NewBinding(
+		key.WithKeys("k", "up"),
+		key.WithHelp("↑/k", "line up"),
+	),
+	Select: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "select phrase"),
+	),
+	Define: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "define"),
+	),
+	Online: key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "look up online"),
+	),
+	Highlight: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "highlight"),
+	),
+	Vocabulary: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "mark unknown word"),
+	),
+	Exit: key.NewBinding(
+		key.WithKeys("esc", "d"),
+		key.WithHelp("esc", "stop lookup"),
+	),
+}
+
+// lookupState tracks the word cursor, the word prompt and the definition overlay
+type lookupState struct {
+	active bool // Word cursor is shown
+	line   int  // Content line of the cursor
+	word   int  // Index of the word within the line
+
+	selecting  bool // Extending a phrase selection from the anchor
+	anchorLine int
+	anchorWord int
+
+	prompt bool // Typing a word to look up
+	input  textinput.Model
+
+	result *DefinitionMsg // Definition overlay, when shown
+	scroll int
+}
+
+// DefinitionMsg carries the result of a dictionary lookup
+type DefinitionMsg struct {
+	Word    string
+	Entries []dictionary.Entry
+	Error   error
+}
+
+// wordSpan is a word's position within a rendered line, in display columns
+type wordSpan struct {
+	text       string
+	start, end int
+}
+
+// lineWords splits a rendered line into words
+func lineWords(line string) []wordSpan {
+	var words []wordSpan
+	var current []rune
+	start, col := 0, 0
+
+	flush := func() {
+		// Apostrophes and hyphens only count inside a word
+		end := col
+		for len(current) > 0 && !isWordRune(current[len(current)-1]) {
+			current = current[:len(current)-1]
+			end--
+		}
+		if len(current) > 0 {
+			// Letter spacing is left out of the word
+			text := strings.ReplaceAll(string(current), "\u00a0", "")
+			words = append(words, wordSpan{text: text, start: start, end: end})
+		}
+		current = nil
+	}
+
+	for _, r := range ansi.Strip(line) {
+		if isWordRune(r) || (len(current) > 0 && strings.ContainsRune("'’-\u00a0", r)) {
+			if len(current) == 0 {
+				start = col
+			}
+			current = append(current, r)
+		} else {
+			flush()
+		}
+		col += ansi.StringWidth(string(r))
+	}
+	flush()
+
+	return words
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// modal reports whether a lookup mode or overlay is taking the keyboard
+func (m *ReaderModel) modal() bool {
+	return m.lookup.active || m.lookup.prompt || m.lookup.result != nil || m.reminder.shown || m.resume.shown || m.focus.onBreak || m.picker.active || m.rsvp.active
+}
+
+// startWordCursor puts the word cursor on the first word in view
+func (m *ReaderModel) startWordCursor() {
+	top := m.viewport.YOffset
+	for line := top; line < min(len(m.lines), top+m.viewport.Height); line++ {
+		if len(lineWords(m.lines[line])) > 0 {
+			m.lookup.active = true
+			m.lookup.selecting = false
+			m.lookup.line = line
+			m.lookup.word = 0
+			return
+		}
+	}
+}
+
+// startWordPrompt opens the prompt for typing a word to look up
+func (m *ReaderModel) startWordPrompt() tea.Cmd {
+	input := textinput.New()
+	input.Prompt = tr("Look up: ")
+	input.CharLimit = 64
+	m.lookup.input = input
+	m.lookup.prompt = true
+	return m.lookup.input.Focus()
+}
+
+// updateLookup handles keys while a lookup mode is active. It reports
+// whether the key was consumed.
+func (m *ReaderModel) updateLookup(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch {
+	case m.lookup.result != nil:
+		// The overlay is modal: scroll it or close it
+		switch msg.String() {
+		case "j", "down":
+			m.lookup.scroll++
+		case "k", "up":
+			m.lookup.scroll = max(0, m.lookup.scroll-1)
+		case "esc", "enter", "d":
+			m.lookup.result = nil
+		}
+		return nil, true
+
+	case m.lookup.prompt:
+		switch msg.Type {
+		case tea.KeyEnter:
+			m.lookup.prompt = false
+			return m.lookupWord(m.lookup.input.Value()), true
+		case tea.KeyEsc:
+			m.lookup.prompt = false
+			return nil, true
+		}
+		var cmd tea.Cmd
+		m.lookup.input, cmd = m.lookup.input.Update(msg)
+		return cmd, true
+
+	case m.lookup.active:
+		switch {
+		case key.Matches(msg, lookupKeys.NextWord):
+			m.moveWord(1)
+		case key.Matches(msg, lookupKeys.PrevWord):
+			m.moveWord(-1)
+		case key.Matches(msg, lookupKeys.LineDown):
+			m.moveLine(1)
+		case key.Matches(msg, lookupKeys.LineUp):
+			m.moveLine(-1)
+		case key.Matches(msg, lookupKeys.Select):
+			m.lookup.selecting = !m.lookup.selecting
+			m.lookup.anchorLine = m.lookup.line
+			m.lookup.anchorWord = m.lookup.word
+		case key.Matches(msg, lookupKeys.Define):
+			return m.lookupWord(m.selectedText()), true
+		case key.Matches(msg, lookupKeys.Online):
+			return m.lookupOnline(m.selectedText()), true
+		case key.Matches(msg, lookupKeys.Highlight):
+			return m.addHighlight(), true
+		case m.config.Reading.Vocabulary && key.Matches(msg, lookupKeys.Vocabulary):
+			return m.toggleVocabulary(), true
+		case key.Matches(msg, lookupKeys.Exit):
+			m.lookup.active = false
+		}
+		return nil, true
+	}
+
+	return nil, false
+}
+
+// cursorWord returns the word under the cursor
+func (m *ReaderModel) cursorWord() (wordSpan, bool) {
+	if m.lookup.line >= len(m.lines) {
+		return wordSpan{}, false
+	}
+	words := lineWords(m.lines[m.lookup.line])
+	if m.lookup.word >= len(words) {
+		return wordSpan{}, false
+	}
+	return words[m.lookup.word], true
+}
+
+// moveWord moves the cursor by delta words, wrapping onto other lines
+func (m *ReaderModel) moveWord(delta int) {
+	words := lineWords(m.lines[m.lookup.line])
+	next := m.lookup.word + delta
+	if next >= 0 && next < len(words) {
+		m.lookup.word = next
+		return
+	}
+
+	if line, lineWords, ok := m.findWordLine(m.lookup.line, delta); ok {
+		m.lookup.line = line
+		m.lookup.word = 0
+		if delta < 0 {
+			m.lookup.word = len(lineWords) - 1
+		}
+		m.scrollToCursor()
+	}
+}
+
+// moveLine moves the cursor to the nearest word on the next line with words
+func (m *ReaderModel) moveLine(delta int) {
+	col := 0
+	if word, ok := m.cursorWord(); ok {
+		col = word.start
+	}
+
+	line, words, ok := m.findWordLine(m.lookup.line, delta)
+	if !ok {
+		return
+	}
+
+	best := 0
+	for i, word := range words {
+		if abs(word.start-col) < abs(words[best].start-col) {
+			best = i
+		}
+	}
+	m.lookup.line = line
+	m.lookup.word = best
+	m.scrollToCursor()
+}
+
+// findWordLine finds the next line in the direction of delta that has words
+func (m *ReaderModel) findWordLine(from, delta int) (int, []wordSpan, bool) {
+	step := 1
+	if delta < 0 {
+		step = -1
+	}
+	for line := from + step; line >= 0 && line < len(m.lines); line += step {
+		if words := lineWords(m.lines[line]); len(words) > 0 {
+			return line, words, true
+		}
+	}
+	return 0, nil, false
+}
+
+// scrollToCursor scrolls the viewport so the cursor line is visible
+func (m *ReaderModel) scrollToCursor() {
+	switch {
+	case m.lookup.line < m.viewport.YOffset:
+		m.viewport.SetYOffset(m.lookup.line)
+	case m.lookup.line >= m.viewport.YOffset+m.viewport.Height:
+		m.viewport.SetYOffset(m.lookup.line - m.viewport.Height + 1)
+	}
+}
+
+// lookupWord queries the dictionaries in the background
+func (m *ReaderModel) lookupWord(word string) tea.Cmd {
+	word = dictionary.CleanWord(word)
+	if word == "" {
+		return nil
+	}
+	dictionaries, online := m.dictionaries, m.online
+	return func() tea.Msg {
+		entries, err := dictionaries.Lookup(word)
+		if len(entries) == 0 && len(online) > 0 {
+			// Fall back to the online sources
+			return queryOnline(online, word)
+		}
+		return DefinitionMsg{Word: word, Entries: entries, Error: err}
+	}
+}
+
+// onlineSources returns the online lookup backends enabled in the config
+func onlineSources(cfg *config.Config) []dictionary.Dictionary {
+	if !cfg.Dictionary.OnlineLookups {
+		return nil
+	}
+	return []dictionary.Dictionary{
+		dictionary.NewWiktionary(cfg.Dictionary.OnlineLanguage, cfg.LookupCacheDir()),
+		dictionary.NewWikipedia(cfg.Dictionary.OnlineLanguage, cfg.LookupCacheDir()),
+	}
+}
+
+// lookupOnline queries Wiktionary and Wikipedia in the background
+func (m *ReaderModel) lookupOnline(phrase string) tea.Cmd {
+	phrase = dictionary.CleanWord(phrase)
+	if phrase == "" {
+		return nil
+	}
+	if len(m.online) == 0 {
+		return func() tea.Msg {
+			return DefinitionMsg{Word: phrase, Error: errorf("online lookups are off; set dictionary.online_lookups in config.toml")}
+		}
+	}
+	online := m.online
+	return func() tea.Msg {
+		return queryOnline(online, phrase)
+	}
+}
+
+// queryOnline asks each online source, also trying the lowercase form
+// since sentence-initial words are capitalized
+func queryOnline(sources []dictionary.Dictionary, phrase string) DefinitionMsg {
+	result := DefinitionMsg{Word: phrase}
+	for _, source := range sources {
+		entries, err := source.Lookup(phrase)
+		if len(entries) == 0 && err == nil && strings.ToLower(phrase) != phrase {
+			entries, err = source.Lookup(strings.ToLower(phrase))
+		}
+		if err != nil {
+			result.Error = err
+			continue
+		}
+		result.Entries = append(result.Entries, entries...)
+	}
+
+	// Only report an error if nothing was found at all
+	if len(result.Entries) > 0 {
+		result.Error = nil
+	}
+	return result
+}
+
+// selectedText returns the selected phrase, or the word under the cursor
+func (m *ReaderModel) selectedText() string {
+	if !m.lookup.selecting {
+		word, _ := m.cursorWord()
+		return word.text
+	}
+
+	var words []string
+	m.forSelectedLines(func(line int, selected []wordSpan) {
+		for _, word := range selected {
+			words = append(words, word.text)
+		}
+	})
+	return strings.Join(words, " ")
+}
+
+// forSelectedLines calls fn with the selected words of each selected line
+func (m *ReaderModel) forSelectedLines(fn func(line int, selected []wordSpan)) {
+	startLine, startWord := m.lookup.line, m.lookup.word
+	endLine, endWord := startLine, startWord
+	if m.lookup.selecting {
+		startLine, startWord = m.lookup.anchorLine, m.lookup.anchorWord
+		if startLine > endLine || (startLine == endLine && startWord > endWord) {
+			startLine, startWord, endLine, endWord = endLine, endWord, startLine, startWord
+		}
+	}
+
+	for line := startLine; line <= endLine && line < len(m.lines); line++ {
+		words := lineWords(m.lines[line])
+		from, to := 0, len(words)-1
+		if line == startLine {
+			from = startWord
+		}
+		if line == endLine {
+			to = min(endWord, len(words)-1)
+		}
+		if from <= to {
+			fn(line, words[from:to+1])
+		}
+	}
+}
+
+// highlightSelection marks the selected words in the viewport view
+func (m *ReaderModel) highlightSelection(view string) string {
+	theme := m.config.ActiveTheme
+	style := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.BackgroundColor)).
+		Background(lipgloss.Color(theme.PrimaryColor))
+
+	lines := strings.Split(view, "\n")
+	m.forSelectedLines(func(line int, selected []wordSpan) {
+		row := line - m.viewport.YOffset
+		if row < 0 || row >= len(lines) {
+			return
+		}
+		start, end := selected[0].start, selected[len(selected)-1].end
+		lines[row] = styleColumns(lines[row], start, end, style)
+	})
+	return strings.Join(lines, "\n")
+}
+
+// definitionView renders the definition overlay box
+func (m *ReaderModel) definitionView() string {
+	theme := m.config.ActiveTheme
+	result := m.lookup.result
+
+	boxWidth := max(20, min(70, m.viewport.Width-4))
+	textWidth := boxWidth - 4 // Border and padding
+	maxLines := max(3, m.viewport.Height-4)
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(theme.HeadingColor))
+	sourceStyle := lipgloss.NewStyle().Italic(true).Foreground(lipgloss.Color(theme.SecondaryColor))
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.MutedTextColor))
+
+	var lines []string
+	switch {
+	case result.Error != nil:
+		lines = strings.Split(wordwrap.String(trf("Lookup failed: %v", result.Error), textWidth), "\n")
+	case len(result.Entries) == 0:
+		lines = []string{trf("No definition found for %q", result.Word)}
+	default:
+		for i, entry := range result.Entries {
+			if i > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, sourceStyle.Render(entry.Dictionary))
+			lines = append(lines, strings.Split(wordwrap.String(entry.Definition, textWidth), "\n")...)
+		}
+	}
+
+	// Clamp scrolling to the content
+	m.lookup.scroll = min(m.lookup.scroll, max(0, len(lines)-maxLines))
+	visible := lines[m.lookup.scroll:min(len(lines), m.lookup.scroll+maxLines)]
+
+	footer := tr("esc close")
+	if m.lookup.scroll+maxLines < len(lines) {
+		footer = plain(tr("↓/j more") + " • " + footer)
+	}
+
+	content := titleStyle.Render(result.Word) + "\n\n" +
+		strings.Join(visible, "\n") + "\n\n" +
+		mutedStyle.Render(footer)
+
+	return lipgloss.NewStyle().
+		Border(panelBorder()).
+		BorderForeground(lipgloss.Color(theme.PrimaryColor)).
+		Padding(0, 1).
+		Width(boxWidth - 2).
+		Render(content)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"time"
+
+	"github.com/cbrasser/cozy/ebook"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// mailTickMsg fires when it is time to check for books sent by email
+type mailTickMsg struct{}
+
+// mailCheckedMsg reports a check for books sent by email
+type mailCheckedMsg struct {
+	result ebook.MailResult
+	err    error
+	manual bool // Asked for with :mail, so no news is worth saying
+}
+
+// runMail checks for books sent by email in the background
+func runMail(m *Model, args []string) (string, tea.Cmd, error) {
+	if len(args) != 0 {
+		return "", nil, errorf("usage: %s", "mail")
+	}
+	if m.config.Mail.Maildir == "" && m.config.Mail.IMAPServer == "" {
+		return "", nil, errorf("no mailbox: set mail.maildir or mail.imap_server")
+	}
+	return tr("Checking mail for books..."), m.checkMail(true), nil
+}
+
+// watchMail checks for books sent by email once cozy starts and then every
+// mail.check_minutes, when a mailbox is set up
+func (m Model) watchMail() tea.Cmd {
+	cfg := m.config
+	if cfg.Mail.CheckMinutes <= 0 || cfg.Restricted.Enabled || (cfg.Mail.Maildir == "" && cfg.Mail.IMAPServer == "") {
+		return nil
+	}
+	return m.checkMail(false)
+}
+
+// checkMail imports the books attached to new mail in the background
+func (m Model) checkMail(manual bool) tea.Cmd {
+	cfg := m.config
+	return func() tea.Msg {
+		options := ebook.ExportOptions{Book: BookOptions(cfg)}
+		result, err := ebook.CheckMail(cfg, options)
+		return mailCheckedMsg{result: result, err: err, manual: manual}
+	}
+}
+
+// applyMailCheck reports the books that came by email and rescans the
+// library for them. After a scheduled check the next one is scheduled.
+func (m *Model) applyMailCheck(msg mailCheckedMsg) tea.Cmd {
+	var cmds []tea.Cmd
+	switch {
+	case msg.err != nil:
+		cmds = append(cmds, notify(toastError, "Mail check: %v", msg.err))
+	case len(msg.result.Failed) > 0:
+		cmds = append(cmds, notify(toastError, "Mail check: %d email(s) could not be read: %v", len(msg.result.Failed), msg.result.Failed[0]))
+	}
+	if len(msg.result.Imported) > 0 {
+		cmds = append(cmds, notify(toastSuccess, "Imported %d book(s) from email", len(msg.result.Imported)), m.library.loadBooks())
+	} else if msg.manual && msg.err == nil && len(msg.result.Failed) == 0 {
+		cmds = append(cmds, notify(toastInfo, "No new books by email"))
+	}
+
+	if !msg.manual && m.config.Mail.CheckMinutes > 0 {
+		cmds = append(cmds, tea.Tick(time.Duration(m.config.Mail.CheckMinutes)*time.Minute, func(time.Time) tea.Msg {
+			return mailTickMsg{}
+		}))
+	}
+	return tea.Batch(cmds...)
+}
@@ -0,0 +1,113 @@
+package tui
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/cbrasser/cozy/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Marks are quick, unnamed places in a book, as in vim: m and a letter
+// sets one, ' and the letter jumps back to it, and '' returns to where the
+// last jump started. They are saved with the progress, apart from the
+// named bookmarks of :bookmark.
+
+// lastJumpMark is the mark set when jumping to another one
+const lastJumpMark = "'"
+
+// updateMark takes the letter after m or '. It reports false if no mark
+// key was pressed before.
+func (m *ReaderModel) updateMark(msg tea.KeyMsg) (tea.Cmd, bool) {
+	pending := m.markPending
+	if pending == "" {
+		return nil, false
+	}
+	m.markPending = ""
+
+	name := msg.String()
+	if msg.Type != tea.KeyRunes || len(msg.Runes) != 1 || !validMark(name, pending) {
+		return nil, true // Anything else cancels
+	}
+	if pending == "m" {
+		m.status = trf("Mark %s set", name)
+		return m.saveMark(name), true
+	}
+	if name == "`" {
+		name = lastJumpMark
+	}
+	m.status = m.gotoMark(name)
+	return nil, true
+}
+
+// validMark reports whether a key names a mark: a letter, or ' (or `) for
+// the last jump, which can't be set by hand
+func validMark(name, pending string) bool {
+	if name == lastJumpMark || name == "`" {
+		return pending != "m"
+	}
+	return len(name) == 1 && (name[0] >= 'a' && name[0] <= 'z' || name[0] >= 'A' && name[0] <= 'Z')
+}
+
+// currentMark returns a mark at the current position
+func (m *ReaderModel) currentMark() config.Mark {
+	return config.Mark{
+		Chapter:      m.currentChapter,
+		ScrollOffset: m.lineInChapter(m.viewport.YOffset),
+		TextOffset:   m.textOffset(),
+	}
+}
+
+// saveMark sets a mark at the current position and saves it
+func (m *ReaderModel) saveMark(name string) tea.Cmd {
+	m.progress.SetMark(m.book.Path, name, m.currentMark())
+	if err := m.SaveProgress(); err != nil {
+		return notify(toastError, "Could not save mark: %v", err)
+	}
+	return nil
+}
+
+// gotoMark jumps to a mark, remembering where it jumped from, and returns
+// the status to show
+func (m *ReaderModel) gotoMark(name string) string {
+	mark, ok := m.Marks()[name]
+	if !ok || mark.Chapter >= m.book.ChapterCount() {
+		if name == lastJumpMark {
+			return tr("No jump to go back from")
+		}
+		return trf("No mark %s", name)
+	}
+
+	// The jump is saved with the next autosave
+	m.progress.SetMark(m.book.Path, lastJumpMark, m.currentMark())
+	if mark.Chapter != m.currentChapter {
+		m.GotoChapter(mark.Chapter)
+	}
+	m.restorePosition(mark.ScrollOffset, mark.TextOffset)
+	return ""
+}
+
+// Marks returns the marks of the open book
+func (m *ReaderModel) Marks() map[string]config.Mark {
+	if m.book == nil {
+		return nil
+	}
+	bookProgress, _ := m.progress.GetBookProgress(m.book.Path)
+	return bookProgress.Marks
+}
+
+// markList describes the marks of the open book, e.g. "a: chapter 3"
+func (m *ReaderModel) markList() string {
+	marks := m.Marks()
+	var list []string
+	for _, name := range slices.Sorted(maps.Keys(marks)) {
+		if name != lastJumpMark {
+			list = append(list, trf("%s: chapter %d", name, marks[name].Chapter+1))
+		}
+	}
+	if len(list) == 0 {
+		return tr("No marks")
+	}
+	return trf("Marks: %s", strings.Join(list, ", "))
+}
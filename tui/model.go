@@ -3,7 +3,10 @@ package tui
 import (
 	"github.com/cbrasser/cozy/config"
 	"github.com/cbrasser/cozy/ebook"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fsnotify/fsnotify"
 )
 
 // View represents different screens in the TUI
@@ -12,32 +15,128 @@ type View int
 const (
 	ViewLibrary View = iota
 	ViewReader
+	ViewDetails
+	ViewHelp
+	ViewFiles
+	ViewJournal
 )
 
+// appKeyMap defines the key bindings the model handles in every view
+type appKeyMap struct {
+	Command key.Binding
+	Help    key.Binding
+	Undo    key.Binding
+	Sync    key.Binding
+	Quit    key.Binding
+}
+
+func (k appKeyMap) ShortHelp() []key.Binding {
+	return localized(k.Command, k.Help, k.Undo, k.Sync, k.Quit)
+}
+
+func (k appKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+var appKeys = appKeyMap{
+	Command: key.NewBinding(
+		key.WithKeys(":"),
+		key.WithHelp(":", "command line"),
+	),
+	Help: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "all keys and commands"),
+	),
+	Undo: key.NewBinding(
+		key.WithKeys("ctrl+z"),
+		key.WithHelp("ctrl+z", "undo the last deletion"),
+	),
+	Sync: key.NewBinding(
+		key.WithKeys("ctrl+s"),
+		key.WithHelp("ctrl+s", "sync with WebDAV"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys("q", "ctrl+c"),
+		key.WithHelp("q/ctrl+c", "quit"),
+	),
+}
+
 // Model is the main Bubbletea model
 type Model struct {
-	config       *config.Config
-	currentView  View
-	library      *LibraryModel
-	reader       *ReaderModel
-	width        int
-	height       int
-	err          error
+	config  *config.Config
+	views   []View // Open views, the current one last; see router.go
+	library *LibraryModel
+	reader  *ReaderModel
+	details *DetailsModel
+	help    *HelpModel
+	files   *FilesModel
+	journal *JournalModel
+	command commandLine
+	width   int
+	height  int
+	err     error
+
+	resume bool // Reopen the last book once the library has loaded
+
+	summary *sessionSummary // Shown after leaving a book, see session.go
+	review  *reviewPrompt   // Shown after finishing a book, see review.go
+
+	// Notifications, see toast.go
+	toasts  []toast
+	toastID int
+
+	// Reloading the config when it changes, see reload.go
+	configWatcher *fsnotify.Watcher
+	reloadTimer   int // Identifies the pending reload so superseded ones are ignored
+
+	// Remote control socket, see remote.go
+	remote    *remoteServer
+	remoteErr error
 }
 
 // NewModel creates a new TUI model
 func NewModel(cfg *config.Config) Model {
+	applyColorProfile(cfg.Display.ColorProfile)
+	applyScreenReader(cfg)
+	applyLanguage(cfg)
+	remote, remoteErr := listenRemote(cfg)
 	return Model{
-		config:      cfg,
-		currentView: ViewLibrary,
-		library:     NewLibraryModel(cfg),
-		reader:      NewReaderModel(cfg),
+		config:  cfg,
+		views:   []View{ViewLibrary},
+		library: NewLibraryModel(cfg),
+		reader:  NewReaderModel(cfg),
+		details: NewDetailsModel(cfg),
+		help:    NewHelpModel(cfg),
+		files:   NewFilesModel(cfg),
+		journal: NewJournalModel(cfg),
+		resume:  cfg.Reading.ResumeLastBook,
+
+		configWatcher: watchConfig(),
+		remote:        remote,
+		remoteErr:     remoteErr,
 	}
 }
 
+// ResumeLastBook makes the model open the last book read instead of
+// starting in the library
+func (m *Model) ResumeLastBook() {
+	m.resume = true
+}
+
 // Init initializes the model
 func (m Model) Init() tea.Cmd {
-	return m.library.Init()
+	cmd := tea.Batch(m.library.Init(), m.configWarnings(), m.waitForConfigChange(), m.waitForRemote(), m.checkAO3(), m.watchMail())
+	if m.remoteErr != nil {
+		cmd = tea.Batch(cmd, notify(toastError, "Remote control: %v", m.remoteErr))
+	}
+	return cmd
+}
+
+// Close stops the remote control server, removing its socket
+func (m Model) Close() {
+	if m.remote != nil {
+		m.remote.close()
+	}
 }
 
 // Update handles messages and updates the model
@@ -46,62 +145,239 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-		m.library.SetSize(msg.Width, msg.Height)
-		m.reader.SetSize(msg.Width, msg.Height)
-		return m, nil
+		for _, s := range m.screens() {
+			s.SetSize(msg.Width, msg.Height)
+		}
+		return m, m.resumeBook()
 
 	case tea.KeyMsg:
+		if m.review != nil {
+			return m, m.updateReview(msg)
+		}
+		if m.summary != nil {
+			return m, m.updateSummary(msg)
+		}
+		if m.command.active {
+			return m, m.updateCommandLine(msg)
+		}
+		if m.command.confirm != nil {
+			return m, m.answerConfirm(msg)
+		}
+		// Any key dismisses the last command's message
+		m.command.message = ""
+
+		// While typing, only ctrl+c quits
+		if msg.String() == "q" && m.capturesInput() {
+			break
+		}
+
+		// The command line and the key reference open from any view that
+		// isn't busy with a key of its own
+		if !m.capturesInput() && !(m.currentView() == ViewReader && m.reader.modal()) {
+			switch {
+			case key.Matches(msg, appKeys.Command):
+				return m, m.openCommandLine()
+			case key.Matches(msg, appKeys.Help) && m.currentView() != ViewHelp:
+				return m, showHelp
+			case key.Matches(msg, appKeys.Undo):
+				return m, m.undoKey()
+			case key.Matches(msg, appKeys.Sync):
+				return m, m.syncWebDAV()
+			}
+		}
+
 		switch msg.String() {
-		case "ctrl+c", "q":
+		case "ctrl+c":
 			// Save reading progress before quitting
-			if m.currentView == ViewReader {
+			if m.currentView() == ViewReader {
 				m.reader.SaveProgress()
 			}
+			m.reader.stopReadAloud()
+			m.journal.save()
 			return m, tea.Quit
+		case "q":
+			return m, m.quit()
 		}
 
 	case BookSelectedMsg:
-		// Switch to reader view when a book is selected
-		m.currentView = ViewReader
+		// Switch to reader view when a book is selected. The details view
+		// and the file browser hand the book over, so they close.
+		if view := m.currentView(); view == ViewDetails || view == ViewFiles {
+			m.pop()
+		}
+		m.push(ViewReader)
 		m.reader.LoadBook(msg.Book)
+		m.reader.startSession()
+		cmd := tea.Batch(m.reader.startReminder(), m.reader.tickClock(), m.reader.prerenderChapters(), m.reader.loadSearchIndex())
+		if m.config.Reading.Focus {
+			cmd = tea.Batch(cmd, m.reader.startFocus())
+		}
+		cmd = tea.Batch(cmd, runHook(m.config, hookEvent{Event: hookBookOpened, Book: m.reader.hookBook()}))
+		if _, ok := m.library.findItem(msg.Book.Path); !ok {
+			cmd = tea.Batch(cmd, m.files.remember(msg.Book.Path))
+		}
+
+		// Remember the book so the next session can resume it
+		if m.config.Reading.CurrentBook != msg.Book.Path {
+			m.config.Reading.CurrentBook = msg.Book.Path
+			if err := config.Save(m.config); err != nil {
+				cmd = tea.Batch(cmd, notify(toastError, "Could not save config: %v", err))
+			}
+		}
+		return m, cmd
+
+	case BookDetailsMsg:
+		// Show the details view for the selected book
+		m.details.SetBook(msg.Book, msg.Progress, msg.HasProgress)
+		return m, m.push(ViewDetails)
+
+	case HelpMsg:
+		m.help.Show(m.helpSections(), msg.Query)
+		return m, m.push(ViewHelp)
+
+	case JournalMsg:
+		cmd := m.journal.Show(msg.Path, msg.Title)
+		return m, tea.Batch(cmd, m.push(ViewJournal))
+
+	case journalEditedMsg:
+		_, cmd := m.journal.Update(msg)
+		return m, cmd
+
+	case FilesMsg:
+		if m.config.Restricted.Enabled {
+			return m, notify(toastError, "The file browser is off in restricted mode")
+		}
+		m.files.Show(msg.Dir)
+		return m, m.push(ViewFiles)
+
+	case openFileMsg:
+		_, cmd, err := m.openFile(msg.path)
+		if err != nil {
+			return m, notify(toastError, "Could not open %s: %v", msg.path, err)
+		}
+		return m, cmd
+
+	case BackMsg:
+		return m, m.pop()
+
+	case BooksLoadedMsg, CoversLoadedMsg, libraryChangedMsg, libraryRescanMsg:
+		// The library keeps itself up to date while another view is shown
+		libModel, cmd := m.library.Update(msg)
+		m.library = libModel.(*LibraryModel)
+		if msg, ok := msg.(BooksLoadedMsg); ok && msg.Error != nil {
+			cmd = tea.Batch(cmd, m.addToast(trf("Could not load library: %v", msg.Error), toastError))
+		}
+		return m, tea.Batch(cmd, m.resumeBook())
+
+	case BookFinishedMsg:
+		m.askReview(msg.Path, m.reader.book.Title)
+		hook := runHook(m.config, hookEvent{Event: hookBookFinished, Book: m.reader.hookBook()})
+		return m, tea.Batch(hook, m.offerNextBook(msg.Path))
+
+	case remoteRequestMsg:
+		return m, m.answerRemote(msg)
+
+	case progressImportedMsg:
+		return m, m.applyImportedProgress(msg)
+
+	case bookFetchedMsg:
+		return m, m.applyFetchedBook(msg)
+
+	case ao3CheckedMsg:
+		return m, m.applyAO3Check(msg)
+
+	case digestMsg:
+		return m, m.applyDigest(msg)
+
+	case mailTickMsg:
+		return m, m.checkMail(false)
+
+	case mailCheckedMsg:
+		return m, m.applyMailCheck(msg)
+
+	case webdavSyncedMsg:
+		return m, m.applyWebDAVSync(msg)
+
+	case reviewPromptMsg:
+		m.askReview(msg.path, msg.title)
+		return m, nil
+
+	case BookLoadErrorMsg:
+		return m, m.addToast(trf("Could not open book: %v", msg.Error), toastError)
+
+	case BookSentMsg:
+		kind := toastSuccess
+		if msg.Error != nil {
+			kind = toastError
+		}
+		return m, m.addToast(msg.Status(), kind)
+
+	case configChangedMsg, configReloadMsg:
+		return m, m.updateConfigWatch(msg)
+
+	case searchIndexMsg:
+		// Kept even when the reader has been left meanwhile
+		if m.reader.book != nil && m.reader.book.Path == msg.path {
+			m.reader.index = msg.index
+		}
 		return m, nil
 
-	case BackToLibraryMsg:
-		// Return to library view
-		m.currentView = ViewLibrary
+	case toastMsg:
+		return m, m.addToast(msg.text, msg.kind)
+
+	case toastExpiredMsg:
+		m.dismissToast(msg.id)
 		return m, nil
 	}
 
-	// Route updates to the current view
-	var cmd tea.Cmd
-	switch m.currentView {
+	return m, tea.Batch(m.updateView(msg), m.resumeBook())
+}
+
+// resumeBook opens the last book read once the library has loaded. The
+// reader needs the screen size to restore the scroll position, so it also
+// waits for that.
+func (m *Model) resumeBook() tea.Cmd {
+	if !m.resume || m.width == 0 || !m.library.loaded {
+		return nil
+	}
+	m.resume = false
+
+	item, ok := m.library.findItem(m.config.Reading.CurrentBook)
+	if !ok {
+		return nil
+	}
+	return m.library.openBook(item)
+}
+
+// capturesInput reports whether the current view is taking text input
+func (m Model) capturesInput() bool {
+	switch m.currentView() {
 	case ViewLibrary:
-		libModel, libCmd := m.library.Update(msg)
-		m.library = libModel.(*LibraryModel)
-		cmd = libCmd
+		return m.library.list.FilterState() == list.Filtering
 	case ViewReader:
-		readerModel, readerCmd := m.reader.Update(msg)
-		m.reader = readerModel.(*ReaderModel)
-		cmd = readerCmd
+		return m.reader.lookup.prompt || m.reader.picker.active || m.reader.find.active
+	case ViewHelp:
+		return m.help.searching
+	case ViewJournal:
+		return true
 	}
-
-	return m, cmd
+	return false
 }
 
 // View renders the current view
 func (m Model) View() string {
 	if m.err != nil {
-		return "Error: " + m.err.Error() + "\n\nPress q to quit."
+		return trf("Error: %v", m.err) + "\n\n" + tr("Press q to quit.")
 	}
 
-	switch m.currentView {
-	case ViewLibrary:
-		return m.library.View()
-	case ViewReader:
-		return m.reader.View()
-	default:
-		return "Unknown view"
+	view := m.screen(m.currentView()).View()
+	if m.summary != nil {
+		view = placeOverlay(view, m.summaryView(), m.width)
 	}
+	if m.review != nil {
+		view = placeOverlay(view, m.reviewView(), m.width)
+	}
+	return deleteKittyImages(resetLineSizes(m.withToasts(m.withCommandLine(view))))
 }
 
 // Messages for inter-view communication
@@ -109,4 +385,8 @@ type BookSelectedMsg struct {
 	Book *ebook.Book
 }
 
-type BackToLibraryMsg struct{}
+type BookDetailsMsg struct {
+	Book        *ebook.Book
+	Progress    config.BookProgress
+	HasProgress bool
+}
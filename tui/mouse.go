@@ -0,0 +1,200 @@
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Screen rows above the content of the library views
+const (
+	libraryTop    = 3 // App title with its padding
+	galleryHeader = 2 // Gallery header and blank line
+)
+
+// updateMouse handles wheel scrolling, link clicks and drag selection in
+// the reader
+func (m *ReaderModel) updateMouse(msg tea.MouseMsg) tea.Cmd {
+	if m.reminder.shown || m.picker.active {
+		return nil
+	}
+
+	row := msg.Y - m.textTop()
+	col := msg.X - m.textLeft()
+	if renderOptions(m.config).DoubleWidth() {
+		col /= 2
+	}
+	line := m.viewport.YOffset + row
+	inText := row >= 0 && row < m.viewport.Height && line < len(m.lines)
+
+	switch msg.Action {
+	case tea.MouseActionPress:
+		if msg.Button != tea.MouseButtonLeft {
+			break
+		}
+
+		// Clicking anywhere closes the definition overlay
+		if m.lookup.result != nil {
+			m.lookup.result = nil
+			return nil
+		}
+		if !inText {
+			return nil
+		}
+
+		if link, ok := m.linkAt(line, col); ok {
+			m.followLink(link)
+			return nil
+		}
+
+		// Start a selection; it becomes visible once the mouse moves
+		m.lookup.active = false
+		m.dragging = true
+		m.dragLine, m.dragCol = line, col
+		return nil
+
+	case tea.MouseActionMotion:
+		if !m.dragging || !inText {
+			return nil
+		}
+		anchor, ok := m.wordAt(m.dragLine, m.dragCol)
+		if !ok {
+			return nil
+		}
+		current, ok := m.wordAt(line, col)
+		if !ok {
+			return nil
+		}
+
+		// Dragging selects like the word cursor, so the selection can be
+		// highlighted or looked up with the usual keys
+		m.lookup.active = true
+		m.lookup.selecting = true
+		m.lookup.anchorLine, m.lookup.anchorWord = m.dragLine, anchor
+		m.lookup.line, m.lookup.word = line, current
+		return nil
+
+	case tea.MouseActionRelease:
+		m.dragging = false
+		return nil
+	}
+
+	// Wheel scrolls the definition overlay when it is open
+	if m.lookup.result != nil {
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			m.lookup.scroll = max(0, m.lookup.scroll-1)
+		case tea.MouseButtonWheelDown:
+			m.lookup.scroll++
+		}
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return cmd
+}
+
+// wordAt returns the index of the word at or nearest after a column
+func (m *ReaderModel) wordAt(line, col int) (int, bool) {
+	if line < 0 || line >= len(m.lines) {
+		return 0, false
+	}
+	words := lineWords(m.lines[line])
+	if len(words) == 0 {
+		return 0, false
+	}
+	for i, word := range words {
+		if col < word.end {
+			return i, true
+		}
+	}
+	return len(words) - 1, true
+}
+
+// linkAt returns the link under a screen position in the text
+func (m *ReaderModel) linkAt(line, col int) (string, bool) {
+	for _, link := range m.links {
+		if link.Line == line && col >= link.StartCol && col < link.EndCol {
+			return link.Href, true
+		}
+	}
+	return "", false
+}
+
+// followLink jumps to the target of an internal link, such as a footnote
+func (m *ReaderModel) followLink(href string) {
+	chapter, fragment, ok := m.book.ResolveLink(m.currentChapter, href)
+	if !ok {
+		m.status = trf("External link: %s", href)
+		return
+	}
+
+	if chapter != m.currentChapter {
+		m.currentChapter = chapter
+		m.updateViewport()
+	}
+	if line, ok := m.anchors[fragment]; ok && fragment != "" {
+		m.viewport.SetYOffset(line)
+	}
+}
+
+// updateMouse handles wheel scrolling and clicks in the library. Clicking
+// the selected book opens it.
+func (m *LibraryModel) updateMouse(msg tea.MouseMsg) tea.Cmd {
+	if m.list.FilterState() == list.Filtering {
+		return nil
+	}
+
+	step := 1
+	if m.gallery {
+		step = m.galleryColumns()
+	}
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.selectIndex(m.list.Index() - step)
+	case tea.MouseButtonWheelDown:
+		m.selectIndex(m.list.Index() + step)
+	case tea.MouseButtonLeft:
+		if msg.Action != tea.MouseActionPress {
+			return nil
+		}
+		index, ok := m.itemAt(msg.X, msg.Y)
+		if !ok {
+			return nil
+		}
+		if index == m.list.Index() {
+			if item, ok := m.list.SelectedItem().(bookItem); ok {
+				return m.openBook(item)
+			}
+		}
+		m.selectIndex(index)
+	}
+	return nil
+}
+
+// itemAt returns the index of the visible item at a screen position
+func (m *LibraryModel) itemAt(x, y int) (int, bool) {
+	var index int
+	if m.gallery {
+		row := (y - libraryTop - galleryHeader) / galleryCellHeight
+		col := x / galleryCellWidth
+		if y < libraryTop+galleryHeader || col >= m.galleryColumns() {
+			return 0, false
+		}
+		index = (m.galleryTop+row)*m.galleryColumns() + col
+	} else {
+		// Items start below the list's title bar
+		top := libraryTop + m.list.Styles.TitleBar.GetVerticalFrameSize() + 1
+		offset := y - top
+		if offset < 0 || offset%m.itemHeight >= m.itemHeight-m.itemSpacing {
+			return 0, false
+		}
+		index = m.list.Paginator.Page*m.list.Paginator.PerPage + offset/m.itemHeight
+	}
+
+	if index >= len(m.list.VisibleItems()) {
+		return 0, false
+	}
+	return index, true
+}
@@ -0,0 +1,43 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// placeOverlay draws fg centered on top of bg, which is width cells wide.
+// The background stays visible around the overlay.
+func placeOverlay(bg, fg string, width int) string {
+	x := max(0, (width-lipgloss.Width(fg))/2)
+	y := max(0, (strings.Count(bg, "\n")-strings.Count(fg, "\n"))/2)
+	return overlayAt(bg, fg, x, y)
+}
+
+// overlayAt draws fg on top of bg with its top left corner at column x
+// and row y
+func overlayAt(bg, fg string, x, y int) string {
+	bgLines := strings.Split(bg, "\n")
+	fgLines := strings.Split(fg, "\n")
+
+	for i, fgLine := range fgLines {
+		row := y + i
+		if row >= len(bgLines) {
+			break
+		}
+		// Rows under the overlay are drawn at normal size so it fits
+		bgLine := lineSizes.Replace(bgLines[row])
+
+		left := ansi.Truncate(bgLine, x, "")
+		if w := ansi.StringWidth(left); w < x {
+			left += strings.Repeat(" ", x-w)
+		}
+		right := ansi.TruncateLeft(bgLine, x+ansi.StringWidth(fgLine), "")
+
+		// Reset styles so the background does not bleed into the overlay
+		bgLines[row] = left + ansi.ResetStyle + fgLine + ansi.ResetStyle + right
+	}
+
+	return strings.Join(bgLines, "\n")
+}
@@ -0,0 +1,46 @@
+package tui
+
+import "github.com/cbrasser/cozy/ebook"
+
+// Reading positions are saved as the number of letters and digits before
+// the top line of the view, see ebook.TextOffsets
+
+// indexLines records how many letters come before each rendered line
+func (m *ReaderModel) indexLines() {
+	m.lineOffsets = ebook.TextOffsets(m.lines)
+}
+
+// textOffset returns the position of the top line in the chapter text
+func (m *ReaderModel) textOffset() int {
+	start := m.chapterStart()
+	if m.viewport.YOffset >= len(m.lineOffsets) || start >= len(m.lineOffsets) {
+		return 0
+	}
+	return max(m.lineOffsets[m.viewport.YOffset]-m.lineOffsets[start], 0)
+}
+
+// gotoTextOffset scrolls to the line containing a text position
+func (m *ReaderModel) gotoTextOffset(offset int) {
+	m.viewport.SetYOffset(m.textOffsetLine(offset))
+}
+
+// textOffsetLine returns the line containing a position in the chapter
+// text
+func (m *ReaderModel) textOffsetLine(offset int) int {
+	start, end := m.chapterLines()
+	if start >= len(m.lineOffsets) {
+		return 0
+	}
+	line := ebook.LineAtTextOffset(m.lineOffsets, offset+m.lineOffsets[start])
+	return max(min(line, end-1), start)
+}
+
+// restorePosition scrolls to a saved position. Progress saved before text
+// offsets existed only has the line offset.
+func (m *ReaderModel) restorePosition(scrollOffset, textOffset int) {
+	if textOffset > 0 {
+		m.gotoTextOffset(textOffset)
+		return
+	}
+	m.viewport.SetYOffset(m.chapterStart() + scrollOffset)
+}
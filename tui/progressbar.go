@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"math"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// With display.progress_bar the footer shows where the reader is as bars:
+// the status line fills up with the chapter read so far, and the rule
+// above it with the whole book, with a tick where each chapter starts.
+// Read parts are drawn heavy as well as colored, so they stay apart
+// without colors.
+
+// Narrowest chapter bar worth drawing
+const minProgressBar = 10
+
+// progressBarShown reports whether the footer draws progress bars
+func (m *ReaderModel) progressBarShown() bool {
+	return m.config.Display.ProgressBar && !screenReader
+}
+
+// progressFooter puts the chapter bar after the status text, filling the
+// rest of the footer
+func (m *ReaderModel) progressFooter(progress string) string {
+	width := m.width - 2 - ansi.StringWidth(progress) - 2 // Padding and gap
+	if width < minProgressBar {
+		return progress
+	}
+	filled := int(math.Round(m.chapterPercent() * float64(width)))
+	return progress + "  " + m.drawBar(strings.Repeat("━", filled), strings.Repeat("─", width-filled))
+}
+
+// bookBar draws the rule above the footer as a bar of the whole book, with
+// chapters as long as their text
+func (m *ReaderModel) bookBar(width int) string {
+	total := 0
+	for _, chapter := range m.book.Chapters {
+		total += chapter.Length()
+	}
+	if total == 0 || width <= 0 {
+		return rule(width)
+	}
+
+	// Columns where chapters after the first start
+	ticks := make(map[int]bool)
+	start := 0
+	for _, chapter := range m.book.Chapters[:len(m.book.Chapters)-1] {
+		start += chapter.Length()
+		if column := start * width / total; column > 0 && column < width {
+			ticks[column] = true
+		}
+	}
+
+	filled := int(math.Round(m.bookPercent() / 100 * float64(width)))
+	var read, unread strings.Builder
+	for column := range width {
+		switch {
+		case column < filled && ticks[column]:
+			read.WriteString("┷")
+		case column < filled:
+			read.WriteString("━")
+		case ticks[column]:
+			unread.WriteString("┴")
+		default:
+			unread.WriteString("─")
+		}
+	}
+	return m.drawBar(read.String(), unread.String())
+}
+
+// drawBar colors the read and unread parts of a bar
+func (m *ReaderModel) drawBar(read, unread string) string {
+	theme := m.config.ActiveTheme
+	readStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.PrimaryColor))
+	unreadStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.MutedTextColor))
+	return readStyle.Render(read) + unreadStyle.Render(unread)
+}
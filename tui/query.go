@@ -0,0 +1,176 @@
+package tui
+
+import (
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// libraryQuery is a library filter such as "tag:scifi author:le-guin
+// finished:no sort:progress". Words without a known key are matched
+// fuzzily against title, author and tags, as a plain filter is.
+type libraryQuery struct {
+	fields   map[string][]string // Values by key, all of which must match
+	finished *bool
+	queued   *bool
+	rating   int // Least stars
+	sort     string
+	text     string // The words without a key
+}
+
+// Keys matched by substring against a book's fields. Hyphens in values
+// stand for spaces, so author:le-guin finds Ursula K. Le Guin.
+var queryFields = map[string]func(bookItem) []string{
+	"tag":    func(i bookItem) []string { return i.tags },
+	"author": func(i bookItem) []string { return []string{i.author} },
+	"title":  func(i bookItem) []string { return []string{i.title} },
+	"series": func(i bookItem) []string { return []string{i.metadata["series"]} },
+	"format": func(i bookItem) []string { return []string{strings.TrimPrefix(filepath.Ext(i.path), ".")} },
+}
+
+// Orders for sort:, each putting a before b
+var querySorts = map[string]func(a, b bookItem) bool{
+	"title":    func(a, b bookItem) bool { return strings.ToLower(a.title) < strings.ToLower(b.title) },
+	"author":   func(a, b bookItem) bool { return strings.ToLower(a.author) < strings.ToLower(b.author) },
+	"progress": func(a, b bookItem) bool { return a.completion > b.completion },
+	"rating":   func(a, b bookItem) bool { return a.rating() > b.rating() },
+	"queue":    func(a, b bookItem) bool { return a.queued > 0 && (b.queued == 0 || a.queued < b.queued) },
+}
+
+// parseQuery splits a filter into its keys and free text. Tokens with an
+// unknown key or an invalid value are free text, so titles like "Re:Zero"
+// still match.
+func parseQuery(term string) libraryQuery {
+	q := libraryQuery{fields: make(map[string][]string)}
+	var words []string
+	for _, token := range strings.Fields(term) {
+		key, value, ok := strings.Cut(token, ":")
+		key = strings.ToLower(key)
+		if !ok || value == "" || !q.set(key, value) {
+			words = append(words, token)
+		}
+	}
+	q.text = strings.Join(words, " ")
+	return q
+}
+
+// set applies one key:value token, reporting whether it is one
+func (q *libraryQuery) set(key, value string) bool {
+	if _, ok := queryFields[key]; ok {
+		q.fields[key] = append(q.fields[key], normalizeQuery(value))
+		return true
+	}
+	switch key {
+	case "finished", "queued":
+		var b bool
+		if parseSwitch(strings.ToLower(value), &b) != nil {
+			return false
+		}
+		if key == "finished" {
+			q.finished = &b
+		} else {
+			q.queued = &b
+		}
+	case "rating":
+		stars, err := strconv.Atoi(strings.TrimPrefix(value, ">="))
+		if err != nil || stars < 1 || stars > 5 {
+			return false
+		}
+		q.rating = stars
+	case "sort":
+		if _, ok := querySorts[strings.ToLower(value)]; !ok {
+			return false
+		}
+		q.sort = strings.ToLower(value)
+	default:
+		return false
+	}
+	return true
+}
+
+// structured reports whether the query has any keys, rather than only
+// free text
+func (q libraryQuery) structured() bool {
+	return len(q.fields) > 0 || q.finished != nil || q.queued != nil || q.rating > 0 || q.sort != ""
+}
+
+// matches reports whether a book satisfies every key of the query
+func (q libraryQuery) matches(item bookItem) bool {
+	for key, values := range q.fields {
+		for _, value := range values {
+			if !containsNormalized(queryFields[key](item), value) {
+				return false
+			}
+		}
+	}
+	if q.finished != nil && item.finished != *q.finished {
+		return false
+	}
+	if q.queued != nil && (item.queued > 0) != *q.queued {
+		return false
+	}
+	return item.rating() >= q.rating
+}
+
+func containsNormalized(fields []string, value string) bool {
+	for _, field := range fields {
+		if strings.Contains(normalizeQuery(field), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeQuery lowercases text and turns hyphens and underscores into
+// spaces
+func normalizeQuery(s string) string {
+	return strings.ToLower(strings.NewReplacer("-", " ", "_", " ").Replace(s))
+}
+
+// rating returns the reader's own rating of a book, or else the one in
+// its metadata
+func (i bookItem) rating() int {
+	if i.review.Rating > 0 {
+		return i.review.Rating
+	}
+	stars, _ := strconv.Atoi(i.metadata["rating"])
+	return stars
+}
+
+// queryFilter returns the list filter for a set of items. Plain terms are
+// matched fuzzily as before; queries with keys also select and order the
+// books by their fields.
+func queryFilter(items []list.Item) list.FilterFunc {
+	return func(term string, targets []string) []list.Rank {
+		q := parseQuery(term)
+		if !q.structured() {
+			return list.DefaultFilter(term, targets)
+		}
+
+		var ranks []list.Rank
+		if q.text != "" {
+			ranks = list.DefaultFilter(q.text, targets)
+		} else {
+			for i := range targets {
+				ranks = append(ranks, list.Rank{Index: i})
+			}
+		}
+
+		matched := ranks[:0]
+		for _, rank := range ranks {
+			if item, ok := items[rank.Index].(bookItem); ok && q.matches(item) {
+				matched = append(matched, rank)
+			}
+		}
+
+		if less, ok := querySorts[q.sort]; ok {
+			sort.SliceStable(matched, func(a, b int) bool {
+				return less(items[matched[a].Index].(bookItem), items[matched[b].Index].(bookItem))
+			})
+		}
+		return matched
+	}
+}
@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// toggleQueue adds the selected book to the end of the up next queue, or
+// takes it out
+func (m *LibraryModel) toggleQueue(item bookItem) tea.Cmd {
+	if item.err != nil {
+		return notify(toastError, "Can't queue %s: %v", item.title, item.err)
+	}
+	added := m.queue.Toggle(item.path)
+	if err := config.SaveQueue(m.config, m.queue); err != nil {
+		return notify(toastError, "Could not save the queue: %v", err)
+	}
+	m.updateItems()
+	if added {
+		return notify(toastInfo, "Queued %s (#%d)", item.title, len(m.queue.Books))
+	}
+	return notify(toastInfo, "Removed %s from the queue", item.title)
+}
+
+// moveInQueue moves the selected book up (negative delta) or down the
+// queue, keeping it selected
+func (m *LibraryModel) moveInQueue(item bookItem, delta int) tea.Cmd {
+	if !m.queue.Move(item.path, delta) {
+		return nil
+	}
+	if err := config.SaveQueue(m.config, m.queue); err != nil {
+		return notify(toastError, "Could not save the queue: %v", err)
+	}
+	m.updateItems()
+	return nil
+}
+
+// dequeue takes a book out of the queue once it is finished or deleted
+func (m *LibraryModel) dequeue(path string) {
+	if m.queue.Remove(path) {
+		config.SaveQueue(m.config, m.queue)
+	}
+}
+
+// queuedItems orders the queued books by their place in the queue.
+// Queued books missing from the library are left out.
+func (m *LibraryModel) queuedItems(queued map[string]bookItem) []list.Item {
+	items := make([]list.Item, 0, len(queued))
+	for _, path := range m.queue.Books {
+		if item, ok := queued[path]; ok {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// nextQueued returns the first queued book that can be opened, other than
+// the given one
+func (m *LibraryModel) nextQueued(path string) (bookItem, bool) {
+	for _, queued := range m.queue.Books {
+		if queued == path {
+			continue
+		}
+		if item, ok := m.findItem(queued); ok && item.err == nil {
+			return item, true
+		}
+	}
+	return bookItem{}, false
+}
+
+// offerNextBook takes a finished book out of the queue and asks whether to
+// open the next queued book, or else the next book of its series
+func (m *Model) offerNextBook(path string) tea.Cmd {
+	m.library.dequeue(path)
+	m.library.updateItems()
+
+	next, ok := m.library.nextQueued(path)
+	if !ok {
+		return m.offerNextInSeries(path)
+	}
+	return m.offerBook(trf("Open the next book in your queue, %s?", next.title), next)
+}
+
+// queueSummary lists the queued books in order
+func (m *LibraryModel) queueSummary() string {
+	var titles []string
+	for _, path := range m.queue.Books {
+		if item, ok := m.findItem(path); ok {
+			titles = append(titles, fmt.Sprintf("%d. %s", len(titles)+1, item.title))
+		}
+	}
+	if len(titles) == 0 {
+		return tr("The queue is empty; press a on a book to add it")
+	}
+	return trf("Up next: %s", strings.Join(titles, "; "))
+}
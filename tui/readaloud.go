@@ -0,0 +1,407 @@
+package tui
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cbrasser/cozy/ebook"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Books with media overlays can be read aloud: the recording of the
+// chapter plays through reading.audio_player while the element being read
+// stays lit and the rest of the page is dimmed, like the reading ruler.
+// The audio is copied out of the book into the temp dir for the player,
+// which is started again at each clip that doesn't run on from the one
+// before. Playback follows the overlays into the next chapters.
+
+// Clips closer than this in the same audio file play on without starting
+// the player again
+const clipGap = 50 * time.Millisecond
+
+// readAloudKeyMap defines key bindings while reading aloud
+type readAloudKeyMap struct {
+	Pause    key.Binding
+	Previous key.Binding
+	Next     key.Binding
+	Exit     key.Binding
+}
+
+func (k readAloudKeyMap) ShortHelp() []key.Binding {
+	return localized(k.Pause, k.Previous, k.Next, k.Exit)
+}
+
+func (k readAloudKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+var readAloudKeys = readAloudKeyMap{
+	Pause: key.NewBinding(
+		key.WithKeys(" ", "enter"),
+		key.WithHelp("space", "pause"),
+	),
+	Previous: key.NewBinding(
+		key.WithKeys("h", "left", "k", "up"),
+		key.WithHelp("h/←", "previous passage"),
+	),
+	Next: key.NewBinding(
+		key.WithKeys("l", "right", "j", "down"),
+		key.WithHelp("l/→", "next passage"),
+	),
+	Exit: key.NewBinding(
+		key.WithKeys("esc", "L"),
+		key.WithHelp("esc", "back to the page"),
+	),
+}
+
+// readAloudState tracks playing the read-aloud audio of the current
+// chapter
+type readAloudState struct {
+	active  bool
+	paused  bool
+	overlay *ebook.MediaOverlay
+	clip    int           // Clip being read
+	player  *exec.Cmd     // Running audio player, nil while starting or paused
+	started time.Time     // When the player started
+	from    time.Duration // Position in the audio file the player started at
+	resume  time.Duration // Position to resume at after a pause
+	timer   int           // Identifies the current player so stale messages are ignored
+}
+
+// readAloudStartedMsg reports the audio player started, or why it didn't
+type readAloudStartedMsg struct {
+	timer  int
+	player *exec.Cmd
+	err    error
+}
+
+// readAloudClipMsg is sent when the current clip is over
+type readAloudClipMsg struct {
+	timer int
+}
+
+// readAloudEndedMsg is sent when the audio player exits
+type readAloudEndedMsg struct {
+	timer int
+	err   error
+}
+
+// startReadAloud starts reading the chapter aloud from the top of the page
+func (m *ReaderModel) startReadAloud() tea.Cmd {
+	overlay, err := m.book.MediaOverlay(m.currentChapter)
+	if err != nil {
+		m.status = err.Error()
+		return nil
+	}
+	if overlay == nil || len(overlay.Clips) == 0 {
+		m.status = tr("This chapter has no read-aloud audio")
+		return nil
+	}
+
+	m.readAloud = readAloudState{active: true, overlay: overlay, timer: m.readAloud.timer}
+	for i, clip := range overlay.Clips {
+		if line, ok := m.anchors[clip.Fragment]; ok && line >= m.viewport.YOffset {
+			m.readAloud.clip = i
+			break
+		}
+	}
+	return m.playClip(m.currentClip().Begin)
+}
+
+// stopReadAloud stops the player and goes back to the page where it was
+func (m *ReaderModel) stopReadAloud() {
+	m.stopPlayer()
+	m.readAloud.active = false
+}
+
+// stopPlayer stops the audio player, if it is running
+func (m *ReaderModel) stopPlayer() {
+	m.readAloud.timer++
+	if m.readAloud.player != nil {
+		m.readAloud.player.Process.Kill()
+		m.readAloud.player = nil
+	}
+}
+
+// currentClip returns the clip being read
+func (m *ReaderModel) currentClip() ebook.AudioClip {
+	return m.readAloud.overlay.Clips[m.readAloud.clip]
+}
+
+// playerPosition returns how far into its audio file the player is
+func (m *ReaderModel) playerPosition() time.Duration {
+	if m.readAloud.player == nil {
+		return m.readAloud.resume
+	}
+	return m.readAloud.from + time.Since(m.readAloud.started)
+}
+
+// playClip starts the player on the audio of the current clip, at a
+// position in its file. The audio is copied out of the book first.
+func (m *ReaderModel) playClip(at time.Duration) tea.Cmd {
+	m.stopPlayer()
+	m.readAloud.from = at
+	m.scrollToClip()
+
+	timer := m.readAloud.timer
+	book, audio, command := m.book, m.currentClip().Audio, m.config.Reading.AudioPlayer
+	return func() tea.Msg {
+		file, err := extractAudio(book, audio)
+		if err != nil {
+			return readAloudStartedMsg{timer: timer, err: err}
+		}
+		player, err := startPlayer(command, file, at)
+		return readAloudStartedMsg{timer: timer, player: player, err: err}
+	}
+}
+
+// playerStarted keeps track of a player that started, and waits for the
+// end of the clip and for the player to exit
+func (m *ReaderModel) playerStarted(msg readAloudStartedMsg) tea.Cmd {
+	if msg.timer != m.readAloud.timer || !m.readAloud.active {
+		if msg.player != nil {
+			msg.player.Process.Kill()
+			go msg.player.Wait()
+		}
+		return nil
+	}
+	if msg.err != nil {
+		m.stopReadAloud()
+		return notify(toastError, "Could not play the audio: %v", msg.err)
+	}
+
+	m.readAloud.player = msg.player
+	m.readAloud.started = time.Now()
+	player, timer := msg.player, msg.timer
+	wait := func() tea.Msg {
+		return readAloudEndedMsg{timer: timer, err: player.Wait()}
+	}
+	return tea.Batch(wait, m.clipTick())
+}
+
+// clipTick waits for the end of the current clip. Clips that run to the
+// end of their file wait for the player to exit instead.
+func (m *ReaderModel) clipTick() tea.Cmd {
+	clip := m.currentClip()
+	if clip.End <= clip.Begin {
+		return nil
+	}
+	timer := m.readAloud.timer
+	return tea.Tick(max(clip.End-m.playerPosition(), 0), func(time.Time) tea.Msg {
+		return readAloudClipMsg{timer: timer}
+	})
+}
+
+// playerEnded moves on when the player reaches the end of its file, and
+// reports a player that failed
+func (m *ReaderModel) playerEnded(msg readAloudEndedMsg) tea.Cmd {
+	if msg.timer != m.readAloud.timer || !m.readAloud.active {
+		return nil
+	}
+	m.readAloud.player = nil
+	if msg.err != nil {
+		m.stopReadAloud()
+		return notify(toastError, "Audio player failed: %v", msg.err)
+	}
+	return m.nextClip()
+}
+
+// stepReadAloud moves on to the next clip when the current one is over
+func (m *ReaderModel) stepReadAloud(msg readAloudClipMsg) tea.Cmd {
+	if msg.timer != m.readAloud.timer || !m.readAloud.active || m.readAloud.paused {
+		return nil
+	}
+	return m.nextClip()
+}
+
+// nextClip moves on to the next clip, and to the next chapter with read-
+// aloud audio at the end of a chapter. The player keeps playing when the
+// clip runs on from the one before.
+func (m *ReaderModel) nextClip() tea.Cmd {
+	previous := m.currentClip()
+	m.readAloud.clip++
+	if m.readAloud.clip >= len(m.readAloud.overlay.Clips) {
+		if !m.nextOverlay() {
+			m.stopReadAloud()
+			m.status = tr("End of the read-aloud audio")
+			return nil
+		}
+		return m.playClip(m.currentClip().Begin)
+	}
+
+	clip := m.currentClip()
+	if m.readAloud.player != nil && clip.Audio == previous.Audio &&
+		(clip.Begin-previous.End).Abs() < clipGap {
+		m.scrollToClip()
+		return m.clipTick()
+	}
+	return m.playClip(clip.Begin)
+}
+
+// nextOverlay opens the next chapter with read-aloud audio
+func (m *ReaderModel) nextOverlay() bool {
+	for chapter := m.currentChapter + 1; chapter < m.book.ChapterCount(); chapter++ {
+		overlay, err := m.book.MediaOverlay(chapter)
+		if err != nil || overlay == nil || len(overlay.Clips) == 0 {
+			continue
+		}
+		m.currentChapter = chapter
+		m.updateViewport()
+		m.readAloud.overlay = overlay
+		m.readAloud.clip = 0
+		return true
+	}
+	return false
+}
+
+// updateReadAloud handles keys while reading aloud
+func (m *ReaderModel) updateReadAloud(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, readAloudKeys.Exit):
+		m.stopReadAloud()
+	case key.Matches(msg, readAloudKeys.Pause):
+		if m.readAloud.paused {
+			m.readAloud.paused = false
+			return m.playClip(m.readAloud.resume)
+		}
+		m.readAloud.resume = m.playerPosition()
+		m.readAloud.paused = true
+		m.stopPlayer()
+	case key.Matches(msg, readAloudKeys.Previous):
+		m.readAloud.clip = max(m.readAloud.clip-1, 0)
+		m.readAloud.paused = false
+		return m.playClip(m.currentClip().Begin)
+	case key.Matches(msg, readAloudKeys.Next):
+		m.readAloud.paused = false
+		m.stopPlayer()
+		return m.nextClip()
+	}
+	return nil
+}
+
+// clipLines returns the lines of the element being read, from its anchor
+// to the line before the next clip's, or -1 if it isn't on the page
+func (m *ReaderModel) clipLines() (int, int) {
+	first, ok := m.anchors[m.currentClip().Fragment]
+	if !ok {
+		return -1, -1
+	}
+	_, last := m.chapterLines()
+	for _, clip := range m.readAloud.overlay.Clips[m.readAloud.clip+1:] {
+		if line, ok := m.anchors[clip.Fragment]; ok && line > first {
+			last = line
+			break
+		}
+	}
+	last = min(last-1, first+m.viewport.Height-1)
+	for last > first && strings.TrimSpace(ansi.Strip(m.lines[last])) == "" {
+		last--
+	}
+	return first, max(last, first)
+}
+
+// scrollToClip scrolls the element being read into view
+func (m *ReaderModel) scrollToClip() {
+	first, last := m.clipLines()
+	if first < 0 {
+		return
+	}
+	if top := m.viewport.YOffset; first < top || last >= top+m.viewport.Height {
+		m.viewport.SetYOffset(first)
+	}
+}
+
+// applyReadAloud dims the visible lines but those being read
+func (m *ReaderModel) applyReadAloud(content string) string {
+	if !m.readAloud.active {
+		return content
+	}
+	first, last := m.clipLines()
+	if first < 0 {
+		return content
+	}
+	first, last = first-m.viewport.YOffset, last-m.viewport.YOffset
+	dim := lipgloss.NewStyle().Faint(true).Foreground(lipgloss.Color(m.config.ActiveTheme.MutedTextColor))
+
+	rows := strings.Split(content, "\n")
+	for i, row := range rows {
+		if i < first || i > last {
+			rows[i] = lineSize(row) + dim.Render(ansi.Strip(row))
+		}
+	}
+	return strings.Join(rows, "\n")
+}
+
+// readAloudStatus describes the playback for the footer
+func (m *ReaderModel) readAloudStatus() string {
+	if m.readAloud.paused {
+		return tr("♪ paused")
+	}
+	return tr("♪ reading aloud")
+}
+
+// extractAudio copies an audio file of the book into the temp dir for the
+// player, once per book and file
+func extractAudio(book *ebook.Book, name string) (string, error) {
+	sum := sha1.Sum([]byte(book.Path + "\x00" + name))
+	target := filepath.Join(os.TempDir(), "cozy-audio", hex.EncodeToString(sum[:8])+path.Ext(name))
+	if _, err := os.Stat(target); err == nil {
+		return target, nil
+	}
+
+	source, err := book.OpenResource(name)
+	if err != nil {
+		return "", errorf("failed to open the audio: %w", err)
+	}
+	defer source.Close()
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return "", errorf("failed to copy the audio: %w", err)
+	}
+	file, err := os.CreateTemp(filepath.Dir(target), "audio-*")
+	if err != nil {
+		return "", errorf("failed to copy the audio: %w", err)
+	}
+	_, err = io.Copy(file, source)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), target)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", errorf("failed to copy the audio: %w", err)
+	}
+	return target, nil
+}
+
+// startPlayer starts the audio player command on a file, at a position
+// in it. {file} and {start}, in seconds, are filled in.
+func startPlayer(command, file string, at time.Duration) (*exec.Cmd, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, errorf("no audio player set in config.toml")
+	}
+	start := fmt.Sprintf("%.3f", at.Seconds())
+	for i, arg := range args {
+		args[i] = strings.NewReplacer("{file}", file, "{start}", start).Replace(arg)
+	}
+
+	player := exec.Command(args[0], args[1:]...)
+	player.Stdin, player.Stdout, player.Stderr = nil, io.Discard, io.Discard
+	if err := player.Start(); err != nil {
+		return nil, err
+	}
+	return player, nil
+}
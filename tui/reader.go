@@ -2,45 +2,61 @@ package tui
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/cbrasser/cozy/config"
+	"github.com/cbrasser/cozy/dictionary"
 	"github.com/cbrasser/cozy/ebook"
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/muesli/reflow/wordwrap"
+	"github.com/charmbracelet/x/ansi"
 )
 
 // readerKeyMap defines key bindings for the reader
 type readerKeyMap struct {
-	NextChapter     key.Binding
-	PrevChapter     key.Binding
-	NextHeading     key.Binding
-	PrevHeading     key.Binding
-	FirstChapter    key.Binding
-	LastChapter     key.Binding
-	ScrollUp        key.Binding
-	ScrollDown      key.Binding
-	HalfPageUp      key.Binding
-	HalfPageDown    key.Binding
-	Back            key.Binding
-	Quit            key.Binding
-	ToggleHelp      key.Binding
+	NextChapter   key.Binding
+	PrevChapter   key.Binding
+	NextHeading   key.Binding
+	PrevHeading   key.Binding
+	FirstChapter  key.Binding
+	ChapterPicker key.Binding
+	LastChapter   key.Binding
+	ScrollUp      key.Binding
+	ScrollDown    key.Binding
+	HalfPageUp    key.Binding
+	HalfPageDown  key.Binding
+	LookupWord    key.Binding
+	LookupPrompt  key.Binding
+	Find          key.Binding
+	Zen           key.Binding
+	SpeedRead     key.Binding
+	ReadAloud     key.Binding
+	Ruler         key.Binding
+	Accessible    key.Binding
+	Journal       key.Binding
+	SetMark       key.Binding
+	GotoMark      key.Binding
+	StatusDetail  key.Binding
+	Back          key.Binding
+	Quit          key.Binding
 }
 
 func (k readerKeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.NextChapter, k.PrevChapter, k.NextHeading, k.PrevHeading, k.ScrollUp, k.ScrollDown, k.Back, k.Quit}
+	return localized(k.NextChapter, k.PrevChapter, k.NextHeading, k.PrevHeading, k.ScrollUp, k.ScrollDown, k.Back, k.Quit, appKeys.Help)
 }
 
 func (k readerKeyMap) FullHelp() [][]key.Binding {
-	return [][]key.Binding{
-		{k.NextChapter, k.PrevChapter, k.NextHeading, k.PrevHeading, k.FirstChapter, k.LastChapter},
-		{k.ScrollUp, k.ScrollDown, k.HalfPageUp, k.HalfPageDown, k.Back, k.Quit},
-		{k.ToggleHelp},
-	}
+	return localizedGroups([][]key.Binding{
+		{k.NextChapter, k.PrevChapter, k.NextHeading, k.PrevHeading, k.FirstChapter, k.LastChapter, k.ChapterPicker},
+		{k.ScrollUp, k.ScrollDown, k.HalfPageUp, k.HalfPageDown, k.Back},
+		{k.Find, k.LookupWord, k.LookupPrompt, k.Zen, k.Ruler, k.Accessible, k.SpeedRead, k.ReadAloud, k.StatusDetail},
+		{k.SetMark, k.GotoMark, k.Journal},
+	})
 }
 
 var readerKeys = readerKeyMap{
@@ -68,6 +84,10 @@ var readerKeys = readerKeyMap{
 		key.WithKeys("end"),
 		key.WithHelp("end", "last chapter"),
 	),
+	ChapterPicker: key.NewBinding(
+		key.WithKeys("g"),
+		key.WithHelp("g", "go to chapter"),
+	),
 	ScrollUp: key.NewBinding(
 		key.WithKeys("up", "k"),
 		key.WithHelp("↑/k", "scroll up"),
@@ -84,6 +104,54 @@ var readerKeys = readerKeyMap{
 		key.WithKeys("J"),
 		key.WithHelp("J", "half page down"),
 	),
+	LookupWord: key.NewBinding(
+		key.WithKeys("d"),
+		key.WithHelp("d", "look up a word"),
+	),
+	LookupPrompt: key.NewBinding(
+		key.WithKeys("D"),
+		key.WithHelp("D", "type a word to look up"),
+	),
+	Find: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "find"),
+	),
+	Zen: key.NewBinding(
+		key.WithKeys("z"),
+		key.WithHelp("z", "zen mode"),
+	),
+	Ruler: key.NewBinding(
+		key.WithKeys("R"),
+		key.WithHelp("R", "reading ruler"),
+	),
+	Accessible: key.NewBinding(
+		key.WithKeys("A"),
+		key.WithHelp("A", "accessible layout"),
+	),
+	SpeedRead: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "speed reading"),
+	),
+	ReadAloud: key.NewBinding(
+		key.WithKeys("L"),
+		key.WithHelp("L", "read aloud"),
+	),
+	Journal: key.NewBinding(
+		key.WithKeys("N"),
+		key.WithHelp("N", "notes on the book"),
+	),
+	SetMark: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m<letter>", "set a mark"),
+	),
+	GotoMark: key.NewBinding(
+		key.WithKeys("'", "`"),
+		key.WithHelp("'<letter>", "jump to a mark ('' jumps back)"),
+	),
+	StatusDetail: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "minimal/verbose status"),
+	),
 	Back: key.NewBinding(
 		key.WithKeys("esc"),
 		key.WithHelp("esc", "back to library"),
@@ -92,24 +160,67 @@ var readerKeys = readerKeyMap{
 		key.WithKeys("q", "ctrl+c"),
 		key.WithHelp("q", "quit"),
 	),
-	ToggleHelp: key.NewBinding(
-		key.WithKeys("?"),
-		key.WithHelp("?", "toggle help"),
-	),
 }
 
 // ReaderModel represents the book reader view
 type ReaderModel struct {
-	config           *config.Config
-	book             *ebook.Book
-	viewport         viewport.Model
-	help             help.Model
-	keys             readerKeyMap
-	currentChapter   int
-	headingPositions []int // Line numbers of H2/H3 headings in current chapter
-	progress         *config.ProgressData
-	width            int
-	height           int
+	config         *config.Config
+	book           *ebook.Book
+	viewport       viewport.Model
+	help           help.Model
+	keys           readerKeyMap
+	currentChapter int
+	headings       []ebook.Heading // Headings of the current chapter, of every level
+	progress       *config.ProgressData
+	fileHash       string // Digest of the book file, saved with the position
+	width          int
+	height         int
+
+	// Dictionary lookup
+	dictionaries *dictionary.Set
+	online       []dictionary.Dictionary // Wiktionary and Wikipedia, when enabled
+	lines        []string                // Rendered lines of the current chapter
+	lineOffsets  []int                   // Letters before each line, see position.go
+	lookup       lookupState
+
+	// Links, anchors and highlights of the current chapter
+	links           []ebook.Link
+	anchors         map[string]int
+	highlights      *config.HighlightData
+	highlightRanges map[int][]columnRange
+
+	// Words marked as unknown, see vocabulary.go
+	vocabulary      *config.VocabularyData
+	vocabularyWords map[string]bool // Lowercase words of the open book's language
+
+	dragging          bool // Left button held for a text selection
+	dragLine, dragCol int
+	status            string // One-off message shown in the footer
+
+	reminder  reminderState
+	resume    resumeState
+	session   sessionState
+	focus     focusState
+	zen       zenState
+	rsvp      rsvpState
+	readAloud readAloudState
+	ruler     int // Line the reading ruler is on, see ruler.go
+	picker    chapterPicker
+	find      findState
+	index     *ebook.SearchIndex // Nil until loaded, see find.go
+	scroll    scrollState
+	count     int // Repeat count typed before a key, as in vim's 10j
+
+	markPending string // "m" or "'" while waiting for a mark's letter, see marks.go
+	cache       renderCache
+	window      chapterWindow // Chapters in the viewport, see continuous.go
+
+	// Footer
+	statusMinimal bool
+	chapterWords  []int // Word count per chapter, for {time_left}
+	clockTimer    int   // Identifies the current clock so stale ticks are ignored
+
+	autosaveTimer int // Identifies the pending autosave so superseded ones are ignored
 }
 
 // NewReaderModel creates a new reader model
@@ -126,12 +237,27 @@ func NewReaderModel(cfg *config.Config) *ReaderModel {
 		}
 	}
 
+	highlights, err := config.LoadHighlights(cfg)
+	if err != nil {
+		highlights = &config.HighlightData{Books: make(map[string][]config.Highlight)}
+	}
+
+	vocabulary, err := config.LoadVocabulary(cfg)
+	if err != nil {
+		vocabulary = &config.VocabularyData{Languages: make(map[string][]config.VocabularyWord)}
+	}
+
 	return &ReaderModel{
 		config:   cfg,
 		viewport: vp,
 		help:     h,
 		keys:     readerKeys,
 		progress: progress,
+
+		dictionaries: dictionary.NewSet(cfg.DictionaryPaths()),
+		online:       onlineSources(cfg),
+		highlights:   highlights,
+		vocabulary:   vocabulary,
 	}
 }
 
@@ -141,16 +267,30 @@ func (m *ReaderModel) Init() tea.Cmd {
 }
 
 // SaveProgress saves the current reading position
-func (m *ReaderModel) SaveProgress() {
-	if m.book != nil {
-		m.progress.SetBookProgress(m.book.Path, m.currentChapter, m.viewport.YOffset, m.book.ChapterCount())
-		config.SaveProgress(m.config, m.progress)
+func (m *ReaderModel) SaveProgress() error {
+	if m.book == nil {
+		return nil
 	}
+	// The saved position stays as it was until the reader decides what
+	// to do about a changed book file
+	if !m.resume.changed {
+		m.progress.SetBookProgress(m.book.Path, m.currentChapter, m.lineInChapter(m.viewport.YOffset), m.textOffset(), m.book.ChapterCount())
+		m.progress.SetPositionContext(m.book.Path, m.fileHash, m.positionContext())
+	}
+	return config.SaveProgress(m.config, m.progress)
 }
 
 // LoadBook loads a book into the reader
 func (m *ReaderModel) LoadBook(book *ebook.Book) {
+	if m.book != nil && m.book != book {
+		m.stopReadAloud()
+		m.book.Close()
+	}
 	m.book = book
+	m.chapterWords = nil
+	m.cache = renderCache{}
+	m.index = nil
+	m.vocabularyWords = m.vocabulary.Words(m.bookLanguage())
 
 	// Try to restore saved progress for this book
 	if savedProgress, exists := m.progress.GetBookProgress(book.Path); exists {
@@ -161,12 +301,14 @@ func (m *ReaderModel) LoadBook(book *ebook.Book) {
 		}
 		m.updateViewport()
 		// Restore scroll position
-		m.viewport.SetYOffset(savedProgress.ScrollOffset)
+		m.restorePosition(savedProgress.ScrollOffset, savedProgress.TextOffset)
 	} else {
 		// No saved progress, start from beginning
 		m.currentChapter = 0
 		m.updateViewport()
 	}
+	m.offerFurthest()
+	m.checkBookFile()
 }
 
 // SetSize updates the size of the reader view
@@ -174,9 +316,61 @@ func (m *ReaderModel) SetSize(width, height int) {
 	m.width = width
 	m.height = height
 	m.help.Width = width
-	m.viewport.Width = width - m.config.Display.MarginLeft - m.config.Display.MarginRight
-	m.viewport.Height = height - 6 // Account for header and footer
-	m.updateViewport()
+	m.layout()
+	m.Refresh()
+}
+
+// layout sizes the viewport for the current mode
+func (m *ReaderModel) layout() {
+	left, right := m.margins()
+	m.viewport.Width = m.width - left - right
+	if m.config.Display.Zen {
+		m.viewport.Height = m.height - 2 // Blank line and progress flash
+		return
+	}
+	m.viewport.Height = m.height - 6 // Account for header and footer
+}
+
+// Text is never squeezed narrower than this by the margins
+const minTextWidth = 20
+
+// margins returns the empty columns left and right of the text
+func (m *ReaderModel) margins() (int, int) {
+	display := m.config.Display
+	var left, right int
+	switch {
+	case display.Zen:
+		left, right = m.zenMargins()
+	case display.MarginMode == "auto":
+		// Center lines of the target length, keeping a cell of space on
+		// narrow terminals
+		text := min(display.LineLength, m.width-2)
+		left = (m.width - text) / 2
+		right = m.width - left - text
+	case display.MarginMode == "percent":
+		left, right = m.width*display.MarginLeft/100, m.width*display.MarginRight/100
+	default:
+		left, right = display.MarginLeft, display.MarginRight
+	}
+
+	// Accessible mode keeps lines short, centering the text
+	if text := m.width - left - right; display.Accessible && text > accessibleLineLength {
+		left += (text - accessibleLineLength) / 2
+		right = m.width - left - accessibleLineLength
+	}
+
+	// The scrollbar takes the last columns, see scrollbar.go
+	if m.scrollbarShown() {
+		right = max(right, scrollbarWidth)
+	}
+
+	// On a narrow terminal the margins give way to the text
+	if m.width-left-right < minTextWidth && left+right > 0 {
+		spare := max(0, m.width-minTextWidth)
+		left = left * spare / (left + right)
+		right = spare - left
+	}
+	return left, right
 }
 
 // updateViewport updates the viewport with the current chapter content
@@ -190,27 +384,144 @@ func (m *ReaderModel) updateViewport() {
 		return
 	}
 
-	// Use viewport width for rendering
-	renderWidth := m.viewport.Width
-	if renderWidth <= 0 {
-		renderWidth = 80 // Default width
+	// Rendering is cached per chapter for the current width and theme
+	rendered := m.renderedWindow()
+	m.headings = rendered.headings
+	m.links = rendered.links
+	m.anchors = rendered.anchors
+
+	m.viewport.SetContent(rendered.text)
+	m.viewport.SetYOffset(m.chapterStart())
+	m.ruler = -1
+	m.scroll.animating = false
+	m.lines = strings.Split(rendered.text, "\n")
+	m.indexLines()
+	m.lookup.active = false
+	m.locateHighlights()
+	m.locateMatches()
+}
+
+// renderOptions builds the HTML layout options from the config
+func renderOptions(cfg *config.Config) ebook.RenderOptions {
+	options := ebook.DefaultRenderOptions()
+	options.Justify = cfg.Display.Justify
+	options.SmartTypography = cfg.Display.SmartTypography
+	options.LetterSpacing = cfg.Display.LetterSpacing
+	options.WordSpacing = cfg.Display.WordSpacing
+	options.Plain = cfg.Display.ScreenReader
+	options.ASCII = cfg.Display.ASCII
+	options.LargeText = cfg.Display.LargeText
+	options.LargeTextStyle = cfg.Display.LargeTextStyle
+	if cfg.Display.Accessible {
+		options.Justify = false
+		options.LetterSpacing = true
+		options.WordSpacing = max(options.WordSpacing, accessibleWordSpacing)
 	}
+	return options
+}
 
-	// Render HTML to styled text based on book format
-	var renderedContent string
-	if m.book.Format == ebook.FormatEPUB {
-		// EPUB: render HTML with rich formatting and track heading positions
-		renderResult := ebook.RenderToStyledTextWithHeadings(chapter.Content, m.config.ActiveTheme, renderWidth)
-		renderedContent = renderResult.Text
-		m.headingPositions = renderResult.HeadingPositions
-	} else {
-		// Plain text: just wrap it
-		renderedContent = wordwrap.String(chapter.Content, renderWidth)
-		m.headingPositions = []int{}
+// Refresh re-renders the current chapter after a settings change, keeping
+// the scroll position
+func (m *ReaderModel) Refresh() {
+	if m.book == nil {
+		return
+	}
+	offset := m.textOffset()
+	m.updateViewport()
+	m.gotoTextOffset(offset)
+}
+
+// GotoChapter jumps to the start of a chapter (zero-based)
+func (m *ReaderModel) GotoChapter(index int) error {
+	if m.book == nil {
+		return errorf("no book open")
+	}
+	if index < 0 || index >= m.book.ChapterCount() {
+		return errorf("chapter must be between 1 and %d", m.book.ChapterCount())
+	}
+	m.currentChapter = index
+	m.updateViewport()
+	return nil
+}
+
+// GotoPercent jumps to a position in the whole book, measured by the
+// length of each chapter's content
+func (m *ReaderModel) GotoPercent(percent float64) error {
+	if m.book == nil {
+		return errorf("no book open")
+	}
+	if percent < 0 || percent > 100 {
+		return errorf("percentage must be between 0 and 100")
+	}
+
+	total := 0
+	for _, chapter := range m.book.Chapters {
+		total += chapter.Length()
+	}
+	target := int(percent / 100 * float64(total))
+
+	for i, chapter := range m.book.Chapters {
+		length := chapter.Length()
+		if target <= length || i == len(m.book.Chapters)-1 {
+			m.currentChapter = i
+			m.updateViewport()
+			if length > 0 {
+				fraction := float64(min(target, length)) / float64(length)
+				start, end := m.chapterLines()
+				m.viewport.SetYOffset(start + int(fraction*float64(end-start)))
+			}
+			return nil
+		}
+		target -= length
+	}
+	return nil
+}
+
+// Bookmarks returns the bookmarks of the open book
+func (m *ReaderModel) Bookmarks() []config.Bookmark {
+	if m.book == nil {
+		return nil
+	}
+	bookProgress, _ := m.progress.GetBookProgress(m.book.Path)
+	return bookProgress.Bookmarks
+}
+
+// AddBookmark bookmarks the current position under a name
+func (m *ReaderModel) AddBookmark(name string) error {
+	if m.book == nil {
+		return errorf("no book open")
+	}
+	m.progress.AddBookmark(m.book.Path, config.Bookmark{
+		Name:         name,
+		Chapter:      m.currentChapter,
+		ScrollOffset: m.lineInChapter(m.viewport.YOffset),
+		TextOffset:   m.textOffset(),
+	})
+	return m.SaveProgress()
+}
+
+// RemoveBookmark deletes a bookmark of the open book, returning it
+func (m *ReaderModel) RemoveBookmark(name string) (config.Bookmark, error) {
+	for _, bookmark := range m.Bookmarks() {
+		if bookmark.Name == name && m.progress.RemoveBookmark(m.book.Path, name) {
+			return bookmark, m.SaveProgress()
+		}
 	}
+	return config.Bookmark{}, errorf("no bookmark named %q", name)
+}
 
-	m.viewport.SetContent(renderedContent)
-	m.viewport.GotoTop()
+// GotoBookmark jumps to a named bookmark
+func (m *ReaderModel) GotoBookmark(name string) error {
+	for _, bookmark := range m.Bookmarks() {
+		if bookmark.Name == name {
+			if err := m.GotoChapter(bookmark.Chapter); err != nil {
+				return err
+			}
+			m.restorePosition(bookmark.ScrollOffset, bookmark.TextOffset)
+			return nil
+		}
+	}
+	return errorf("no bookmark named %q", name)
 }
 
 // Update handles messages for the reader view
@@ -219,122 +530,377 @@ func (m *ReaderModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	chapter, offset := m.currentChapter, m.viewport.YOffset
+	cmd := m.update(msg)
+	m.followChapter()
+	cmd = tea.Batch(cmd, m.prerenderChapters())
+	m.markChapterRead()
+	cmd = tea.Batch(cmd, m.checkFinished())
+
+	if chapter != m.currentChapter || offset != m.viewport.YOffset {
+		cmd = tea.Batch(cmd, m.scheduleAutosave())
+		// Screen readers hear of a new chapter on the status line
+		if screenReader && chapter != m.currentChapter {
+			m.status = strings.TrimSuffix(m.chapterAnnouncement()+" • "+m.status, " • ")
+		}
+		// Moving through the book in zen mode briefly shows the progress
+		if m.config.Display.Zen {
+			cmd = tea.Batch(cmd, m.flashProgress())
+		}
+	}
+	return m, cmd
+}
+
+// update handles a message for Update
+func (m *ReaderModel) update(msg tea.Msg) tea.Cmd {
 	switch msg := msg.(type) {
+	case DefinitionMsg:
+		m.lookup.result = &msg
+		m.lookup.scroll = 0
+		return nil
+
+	case autosaveMsg:
+		if msg.timer == m.autosaveTimer {
+			if err := m.SaveProgress(); err != nil {
+				return notify(toastError, "Could not save progress: %v", err)
+			}
+		}
+		return nil
+
+	case zenFlashMsg:
+		if msg.timer == m.zen.flashTimer {
+			m.zen.flashing = false
+		}
+		return nil
+
+	case list.FilterMatchesMsg:
+		if m.picker.active {
+			// Select the best match as the filter changes
+			m.picker.list, _ = m.picker.list.Update(msg)
+			m.picker.list.Select(0)
+		}
+		return nil
+
+	case chapterRenderedMsg:
+		m.storeRendered(msg)
+		return nil
+
+	case scrollFrameMsg:
+		if msg.timer == m.scroll.timer && m.scroll.animating {
+			return m.stepScroll()
+		}
+		return nil
+
+	case clockMsg:
+		if msg.timer == m.clockTimer {
+			return m.tickClock()
+		}
+		return nil
+
+	case focusMsg:
+		return m.updateFocusTimer(msg)
+
+	case rsvpMsg:
+		return m.stepRSVP(msg)
+
+	case readAloudStartedMsg:
+		return m.playerStarted(msg)
+
+	case readAloudClipMsg:
+		return m.stepReadAloud(msg)
+
+	case readAloudEndedMsg:
+		return m.playerEnded(msg)
+
+	case reminderMsg:
+		if msg.timer == m.reminder.timer {
+			m.reminder.shown = true
+		}
+		return nil
+
+	case tea.MouseMsg:
+		return m.updateMouse(msg)
+
 	case tea.KeyMsg:
+		m.status = ""
+		if m.reminder.shown {
+			return m.updateReminder(msg)
+		}
+		if m.focus.onBreak {
+			return m.updateFocusBreak(msg)
+		}
+		if m.resume.shown {
+			return m.updateResume(msg)
+		}
+		if m.picker.active {
+			return m.updateChapterPicker(msg)
+		}
+		if m.find.active {
+			return m.updateFind(msg)
+		}
+		if m.rsvp.active {
+			return m.updateRSVP(msg)
+		}
+		if m.readAloud.active {
+			return m.updateReadAloud(msg)
+		}
+		if cmd, handled := m.updateLookup(msg); handled {
+			return cmd
+		}
+		if cmd, handled := m.updateMark(msg); handled {
+			return cmd
+		}
+
+		// Digits before a key repeat it: 10j scrolls ten lines, 3l moves
+		// three chapters ahead and 5g goes to chapter 5
+		if digit, ok := countDigit(msg, m.count); ok {
+			m.count = min(m.count*10+digit, maxCount)
+			m.status = strconv.Itoa(m.count)
+			return nil
+		}
+		counted, count := m.count > 0, max(m.count, 1)
+		m.count = 0
+
 		switch {
-		case key.Matches(msg, m.keys.ToggleHelp):
-			m.help.ShowAll = !m.help.ShowAll
-			return m, nil
+		case key.Matches(msg, m.keys.LookupWord):
+			m.startWordCursor()
+			return nil
+
+		case key.Matches(msg, m.keys.LookupPrompt):
+			return m.startWordPrompt()
+
+		case key.Matches(msg, m.keys.Find):
+			return m.startFind()
+
+		case key.Matches(msg, m.keys.ChapterPicker):
+			if counted {
+				if err := m.GotoChapter(count - 1); err != nil {
+					m.status = err.Error()
+				}
+				return nil
+			}
+			m.openChapterPicker()
+			return nil
+
+		case key.Matches(msg, m.keys.Zen):
+			if err := m.toggleZen(); err != nil {
+				m.status = trf("Could not save settings: %v", err)
+			}
+			return nil
+
+		case key.Matches(msg, m.keys.SpeedRead):
+			return m.startRSVP()
+
+		case key.Matches(msg, m.keys.ReadAloud):
+			return m.startReadAloud()
+
+		case key.Matches(msg, m.keys.Ruler):
+			m.status = m.cycleRuler()
+			return nil
+
+		case key.Matches(msg, m.keys.Accessible):
+			m.status = m.toggleAccessible()
+			return nil
+
+		case key.Matches(msg, m.keys.Journal):
+			path, title := m.book.Path, m.book.Title
+			return func() tea.Msg { return JournalMsg{Path: path, Title: title} }
+
+		case key.Matches(msg, m.keys.SetMark):
+			m.markPending, m.status = "m", "m"
+			return nil
+
+		case key.Matches(msg, m.keys.GotoMark):
+			m.markPending, m.status = "'", "'"
+			return nil
+
+		case key.Matches(msg, m.keys.StatusDetail):
+			m.statusMinimal = !m.statusMinimal
+			return nil
 
 		case key.Matches(msg, m.keys.Back):
 			// Save reading progress
-			m.SaveProgress()
-			return m, func() tea.Msg { return BackToLibraryMsg{} }
+			back := func() tea.Msg { return BackMsg{} }
+			if err := m.SaveProgress(); err != nil {
+				return tea.Batch(back, notify(toastError, "Could not save progress: %v", err))
+			}
+			return tea.Batch(back, notify(toastSuccess, "Progress saved"))
 
 		case key.Matches(msg, m.keys.NextChapter):
 			// Next chapter
 			if m.currentChapter < m.book.ChapterCount()-1 {
-				m.currentChapter++
+				m.currentChapter = min(m.currentChapter+count, m.book.ChapterCount()-1)
 				m.updateViewport()
 			}
-			return m, nil
+			return nil
 
 		case key.Matches(msg, m.keys.NextHeading):
-			// Jump to next heading (H2/H3) within the current chapter
-			currentLine := m.viewport.YOffset
-
-			// Find the next heading after the current position
-			nextHeadingLine := -1
-			for _, headingLine := range m.headingPositions {
-				if headingLine > currentLine {
-					nextHeadingLine = headingLine
-					break
-				}
-			}
-
-			if nextHeadingLine >= 0 {
-				// Jump to the heading within the current chapter
-				m.viewport.SetYOffset(nextHeadingLine)
-			} else {
-				// No more headings in this chapter, go to next chapter
-				if m.currentChapter < m.book.ChapterCount()-1 {
-					m.currentChapter++
-					m.updateViewport()
-				}
-			}
-			return m, nil
+			// A count picks the deepest heading level to stop at: 1s
+			// skips to the next h1
+			m.nextHeading(m.sectionDepth(counted, count))
+			return nil
 
 		case key.Matches(msg, m.keys.PrevHeading):
-			// Jump to previous heading (H2/H3) within the current chapter
-			currentLine := m.viewport.YOffset
-
-			// Find the previous heading before the current position
-			prevHeadingLine := -1
-			for i := len(m.headingPositions) - 1; i >= 0; i-- {
-				headingLine := m.headingPositions[i]
-				if headingLine < currentLine {
-					prevHeadingLine = headingLine
-					break
-				}
-			}
+			m.prevHeading(m.sectionDepth(counted, count))
+			return nil
 
-			if prevHeadingLine >= 0 {
-				// Jump to the heading within the current chapter
-				m.viewport.SetYOffset(prevHeadingLine)
-			} else {
-				// No more headings before this in the chapter, go to previous chapter
-				if m.currentChapter > 0 {
-					m.currentChapter--
-					m.updateViewport()
-					// Go to the last heading in the previous chapter
-					if len(m.headingPositions) > 0 {
-						m.viewport.SetYOffset(m.headingPositions[len(m.headingPositions)-1])
-					}
-				}
-			}
-			return m, nil
-
-		case key.Matches(msg, m.keys.HalfPageDown):
+		case key.Matches(msg, m.keys.HalfPageDown, m.viewport.KeyMap.HalfPageDown):
 			// Scroll down half a viewport
-			m.viewport.HalfViewDown()
-			return m, nil
+			return m.scrollBy(count * m.viewport.Height / 2)
 
-		case key.Matches(msg, m.keys.HalfPageUp):
+		case key.Matches(msg, m.keys.HalfPageUp, m.viewport.KeyMap.HalfPageUp):
 			// Scroll up half a viewport
-			m.viewport.HalfViewUp()
-			return m, nil
+			return m.scrollBy(-count * m.viewport.Height / 2)
+
+		case key.Matches(msg, m.viewport.KeyMap.PageDown):
+			return m.scrollBy(count * m.pageStep())
+
+		case key.Matches(msg, m.viewport.KeyMap.PageUp):
+			return m.scrollBy(-count * m.pageStep())
+
+		case key.Matches(msg, m.keys.ScrollDown, m.keys.ScrollUp) && m.rulerActive() && !m.config.Display.Paged:
+			step := 1
+			if key.Matches(msg, m.keys.ScrollUp) {
+				step = -1
+			}
+			var cmds []tea.Cmd
+			for range count * m.scrollStep() {
+				cmds = append(cmds, m.moveRuler(step))
+			}
+			return tea.Batch(cmds...)
+
+		case key.Matches(msg, m.keys.ScrollDown):
+			return m.scrollBy(count * m.scrollStep())
+
+		case key.Matches(msg, m.keys.ScrollUp):
+			return m.scrollBy(-count * m.scrollStep())
 
 		case key.Matches(msg, m.keys.PrevChapter):
 			// Previous chapter
 			if m.currentChapter > 0 {
-				m.currentChapter--
+				m.currentChapter = max(m.currentChapter-count, 0)
 				m.updateViewport()
 			}
-			return m, nil
+			return nil
 
 		case key.Matches(msg, m.keys.FirstChapter):
 			// First chapter
 			m.currentChapter = 0
 			m.updateViewport()
-			return m, nil
+			return nil
 
 		case key.Matches(msg, m.keys.LastChapter):
 			// Last chapter
 			m.currentChapter = m.book.ChapterCount() - 1
 			m.updateViewport()
-			return m, nil
+			return nil
 		}
 	}
 
 	var cmd tea.Cmd
 	m.viewport, cmd = m.viewport.Update(msg)
-	return m, cmd
+	return cmd
+}
+
+// Largest repeat count, so a held digit can't overflow it
+const maxCount = 9999
+
+// countDigit returns the digit a key adds to a repeat count. A count can't
+// start with 0.
+func countDigit(msg tea.KeyMsg, count int) (int, bool) {
+	if msg.Type != tea.KeyRunes || msg.Alt || len(msg.Runes) != 1 {
+		return 0, false
+	}
+	r := msg.Runes[0]
+	if r < '0' || r > '9' || r == '0' && count == 0 {
+		return 0, false
+	}
+	return int(r - '0'), true
+}
+
+// sectionDepth returns the deepest heading level the section keys stop at,
+// section_depth or the count typed before them
+func (m *ReaderModel) sectionDepth(counted bool, count int) int {
+	if counted {
+		return min(count, 6)
+	}
+	return m.config.Reading.SectionDepth
+}
+
+// sectionLines returns the lines of the headings down to a level
+func (m *ReaderModel) sectionLines(depth int) []int {
+	var lines []int
+	for _, heading := range m.headings {
+		if heading.Level <= depth {
+			lines = append(lines, heading.Line)
+		}
+	}
+	return lines
+}
+
+// nextHeading jumps to the next heading down to a level within the
+// current chapter, or to the next chapter after the last one
+func (m *ReaderModel) nextHeading(depth int) {
+	currentLine := m.viewport.YOffset
+
+	// Find the next heading after the current position
+	nextHeadingLine := -1
+	for _, headingLine := range m.sectionLines(depth) {
+		if headingLine > currentLine {
+			nextHeadingLine = headingLine
+			break
+		}
+	}
+
+	if nextHeadingLine >= 0 {
+		// Jump to the heading within the current chapter
+		m.viewport.SetYOffset(nextHeadingLine)
+	} else {
+		// No more headings in this chapter, go to next chapter
+		if m.currentChapter < m.book.ChapterCount()-1 {
+			m.currentChapter++
+			m.updateViewport()
+		}
+	}
+}
+
+// prevHeading jumps to the previous heading down to a level within the
+// current chapter, or to the last heading of the previous chapter before
+// the first
+func (m *ReaderModel) prevHeading(depth int) {
+	currentLine := m.viewport.YOffset
+
+	// Find the previous heading before the current position
+	prevHeadingLine := -1
+	headingLines := m.sectionLines(depth)
+	for i := len(headingLines) - 1; i >= 0; i-- {
+		headingLine := headingLines[i]
+		if headingLine < currentLine {
+			prevHeadingLine = headingLine
+			break
+		}
+	}
+
+	if prevHeadingLine >= 0 {
+		// Jump to the heading within the current chapter
+		m.viewport.SetYOffset(prevHeadingLine)
+	} else {
+		// No more headings before this in the chapter, go to previous chapter
+		if m.currentChapter > 0 {
+			m.currentChapter--
+			m.updateViewport()
+			// Go to the last heading in the previous chapter
+			if headingLines := m.sectionLines(depth); len(headingLines) > 0 {
+				m.viewport.SetYOffset(headingLines[len(headingLines)-1])
+			}
+		}
+	}
 }
 
 // View renders the reader view
 func (m *ReaderModel) View() string {
 	if m.book == nil || m.config.ActiveTheme == nil {
-		return "No book loaded"
+		return tr("No book loaded")
 	}
 
 	theme := m.config.ActiveTheme
@@ -355,8 +921,8 @@ func (m *ReaderModel) View() string {
 
 	// Header with book title
 	title := m.book.Title
-	if m.book.Author != "" {
-		title = fmt.Sprintf("%s - %s", m.book.Title, m.book.Author)
+	if byline := m.book.Byline(); byline != "" {
+		title = fmt.Sprintf("%s - %s", m.book.Title, byline)
 	}
 	header := headerStyle.Render(title)
 
@@ -364,31 +930,94 @@ func (m *ReaderModel) View() string {
 	chapter := m.book.GetChapter(m.currentChapter)
 	chapterTitle := ""
 	if chapter != nil {
-		chapterTitle = chapterTitleStyle.Render(fmt.Sprintf("Chapter %d/%d: %s",
-			m.currentChapter+1,
-			m.book.ChapterCount(),
-			chapter.Title))
+		prefix := trf("Chapter %d/%d: ", m.currentChapter+1, m.book.ChapterCount())
+		chapterTitle = chapterTitleStyle.Render(prefix + m.breadcrumb(m.width-2-ansi.StringWidth(prefix)))
 	}
 
-	// Progress indicator
-	progress := fmt.Sprintf("Chapter %d/%d • Scroll: %.0f%%",
-		m.currentChapter+1,
-		m.book.ChapterCount(),
-		m.viewport.ScrollPercent()*100,
-	)
+	// Progress indicator, with the focus timer
+	progress := m.progressText()
+	if focus := m.focusStatus(); focus != "" {
+		progress += " • " + focus
+	}
+	if m.readAloud.active {
+		progress += " • " + m.readAloudStatus()
+	}
 
 	// Help view
 	helpView := m.help.View(m.keys)
+	if m.lookup.active {
+		keys := lookupKeys
+		keys.Vocabulary.SetEnabled(m.config.Reading.Vocabulary)
+		helpView = m.help.View(keys)
+	}
+	if m.rsvp.active {
+		helpView = m.help.View(rsvpKeys)
+	}
+	if m.readAloud.active {
+		helpView = m.help.View(readAloudKeys)
+	}
+	if m.find.active {
+		helpView = m.help.View(findKeys)
+	}
+	helpView = plain(helpView)
+
+	// Highlights, word cursor and overlays
+	content := m.applyReadAloud(m.applyRuler(m.applyFind(m.applyHighlights(m.applyVocabulary(m.viewport.View())))))
+	if m.rsvp.active {
+		content = m.rsvpView()
+	}
+	if m.lookup.active {
+		content = m.highlightSelection(content)
+	}
+	if m.lookup.result != nil {
+		content = placeOverlay(content, m.definitionView(), m.viewport.Width)
+	}
+	if m.picker.active {
+		content = placeOverlay(content, m.chapterPickerView(), m.viewport.Width)
+	}
+	if m.resume.shown {
+		content = placeOverlay(content, m.resumeView(), m.viewport.Width)
+	}
+	if m.focus.onBreak {
+		content = placeOverlay(content, m.focusBreakView(), m.viewport.Width)
+	}
+	if m.reminder.shown {
+		content = placeOverlay(content, m.reminderView(), m.viewport.Width)
+	}
+
+	if m.config.Display.Zen {
+		return m.zenView(content)
+	}
+	if left := m.textIndent(); left > 0 {
+		content = lipgloss.NewStyle().PaddingLeft(left).Render(content)
+	}
+	content = m.applyScrollbar(content)
+
+	footer := progressStyle.Render(plain(progress))
+	footerRule := rule(m.width)
+	if m.progressBarShown() {
+		footer = progressStyle.Render(m.progressFooter(progress))
+		footerRule = m.bookBar(m.width)
+	}
+	if m.status != "" {
+		footer = progressStyle.Render(plain(m.status))
+	}
+	if m.lookup.prompt {
+		footer = progressStyle.Render(m.lookup.input.View())
+	}
+	if m.find.active {
+		footer = progressStyle.Render(m.findFooter())
+	}
 
 	// Combine header, viewport, and footer
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
 		header,
 		chapterTitle,
-		strings.Repeat("─", m.width),
-		m.viewport.View(),
-		strings.Repeat("─", m.width),
-		progressStyle.Render(progress),
+		rule(m.width),
+		content,
+		footerRule,
+		footer,
 		helpView,
 	)
 }
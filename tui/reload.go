@@ -0,0 +1,149 @@
+package tui
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/cbrasser/cozy/config"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fsnotify/fsnotify"
+)
+
+// Editors often write a file in several steps, so changes are collected
+// this long before reloading
+const configReloadDelay = 300 * time.Millisecond
+
+// configChangedMsg reports a change to config.toml or a theme file
+type configChangedMsg struct{}
+
+// configReloadMsg reloads the config once changes have settled
+type configReloadMsg struct {
+	timer int
+}
+
+// watchConfig watches config.toml and the themes folder. It returns nil if
+// watching isn't possible; the app then works as before, without reloads.
+func watchConfig() *fsnotify.Watcher {
+	configDir, err := config.ConfigDir()
+	if err != nil {
+		return nil
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil
+	}
+	// The folder is watched rather than the file, since atomic saves
+	// replace the file
+	if err := watcher.Add(configDir); err != nil {
+		watcher.Close()
+		return nil
+	}
+	watchThemes(watcher)
+	return watcher
+}
+
+// watchThemes adds the themes folder, which may only be created later
+func watchThemes(watcher *fsnotify.Watcher) {
+	if configDir, err := config.ConfigDir(); err == nil {
+		watcher.Add(filepath.Join(configDir, "themes"))
+	}
+}
+
+// waitForConfigChange waits for the next change to the config or a theme
+func (m Model) waitForConfigChange() tea.Cmd {
+	watcher := m.configWatcher
+	if watcher == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return nil
+				}
+				if affectsConfig(event) {
+					return configChangedMsg{}
+				}
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// affectsConfig reports whether a file event changes the config or a theme
+func affectsConfig(event fsnotify.Event) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+	name := filepath.Base(event.Name)
+	if filepath.Base(filepath.Dir(event.Name)) == "themes" {
+		return strings.HasSuffix(name, ".toml") && !strings.HasPrefix(name, ".")
+	}
+	configPath, err := config.ConfigPath()
+	return err == nil && filepath.Clean(event.Name) == configPath
+}
+
+// updateConfigWatch handles messages from the config watcher
+func (m *Model) updateConfigWatch(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case configChangedMsg:
+		m.reloadTimer++
+		timer := m.reloadTimer
+		return tea.Batch(m.waitForConfigChange(), tea.Tick(configReloadDelay, func(time.Time) tea.Msg {
+			return configReloadMsg{timer: timer}
+		}))
+	case configReloadMsg:
+		if msg.timer == m.reloadTimer {
+			return m.reloadConfig()
+		}
+	}
+	return nil
+}
+
+// reloadConfig reads the config and the theme again and re-renders the
+// views. The config is updated in place, since every view shares it.
+func (m *Model) reloadConfig() tea.Cmd {
+	watchThemes(m.configWatcher)
+
+	cfg, err := config.Load()
+	if err != nil {
+		return m.addToast(trf("Could not reload config: %v", err), toastError)
+	}
+	// Saving settings from the app changes the file too
+	if sameSettings(m.config, cfg) {
+		return nil
+	}
+
+	libraryPath := m.config.Library.Path
+	*m.config = *cfg
+	m.applySettings()
+	m.details.updateViewport()
+
+	cmds := []tea.Cmd{m.addToast(tr("Settings reloaded"), toastInfo)}
+	if m.config.Library.Path != libraryPath {
+		cmds = append(cmds, m.library.loadBooks())
+	}
+	if len(cfg.Warnings) > 0 {
+		cmds = append(cmds, m.configWarnings())
+	}
+	return tea.Batch(cmds...)
+}
+
+// sameSettings reports whether two configs have the same settings and theme
+func sameSettings(a, b *config.Config) bool {
+	if *a.ActiveTheme != *b.ActiveTheme {
+		return false
+	}
+	var encodedA, encodedB bytes.Buffer
+	if toml.NewEncoder(&encodedA).Encode(a) != nil || toml.NewEncoder(&encodedB).Encode(b) != nil {
+		return false
+	}
+	return bytes.Equal(encodedA.Bytes(), encodedB.Bytes())
+}
@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// reminderState tracks the reading time reminder
+type reminderState struct {
+	started time.Time // When the book was opened
+	timer   int       // Identifies the current timer so stale ticks are ignored
+	shown   bool
+}
+
+// reminderMsg fires when a reminder timer runs out
+type reminderMsg struct {
+	timer int
+}
+
+// startReminder starts timing a reading session. It does nothing when
+// reminders are disabled.
+func (m *ReaderModel) startReminder() tea.Cmd {
+	m.reminder.started = time.Now()
+	m.reminder.shown = false
+	return m.scheduleReminder(m.config.Reading.ReminderMinutes)
+}
+
+// scheduleReminder shows the reminder again after some minutes
+func (m *ReaderModel) scheduleReminder(minutes int) tea.Cmd {
+	m.reminder.timer++
+	if minutes <= 0 {
+		return nil
+	}
+	timer := m.reminder.timer
+	return tea.Tick(time.Duration(minutes)*time.Minute, func(time.Time) tea.Msg {
+		return reminderMsg{timer: timer}
+	})
+}
+
+// updateReminder handles keys while the reminder is shown. Quitting is
+// left to the main model, which saves the progress first.
+func (m *ReaderModel) updateReminder(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "s":
+		m.reminder.shown = false
+		return m.scheduleReminder(max(1, m.config.Reading.SnoozeMinutes))
+	case "esc", "enter":
+		// Keep reading; remind again after another full session
+		m.reminder.shown = false
+		return m.scheduleReminder(m.config.Reading.ReminderMinutes)
+	}
+	return nil
+}
+
+// reminderView renders the reminder overlay
+func (m *ReaderModel) reminderView() string {
+	theme := m.config.ActiveTheme
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(theme.HeadingColor))
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.MutedTextColor))
+
+	minutes := int(time.Since(m.reminder.started).Minutes())
+	reading := trf("%d min", minutes)
+	if minutes >= 60 {
+		reading = trf("%dh %02d min", minutes/60, minutes%60)
+	}
+
+	content := titleStyle.Render(tr("Time for a break?")) + "\n\n" +
+		trf("You've been reading for %s.", reading) + "\n\n" +
+		mutedStyle.Render(plain(trf("s snooze %d min • q save and quit • esc keep reading",
+			max(1, m.config.Reading.SnoozeMinutes))))
+
+	return lipgloss.NewStyle().
+		Border(panelBorder()).
+		BorderForeground(lipgloss.Color(theme.PrimaryColor)).
+		Padding(1, 2).
+		Render(content)
+}
@@ -0,0 +1,211 @@
+package tui
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cbrasser/cozy/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Requests not answered within this time fail, e.g. while cozy is busy
+const remoteTimeout = 5 * time.Second
+
+// RemoteRequest is one line of JSON sent to the remote control socket.
+// Besides "status", commands are those of the command line, such as
+// "open <path>", "chapter next" or "goto 50%".
+type RemoteRequest struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+}
+
+// RemoteResponse is the line of JSON answering a request
+type RemoteResponse struct {
+	OK      bool          `json:"ok"`
+	Message string        `json:"message,omitempty"`
+	Error   string        `json:"error,omitempty"`
+	Status  *RemoteStatus `json:"status,omitempty"`
+}
+
+// RemoteStatus is what cozy is showing
+type RemoteStatus struct {
+	View string      `json:"view"` // "library", "reader", "details", "help" or "files"
+	Book *RemoteBook `json:"book,omitempty"`
+}
+
+// RemoteBook is the book open in the reader and the position in it
+type RemoteBook struct {
+	hookBook
+	Chapter      int    `json:"chapter"` // Counting from 1
+	Chapters     int    `json:"chapters"`
+	ChapterTitle string `json:"chapter_title,omitempty"`
+}
+
+// remoteServer accepts requests on the socket and hands them to the model
+type remoteServer struct {
+	listener net.Listener
+	requests chan remoteRequestMsg
+}
+
+// remoteRequestMsg is a request waiting for the model to answer it
+type remoteRequestMsg struct {
+	request RemoteRequest
+	reply   chan RemoteResponse
+}
+
+// listenRemote opens the remote control socket if it is enabled. A socket
+// left behind by a crashed instance is replaced; one in use by a running
+// instance is an error.
+func listenRemote(cfg *config.Config) (*remoteServer, error) {
+	if !cfg.Remote.Enabled {
+		return nil, nil
+	}
+	socket := cfg.RemoteSocket()
+	if conn, err := net.Dial("unix", socket); err == nil {
+		conn.Close()
+		return nil, errorf("remote control socket %s is in use by another cozy", socket)
+	}
+	os.Remove(socket)
+	if err := os.MkdirAll(filepath.Dir(socket), 0755); err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, err
+	}
+	server := &remoteServer{listener: listener, requests: make(chan remoteRequestMsg)}
+	go server.accept()
+	return server, nil
+}
+
+// accept serves connections until the socket is closed
+func (s *remoteServer) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.serve(conn)
+	}
+}
+
+// serve answers the requests of one connection, a line of JSON each
+func (s *remoteServer) serve(conn net.Conn) {
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	encoder := json.NewEncoder(conn)
+	for scanner.Scan() {
+		var request RemoteRequest
+		if err := json.Unmarshal(scanner.Bytes(), &request); err != nil {
+			encoder.Encode(RemoteResponse{Error: "invalid request: " + err.Error()})
+			continue
+		}
+
+		reply := make(chan RemoteResponse, 1)
+		var response RemoteResponse
+		select {
+		case s.requests <- remoteRequestMsg{request: request, reply: reply}:
+			select {
+			case response = <-reply:
+			case <-time.After(remoteTimeout):
+				response = RemoteResponse{Error: "timed out"}
+			}
+		case <-time.After(remoteTimeout):
+			response = RemoteResponse{Error: "timed out"}
+		}
+		if encoder.Encode(response) != nil {
+			return
+		}
+	}
+}
+
+// close stops accepting requests and removes the socket
+func (s *remoteServer) close() {
+	s.listener.Close()
+}
+
+// waitForRemote waits for the next remote request
+func (m Model) waitForRemote() tea.Cmd {
+	server := m.remote
+	if server == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		return <-server.requests
+	}
+}
+
+// answerRemote runs a remote request and replies to it
+func (m *Model) answerRemote(msg remoteRequestMsg) tea.Cmd {
+	var response RemoteResponse
+	var cmd tea.Cmd
+	switch msg.request.Command {
+	case "status":
+		response.Status = m.remoteStatus()
+	default:
+		c, err := m.findCommand(msg.request.Command)
+		if err != nil {
+			response.Error = err.Error()
+			break
+		}
+		var message string
+		message, cmd, err = c.run(m, msg.request.Args)
+		if err != nil {
+			response.Error = err.Error()
+			break
+		}
+		response.Message = message
+		if m.command.confirm != nil {
+			// Questions are for the person at the terminal to answer
+			m.command.message, m.command.isError = message, false
+		}
+	}
+	response.OK = response.Error == ""
+	msg.reply <- response
+	return tea.Batch(cmd, m.waitForRemote())
+}
+
+// remoteStatus describes the current view and book
+func (m *Model) remoteStatus() *RemoteStatus {
+	status := &RemoteStatus{View: viewName(m.currentView())}
+	if r := m.reader; m.currentView() == ViewReader && r.book != nil {
+		status.Book = &RemoteBook{
+			hookBook: r.hookBook(),
+			Chapter:  r.currentChapter + 1,
+			Chapters: r.book.ChapterCount(),
+		}
+		if chapter := r.book.GetChapter(r.currentChapter); chapter != nil {
+			status.Book.ChapterTitle = chapter.Title
+		}
+	}
+	return status
+}
+
+// SendRemote sends a request to a running cozy and returns its answer
+func SendRemote(socket string, request RemoteRequest) (RemoteResponse, error) {
+	conn, err := net.DialTimeout("unix", socket, remoteTimeout)
+	if err != nil {
+		return RemoteResponse{}, fmt.Errorf("cozy isn't running with [remote] enabled: %w", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(2 * remoteTimeout))
+
+	if err := json.NewEncoder(conn).Encode(request); err != nil {
+		return RemoteResponse{}, err
+	}
+	var response RemoteResponse
+	if err := json.NewDecoder(conn).Decode(&response); err != nil {
+		return RemoteResponse{}, err
+	}
+	if !response.OK {
+		return response, errors.New(response.Error)
+	}
+	return response, nil
+}
@@ -0,0 +1,173 @@
+package tui
+
+import (
+	"github.com/cbrasser/cozy/config"
+	"github.com/cbrasser/cozy/ebook"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Chapters further than this from the current one are dropped from the cache
+const renderCacheRadius = 2
+
+// renderedChapter is a chapter rendered for the viewport
+type renderedChapter struct {
+	text     string
+	headings []ebook.Heading
+	links    []ebook.Link
+	anchors  map[string]int
+}
+
+// renderSettings is everything besides the chapter that affects rendering
+type renderSettings struct {
+	width   int
+	theme   *config.Theme
+	options ebook.RenderOptions
+	profile termenv.Profile // Colors are baked into the rendered text
+}
+
+// renderCache keeps rendered chapters of the open book so switching back
+// and forth is instant. The neighbours of the current chapter are rendered
+// in the background, which also loads their content from the archive
+// before they are needed. Parsed chapters are kept as well, so resizing
+// the terminal or switching themes only lays them out again.
+type renderCache struct {
+	settings   renderSettings
+	generation int // Bumped on invalidation so late background renders are dropped
+	chapters   map[int]renderedChapter
+	documents  map[int]*ebook.Document
+	pending    map[int]bool
+}
+
+// chapterRenderedMsg delivers a chapter rendered in the background
+type chapterRenderedMsg struct {
+	generation int
+	chapter    int
+	rendered   renderedChapter
+	document   *ebook.Document
+}
+
+// renderChapter lays out a parsed chapter with the reader's settings
+func renderChapter(document *ebook.Document, settings renderSettings) renderedChapter {
+	result := document.Layout(settings.theme, settings.width)
+	return renderedChapter{
+		text:     result.Text,
+		headings: result.Headings,
+		links:    result.Links,
+		anchors:  result.Anchors,
+	}
+}
+
+// document returns a chapter parsed with the reader's settings, parsing it
+// unless the cache has it
+func (c *renderCache) document(chapter *ebook.Chapter, index int) *ebook.Document {
+	if document, ok := c.documents[index]; ok {
+		return document
+	}
+	return chapter.Parse(c.settings.options)
+}
+
+// reset empties the cache when the book or the render settings change.
+// Parsed chapters are kept unless the book or the options changed.
+func (c *renderCache) reset(settings renderSettings) {
+	if c.documents == nil || settings.options != c.settings.options {
+		c.documents = make(map[int]*ebook.Document)
+	}
+	c.settings = settings
+	c.generation++
+	c.chapters = make(map[int]renderedChapter)
+	c.pending = make(map[int]bool)
+}
+
+// renderSettings returns the current render settings of the reader
+func (m *ReaderModel) renderSettings() renderSettings {
+	width := m.viewport.Width
+	if width <= 0 {
+		width = 80 // Default width
+	}
+	options := renderOptions(m.config)
+	if m.book != nil {
+		options.Language = m.book.Metadata["language"]
+		options.Stylesheet = m.book.Styles
+	}
+	return renderSettings{
+		width:   width,
+		theme:   m.config.ActiveTheme,
+		options: options,
+		profile: lipgloss.ColorProfile(),
+	}
+}
+
+// renderedChapter returns a chapter from the cache, rendering it if needed
+func (m *ReaderModel) renderedChapter(index int) renderedChapter {
+	if settings := m.renderSettings(); settings != m.cache.settings || m.cache.chapters == nil {
+		m.cache.reset(settings)
+	}
+	if rendered, ok := m.cache.chapters[index]; ok {
+		return rendered
+	}
+
+	document := m.cache.document(m.book.GetChapter(index), index)
+	rendered := renderChapter(document, m.cache.settings)
+	m.cache.chapters[index] = rendered
+	m.cache.documents[index] = document
+	return rendered
+}
+
+// prerenderChapters renders the chapters around the current one in the
+// background and forgets chapters that are far away
+func (m *ReaderModel) prerenderChapters() tea.Cmd {
+	if m.book == nil || m.cache.chapters == nil {
+		return nil
+	}
+
+	for index := range m.cache.chapters {
+		if abs(index-m.currentChapter) > renderCacheRadius && !m.window.contains(index) {
+			delete(m.cache.chapters, index)
+		}
+	}
+	for index := range m.cache.documents {
+		if abs(index-m.currentChapter) > renderCacheRadius && !m.window.contains(index) {
+			delete(m.cache.documents, index)
+		}
+	}
+
+	// The chapter after a continuous window is the next one it takes in
+	var cmds []tea.Cmd
+	for _, index := range []int{m.currentChapter + 1, m.currentChapter - 1, m.window.next()} {
+		chapter := m.book.GetChapter(index)
+		if chapter == nil || m.cache.pending[index] {
+			continue
+		}
+		if _, ok := m.cache.chapters[index]; ok {
+			continue
+		}
+
+		m.cache.pending[index] = true
+		generation, settings := m.cache.generation, m.cache.settings
+		document, parsed := m.cache.documents[index]
+		cmds = append(cmds, func() tea.Msg {
+			if !parsed {
+				document = chapter.Parse(settings.options)
+			}
+			return chapterRenderedMsg{
+				generation: generation,
+				chapter:    index,
+				rendered:   renderChapter(document, settings),
+				document:   document,
+			}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// storeRendered adds a chapter rendered in the background to the cache
+func (m *ReaderModel) storeRendered(msg chapterRenderedMsg) {
+	if msg.generation != m.cache.generation {
+		return
+	}
+	delete(m.cache.pending, msg.chapter)
+	m.cache.chapters[msg.chapter] = msg.rendered
+	m.cache.documents[msg.chapter] = msg.document
+}
@@ -0,0 +1,198 @@
+package tui
+
+import (
+	"github.com/cbrasser/cozy/ebook"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Letters the furthest position has to be ahead of the saved one, about a
+// page, before reopening the book offers to jump there
+const furthestMargin = 1500
+
+// resumeState is the prompt shown when a book is reopened after flipping
+// back: resume where reading stopped, or jump to the furthest point read.
+// When the book file was replaced, say by a new edition, it asks instead
+// whether to keep the saved position, start over or look for the text
+// that was at the position.
+type resumeState struct {
+	shown      bool
+	chapter    int // Furthest position
+	textOffset int
+
+	changed bool   // The book file changed since the position was saved
+	context string // Text at the saved position, see ebook.PositionContext
+}
+
+// offerFurthest shows the resume prompt if the furthest position reached
+// is well ahead of the current one
+func (m *ReaderModel) offerFurthest() {
+	m.resume.shown = false
+	saved, ok := m.progress.GetBookProgress(m.book.Path)
+	if !ok || saved.FurthestChapter >= m.book.ChapterCount() {
+		return
+	}
+	ahead := saved.FurthestChapter > m.currentChapter ||
+		(saved.FurthestChapter == m.currentChapter && saved.FurthestOffset-m.textOffset() > furthestMargin)
+	if ahead {
+		m.resume = resumeState{shown: true, chapter: saved.FurthestChapter, textOffset: saved.FurthestOffset}
+	}
+}
+
+// checkBookFile asks what to do with the saved position if the book file
+// is not the one it was saved in
+func (m *ReaderModel) checkBookFile() {
+	saved, ok := m.progress.GetBookProgress(m.book.Path)
+	hash, err := ebook.FileHash(m.book.Path)
+	if err != nil {
+		m.fileHash = saved.FileHash
+		return
+	}
+	m.fileHash = hash
+	// Progress saved before files were hashed is taken to be for this file
+	if ok && saved.FileHash != "" && saved.FileHash != hash {
+		m.resume = resumeState{shown: true, changed: true, context: saved.Context}
+	}
+}
+
+// positionContext returns the text at the top of the view, see
+// ebook.PositionContext
+func (m *ReaderModel) positionContext() string {
+	_, end := m.chapterLines()
+	return ebook.PositionContext(m.lines[min(m.viewport.YOffset, end):end])
+}
+
+// relocate looks for the text of the saved position in the changed book
+func (m *ReaderModel) relocate(context string) tea.Cmd {
+	position, ok := ebook.RelocatePosition(m.book, context, ebook.Position{Chapter: m.currentChapter, TextOffset: m.textOffset()})
+	if !ok {
+		return notify(toastError, "Could not find your place in the new file")
+	}
+	m.currentChapter = position.Chapter
+	m.updateViewport()
+	m.gotoTextOffset(position.TextOffset)
+	return notify(toastSuccess, "Found your place in chapter %d", position.Chapter+1)
+}
+
+// GotoFurthest jumps to the furthest position read in the book
+func (m *ReaderModel) GotoFurthest() error {
+	saved, ok := m.progress.GetBookProgress(m.book.Path)
+	if !ok || saved.FurthestChapter >= m.book.ChapterCount() {
+		return errorf("no reading position saved for this book")
+	}
+	m.currentChapter = saved.FurthestChapter
+	m.updateViewport()
+	m.gotoTextOffset(saved.FurthestOffset)
+	return nil
+}
+
+// markChapterRead records the current chapter as read once its end is in
+// view
+func (m *ReaderModel) markChapterRead() {
+	if m.atChapterEnd() {
+		m.progress.MarkChapterRead(m.book.Path, m.currentChapter)
+	}
+}
+
+// checkFinished marks the book finished once the end of the last chapter
+// is in view
+func (m *ReaderModel) checkFinished() tea.Cmd {
+	if m.currentChapter != m.book.ChapterCount()-1 || !m.atChapterEnd() {
+		return nil
+	}
+	if saved, _ := m.progress.GetBookProgress(m.book.Path); saved.Finished {
+		return nil
+	}
+	m.progress.SetBookFinished(m.book.Path, true)
+	path := m.book.Path
+	finished := func() tea.Msg { return BookFinishedMsg{Path: path} }
+	return tea.Batch(notify(toastSuccess, "Finished %s", m.book.Title), finished)
+}
+
+// updateResume handles keys while the resume prompt is shown
+func (m *ReaderModel) updateResume(msg tea.KeyMsg) tea.Cmd {
+	if m.resume.changed {
+		return m.updateChangedBook(msg)
+	}
+	switch msg.String() {
+	case "f":
+		m.resume.shown = false
+		m.currentChapter = m.resume.chapter
+		m.updateViewport()
+		m.gotoTextOffset(m.resume.textOffset)
+		m.markSessionStart()
+	case "esc", "enter", "r":
+		m.resume.shown = false
+	}
+	return nil
+}
+
+// updateChangedBook handles keys while asking about a changed book file
+func (m *ReaderModel) updateChangedBook(msg tea.KeyMsg) tea.Cmd {
+	var cmd tea.Cmd
+	switch msg.String() {
+	case "f":
+		if m.resume.context == "" {
+			return nil
+		}
+		cmd = m.relocate(m.resume.context)
+	case "s":
+		m.progress.RestartBook(m.book.Path)
+		m.currentChapter = 0
+		m.updateViewport()
+	case "esc", "enter", "k":
+	default:
+		return nil
+	}
+	m.resume = resumeState{}
+	m.markSessionStart()
+	return tea.Batch(cmd, m.scheduleAutosave())
+}
+
+// resumeView renders the resume prompt
+func (m *ReaderModel) resumeView() string {
+	theme := m.config.ActiveTheme
+	if m.resume.changed {
+		return m.changedBookView()
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(theme.HeadingColor))
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.MutedTextColor))
+
+	furthest := trf("chapter %d", m.resume.chapter+1)
+	if chapter := m.book.GetChapter(m.resume.chapter); chapter != nil {
+		furthest = trf("chapter %d, %s", m.resume.chapter+1, chapter.Title)
+	}
+
+	content := titleStyle.Render(tr("Welcome back")) + "\n\n" +
+		trf("You left off in chapter %d, but you have read up to\n%s.", m.currentChapter+1, furthest) + "\n\n" +
+		mutedStyle.Render(plain(tr("enter resume here • f jump to furthest")))
+
+	return lipgloss.NewStyle().
+		Border(panelBorder()).
+		BorderForeground(lipgloss.Color(theme.PrimaryColor)).
+		Padding(1, 2).
+		Render(content)
+}
+
+// changedBookView renders the prompt for a changed book file
+func (m *ReaderModel) changedBookView() string {
+	theme := m.config.ActiveTheme
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(theme.HeadingColor))
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.MutedTextColor))
+
+	keys := tr("enter keep position • s start over")
+	if m.resume.context != "" {
+		keys = tr("enter keep position • f find my place • s start over")
+	}
+	content := titleStyle.Render(tr("This book has changed")) + "\n\n" +
+		trf("The file was replaced since you last read it, so your\nplace in chapter %d may have moved.", m.currentChapter+1) + "\n\n" +
+		mutedStyle.Render(plain(keys))
+
+	return lipgloss.NewStyle().
+		Border(panelBorder()).
+		BorderForeground(lipgloss.Color(theme.PrimaryColor)).
+		Padding(1, 2).
+		Render(content)
+}
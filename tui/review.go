@@ -0,0 +1,109 @@
+package tui
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Longest review, to keep it to a few words
+const maxReviewLength = 280
+
+// reviewPrompt asks for a rating, then a short review, once a book is
+// finished
+type reviewPrompt struct {
+	path   string
+	title  string
+	rating int             // Stars given, 0 while choosing
+	input  textinput.Model // The review, typed after rating
+}
+
+// reviewPromptMsg asks for a review of a book marked finished in the
+// library
+type reviewPromptMsg struct {
+	path  string
+	title string
+}
+
+// askReview shows the review prompt for a finished book, unless it was
+// reviewed before
+func (m *Model) askReview(path, title string) {
+	if _, reviewed := m.library.reviews.Books[path]; reviewed {
+		return
+	}
+	m.review = &reviewPrompt{path: path, title: title}
+}
+
+// updateReview handles keys while the review prompt is shown: 1-5 rates
+// the book, then enter saves the review typed. Esc skips the review, or
+// the rating.
+func (m *Model) updateReview(msg tea.KeyMsg) tea.Cmd {
+	r := m.review
+	if r.rating == 0 {
+		switch key := msg.String(); key {
+		case "1", "2", "3", "4", "5":
+			r.rating, _ = strconv.Atoi(key)
+			r.input = textinput.New()
+			r.input.Prompt = "> "
+			r.input.Placeholder = tr("A few words (optional)")
+			r.input.CharLimit = maxReviewLength
+			r.input.Width = 40
+			return r.input.Focus()
+		case "esc", "ctrl+c":
+			m.review = nil
+		}
+		return nil
+	}
+
+	switch msg.String() {
+	case "enter":
+		return m.saveReview(r.input.Value())
+	case "esc", "ctrl+c":
+		return m.saveReview("")
+	}
+	var cmd tea.Cmd
+	r.input, cmd = r.input.Update(msg)
+	return cmd
+}
+
+// saveReview stores the rating and review, and closes the prompt
+func (m *Model) saveReview(text string) tea.Cmd {
+	r := m.review
+	m.review = nil
+	m.library.reviews.SetReview(r.path, config.Review{Rating: r.rating, Text: text, Created: time.Now()})
+	if err := config.SaveReviews(m.config, m.library.reviews); err != nil {
+		return notify(toastError, "Could not save the review: %v", err)
+	}
+	m.library.updateItems()
+	return notify(toastSuccess, "Rated %s %s", r.title, stars(r.rating))
+}
+
+// reviewView renders the review prompt overlay
+func (m *Model) reviewView() string {
+	theme := m.config.ActiveTheme
+	r := m.review
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(theme.HeadingColor))
+	starStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.PrimaryColor))
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.MutedTextColor))
+
+	content := titleStyle.Render(trf("You finished %s", r.title)) + "\n\n"
+	if r.rating == 0 {
+		content += tr("How many stars?") + " " + starStyle.Render(stars(0)) + "\n\n" +
+			mutedStyle.Render(plain(tr("1-5 to rate • esc to skip")))
+	} else {
+		content += tr("Rated") + " " + starStyle.Render(stars(r.rating)) + "\n\n" +
+			r.input.View() + "\n\n" +
+			mutedStyle.Render(plain(tr("enter to save • esc to save without a review")))
+	}
+
+	return lipgloss.NewStyle().
+		Border(panelBorder()).
+		BorderForeground(lipgloss.Color(theme.PrimaryColor)).
+		Padding(1, 2).
+		Render(content)
+}
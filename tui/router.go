@@ -0,0 +1,105 @@
+package tui
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// screen is a full-screen view of the app. The model keeps the open views
+// on a stack: showing a view pushes it on top of the current one, and going
+// back pops it, returning to the view below. Every view is kept at the
+// terminal size, so it fits whenever it is shown.
+type screen interface {
+	Update(tea.Msg) (tea.Model, tea.Cmd)
+	View() string
+	SetSize(width, height int)
+}
+
+// BackMsg closes the current view, returning to the one below it
+type BackMsg struct{}
+
+// screen returns the model of a view
+func (m *Model) screen(view View) screen {
+	switch view {
+	case ViewReader:
+		return m.reader
+	case ViewDetails:
+		return m.details
+	case ViewHelp:
+		return m.help
+	case ViewFiles:
+		return m.files
+	case ViewJournal:
+		return m.journal
+	}
+	return m.library
+}
+
+// screens returns every view's model, for resizing
+func (m *Model) screens() []screen {
+	return []screen{m.library, m.reader, m.details, m.help, m.files, m.journal}
+}
+
+// currentView returns the view on top of the stack. The library is always
+// at the bottom.
+func (m Model) currentView() View {
+	if len(m.views) == 0 {
+		return ViewLibrary
+	}
+	return m.views[len(m.views)-1]
+}
+
+// push shows a view on top of the current one. A view already open
+// further down is returned to instead, closing those above it, so a view
+// is never on the stack twice.
+func (m *Model) push(view View) tea.Cmd {
+	for i, open := range m.views {
+		if open == view {
+			return m.popTo(i + 1)
+		}
+	}
+	m.views = append(m.views, view)
+	return nil
+}
+
+// pop closes the current view, unless it is the library
+func (m *Model) pop() tea.Cmd {
+	return m.popTo(len(m.views) - 1)
+}
+
+// popTo closes the views above the first n, letting each wind down
+func (m *Model) popTo(n int) tea.Cmd {
+	n = max(n, 1)
+	var cmds []tea.Cmd
+	for len(m.views) > n {
+		cmds = append(cmds, m.leave(m.currentView()))
+		m.views = m.views[:len(m.views)-1]
+	}
+	if m.currentView() == ViewLibrary {
+		m.library.reloadProgress()
+	}
+	return tea.Batch(cmds...)
+}
+
+// leave winds a view down as it is closed: leaving the reader ends the
+// reading session, summing it up
+func (m *Model) leave(view View) tea.Cmd {
+	if view != ViewReader {
+		return nil
+	}
+	m.reader.stopFocus()
+	return m.endSession()
+}
+
+// updateView routes a message to the current view. The key reference only
+// takes input, so other messages, such as the reader's clock ticks, also
+// reach the view below it.
+func (m *Model) updateView(msg tea.Msg) tea.Cmd {
+	_, cmd := m.screen(m.currentView()).Update(msg)
+	switch msg.(type) {
+	case tea.KeyMsg, tea.MouseMsg:
+		return cmd
+	}
+	if m.currentView() == ViewHelp && len(m.views) > 1 {
+		_, below := m.screen(m.views[len(m.views)-2]).Update(msg)
+		cmd = tea.Batch(cmd, below)
+	}
+	return cmd
+}
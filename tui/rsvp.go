@@ -0,0 +1,276 @@
+package tui
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Speed reading flashes the text a word at a time, or rsvp_chunk words, in
+// the middle of the screen at rsvp_words_per_minute. The page behind it
+// follows along, so leaving speed reading continues at the same place.
+
+// Words skipped by rewinding or skipping ahead
+const rsvpSkipWords = 10
+
+// Change in speed per key press
+const rsvpSpeedStep = 25
+
+// rsvpKeyMap defines key bindings for speed reading
+type rsvpKeyMap struct {
+	Pause   key.Binding
+	Rewind  key.Binding
+	Forward key.Binding
+	Faster  key.Binding
+	Slower  key.Binding
+	Exit    key.Binding
+}
+
+func (k rsvpKeyMap) ShortHelp() []key.Binding {
+	return localized(k.Pause, k.Rewind, k.Forward, k.Faster, k.Slower, k.Exit)
+}
+
+func (k rsvpKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+var rsvpKeys = rsvpKeyMap{
+	Pause: key.NewBinding(
+		key.WithKeys(" ", "enter"),
+		key.WithHelp("space", "pause"),
+	),
+	Rewind: key.NewBinding(
+		key.WithKeys("h", "left"),
+		key.WithHelp("h/←", "rewind"),
+	),
+	Forward: key.NewBinding(
+		key.WithKeys("l", "right"),
+		key.WithHelp("l/→", "skip ahead"),
+	),
+	Faster: key.NewBinding(
+		key.WithKeys("k", "up", "+"),
+		key.WithHelp("k/+", "faster"),
+	),
+	Slower: key.NewBinding(
+		key.WithKeys("j", "down", "-"),
+		key.WithHelp("j/-", "slower"),
+	),
+	Exit: key.NewBinding(
+		key.WithKeys("esc", "r"),
+		key.WithHelp("esc", "back to the page"),
+	),
+}
+
+// rsvpState tracks speed reading through the current chapter
+type rsvpState struct {
+	active bool
+	paused bool
+	words  []rsvpWord
+	index  int // First word shown
+	timer  int // Identifies the current tick so stale ones are ignored
+}
+
+// rsvpWord is a word of the chapter and the rendered line it is on
+type rsvpWord struct {
+	text string
+	line int
+}
+
+// rsvpMsg shows the next words
+type rsvpMsg struct {
+	timer int
+}
+
+// startRSVP starts speed reading from the top of the page
+func (m *ReaderModel) startRSVP() tea.Cmd {
+	m.rsvp = rsvpState{active: true, timer: m.rsvp.timer}
+	m.rsvpChapter()
+	for i, word := range m.rsvp.words {
+		if word.line >= m.viewport.YOffset {
+			m.rsvp.index = i
+			break
+		}
+	}
+	return m.rsvpTick()
+}
+
+// stopRSVP goes back to the page, at the line last shown
+func (m *ReaderModel) stopRSVP() {
+	m.rsvp.active = false
+	m.rsvp.timer++
+	m.syncRSVP()
+}
+
+// rsvpChapter collects the words of the current chapter
+func (m *ReaderModel) rsvpChapter() {
+	m.rsvp.words = nil
+	m.rsvp.index = 0
+	start, end := m.chapterLines()
+	for line := start; line < end; line++ {
+		text := m.lines[line]
+		// Words are shown without the letter spacing
+		for _, word := range strings.Fields(strings.ReplaceAll(ansi.Strip(text), "\u00a0", "")) {
+			m.rsvp.words = append(m.rsvp.words, rsvpWord{text: word, line: line})
+		}
+	}
+}
+
+// rsvpChunk returns the words shown at once
+func (m *ReaderModel) rsvpChunk() []rsvpWord {
+	end := min(m.rsvp.index+max(m.config.Reading.RSVPChunk, 1), len(m.rsvp.words))
+	if m.rsvp.index >= end {
+		return nil
+	}
+	return m.rsvp.words[m.rsvp.index:end]
+}
+
+// syncRSVP scrolls the page to the line of the words shown
+func (m *ReaderModel) syncRSVP() {
+	if chunk := m.rsvpChunk(); len(chunk) > 0 {
+		m.viewport.SetYOffset(chunk[0].line)
+	}
+}
+
+// rsvpTick waits as long as the words shown take to read. The end of a
+// sentence or clause gets a longer pause.
+func (m *ReaderModel) rsvpTick() tea.Cmd {
+	if m.rsvp.paused {
+		return nil
+	}
+	chunk := m.rsvpChunk()
+	delay := time.Minute / time.Duration(m.config.Reading.RSVPWordsPerMinute) * time.Duration(max(len(chunk), 1))
+	if len(chunk) > 0 {
+		last, _ := utf8.DecodeLastRuneInString(strings.TrimRight(chunk[len(chunk)-1].text, `"'”’)»`))
+		switch {
+		case strings.ContainsRune(".!?…", last):
+			delay *= 2
+		case strings.ContainsRune(",;:—", last):
+			delay = delay * 3 / 2
+		}
+	}
+	m.rsvp.timer++
+	timer := m.rsvp.timer
+	return tea.Tick(delay, func(time.Time) tea.Msg {
+		return rsvpMsg{timer: timer}
+	})
+}
+
+// stepRSVP moves on to the next words, and into the next chapter at the
+// end of one
+func (m *ReaderModel) stepRSVP(msg rsvpMsg) tea.Cmd {
+	if msg.timer != m.rsvp.timer || !m.rsvp.active || m.rsvp.paused {
+		return nil
+	}
+	m.rsvp.index += max(m.config.Reading.RSVPChunk, 1)
+	for m.rsvp.index >= len(m.rsvp.words) {
+		if m.currentChapter >= m.book.ChapterCount()-1 {
+			m.rsvp.index = max(len(m.rsvp.words)-1, 0)
+			m.rsvp.paused = true
+			m.viewport.GotoBottom()
+			return nil
+		}
+		m.currentChapter++
+		m.updateViewport()
+		m.rsvpChapter()
+	}
+	m.syncRSVP()
+	return m.rsvpTick()
+}
+
+// updateRSVP handles keys while speed reading
+func (m *ReaderModel) updateRSVP(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, rsvpKeys.Exit):
+		m.stopRSVP()
+		return nil
+	case key.Matches(msg, rsvpKeys.Pause):
+		m.rsvp.paused = !m.rsvp.paused
+		return m.rsvpTick()
+	case key.Matches(msg, rsvpKeys.Rewind):
+		m.rsvp.index = max(m.rsvp.index-rsvpSkipWords, 0)
+	case key.Matches(msg, rsvpKeys.Forward):
+		m.rsvp.index = min(m.rsvp.index+rsvpSkipWords, max(len(m.rsvp.words)-1, 0))
+	case key.Matches(msg, rsvpKeys.Faster):
+		return m.setRSVPSpeed(m.config.Reading.RSVPWordsPerMinute + rsvpSpeedStep)
+	case key.Matches(msg, rsvpKeys.Slower):
+		return m.setRSVPSpeed(m.config.Reading.RSVPWordsPerMinute - rsvpSpeedStep)
+	default:
+		return nil
+	}
+	m.syncRSVP()
+	return m.rsvpTick()
+}
+
+// setRSVPSpeed changes the speed and remembers it in the config
+func (m *ReaderModel) setRSVPSpeed(wordsPerMinute int) tea.Cmd {
+	m.config.Reading.RSVPWordsPerMinute = max(rsvpSpeedStep, min(wordsPerMinute, 2000))
+	if err := saveToggle(m.config); err != nil {
+		return notify(toastError, "Could not save settings: %v", err)
+	}
+	return nil
+}
+
+// focusLetter returns the letter of a word the eye should rest on, a
+// little left of the middle
+func focusLetter(word string) int {
+	switch length := utf8.RuneCountInString(word); {
+	case length <= 1:
+		return 0
+	case length <= 5:
+		return 1
+	case length <= 9:
+		return 2
+	case length <= 13:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// rsvpView shows the words in the middle of the text area, the focus letter
+// of a single word lined up with the markers above and below it
+func (m *ReaderModel) rsvpView() string {
+	theme := m.config.ActiveTheme
+	textStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(theme.TextColor))
+	focusStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(theme.PrimaryColor))
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.MutedTextColor))
+
+	width := m.viewport.Width
+	center := width / 2
+	var words []string
+	for _, word := range m.rsvpChunk() {
+		words = append(words, word.text)
+	}
+	text := strings.Join(words, " ")
+
+	var line string
+	if len(words) == 1 {
+		runes := []rune(text)
+		focus := focusLetter(text)
+		line = strings.Repeat(" ", max(center-focus, 0)) +
+			textStyle.Render(string(runes[:focus])) +
+			focusStyle.Render(string(runes[focus])) +
+			textStyle.Render(string(runes[focus+1:]))
+	} else {
+		line = strings.Repeat(" ", max(center-ansi.StringWidth(text)/2, 0)) + textStyle.Render(text)
+	}
+
+	status := trf("%d wpm", m.config.Reading.RSVPWordsPerMinute)
+	if m.rsvp.paused {
+		status += " • " + tr("paused")
+	}
+	marker := strings.Repeat(" ", center)
+	rows := []string{
+		mutedStyle.Render(marker + "▾"),
+		line,
+		mutedStyle.Render(marker + "▴"),
+		"",
+		lipgloss.PlaceHorizontal(width, lipgloss.Center, mutedStyle.Render(plain(status))),
+	}
+	return lipgloss.Place(width, m.viewport.Height, lipgloss.Left, lipgloss.Center, strings.Join(rows, "\n"))
+}
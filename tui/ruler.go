@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// The reading ruler dims every line but the one being read, or a band of
+// three lines around it, to help keep one's place in dense text. j and k
+// move the ruler rather than the page, which follows when it reaches an
+// edge. display.ruler is "off", "line" or "band".
+
+// Rulers in the order the ruler key cycles through them
+var rulerModes = []string{"off", "line", "band"}
+
+// rulerActive reports whether the ruler is shown
+func (m *ReaderModel) rulerActive() bool {
+	return m.config.Display.Ruler == "line" || m.config.Display.Ruler == "band"
+}
+
+// cycleRuler switches to the next ruler and remembers it in the config
+func (m *ReaderModel) cycleRuler() string {
+	next := rulerModes[0]
+	for i, mode := range rulerModes {
+		if mode == m.config.Display.Ruler {
+			next = rulerModes[(i+1)%len(rulerModes)]
+		}
+	}
+	m.config.Display.Ruler = next
+	m.ruler = -1
+	if err := saveToggle(m.config); err != nil {
+		return trf("Could not save settings: %v", err)
+	}
+	return trf("Reading ruler: %s", tr(next))
+}
+
+// rulerLine returns the line the ruler is on. It stays on the page when
+// the page is scrolled some other way, and starts on the first line of
+// text.
+func (m *ReaderModel) rulerLine() int {
+	top := m.viewport.YOffset
+	bottom := min(top+m.viewport.Height, len(m.lines)) - 1
+	if m.ruler >= top && m.ruler <= bottom {
+		return m.ruler
+	}
+	start := top
+	if m.ruler > bottom {
+		start = bottom
+	}
+	if line, ok := m.textLineFrom(start, 1, bottom); ok {
+		return line
+	}
+	return start
+}
+
+// textLineFrom finds the first line with text from a line on, in a
+// direction, without passing limit
+func (m *ReaderModel) textLineFrom(line, step, limit int) (int, bool) {
+	for ; line >= 0 && line < len(m.lines); line += step {
+		if strings.TrimSpace(ansi.Strip(m.lines[line])) != "" {
+			return line, true
+		}
+		if line == limit {
+			break
+		}
+	}
+	return 0, false
+}
+
+// moveRuler moves the ruler to the next or previous line of text,
+// scrolling the page to keep it in view. Past the first or last line the
+// page scrolls as usual, which may open another chapter.
+func (m *ReaderModel) moveRuler(step int) tea.Cmd {
+	line, ok := m.textLineFrom(m.rulerLine()+step, step, -1)
+	if !ok {
+		return m.scrollBy(step)
+	}
+	m.ruler = line
+	switch top := m.viewport.YOffset; {
+	case line < top:
+		m.viewport.SetYOffset(line)
+	case line >= top+m.viewport.Height:
+		m.viewport.SetYOffset(line - m.viewport.Height + 1)
+	}
+	return nil
+}
+
+// applyRuler dims the visible lines away from the ruler
+func (m *ReaderModel) applyRuler(content string) string {
+	if !m.rulerActive() {
+		return content
+	}
+	band := 0
+	if m.config.Display.Ruler == "band" {
+		band = 1
+	}
+	ruler := m.rulerLine() - m.viewport.YOffset
+	dim := lipgloss.NewStyle().Faint(true).Foreground(lipgloss.Color(m.config.ActiveTheme.MutedTextColor))
+
+	rows := strings.Split(content, "\n")
+	for i, row := range rows {
+		if i < ruler-band || i > ruler+band {
+			rows[i] = lineSize(row) + dim.Render(ansi.Strip(row))
+		}
+	}
+	return strings.Join(rows, "\n")
+}
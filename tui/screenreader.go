@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/paginator"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Screen reader mode keeps the screen to text that reads well aloud: no
+// colors or other styling, no borders, rules or bullets, and a plain line
+// on the status bar when the chapter changes. It is turned on with
+// display.screen_reader or --screen-reader.
+
+// screenReader is set from the config like the color profile, as both
+// change how everything is drawn
+var screenReader bool
+
+// applyScreenReader switches screen reader mode, after the color profile
+// has been applied
+func applyScreenReader(cfg *config.Config) {
+	screenReader = cfg.Display.ScreenReader
+	if screenReader {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+}
+
+// plain swaps the dots between items on a line for commas in screen
+// reader mode
+func plain(s string) string {
+	if !screenReader {
+		return s
+	}
+	return strings.ReplaceAll(s, " • ", ", ")
+}
+
+// panelBorder returns the border drawn around popups, blank in screen
+// reader mode
+func panelBorder() lipgloss.Border {
+	if screenReader {
+		return lipgloss.HiddenBorder()
+	}
+	return lipgloss.RoundedBorder()
+}
+
+// rule returns a line across the screen, blank in screen reader mode
+func rule(width int) string {
+	if screenReader {
+		return ""
+	}
+	return strings.Repeat("─", width)
+}
+
+// plainList draws a list for the current mode. In screen reader mode a ">"
+// marks the selected item instead of a colored bar, and pages are numbered
+// rather than shown as dots.
+func plainList(l *list.Model) {
+	delegate := list.NewDefaultDelegate()
+	l.Help.ShortSeparator = " • "
+	l.Paginator.Type = paginator.Dots
+	if screenReader {
+		delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.BorderStyle(lipgloss.Border{Left: ">"})
+		delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.BorderStyle(lipgloss.Border{Left: " "})
+		l.Help.ShortSeparator = ", "
+		l.Paginator.Type = paginator.Arabic
+	}
+	l.SetDelegate(delegate)
+}
+
+// chapterAnnouncement is the status line telling of a new chapter
+func (m *ReaderModel) chapterAnnouncement() string {
+	text := trf("Chapter %d of %d", m.currentChapter+1, m.book.ChapterCount())
+	if chapter := m.book.GetChapter(m.currentChapter); chapter != nil && chapter.Title != "" {
+		text += ": " + chapter.Title
+	}
+	return text
+}
@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const scrollFrameTime = 16 * time.Millisecond
+
+// scrollState tracks a smooth scroll animation
+type scrollState struct {
+	animating bool
+	target    int
+	timer     int // Identifies the current animation so stale frames are ignored
+}
+
+// scrollFrameMsg advances a smooth scroll animation
+type scrollFrameMsg struct {
+	timer int
+}
+
+// scrollBy moves the view by a number of lines. At the end of a chapter it
+// continues into the next or previous one when flow_chapters is set, and
+// with smooth_scroll it animates the movement.
+func (m *ReaderModel) scrollBy(lines int) tea.Cmd {
+	from := m.viewport.YOffset
+	if m.scroll.animating {
+		from = m.scroll.target
+	}
+	maxOffset := max(0, m.viewport.TotalLineCount()-m.viewport.Height)
+
+	switch {
+	case lines > 0 && from >= maxOffset:
+		m.flowChapter(1)
+		return nil
+	case lines < 0 && from <= 0:
+		m.flowChapter(-1)
+		return nil
+	}
+
+	target := max(0, min(from+lines, maxOffset))
+	if !m.config.Display.SmoothScroll || abs(target-m.viewport.YOffset) <= 1 {
+		m.scroll.animating = false
+		m.viewport.SetYOffset(target)
+		return nil
+	}
+
+	m.scroll.target = target
+	if m.scroll.animating {
+		return nil // The running animation picks up the new target
+	}
+	m.scroll.animating = true
+	m.scroll.timer++
+	return m.scrollFrame()
+}
+
+// scrollStep returns how far the scroll keys move: scroll_lines, or a page
+// in paged mode
+func (m *ReaderModel) scrollStep() int {
+	if m.config.Display.Paged {
+		return m.pageStep()
+	}
+	return max(m.config.Display.ScrollLines, 1)
+}
+
+// pageStep returns how far turning a page moves, keeping page_overlap
+// lines of the last page in view
+func (m *ReaderModel) pageStep() int {
+	return max(m.viewport.Height-m.config.Display.PageOverlap, 1)
+}
+
+// flowChapter continues reading in the adjacent chapter
+func (m *ReaderModel) flowChapter(direction int) {
+	next := m.currentChapter + direction
+	if !m.config.Reading.FlowChapters || next < 0 || next >= m.book.ChapterCount() {
+		return
+	}
+	// The continuous text already runs on into the chapters around it and
+	// only ends with the book
+	if m.config.Reading.Continuous {
+		return
+	}
+
+	m.scroll.animating = false
+	m.currentChapter = next
+	m.updateViewport()
+	if direction < 0 {
+		m.viewport.GotoBottom()
+	}
+}
+
+func (m *ReaderModel) scrollFrame() tea.Cmd {
+	timer := m.scroll.timer
+	return tea.Tick(scrollFrameTime, func(time.Time) tea.Msg {
+		return scrollFrameMsg{timer: timer}
+	})
+}
+
+// stepScroll moves a third of the remaining distance each frame, so the
+// animation eases out
+func (m *ReaderModel) stepScroll() tea.Cmd {
+	distance := m.scroll.target - m.viewport.YOffset
+	step := distance / 3
+	if step == 0 {
+		step = distance
+	}
+	m.viewport.SetYOffset(m.viewport.YOffset + step)
+
+	if m.viewport.YOffset == m.scroll.target || step == 0 {
+		m.scroll.animating = false
+		return nil
+	}
+	return m.scrollFrame()
+}
@@ -0,0 +1,122 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/cbrasser/cozy/ebook"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// With display.scrollbar the reader draws a scrollbar down the right edge,
+// the whole chapter squeezed into the height of the page. Besides the part
+// in view it marks headings, bookmarks and marks, highlights and search
+// matches, so the shape of the chapter shows at a glance.
+
+// Columns the scrollbar takes from the right margin, a space and the bar
+const scrollbarWidth = 2
+
+// Markers on the scrollbar, by what is on the lines a row stands for. The
+// first kind found on a row wins.
+const (
+	scrollbarMatch     = "◆"
+	scrollbarBookmark  = "●"
+	scrollbarHeading   = "■"
+	scrollbarHighlight = "▪"
+	scrollbarThumb     = "┃"
+	scrollbarTrack     = "│"
+)
+
+// scrollbarShown reports whether the scrollbar is drawn. Double width lines
+// have no room for it.
+func (m *ReaderModel) scrollbarShown() bool {
+	return m.config.Display.Scrollbar && !m.config.Display.Zen && !screenReader && !renderOptions(m.config).DoubleWidth()
+}
+
+// scrollbarRows returns the marker for each row of the scrollbar, or "" for
+// none
+func (m *ReaderModel) scrollbarRows(height int) []string {
+	rows := make([]string, height)
+	total := max(m.viewport.TotalLineCount(), 1)
+	row := func(line int) int {
+		return min(line*height/total, height-1)
+	}
+	mark := func(line int, marker string) {
+		if line >= 0 && line < total && rows[row(line)] == "" {
+			rows[row(line)] = marker
+		}
+	}
+
+	for _, match := range m.find.matches {
+		mark(match.line, scrollbarMatch)
+	}
+	for _, bookmark := range m.Bookmarks() {
+		if bookmark.Chapter == m.currentChapter {
+			mark(m.positionLine(bookmark.ScrollOffset, bookmark.TextOffset), scrollbarBookmark)
+		}
+	}
+	for name, mk := range m.Marks() {
+		if name != lastJumpMark && mk.Chapter == m.currentChapter {
+			mark(m.positionLine(mk.ScrollOffset, mk.TextOffset), scrollbarBookmark)
+		}
+	}
+	for _, heading := range m.headings {
+		mark(heading.Line, scrollbarHeading)
+	}
+	for line := range m.highlightRanges {
+		mark(line, scrollbarHighlight)
+	}
+	return rows
+}
+
+// positionLine returns the line of a saved position, like restorePosition
+func (m *ReaderModel) positionLine(scrollOffset, textOffset int) int {
+	if textOffset > 0 {
+		return m.textOffsetLine(textOffset)
+	}
+	return m.chapterStart() + scrollOffset
+}
+
+// applyScrollbar draws the scrollbar at the right edge of the page
+func (m *ReaderModel) applyScrollbar(content string) string {
+	if !m.scrollbarShown() {
+		return content
+	}
+
+	theme := m.config.ActiveTheme
+	trackStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.MutedTextColor))
+	thumbStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.SecondaryColor))
+	markerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.PrimaryColor))
+
+	lines := strings.Split(content, "\n")
+	height := m.viewport.Height
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+
+	// The thumb covers the rows of the lines in view, at least one
+	total := max(m.viewport.TotalLineCount(), 1)
+	thumbStart := m.viewport.YOffset * height / total
+	thumbEnd := max((m.viewport.YOffset+height)*height/total, thumbStart+1)
+
+	rows := m.scrollbarRows(height)
+	for i := range height {
+		bar := trackStyle.Render(scrollbarTrack)
+		switch {
+		case rows[i] != "" && i >= thumbStart && i < thumbEnd:
+			bar = thumbStyle.Render(rows[i])
+		case rows[i] != "":
+			bar = markerStyle.Render(rows[i])
+		case i >= thumbStart && i < thumbEnd:
+			bar = thumbStyle.Render(scrollbarThumb)
+		}
+		// Large headings are drawn at double width, see largetext.go
+		column := m.width - 1
+		if size := lineSize(lines[i]); size != "" && size != ebook.SingleWidth {
+			column = m.width/2 - 1
+		}
+		line := ansi.Truncate(lines[i], column, "")
+		lines[i] = line + strings.Repeat(" ", max(column-ansi.StringWidth(line), 0)) + bar
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,154 @@
+package tui
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// BookFinishedMsg is sent when the end of a book is reached
+type BookFinishedMsg struct {
+	Path string
+}
+
+// seriesOf returns a book's series and its position in it, from the
+// calibre:series or belongs-to-collection metadata
+func seriesOf(metadata map[string]string) (string, float64, bool) {
+	series := metadata["series"]
+	if series == "" {
+		return "", 0, false
+	}
+	index, err := strconv.ParseFloat(metadata["series_index"], 64)
+	if err != nil {
+		index = 0
+	}
+	return series, index, true
+}
+
+// seriesLengths returns the number of books in each series of the library:
+// the books found, or the highest position if books are missing
+func (m *LibraryModel) seriesLengths() map[string]int {
+	lengths := make(map[string]int)
+	counts := make(map[string]int)
+	for _, book := range m.books {
+		series, index, ok := seriesOf(book.Metadata)
+		if !ok {
+			continue
+		}
+		counts[series]++
+		lengths[series] = max(lengths[series], counts[series], int(math.Ceil(index)))
+	}
+	return lengths
+}
+
+// seriesLabel describes a book's place in its series, e.g. "Book 3 of 7
+// in The Expanse"
+func seriesLabel(metadata map[string]string, lengths map[string]int) string {
+	series, index, ok := seriesOf(metadata)
+	if !ok {
+		return ""
+	}
+	if index <= 0 {
+		return series
+	}
+	position := metadata["series_index"]
+	return trf("Book %s of %d in %s", position, lengths[series], series)
+}
+
+// groupSeries moves the books of a series together, in series order, to
+// where the first of them is listed
+func groupSeries(items []list.Item) []list.Item {
+	members := make(map[string][]bookItem)
+	for _, listItem := range items {
+		if item, ok := listItem.(bookItem); ok {
+			if series, _, ok := seriesOf(item.metadata); ok {
+				members[series] = append(members[series], item)
+			}
+		}
+	}
+
+	grouped := make([]list.Item, 0, len(items))
+	placed := make(map[string]bool)
+	for _, listItem := range items {
+		item, ok := listItem.(bookItem)
+		series, _, inSeries := seriesOf(item.metadata)
+		if !ok || !inSeries {
+			grouped = append(grouped, listItem)
+			continue
+		}
+		if placed[series] {
+			continue
+		}
+		placed[series] = true
+		books := members[series]
+		sort.SliceStable(books, func(i, j int) bool {
+			_, a, _ := seriesOf(books[i].metadata)
+			_, b, _ := seriesOf(books[j].metadata)
+			return a < b
+		})
+		for _, book := range books {
+			grouped = append(grouped, book)
+		}
+	}
+	return grouped
+}
+
+// nextInSeries returns the unfinished book that follows a book in its
+// series
+func (m *LibraryModel) nextInSeries(path string) (bookItem, bool) {
+	current, ok := m.findItem(path)
+	if !ok {
+		return bookItem{}, false
+	}
+	series, index, ok := seriesOf(current.metadata)
+	if !ok {
+		return bookItem{}, false
+	}
+
+	var next bookItem
+	found := false
+	for _, listItem := range m.list.Items() {
+		item, ok := listItem.(bookItem)
+		if !ok || item.finished || item.err != nil || item.path == path {
+			continue
+		}
+		itemSeries, itemIndex, ok := seriesOf(item.metadata)
+		if !ok || itemSeries != series || itemIndex <= index {
+			continue
+		}
+		if _, nextIndex, _ := seriesOf(next.metadata); !found || itemIndex < nextIndex {
+			next, found = item, true
+		}
+	}
+	return next, found
+}
+
+// offerNextInSeries asks whether to open the next book of the series once
+// a book is finished
+func (m *Model) offerNextInSeries(path string) tea.Cmd {
+	next, ok := m.library.nextInSeries(path)
+	if !ok {
+		return nil
+	}
+	question := trf("Open the next in the series, %s?", next.title)
+	if label := next.series; label != "" {
+		question = trf("Open the next in the series, %s (%s)?", next.title, label)
+	}
+	return m.offerBook(question, next)
+}
+
+// offerBook asks on the command line whether to open a book, saving the
+// progress of the one being read first
+func (m *Model) offerBook(question string, next bookItem) tea.Cmd {
+	m.command.message, _, _ = m.confirm(question, func() (string, tea.Cmd, error) {
+		if err := m.reader.SaveProgress(); err != nil {
+			return "", nil, err
+		}
+		return "", m.library.openBook(next), nil
+	})
+	m.command.isError = false
+	return nil
+}
@@ -0,0 +1,203 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Sessions shorter than this only get a summary if they moved the reader
+// forward
+const minSessionLength = time.Minute
+
+// sessionState is where a reading session started
+type sessionState struct {
+	started   time.Time
+	percent   float64 // Book percentage at the start
+	wordsLeft int
+
+	paused   time.Duration // Time on breaks, which doesn't count
+	pausedAt time.Time     // Start of the current break
+}
+
+// sessionSummary is what a reading session covered. It is shown when
+// leaving a book.
+type sessionSummary struct {
+	title        string
+	duration     time.Duration
+	words        int
+	pages        int // Pages of words_per_page words, rounded
+	startPercent float64
+	endPercent   float64
+	timeLeft     string
+	pace         int  // Words per minute the estimate is based on
+	measured     bool // pace is the speed of this session
+	quitting     bool // Quit once the summary is dismissed
+}
+
+// startSession starts counting a reading session from the current position
+func (m *ReaderModel) startSession() {
+	m.session = sessionState{started: time.Now()}
+	m.markSessionStart()
+}
+
+// markSessionStart counts the session from the current position, so jumps
+// such as to the furthest position read don't count as reading
+func (m *ReaderModel) markSessionStart() {
+	m.session.percent = m.bookPercent()
+	m.session.wordsLeft = m.wordsLeft()
+}
+
+// pauseSession stops counting reading time, for a break
+func (m *ReaderModel) pauseSession() {
+	if m.session.pausedAt.IsZero() {
+		m.session.pausedAt = time.Now()
+	}
+}
+
+// resumeSession counts reading time again after a break
+func (m *ReaderModel) resumeSession() {
+	if !m.session.pausedAt.IsZero() {
+		m.session.paused += time.Since(m.session.pausedAt)
+		m.session.pausedAt = time.Time{}
+	}
+}
+
+// endSession ends the session and summarizes it. It returns nil when no
+// session was running.
+func (m *ReaderModel) endSession() *sessionSummary {
+	if m.book == nil || m.session.started.IsZero() {
+		return nil
+	}
+	m.resumeSession()
+	summary := &sessionSummary{
+		title:        m.book.Title,
+		duration:     time.Since(m.session.started) - m.session.paused,
+		words:        max(0, m.session.wordsLeft-m.wordsLeft()),
+		startPercent: m.session.percent,
+		endPercent:   m.bookPercent(),
+		pace:         m.config.Reading.WordsPerMinute,
+	}
+	m.session = sessionState{}
+	wordsPerPage := m.config.Reading.WordsPerPage
+	summary.pages = (summary.words + wordsPerPage/2) / wordsPerPage
+
+	// Estimate the rest at the speed of this session once it is long
+	// enough to tell
+	if minutes := summary.duration.Minutes(); minutes >= 5 && summary.words > 0 {
+		summary.pace = int(float64(summary.words) / minutes)
+		summary.measured = true
+	}
+	summary.timeLeft = formatTimeLeft(m.wordsLeft(), summary.pace)
+	return summary
+}
+
+// updateSummary handles keys while the session summary is shown: any key
+// dismisses it, and quits if it was shown on quitting
+func (m *Model) updateSummary(msg tea.KeyMsg) tea.Cmd {
+	quitting := m.summary.quitting || msg.String() == "ctrl+c"
+	m.summary = nil
+	if quitting {
+		return tea.Quit
+	}
+	return nil
+}
+
+// summaryView renders the session summary overlay
+func (m *Model) summaryView() string {
+	theme := m.config.ActiveTheme
+	s := m.summary
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(theme.HeadingColor))
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.SecondaryColor)).Width(10)
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.MutedTextColor))
+
+	minutes := int(s.duration.Minutes())
+	reading := trf("%d min", minutes)
+	switch {
+	case minutes < 1:
+		reading = tr("under a minute")
+	case minutes >= 60:
+		reading = trf("%dh %02d min", minutes/60, minutes%60)
+	}
+
+	covered := trf("%d words", s.words)
+	if s.pages > 0 {
+		covered = trf("%d words, about %d pages", s.words, s.pages)
+	}
+
+	progress := fmt.Sprintf("%.0f%% → %.0f%%", s.startPercent, s.endPercent)
+	if advanced := s.endPercent - s.startPercent; advanced >= 0.5 {
+		progress += fmt.Sprintf(" (+%.0f%%)", advanced)
+	}
+
+	left := trf("%s to go", s.timeLeft)
+	if s.measured {
+		left = trf("%s to go at %d wpm", s.timeLeft, s.pace)
+	}
+	if s.endPercent >= 99.5 {
+		left = tr("Finished!")
+	}
+
+	action := tr("any key to continue")
+	if s.quitting {
+		action = tr("any key to quit")
+	}
+
+	content := titleStyle.Render(tr("Reading session")) + "\n" +
+		mutedStyle.Render(s.title) + "\n\n" +
+		labelStyle.Render(tr("Time")) + reading + "\n" +
+		labelStyle.Render(tr("Read")) + covered + "\n" +
+		labelStyle.Render(tr("Progress")) + progress + "\n" +
+		labelStyle.Render(tr("Left")) + left + "\n\n" +
+		mutedStyle.Render(action)
+
+	return lipgloss.NewStyle().
+		Border(panelBorder()).
+		BorderForeground(lipgloss.Color(theme.PrimaryColor)).
+		Padding(1, 2).
+		Render(content)
+}
+
+// quit saves the reading progress and quits, after showing the session
+// summary if there is one
+func (m *Model) quit() tea.Cmd {
+	m.reader.stopReadAloud()
+	if m.currentView() != ViewReader {
+		return tea.Quit
+	}
+	m.reader.SaveProgress()
+	hook := m.endSession()
+	if m.summary != nil {
+		m.summary.quitting = true
+		return hook
+	}
+	return tea.Batch(hook, tea.Quit)
+}
+
+// endSession ends the reading session and runs the session_ended hook. The
+// summary is kept to show unless summaries are disabled or nothing was
+// read.
+func (m *Model) endSession() tea.Cmd {
+	summary := m.reader.endSession()
+	if summary == nil {
+		return nil
+	}
+	hook := runHook(m.config, hookEvent{
+		Event: hookSessionEnded,
+		Book:  m.reader.hookBook(),
+		Session: &hookSession{
+			Minutes:      summary.duration.Minutes(),
+			Words:        summary.words,
+			Pages:        summary.pages,
+			StartPercent: summary.startPercent,
+			EndPercent:   summary.endPercent,
+		},
+	})
+	if m.config.Reading.SessionSummary && (summary.duration >= minSessionLength || summary.words > 0) {
+		m.summary = summary
+	}
+	return hook
+}
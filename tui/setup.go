@@ -0,0 +1,291 @@
+package tui
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/cbrasser/cozy/ebook"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Steps of the setup wizard
+const (
+	setupLibrary = iota
+	setupTheme
+	setupMode
+)
+
+// Shown in the theme step, rendered as a book would be
+const setupPreview = `<h1>Chapter One</h1>
+<p>It was a <em>quiet</em> evening, and the lamp threw a <strong>warm</strong> circle of light across the page.</p>
+<blockquote>Books are a uniquely portable magic.</blockquote>
+<p>See <a href="#notes">the notes</a> for more.</p>`
+
+// setupKeyMap defines key bindings for the setup wizard
+type setupKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Choose key.Binding
+	Back   key.Binding
+	Skip   key.Binding
+}
+
+func (k setupKeyMap) ShortHelp() []key.Binding {
+	return localized(k.Up, k.Down, k.Choose, k.Back, k.Skip)
+}
+
+func (k setupKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+var setupKeys = setupKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "down"),
+	),
+	Choose: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "choose"),
+	),
+	Back: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "back"),
+	),
+	Skip: key.NewBinding(
+		key.WithKeys("ctrl+c"),
+		key.WithHelp("ctrl+c", "skip setup"),
+	),
+}
+
+// SetupModel walks a new user through the main settings: the library
+// folder, the theme and how the reader scrolls
+type SetupModel struct {
+	config  *config.Config
+	step    int
+	browser dirBrowser
+	themes  []string
+	theme   int // Index of the theme tried on
+	paged   bool
+	help    help.Model
+	err     error
+	width   int
+	height  int
+
+	// The theme before setup, put back when it's skipped
+	originalName  string
+	originalTheme *config.Theme
+}
+
+// NewSetupModel creates a setup wizard starting from the settings in cfg
+func NewSetupModel(cfg *config.Config) *SetupModel {
+	themes, _ := config.ListThemes()
+	sort.Strings(themes)
+	m := &SetupModel{
+		config:        cfg,
+		browser:       newDirBrowser(cfg.Library.Path, false),
+		themes:        themes,
+		paged:         cfg.Display.Paged,
+		help:          help.New(),
+		originalName:  cfg.ThemeName,
+		originalTheme: cfg.ActiveTheme,
+	}
+	for i, name := range themes {
+		if name == cfg.ThemeName {
+			m.theme = i
+		}
+	}
+	return m
+}
+
+// RunSetup runs the setup wizard and saves the settings chosen to
+// config.toml. Skipping it keeps the defaults.
+func RunSetup(cfg *config.Config) error {
+	applyScreenReader(cfg)
+	applyLanguage(cfg)
+	_, err := tea.NewProgram(NewSetupModel(cfg), tea.WithAltScreen()).Run()
+	return err
+}
+
+// Init initializes the setup wizard
+func (m *SetupModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles keys in the setup wizard
+func (m *SetupModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.help.Width = msg.Width
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, setupKeys.Skip):
+			m.config.ThemeName, m.config.ActiveTheme = m.originalName, m.originalTheme
+			return m, tea.Quit
+		case key.Matches(msg, setupKeys.Back):
+			if m.step == setupLibrary {
+				m.config.ThemeName, m.config.ActiveTheme = m.originalName, m.originalTheme
+				return m, tea.Quit
+			}
+			m.step--
+			return m, nil
+		case key.Matches(msg, setupKeys.Choose):
+			return m, m.next()
+		}
+
+		switch m.step {
+		case setupLibrary:
+			m.browser.update(msg)
+		case setupTheme:
+			switch {
+			case key.Matches(msg, setupKeys.Up):
+				m.tryTheme(m.theme - 1)
+			case key.Matches(msg, setupKeys.Down):
+				m.tryTheme(m.theme + 1)
+			}
+		case setupMode:
+			if key.Matches(msg, setupKeys.Up, setupKeys.Down) {
+				m.paged = !m.paged
+			}
+		}
+	}
+	return m, nil
+}
+
+// tryTheme switches to a theme, so the wizard and the preview show it
+func (m *SetupModel) tryTheme(index int) {
+	if len(m.themes) == 0 {
+		return
+	}
+	m.theme = (index + len(m.themes)) % len(m.themes)
+	theme, err := config.LoadTheme(m.themes[m.theme])
+	if err != nil {
+		m.err = err
+		return
+	}
+	m.err = nil
+	m.config.ThemeName = m.themes[m.theme]
+	m.config.ActiveTheme = theme
+}
+
+// next goes on to the next step, saving the settings after the last one
+func (m *SetupModel) next() tea.Cmd {
+	if m.step < setupMode {
+		m.step++
+		return nil
+	}
+	m.config.SetLibrary(m.browser.dir)
+	m.config.Display.Paged = m.paged
+	if err := config.Save(m.config); err != nil {
+		m.err = err
+		return nil
+	}
+	return tea.Quit
+}
+
+// View renders the current step
+func (m *SetupModel) View() string {
+	theme := m.config.ActiveTheme
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(theme.PrimaryColor))
+	textStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.TextColor))
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.MutedTextColor))
+	selectedStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(theme.SecondaryColor))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+
+	var title, intro, body string
+	// Rows left for the step's own content
+	rows := max(m.height-9, 3)
+	switch m.step {
+	case setupLibrary:
+		title = tr("Where are your books?")
+		intro = tr("Browse to the folder holding your e-books and press enter to use it.")
+		body = m.browser.view(rows, selectedStyle, textStyle, mutedStyle)
+	case setupTheme:
+		title = tr("Pick a theme")
+		intro = tr("Move through the themes to try them on.")
+		body = m.themeView(rows, selectedStyle, textStyle)
+	case setupMode:
+		title = tr("How should the reader move?")
+		intro = tr("You can change this later with :set paged on|off.")
+		body = m.modeView(selectedStyle, textStyle, mutedStyle)
+	}
+
+	lines := []string{
+		titleStyle.Render(tr("Welcome to cozy")),
+		mutedStyle.Render(trf("Step %d of 3", m.step+1)),
+		"",
+		titleStyle.Render(title),
+		textStyle.Render(intro),
+		"",
+		body,
+	}
+	if m.err != nil {
+		lines = append(lines, "", errorStyle.Render(m.err.Error()))
+	}
+	view := lipgloss.NewStyle().Padding(1, 2).Render(strings.Join(lines, "\n"))
+
+	keys := []key.Binding{setupKeys.Choose, setupKeys.Back, setupKeys.Skip}
+	if m.step == setupLibrary {
+		keys = append([]key.Binding{browserKeys.Up, browserKeys.Down, browserKeys.Open, browserKeys.Parent}, keys...)
+		keys = append(keys, browserKeys.Hidden)
+	} else {
+		keys = append([]key.Binding{setupKeys.Up, setupKeys.Down}, keys...)
+	}
+	footer := plain(m.help.ShortHelpView(keys))
+	gap := max(m.height-lipgloss.Height(view)-1, 0)
+	return view + strings.Repeat("\n", gap+1) + footer
+}
+
+// themeView lists the themes beside a preview of the one tried on
+func (m *SetupModel) themeView(rows int, selected, normal lipgloss.Style) string {
+	var names []string
+	for i, name := range m.themes {
+		if i == m.theme {
+			names = append(names, selected.Render("> "+name))
+		} else {
+			names = append(names, normal.Render("  "+name))
+		}
+	}
+	// Keep the theme tried on in view
+	first := max(0, min(m.theme-rows/2, len(names)-rows))
+	names = names[first:min(first+rows, len(names))]
+
+	width := max(min(m.width-40, 60), 20)
+	preview := lipgloss.NewStyle().
+		Width(width+2).
+		Border(panelBorder()).
+		BorderForeground(lipgloss.Color(m.config.ActiveTheme.PrimaryColor)).
+		Padding(0, 1).
+		Render(strings.TrimSpace(ebook.RenderToStyledText(setupPreview, m.config.ActiveTheme, width)))
+	return lipgloss.JoinHorizontal(lipgloss.Top, strings.Join(names, "\n"), "    ", preview)
+}
+
+// modeView offers scrolling line by line or turning pages
+func (m *SetupModel) modeView(selected, normal, muted lipgloss.Style) string {
+	options := []struct {
+		name, desc string
+		paged      bool
+	}{
+		{"Scroll", "j and k move a line at a time, like a web page", false},
+		{"Pages", "j and k turn a whole screen at a time, like a book", true},
+	}
+	var lines []string
+	for _, option := range options {
+		if option.paged == m.paged {
+			lines = append(lines, selected.Render("> "+tr(option.name)))
+		} else {
+			lines = append(lines, normal.Render("  "+tr(option.name)))
+		}
+		lines = append(lines, muted.Render("    "+tr(option.desc)))
+	}
+	return strings.Join(lines, "\n")
+}
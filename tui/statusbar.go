@@ -0,0 +1,121 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cbrasser/cozy/ebook"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// clockMsg redraws the footer so {clock} stays current
+type clockMsg struct {
+	timer int
+}
+
+// tickClock starts updating the footer every minute
+func (m *ReaderModel) tickClock() tea.Cmd {
+	m.clockTimer++
+	timer := m.clockTimer
+	return tea.Every(time.Minute, func(time.Time) tea.Msg {
+		return clockMsg{timer: timer}
+	})
+}
+
+// statusFormat returns the footer template for the current detail level
+func (m *ReaderModel) statusFormat() string {
+	if m.statusMinimal {
+		return m.config.Display.StatusFormatMinimal
+	}
+	return m.config.Display.StatusFormat
+}
+
+// progressText fills in the footer template
+func (m *ReaderModel) progressText() string {
+	chapter := m.book.GetChapter(m.currentChapter)
+	chapterTitle := ""
+	if chapter != nil {
+		chapterTitle = chapter.Title
+	}
+
+	page, pages := m.page()
+	return strings.NewReplacer(
+		"{book_title}", m.book.Title,
+		"{author}", m.book.Byline(),
+		"{chapter}", fmt.Sprint(m.currentChapter+1),
+		"{chapters}", fmt.Sprint(m.book.ChapterCount()),
+		"{chapter_title}", chapterTitle,
+		"{scroll}", fmt.Sprintf("%.0f", m.chapterPercent()*100),
+		"{percent}", fmt.Sprintf("%.0f", m.bookPercent()),
+		"{pages}", fmt.Sprint(pages),
+		"{page}", fmt.Sprint(page),
+		"{time_left}", formatTimeLeft(m.wordsLeft(), m.config.Reading.WordsPerMinute),
+		"{clock}", time.Now().Format("15:04"),
+	).Replace(tr(m.statusFormat()))
+}
+
+// bookPercent returns how far into the book the reader is, weighting
+// chapters by length like GotoPercent
+func (m *ReaderModel) bookPercent() float64 {
+	total, read := 0, 0.0
+	for i, chapter := range m.book.Chapters {
+		length := chapter.Length()
+		total += length
+		switch {
+		case i < m.currentChapter:
+			read += float64(length)
+		case i == m.currentChapter:
+			read += m.chapterPercent() * float64(length)
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return read / float64(total) * 100
+}
+
+// wordsLeft estimates the number of words until the end of the book
+func (m *ReaderModel) wordsLeft() int {
+	if len(m.chapterWords) != m.book.ChapterCount() {
+		m.chapterWords = make([]int, m.book.ChapterCount())
+		for i := range m.book.Chapters {
+			m.chapterWords[i] = m.book.Chapters[i].WordCount()
+		}
+	}
+
+	left := 0.0
+	for i := m.currentChapter; i < len(m.chapterWords); i++ {
+		words := float64(m.chapterWords[i])
+		if i == m.currentChapter {
+			words *= 1 - m.chapterPercent()
+		}
+		left += words
+	}
+	return int(left)
+}
+
+// page returns the page the reader is on and the number of pages in the
+// book, in pages of the configured number of words
+func (m *ReaderModel) page() (int, int) {
+	left := m.wordsLeft()
+	total := 0
+	for _, words := range m.chapterWords {
+		total += words
+	}
+	wordsPerPage := m.config.Reading.WordsPerPage
+	pages := max(ebook.Pages(total, wordsPerPage), 1)
+	return min(ebook.Pages(total-left+1, wordsPerPage), pages), pages
+}
+
+// formatTimeLeft turns a word count into a reading time like "1h 05m"
+func formatTimeLeft(words, wordsPerMinute int) string {
+	if wordsPerMinute <= 0 {
+		wordsPerMinute = 250
+	}
+	minutes := (words + wordsPerMinute - 1) / wordsPerMinute
+	if minutes >= 60 {
+		return trf("%dh %02dm", minutes/60, minutes%60)
+	}
+	return trf("%d min", minutes)
+}
@@ -0,0 +1,123 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	toastDuration      = 3 * time.Second
+	toastErrorDuration = 6 * time.Second // Errors stay longer so they can be read
+	maxToasts          = 3               // Older toasts are dropped first
+)
+
+type toastKind int
+
+const (
+	toastInfo toastKind = iota
+	toastSuccess
+	toastError
+)
+
+// toast is a notification shown in the top right corner of every view
+type toast struct {
+	id   int
+	text string
+	kind toastKind
+}
+
+// toastMsg asks the root model to show a toast
+type toastMsg struct {
+	text string
+	kind toastKind
+}
+
+// toastExpiredMsg dismisses a toast
+type toastExpiredMsg struct {
+	id int
+}
+
+// notify returns a command that shows a toast
+func notify(kind toastKind, format string, args ...any) tea.Cmd {
+	text := trf(format, args...)
+	return func() tea.Msg { return toastMsg{text: text, kind: kind} }
+}
+
+// addToast shows a toast and schedules its dismissal
+func (m *Model) addToast(text string, kind toastKind) tea.Cmd {
+	m.toastID++
+	m.toasts = append(m.toasts, toast{id: m.toastID, text: text, kind: kind})
+	if len(m.toasts) > maxToasts {
+		m.toasts = m.toasts[len(m.toasts)-maxToasts:]
+	}
+
+	duration := toastDuration
+	if kind == toastError {
+		duration = toastErrorDuration
+	}
+	id := m.toastID
+	return tea.Tick(duration, func(time.Time) tea.Msg { return toastExpiredMsg{id: id} })
+}
+
+// dismissToast removes an expired toast
+func (m *Model) dismissToast(id int) {
+	for i, t := range m.toasts {
+		if t.id == id {
+			m.toasts = append(m.toasts[:i:i], m.toasts[i+1:]...)
+			return
+		}
+	}
+}
+
+// withToasts draws the toasts over the top right corner of a view
+func (m *Model) withToasts(view string) string {
+	if len(m.toasts) == 0 || m.config.ActiveTheme == nil {
+		return view
+	}
+
+	theme := m.config.ActiveTheme
+	// All toasts share the width of the longest, up to half the screen
+	width := 0
+	for _, t := range m.toasts {
+		width = max(width, lipgloss.Width(t.text)+2)
+	}
+	width = min(width, max(20, m.width/2))
+	boxes := make([]string, len(m.toasts))
+	for i, t := range m.toasts {
+		color := theme.PrimaryColor
+		switch t.kind {
+		case toastSuccess:
+			color = theme.SecondaryColor
+		case toastError:
+			color = "9" // Themes have no error color, use the terminal's red
+		}
+		boxes[i] = lipgloss.NewStyle().
+			Border(panelBorder()).
+			BorderForeground(lipgloss.Color(color)).
+			Foreground(lipgloss.Color(theme.TextColor)).
+			Padding(0, 1).
+			Width(width).
+			Render(t.text)
+	}
+	stack := strings.Join(boxes, "\n")
+
+	return overlayAt(view, stack, max(0, m.width-lipgloss.Width(stack)-1), 1)
+}
+
+// configWarnings shows the problems found in the config at startup. Only
+// the first is shown in full, the full list comes from -check-config.
+func (m Model) configWarnings() tea.Cmd {
+	switch len(m.config.Warnings) {
+	case 0:
+		return nil
+	case 1:
+		return notify(toastError, "Config: %s", m.config.Warnings[0])
+	}
+	return tea.Batch(
+		notify(toastError, "Config: %s", m.config.Warnings[0]),
+		notify(toastError, "%d more problems in config.toml; run cozy -check-config to list them", len(m.config.Warnings)-1),
+	)
+}
@@ -0,0 +1,181 @@
+package tui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/cbrasser/cozy/ebook"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Deleting a book, its progress or a bookmark puts it in the trash of the
+// data directory, from which undo restores the last thing deleted. The
+// trash is only emptied on request.
+
+// addToTrash records something deleted
+func (m *Model) addToTrash(kind, book, file string, data any) error {
+	trash, err := config.LoadTrash(m.config)
+	if err != nil {
+		return err
+	}
+	if err := trash.Add(kind, book, file, data); err != nil {
+		return err
+	}
+	return config.SaveTrash(m.config, trash)
+}
+
+// undoHint tells how to undo a deletion
+func undoHint() string {
+	return trf("%s undoes", appKeys.Undo.Help().Key)
+}
+
+// trashBook moves a book file to the trash folder. Its reading progress
+// stays in the reading history.
+func (m *Model) trashBook(item bookItem) (string, tea.Cmd, error) {
+	file, err := ebook.MoveBook(item.path, m.config.TrashDir())
+	if err != nil {
+		return "", nil, err
+	}
+	if err := m.addToTrash(config.TrashBook, item.path, file, nil); err != nil {
+		return "", nil, err
+	}
+	return trf("Moved %s to the trash; %s", filepath.Base(item.path), undoHint()), m.library.loadBooks(), nil
+}
+
+// clearProgress forgets where a book was left, with its bookmarks
+func (m *Model) clearProgress(item bookItem) (string, tea.Cmd, error) {
+	progress, ok := m.library.progress.RemoveBook(item.path)
+	if !ok {
+		return "", nil, errorf("%s has no reading progress", item.title)
+	}
+	// The reader saves its copy too, which would bring the progress back
+	m.reader.progress.RemoveBook(item.path)
+	if err := m.addToTrash(config.TrashProgress, item.path, "", progress); err != nil {
+		return "", nil, err
+	}
+	if err := config.SaveProgress(m.config, m.library.progress); err != nil {
+		return "", nil, err
+	}
+	m.library.updateItems()
+	return trf("Cleared the progress of %s; %s", item.title, undoHint()), nil, nil
+}
+
+// removeBookmark deletes a bookmark of the open book
+func (m *Model) removeBookmark(name string) (string, tea.Cmd, error) {
+	bookmark, err := m.reader.RemoveBookmark(name)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := m.addToTrash(config.TrashBookmark, m.reader.book.Path, "", bookmark); err != nil {
+		return "", nil, err
+	}
+	return trf("Bookmark %q deleted; %s", name, undoHint()), nil, nil
+}
+
+// undo restores the last thing deleted
+func (m *Model) undo() (string, tea.Cmd, error) {
+	trash, err := config.LoadTrash(m.config)
+	if err != nil {
+		return "", nil, err
+	}
+	item, ok := trash.Last()
+	if !ok {
+		return "", nil, errorf("nothing to undo")
+	}
+
+	var message string
+	var cmd tea.Cmd
+	switch item.Kind {
+	case config.TrashBook:
+		if err := os.MkdirAll(filepath.Dir(item.Book), 0755); err != nil {
+			return "", nil, err
+		}
+		if err := ebook.RenameBook(item.File, item.Book); err != nil {
+			return "", nil, err
+		}
+		message = trf("Restored %s", filepath.Base(item.Book))
+		cmd = m.library.loadBooks()
+
+	case config.TrashProgress:
+		var progress config.BookProgress
+		if err := json.Unmarshal(item.Data, &progress); err != nil {
+			return "", nil, err
+		}
+		m.library.progress.RestoreBook(progress)
+		m.reader.progress.RestoreBook(progress)
+		if err := config.SaveProgress(m.config, m.library.progress); err != nil {
+			return "", nil, err
+		}
+		m.library.updateItems()
+		message = trf("Restored the progress of %s", filepath.Base(item.Book))
+
+	case config.TrashBookmark:
+		var bookmark config.Bookmark
+		if err := json.Unmarshal(item.Data, &bookmark); err != nil {
+			return "", nil, err
+		}
+		m.reader.progress.AddBookmark(item.Book, bookmark)
+		if err := config.SaveProgress(m.config, m.reader.progress); err != nil {
+			return "", nil, err
+		}
+		message = trf("Restored bookmark %q", bookmark.Name)
+
+	default:
+		return "", nil, errorf("can't restore a %s", item.Kind)
+	}
+
+	trash.DropLast()
+	return message, cmd, config.SaveTrash(m.config, trash)
+}
+
+// undoKey runs undo from its key binding, reporting the outcome as a toast
+func (m *Model) undoKey() tea.Cmd {
+	message, cmd, err := m.undo()
+	if err != nil {
+		return tea.Batch(cmd, notify(toastError, "Undo: %v", err))
+	}
+	return tea.Batch(cmd, notify(toastSuccess, "%s", message))
+}
+
+// runTrash reports what's in the trash, or empties it
+func runTrash(m *Model, args []string) (string, tea.Cmd, error) {
+	trash, err := config.LoadTrash(m.config)
+	if err != nil {
+		return "", nil, err
+	}
+	switch {
+	case len(args) == 0:
+		if len(trash.Items) == 0 {
+			return tr("The trash is empty"), nil, nil
+		}
+		return trf("%d deleted item(s) in the trash; %s restores the last", len(trash.Items), appKeys.Undo.Help().Key), nil, nil
+	case len(args) == 1 && args[0] == "empty":
+		if len(trash.Items) == 0 {
+			return tr("The trash is empty"), nil, nil
+		}
+		question := trf("Delete the %d item(s) in the trash for good?", len(trash.Items))
+		return m.confirm(question, func() (string, tea.Cmd, error) {
+			trash, err := config.LoadTrash(m.config)
+			if err != nil {
+				return "", nil, err
+			}
+			// Books deleted for good take their synced data along
+			for _, item := range trash.Items {
+				if _, err := os.Stat(item.Book); item.Kind == config.TrashBook && os.IsNotExist(err) {
+					config.RemoveSyncedBook(m.config, item.Book)
+				}
+			}
+			emptyErr := trash.Empty()
+			if err := config.SaveTrash(m.config, trash); err != nil {
+				return "", nil, err
+			}
+			if emptyErr != nil {
+				return "", nil, emptyErr
+			}
+			return tr("Emptied the trash"), nil, nil
+		})
+	}
+	return "", nil, errorf("usage: %s", "trash [empty]")
+}
@@ -0,0 +1,177 @@
+package tui
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/cbrasser/cozy/ebook"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Language learning: with reading.vocabulary on, m in the word cursor marks
+// the word under it as unknown, or unmarks it. Words go in the vocabulary
+// list of the book's language with the sentence they were found in, and
+// are underlined wherever they come up again, in any book in that
+// language.
+
+// Characters that end a sentence, and those that may close one after them
+const (
+	sentenceEnds   = ".!?…。！？"
+	sentenceCloses = "\"'”’»)]"
+)
+
+// bookLanguage returns the vocabulary list of the open book
+func (m *ReaderModel) bookLanguage() string {
+	if m.book == nil {
+		return config.UnknownLanguage
+	}
+	return config.VocabularyLanguage(m.book.Metadata["language"])
+}
+
+// toggleVocabulary marks the word under the cursor as unknown, or unmarks
+// it if it already is
+func (m *ReaderModel) toggleVocabulary() tea.Cmd {
+	word, ok := m.cursorWord()
+	if !ok || m.book == nil || strings.IndexFunc(word.text, unicode.IsLetter) < 0 {
+		return nil
+	}
+	language := m.bookLanguage()
+
+	if m.vocabulary.Remove(language, word.text) {
+		m.status = trf("Removed %q from the vocabulary", word.text)
+	} else {
+		m.vocabulary.Add(language, config.VocabularyWord{
+			Word:    word.text,
+			Context: m.sentenceAt(m.lookup.line, word),
+			Book:    m.book.Title,
+			Added:   time.Now(),
+		})
+		m.status = trf("Added %q to the vocabulary (%s)", word.text, language)
+	}
+	if err := config.SaveVocabulary(m.config, m.vocabulary); err != nil {
+		m.status = trf("Could not save vocabulary: %v", err)
+	}
+	m.vocabularyWords = m.vocabulary.Words(language)
+	return nil
+}
+
+// sentenceAt returns the sentence a word on a rendered line is in, looking
+// no further than its paragraph
+func (m *ReaderModel) sentenceAt(line int, word wordSpan) string {
+	blank := func(l int) bool { return strings.TrimSpace(ansi.Strip(m.lines[l])) == "" }
+	first, last := line, line
+	for first > 0 && !blank(first-1) {
+		first--
+	}
+	for last < len(m.lines)-1 && !blank(last+1) {
+		last++
+	}
+
+	// Join the lines of the paragraph, noting where the word is
+	var paragraph strings.Builder
+	offset := 0
+	for l := first; l <= last; l++ {
+		text := ansi.Strip(m.lines[l])
+		indent := len(text) - len(strings.TrimLeft(text, " "))
+		if l == line {
+			offset = paragraph.Len() + len(ansi.Strip(ansi.Truncate(m.lines[l], word.start, ""))) - indent
+		}
+		paragraph.WriteString(strings.TrimSpace(text) + " ")
+	}
+	text := paragraph.String()
+	offset = max(0, min(offset, len(text)))
+
+	start := 0
+	for i := offset - 1; i > 0; i-- {
+		if unicode.IsSpace(rune(text[i])) && endsSentence(strings.TrimRight(text[:i], sentenceCloses)) {
+			start = i
+			break
+		}
+	}
+	end := len(text)
+	for i := offset; i < len(text); i++ {
+		if unicode.IsSpace(rune(text[i])) && endsSentence(strings.TrimRight(text[:i], sentenceCloses)) {
+			end = i
+			break
+		}
+	}
+
+	sentence := text[start:end]
+	if renderOptions(m.config).LetterSpacing {
+		// Letters are spaced apart, see ebook/spacing.go
+		sentence = strings.ReplaceAll(sentence, "\u00a0", "")
+	}
+	return strings.Join(strings.Fields(sentence), " ")
+}
+
+// endsSentence reports whether text ends with sentence punctuation
+func endsSentence(text string) bool {
+	for _, end := range sentenceEnds {
+		if strings.HasSuffix(text, string(end)) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyVocabulary underlines the words of the vocabulary in the viewport
+// view
+func (m *ReaderModel) applyVocabulary(view string) string {
+	if !m.config.Reading.Vocabulary || len(m.vocabularyWords) == 0 {
+		return view
+	}
+
+	theme := m.config.ActiveTheme
+	style := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.SecondaryColor)).
+		Underline(true)
+
+	lines := strings.Split(view, "\n")
+	for row := range lines {
+		line := m.viewport.YOffset + row
+		if line >= len(m.lines) {
+			break
+		}
+		for _, word := range lineWords(m.lines[line]) {
+			if m.vocabularyWords[strings.ToLower(word.text)] {
+				lines[row] = styleColumns(lines[row], word.start, word.end, style)
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// exportVocabulary writes vocabulary lists to Anki TSV files in the
+// background, see ebook.ExportVocabulary. Without languages it exports
+// them all.
+func exportVocabulary(cfg *config.Config, languages []string) tea.Cmd {
+	return func() tea.Msg {
+		vocabulary, err := config.LoadVocabulary(cfg)
+		if err != nil {
+			return toastMsg{text: trf("Could not read vocabulary: %v", err), kind: toastError}
+		}
+		if len(languages) == 0 {
+			languages = vocabulary.LanguageList()
+		}
+
+		dest := cfg.HighlightsExportDir()
+		words := 0
+		for _, language := range languages {
+			if len(vocabulary.Languages[language]) == 0 {
+				continue
+			}
+			if _, err := ebook.ExportVocabulary(dest, language, vocabulary.Languages[language]); err != nil {
+				return toastMsg{text: trf("Could not export vocabulary: %v", err), kind: toastError}
+			}
+			words += len(vocabulary.Languages[language])
+		}
+		if words == 0 {
+			return toastMsg{text: tr("No vocabulary to export"), kind: toastInfo}
+		}
+		return toastMsg{text: trf("Exported %d word(s) to %s", words, dest), kind: toastSuccess}
+	}
+}
@@ -0,0 +1,112 @@
+package tui
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cbrasser/cozy/ebook"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fsnotify/fsnotify"
+)
+
+// Changes are collected this long before rescanning, so copying a folder
+// of books causes one rescan rather than one per file
+const libraryRescanDelay = 500 * time.Millisecond
+
+// libraryChangedMsg reports a change in the library directory
+type libraryChangedMsg struct{}
+
+// libraryRescanMsg rescans the library once changes have settled
+type libraryRescanMsg struct {
+	timer int
+}
+
+// startWatching watches the library directory for books being added,
+// removed or changed. Without a watcher the library can still be
+// refreshed with "r".
+func (m *LibraryModel) startWatching() tea.Cmd {
+	if !m.config.Library.Watch || m.watcher != nil {
+		return nil
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil
+	}
+	m.watcher = watcher
+	m.watchDirectories()
+	return m.waitForChange()
+}
+
+// watchDirectories adds every folder of the library to the watcher, since
+// fsnotify does not watch subfolders. New folders are picked up after the
+// rescan their creation triggers. Hidden folders are left out.
+func (m *LibraryModel) watchDirectories() {
+	if m.watcher == nil {
+		return
+	}
+	filepath.WalkDir(m.config.Library.Path, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() {
+			return nil
+		}
+		if path != m.config.Library.Path && strings.HasPrefix(entry.Name(), ".") {
+			return filepath.SkipDir // Data kept in the library, see ebook.ListBooks
+		}
+		m.watcher.Add(path)
+		return nil
+	})
+}
+
+// waitForChange waits for the next change that could affect the library
+func (m *LibraryModel) waitForChange() tea.Cmd {
+	watcher := m.watcher
+	return func() tea.Msg {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return nil
+				}
+				if affectsLibrary(event) {
+					return libraryChangedMsg{}
+				}
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// affectsLibrary reports whether a file event can change the book list.
+// Folders have no extension; hidden files and data files are ignored so
+// saving progress inside the library doesn't cause rescans.
+func affectsLibrary(event fsnotify.Event) bool {
+	name := filepath.Base(event.Name)
+	if strings.HasPrefix(name, ".") || event.Op == fsnotify.Chmod {
+		return false
+	}
+	if filepath.Ext(name) == "" || ebook.IsBookFile(name) {
+		return true
+	}
+	return name == "metadata.db" // Calibre's database
+}
+
+// updateWatch handles messages from the library watcher
+func (m *LibraryModel) updateWatch(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case libraryChangedMsg:
+		m.rescanTimer++
+		timer := m.rescanTimer
+		return tea.Batch(m.waitForChange(), tea.Tick(libraryRescanDelay, func(time.Time) tea.Msg {
+			return libraryRescanMsg{timer: timer}
+		}))
+	case libraryRescanMsg:
+		if msg.timer == m.rescanTimer {
+			return m.loadBooks()
+		}
+	}
+	return nil
+}
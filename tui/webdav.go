@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"github.com/cbrasser/cozy/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// webdavSyncedMsg reports the end of a WebDAV sync
+type webdavSyncedMsg struct {
+	result config.WebDAVSyncResult
+	err    error
+}
+
+// syncWebDAV saves what is open and syncs with the WebDAV server in the
+// background
+func (m *Model) syncWebDAV() tea.Cmd {
+	if m.config.Sync.WebDAVURL == "" {
+		return m.addToast(tr("Set sync.webdav_url in config.toml to sync"), toastError)
+	}
+	if m.reader.book != nil {
+		if err := m.reader.SaveProgress(); err != nil {
+			return m.addToast(trf("Could not save progress: %v", err), toastError)
+		}
+	}
+	m.journal.save()
+
+	cfg := m.config
+	run := func() tea.Msg {
+		result, err := config.SyncWebDAV(cfg)
+		return webdavSyncedMsg{result: result, err: err}
+	}
+	return tea.Batch(m.addToast(tr("Syncing…"), toastInfo), run)
+}
+
+// applyWebDAVSync reads the synced progress and highlights. The open book
+// moves to the synced position if another machine saved it later.
+func (m *Model) applyWebDAVSync(msg webdavSyncedMsg) tea.Cmd {
+	if msg.err != nil {
+		return m.addToast(trf("Could not sync: %v", msg.err), toastError)
+	}
+
+	progress, err := config.LoadProgress(m.config)
+	if err != nil {
+		return m.addToast(trf("Could not read synced progress: %v", err), toastError)
+	}
+	if book := m.reader.book; book != nil {
+		saved := m.reader.progress.Books[book.Path]
+		synced, ok := progress.GetBookProgress(book.Path)
+		m.reader.progress = progress
+		if ok && synced.UpdatedAt.After(saved.UpdatedAt) && synced.CurrentChapter < book.ChapterCount() {
+			m.reader.currentChapter = synced.CurrentChapter
+			m.reader.updateViewport()
+			m.reader.restorePosition(synced.ScrollOffset, synced.TextOffset)
+		}
+	} else {
+		m.reader.progress = progress
+	}
+	if err := config.SaveProgress(m.config, m.reader.progress); err != nil {
+		return m.addToast(trf("Could not save progress: %v", err), toastError)
+	}
+	m.library.reloadProgress()
+
+	if highlights, err := config.LoadHighlights(m.config); err == nil {
+		m.reader.highlights = highlights
+		if m.reader.book != nil {
+			m.reader.locateHighlights()
+		}
+	}
+
+	return m.addToast(trf("Synced: %d file(s) downloaded, %d uploaded", msg.result.Downloaded, msg.result.Uploaded), toastSuccess)
+}
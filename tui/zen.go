@@ -0,0 +1,88 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	zenTextWidth = 72 // Widest line in zen mode
+	zenFlashTime = 1500 * time.Millisecond
+)
+
+// zenState tracks the progress flash in zen mode
+type zenState struct {
+	flashTimer int // Identifies the current flash so stale ticks are ignored
+	flashing   bool
+}
+
+// zenFlashMsg ends a progress flash
+type zenFlashMsg struct {
+	timer int
+}
+
+// toggleZen switches zen mode and remembers it in the config
+func (m *ReaderModel) toggleZen() error {
+	m.config.Display.Zen = !m.config.Display.Zen
+	m.layout()
+	m.Refresh()
+	return saveToggle(m.config)
+}
+
+// zenMargins returns the left and right margin around the text in zen mode
+func (m *ReaderModel) zenMargins() (int, int) {
+	side := max(m.width/8, (m.width-zenTextWidth)/2)
+	return side, max(0, m.width-side-zenTextWidth)
+}
+
+// flashProgress briefly shows the progress line in zen mode
+func (m *ReaderModel) flashProgress() tea.Cmd {
+	m.zen.flashTimer++
+	m.zen.flashing = true
+	timer := m.zen.flashTimer
+	return tea.Tick(zenFlashTime, func(time.Time) tea.Msg {
+		return zenFlashMsg{timer: timer}
+	})
+}
+
+// textTop returns the screen row of the first line of text
+func (m *ReaderModel) textTop() int {
+	if m.config.Display.Zen {
+		return 1 // Blank line
+	}
+	return 3 // Book title, chapter title and rule
+}
+
+// textLeft returns the screen column of the start of the text
+func (m *ReaderModel) textLeft() int {
+	if renderOptions(m.config).DoubleWidth() {
+		return m.textIndent() * 2
+	}
+	left, _ := m.margins()
+	return left
+}
+
+// zenView renders only the text, with the progress line while flashing
+func (m *ReaderModel) zenView(content string) string {
+	progressStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.config.ActiveTheme.MutedTextColor)).
+		Width(m.viewport.Width).
+		Align(lipgloss.Center)
+
+	footer := ""
+	switch {
+	case m.status != "":
+		footer = progressStyle.Render(plain(m.status))
+	case m.lookup.prompt:
+		footer = m.lookup.input.View()
+	case m.find.active:
+		footer = m.findFooter()
+	case m.zen.flashing:
+		footer = progressStyle.Render(plain(m.progressText()))
+	}
+
+	return "\n" + lipgloss.NewStyle().PaddingLeft(m.textIndent()).Render(content) + "\n" +
+		lipgloss.NewStyle().PaddingLeft(m.textLeft()).Render(footer)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/cbrasser/cozy/config"
+	"github.com/cbrasser/cozy/ebook"
+)
+
+// runExportVocabulary writes the vocabulary lists of the language learning
+// mode to Anki TSV files, one per language
+func runExportVocabulary(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("export-vocabulary", flag.ContinueOnError)
+	dest := flags.String("to", cfg.HighlightsExportDir(), "destination folder (defaults to notes.export_dir)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: cozy export-vocabulary [-to dir] [language...]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	vocabulary, err := config.LoadVocabulary(cfg)
+	if err != nil {
+		return err
+	}
+
+	languages := vocabulary.LanguageList()
+	if flags.NArg() > 0 {
+		languages = nil
+		for _, language := range flags.Args() {
+			languages = append(languages, config.VocabularyLanguage(language))
+		}
+	}
+	if len(languages) == 0 {
+		return fmt.Errorf("no vocabulary to export")
+	}
+
+	for _, language := range languages {
+		words := vocabulary.Languages[language]
+		if len(words) == 0 {
+			fmt.Printf("skipped %s: no words\n", language)
+			continue
+		}
+		target, err := ebook.ExportVocabulary(*dest, language, words)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("exported %d words (%s) -> %s\n", len(words), language, target)
+	}
+	return nil
+}